@@ -8,4 +8,6 @@ import (
 func main() {
 	plugin.Serve(&plugin.ServeOpts{
 		ProviderFunc: vcd.Provider})
+	// Sends the spans that are still pending when Terraform stops the plugin
+	vcd.ShutdownTracing()
 }
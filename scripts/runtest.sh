@@ -82,11 +82,13 @@ function unit_test {
     then
         echo "go test -tags unit ${TEST} || exit 1"
         echo "go test -tags unit -v -timeout 5m"
+        echo "go test -tags unit -timeout 5m ./mockvcd"
     fi
     if [ -z "$DRY_RUN" ]
     then
         go test -tags unit ${TEST} || exit 1
-        go test -tags unit -v -timeout 5m
+        go test -tags unit -v -timeout 5m || exit 1
+        go test -tags unit -timeout 5m ./mockvcd
     fi
 }
 
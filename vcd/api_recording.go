@@ -0,0 +1,401 @@
+package vcd
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+const (
+	// apiRecordingModeRecord saves the API interactions to the recording file
+	apiRecordingModeRecord = "record"
+	// apiRecordingModeReplay serves the API interactions from the recording file, without contacting VCD
+	apiRecordingModeReplay = "replay"
+
+	// apiRecordingRedacted replaces the sensitive values in the recorded interactions
+	apiRecordingRedacted = "[REDACTED]"
+	// apiRecordingMaxBodySize is the biggest body that is recorded. Bigger bodies, such as file uploads, are omitted
+	apiRecordingMaxBodySize = 1024 * 1024
+)
+
+// apiRecordingSensitiveHeaders are the headers whose values are never recorded
+var apiRecordingSensitiveHeaders = []string{
+	"Authorization",
+	"Cookie",
+	"Set-Cookie",
+	"X-Vcloud-Authorization",
+	"X-Vmware-Vcloud-Access-Token",
+}
+
+// apiRecordingSensitiveName matches the names of JSON fields, XML elements and attributes, and form fields that
+// contain secrets
+var apiRecordingSensitiveName = regexp.MustCompile(`(?i)password|secret|token|passphrase|privateKey`)
+
+// These expressions find the XML elements and attributes, and the form fields, whose names are checked against
+// apiRecordingSensitiveName
+var (
+	apiRecordingXmlElement   = regexp.MustCompile(`(?s)<([\w:]+)(\s[^>]*)?>([^<]*)</([\w:]+)>`)
+	apiRecordingXmlAttribute = regexp.MustCompile(`([\w:]+)="([^"]*)"`)
+	apiRecordingFormField    = regexp.MustCompile(`(^|&)([^=&]+)=([^&]*)`)
+)
+
+// apiInteraction is a sanitized HTTP request and its response, as stored in the recording file
+type apiInteraction struct {
+	Method          string      `json:"method"`
+	Url             string      `json:"url"`
+	RequestHeaders  http.Header `json:"request_headers,omitempty"`
+	RequestBody     string      `json:"request_body,omitempty"`
+	StatusCode      int         `json:"status_code"`
+	ResponseHeaders http.Header `json:"response_headers,omitempty"`
+	ResponseBody    string      `json:"response_body,omitempty"`
+}
+
+// key returns the value used to match requests with recorded interactions. The host is ignored, so that
+// recordings can be replayed with a different URL
+func (interaction *apiInteraction) key() string {
+	return interaction.Method + " " + apiRecordingPath(interaction.Url)
+}
+
+// apiRecordingTransport is an http.RoundTripper that records the API interactions of the provider or replays them.
+// The recording file contains one JSON interaction per line. Recorded interactions are appended to the file, so
+// that several runs of the provider can be recorded together.
+// During replay, interactions with the same method and URL are served in the order in which they were recorded,
+// and the last one is repeated when all of them were served
+type apiRecordingTransport struct {
+	mode     string
+	fileName string
+	next     http.RoundTripper
+
+	mutex        sync.Mutex
+	interactions map[string][]*apiInteraction
+	served       map[string]int
+}
+
+// newApiRecordingTransport creates a transport for the given mode. In record mode, requests are sent with next
+func newApiRecordingTransport(mode, fileName string, next http.RoundTripper) (*apiRecordingTransport, error) {
+	if fileName == "" {
+		return nil, fmt.Errorf("the API recording file is required in '%s' mode", mode)
+	}
+	transport := &apiRecordingTransport{
+		mode:     mode,
+		fileName: fileName,
+		next:     next,
+	}
+	switch mode {
+	case apiRecordingModeRecord:
+		if transport.next == nil {
+			transport.next = http.DefaultTransport
+		}
+	case apiRecordingModeReplay:
+		interactions, err := readApiInteractions(fileName)
+		if err != nil {
+			return nil, err
+		}
+		transport.interactions = make(map[string][]*apiInteraction)
+		transport.served = make(map[string]int)
+		for _, interaction := range interactions {
+			transport.interactions[interaction.key()] = append(transport.interactions[interaction.key()], interaction)
+		}
+	default:
+		return nil, fmt.Errorf("invalid API recording mode '%s'. Valid values are '%s' and '%s'",
+			mode, apiRecordingModeRecord, apiRecordingModeReplay)
+	}
+	return transport, nil
+}
+
+// RoundTrip implements http.RoundTripper
+func (transport *apiRecordingTransport) RoundTrip(request *http.Request) (*http.Response, error) {
+	if transport.mode == apiRecordingModeReplay {
+		return transport.replay(request)
+	}
+	return transport.record(request)
+}
+
+// record sends the request and records it with its response. The bodies are streamed to their readers, keeping only
+// the part that can be recorded, so that file uploads and downloads are never held in memory. The interaction is saved
+// when the response body is closed
+func (transport *apiRecordingTransport) record(request *http.Request) (*http.Response, error) {
+	requestBody := &apiRecordingBuffer{}
+	if request.Body != nil && request.Body != http.NoBody {
+		request = request.Clone(request.Context())
+		request.Body = &apiRecordingBody{ReadCloser: request.Body, buffer: requestBody}
+	}
+
+	response, err := transport.next.RoundTrip(request)
+	if err != nil {
+		return nil, err
+	}
+
+	responseBody := &apiRecordingBuffer{}
+	save := func() error {
+		requestData, requestSize := requestBody.content()
+		responseData, responseSize := responseBody.content()
+		return transport.save(&apiInteraction{
+			Method:          request.Method,
+			Url:             request.URL.String(),
+			RequestHeaders:  sanitizeApiRecordingHeaders(request.Header),
+			RequestBody:     sanitizeApiRecordingBody(requestData, requestSize),
+			StatusCode:      response.StatusCode,
+			ResponseHeaders: sanitizeApiRecordingHeaders(response.Header),
+			ResponseBody:    sanitizeApiRecordingBody(responseData, responseSize),
+		})
+	}
+	if response.Body == nil {
+		err = save()
+		if err != nil {
+			return nil, err
+		}
+		return response, nil
+	}
+	response.Body = &apiRecordingBody{ReadCloser: response.Body, buffer: responseBody, onClose: save}
+	return response, nil
+}
+
+// apiRecordingBuffer keeps the beginning of a streamed body, up to one byte more than apiRecordingMaxBodySize, and
+// counts its total size. Request bodies are read by the transport in its own goroutine, hence the mutex
+type apiRecordingBuffer struct {
+	mutex sync.Mutex
+	data  []byte
+	size  int64
+}
+
+// Write implements io.Writer. It never fails, as the data beyond the limit is only counted
+func (buffer *apiRecordingBuffer) Write(data []byte) (int, error) {
+	buffer.mutex.Lock()
+	defer buffer.mutex.Unlock()
+	buffer.size += int64(len(data))
+	if room := apiRecordingMaxBodySize + 1 - len(buffer.data); room > 0 {
+		buffer.data = append(buffer.data, data[:min(room, len(data))]...)
+	}
+	return len(data), nil
+}
+
+// content returns the kept data and the total size of the body
+func (buffer *apiRecordingBuffer) content() ([]byte, int64) {
+	buffer.mutex.Lock()
+	defer buffer.mutex.Unlock()
+	return buffer.data, buffer.size
+}
+
+// full returns true when the buffer kept all the data it can
+func (buffer *apiRecordingBuffer) full() bool {
+	buffer.mutex.Lock()
+	defer buffer.mutex.Unlock()
+	return len(buffer.data) > apiRecordingMaxBodySize
+}
+
+// apiRecordingBody passes a body through to its reader, copying what is read to a buffer. onClose, when set, is
+// called once when the body is closed
+type apiRecordingBody struct {
+	io.ReadCloser
+	buffer  *apiRecordingBuffer
+	onClose func() error
+	closed  sync.Once
+}
+
+func (body *apiRecordingBody) Read(data []byte) (int, error) {
+	n, err := body.ReadCloser.Read(data)
+	_, _ = body.buffer.Write(data[:n])
+	return n, err
+}
+
+// Close closes the body and calls onClose. A small body that was not read to the end (e.g. by a JSON decoder) is
+// read up to the recording limit, so that it is recorded whole
+func (body *apiRecordingBody) Close() error {
+	var err error
+	body.closed.Do(func() {
+		if body.onClose != nil && !body.buffer.full() {
+			_, _ = io.Copy(body.buffer, io.LimitReader(body.ReadCloser, apiRecordingMaxBodySize+1))
+		}
+		err = body.ReadCloser.Close()
+		if body.onClose != nil {
+			if saveErr := body.onClose(); err == nil {
+				err = saveErr
+			}
+		}
+	})
+	return err
+}
+
+func (transport *apiRecordingTransport) save(interaction *apiInteraction) error {
+	line, err := json.Marshal(interaction)
+	if err != nil {
+		return fmt.Errorf("error encoding API interaction: %s", err)
+	}
+
+	transport.mutex.Lock()
+	defer transport.mutex.Unlock()
+	file, err := os.OpenFile(transport.fileName, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return fmt.Errorf("error opening API recording file '%s': %s", transport.fileName, err)
+	}
+	_, err = file.Write(append(line, '\n'))
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return fmt.Errorf("error writing API recording file '%s': %s", transport.fileName, err)
+	}
+	return nil
+}
+
+func (transport *apiRecordingTransport) replay(request *http.Request) (*http.Response, error) {
+	if request.Body != nil {
+		_ = request.Body.Close()
+	}
+	key := request.Method + " " + apiRecordingPath(request.URL.String())
+
+	transport.mutex.Lock()
+	interactions := transport.interactions[key]
+	if len(interactions) == 0 {
+		transport.mutex.Unlock()
+		return nil, fmt.Errorf("no recorded API interaction for '%s' in '%s'", key, transport.fileName)
+	}
+	index := min(transport.served[key], len(interactions)-1)
+	transport.served[key]++
+	transport.mutex.Unlock()
+
+	interaction := interactions[index]
+	header := interaction.ResponseHeaders.Clone()
+	if header == nil {
+		header = http.Header{}
+	}
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", interaction.StatusCode, http.StatusText(interaction.StatusCode)),
+		StatusCode:    interaction.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(strings.NewReader(interaction.ResponseBody)),
+		ContentLength: int64(len(interaction.ResponseBody)),
+		Request:       request,
+	}, nil
+}
+
+// readApiInteractions reads all the interactions of a recording file
+func readApiInteractions(fileName string) ([]*apiInteraction, error) {
+	file, err := os.Open(fileName) // #nosec G304 -- the file is chosen by the user in the provider configuration
+	if err != nil {
+		return nil, fmt.Errorf("error opening API recording file '%s': %s", fileName, err)
+	}
+	defer func() { _ = file.Close() }()
+
+	var interactions []*apiInteraction
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(nil, 4*apiRecordingMaxBodySize)
+	for lineNumber := 1; scanner.Scan(); lineNumber++ {
+		if strings.TrimSpace(scanner.Text()) == "" {
+			continue
+		}
+		var interaction apiInteraction
+		err = json.Unmarshal(scanner.Bytes(), &interaction)
+		if err != nil {
+			return nil, fmt.Errorf("error decoding line %d of API recording file '%s': %s", lineNumber, fileName, err)
+		}
+		interactions = append(interactions, &interaction)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("error reading API recording file '%s': %s", fileName, err)
+	}
+	return interactions, nil
+}
+
+// apiRecordingPath returns the given URL without scheme and host
+func apiRecordingPath(rawUrl string) string {
+	if schemeEnd := strings.Index(rawUrl, "://"); schemeEnd >= 0 {
+		rawUrl = rawUrl[schemeEnd+3:]
+		if pathStart := strings.Index(rawUrl, "/"); pathStart >= 0 {
+			return rawUrl[pathStart:]
+		}
+		return "/"
+	}
+	return rawUrl
+}
+
+// sanitizeApiRecordingHeaders returns a copy of the headers in which the credentials are redacted
+func sanitizeApiRecordingHeaders(header http.Header) http.Header {
+	sanitized := header.Clone()
+	for _, name := range apiRecordingSensitiveHeaders {
+		if sanitized.Get(name) != "" {
+			sanitized.Set(name, apiRecordingRedacted)
+		}
+	}
+	return sanitized
+}
+
+// sanitizeApiRecordingBody returns the body as text, with the values of sensitive fields redacted. JSON, XML and
+// form encoded bodies are supported. Bodies that are not text or are too big are omitted. The body can be truncated,
+// while size is always its full size
+func sanitizeApiRecordingBody(body []byte, size int64) string {
+	if size == 0 {
+		return ""
+	}
+	if size > apiRecordingMaxBodySize || bytes.IndexByte(body, 0) >= 0 {
+		return fmt.Sprintf("[%d bytes omitted]", size)
+	}
+
+	var value interface{}
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	// Numbers are kept as they are, as big integers would lose precision as float64
+	decoder.UseNumber()
+	if decoder.Decode(&value) == nil && !decoder.More() {
+		sanitized, err := json.Marshal(sanitizeApiRecordingJson(value))
+		if err == nil {
+			return string(sanitized)
+		}
+	}
+
+	text := string(body)
+	if strings.HasPrefix(strings.TrimSpace(text), "<") {
+		text = apiRecordingXmlElement.ReplaceAllStringFunc(text, func(element string) string {
+			parts := apiRecordingXmlElement.FindStringSubmatch(element)
+			if !apiRecordingSensitiveName.MatchString(parts[1]) {
+				return element
+			}
+			return fmt.Sprintf("<%s%s>%s</%s>", parts[1], parts[2], apiRecordingRedacted, parts[4])
+		})
+		return apiRecordingXmlAttribute.ReplaceAllStringFunc(text, func(attribute string) string {
+			parts := apiRecordingXmlAttribute.FindStringSubmatch(attribute)
+			if !apiRecordingSensitiveName.MatchString(parts[1]) {
+				return attribute
+			}
+			return fmt.Sprintf(`%s="%s"`, parts[1], apiRecordingRedacted)
+		})
+	}
+	return apiRecordingFormField.ReplaceAllStringFunc(text, func(field string) string {
+		parts := apiRecordingFormField.FindStringSubmatch(field)
+		if !apiRecordingSensitiveName.MatchString(parts[2]) {
+			return field
+		}
+		return parts[1] + parts[2] + "=" + apiRecordingRedacted
+	})
+}
+
+// sanitizeApiRecordingJson redacts the values of the JSON fields whose name denotes a secret
+func sanitizeApiRecordingJson(value interface{}) interface{} {
+	switch typedValue := value.(type) {
+	case map[string]interface{}:
+		for key, item := range typedValue {
+			if apiRecordingSensitiveName.MatchString(key) && item != nil {
+				if _, isObject := item.(map[string]interface{}); !isObject {
+					typedValue[key] = apiRecordingRedacted
+					continue
+				}
+			}
+			typedValue[key] = sanitizeApiRecordingJson(item)
+		}
+	case []interface{}:
+		for index, item := range typedValue {
+			typedValue[index] = sanitizeApiRecordingJson(item)
+		}
+	}
+	return value
+}
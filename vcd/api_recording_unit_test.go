@@ -0,0 +1,188 @@
+//go:build unit || ALL
+
+package vcd
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/vmware/terraform-provider-vcd/v4/vcd/mockvcd"
+)
+
+func Test_sanitizeApiRecordingBody(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+		want string
+	}{
+		{
+			name: "json",
+			body: `{"name":"user1","password":"secret1","nested":{"refresh_token":"abc","size":12345678901234567890}}`,
+			want: `{"name":"user1","nested":{"refresh_token":"[REDACTED]","size":12345678901234567890},"password":"[REDACTED]"}`,
+		},
+		{
+			name: "xml",
+			body: `<User name="user1"><Password>secret1</Password><FullName>User</FullName></User>`,
+			want: `<User name="user1"><Password>[REDACTED]</Password><FullName>User</FullName></User>`,
+		},
+		{
+			name: "xml attribute",
+			body: `<Settings adminPassword="secret1" enabled="true"/>`,
+			want: `<Settings adminPassword="[REDACTED]" enabled="true"/>`,
+		},
+		{
+			name: "form",
+			body: `grant_type=refresh_token&refresh_token=abc`,
+			want: `grant_type=refresh_token&refresh_token=[REDACTED]`,
+		},
+		{
+			name: "binary",
+			body: "\x00\x01\x02",
+			want: "[3 bytes omitted]",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := sanitizeApiRecordingBody([]byte(tt.body), int64(len(tt.body))); got != tt.want {
+				t.Errorf("sanitizeApiRecordingBody() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_sanitizeApiRecordingHeaders(t *testing.T) {
+	header := http.Header{}
+	header.Set("Authorization", "Bearer abc")
+	header.Set("X-Vmware-Vcloud-Access-Token", "abc")
+	header.Set("Accept", "application/json")
+	sanitized := sanitizeApiRecordingHeaders(header)
+	if sanitized.Get("Authorization") != apiRecordingRedacted || sanitized.Get("X-Vmware-Vcloud-Access-Token") != apiRecordingRedacted {
+		t.Errorf("credentials were not redacted: %v", sanitized)
+	}
+	if sanitized.Get("Accept") != "application/json" {
+		t.Errorf("unexpected change in header 'Accept': %v", sanitized)
+	}
+	if header.Get("Authorization") != "Bearer abc" {
+		t.Errorf("the original headers were modified: %v", header)
+	}
+}
+
+func Test_apiRecordingTransport(t *testing.T) {
+	server := mockvcd.NewServer()
+	roleId := server.AddEntity("1.0.0/globalRoles", map[string]interface{}{"name": "role1"})
+	fileName := filepath.Join(t.TempDir(), "recording.jsonl")
+	config := Config{
+		User:             mockvcd.DefaultUser,
+		Password:         mockvcd.DefaultPassword,
+		SysOrg:           "System",
+		Href:             server.ApiHref(),
+		InsecureFlag:     true,
+		ApiRecordingMode: apiRecordingModeRecord,
+		ApiRecordingFile: fileName,
+	}
+
+	vcdClient, err := config.Client()
+	if err != nil {
+		t.Fatalf("error connecting in record mode: %s", err)
+	}
+	role, err := vcdClient.Client.GetGlobalRoleByName("role1")
+	if err != nil {
+		t.Fatalf("error retrieving role in record mode: %s", err)
+	}
+	server.Close()
+
+	recording, err := os.ReadFile(filepath.Clean(fileName))
+	if err != nil {
+		t.Fatalf("error reading recording: %s", err)
+	}
+	for _, secret := range []string{mockvcd.DefaultPassword, vcdClient.Client.VCDToken} {
+		if strings.Contains(string(recording), secret) {
+			t.Errorf("the recording contains the secret '%s'", secret)
+		}
+	}
+
+	// The server is closed, so the replayed session can only use the recording
+	config.ApiRecordingMode = apiRecordingModeReplay
+	vcdClient, err = config.Client()
+	if err != nil {
+		t.Fatalf("error connecting in replay mode: %s", err)
+	}
+	replayedRole, err := vcdClient.Client.GetGlobalRoleByName("role1")
+	if err != nil {
+		t.Fatalf("error retrieving role in replay mode: %s", err)
+	}
+	if replayedRole.GlobalRole.Id != roleId || replayedRole.GlobalRole.Id != role.GlobalRole.Id {
+		t.Errorf("replayed role '%s', expected '%s'", replayedRole.GlobalRole.Id, roleId)
+	}
+	_, err = vcdClient.Client.GetGlobalRoleByName("role2")
+	if err == nil || !strings.Contains(err.Error(), "no recorded API interaction") {
+		t.Errorf("expected error for a request that was not recorded, got %v", err)
+	}
+}
+
+// apiRecordingRoundTripper is an http.RoundTripper implemented by a function
+type apiRecordingRoundTripper func(*http.Request) (*http.Response, error)
+
+func (roundTripper apiRecordingRoundTripper) RoundTrip(request *http.Request) (*http.Response, error) {
+	return roundTripper(request)
+}
+
+// Test_apiRecordingTransportBigBodies checks that bodies bigger than the recording limit are passed through whole,
+// and recorded only with their size
+func Test_apiRecordingTransportBigBodies(t *testing.T) {
+	fileName := filepath.Join(t.TempDir(), "recording.jsonl")
+	upload := bytes.Repeat([]byte("u"), 3*apiRecordingMaxBodySize)
+	download := bytes.Repeat([]byte("d"), 2*apiRecordingMaxBodySize+1)
+	var received []byte
+	transport, err := newApiRecordingTransport(apiRecordingModeRecord, fileName,
+		apiRecordingRoundTripper(func(request *http.Request) (*http.Response, error) {
+			var err error
+			received, err = io.ReadAll(request.Body)
+			if err != nil {
+				return nil, err
+			}
+			return &http.Response{StatusCode: http.StatusOK, Header: http.Header{},
+				Body: io.NopCloser(bytes.NewReader(download))}, nil
+		}))
+	if err != nil {
+		t.Fatalf("error creating transport: %s", err)
+	}
+
+	request, err := http.NewRequest(http.MethodPut, "https://vcd.example.com/transfer/1/disk.vmdk", bytes.NewReader(upload))
+	if err != nil {
+		t.Fatalf("error creating request: %s", err)
+	}
+	response, err := transport.RoundTrip(request)
+	if err != nil {
+		t.Fatalf("error sending request: %s", err)
+	}
+	body, err := io.ReadAll(response.Body)
+	if err != nil {
+		t.Fatalf("error reading response: %s", err)
+	}
+	if err := response.Body.Close(); err != nil {
+		t.Fatalf("error closing response: %s", err)
+	}
+	if !bytes.Equal(received, upload) || !bytes.Equal(body, download) {
+		t.Errorf("the bodies were changed: sent %d of %d bytes, received %d of %d bytes",
+			len(received), len(upload), len(body), len(download))
+	}
+
+	interactions, err := readApiInteractions(fileName)
+	if err != nil {
+		t.Fatalf("error reading recording: %s", err)
+	}
+	if len(interactions) != 1 {
+		t.Fatalf("expected one interaction, got %d", len(interactions))
+	}
+	recorded, _ := json.Marshal(interactions[0])
+	if interactions[0].RequestBody != "[3145728 bytes omitted]" || interactions[0].ResponseBody != "[2097153 bytes omitted]" {
+		t.Errorf("expected the bodies to be omitted, got: %s", recorded)
+	}
+}
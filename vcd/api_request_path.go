@@ -0,0 +1,343 @@
+package vcd
+
+// This file contains the evaluation of the simple JSONPath and XPath expressions used by the vcd_api_request data source.
+// Only the subset of both languages that is needed to pick values from VCD responses is supported.
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// jsonPathStep is a single element of a JSONPath expression
+type jsonPathStep struct {
+	key       string // name of the key to follow. Empty when 'index' or 'wildcard' are used
+	index     int    // list index to follow, when 'isIndex' is true. Negative values count from the end
+	isIndex   bool
+	wildcard  bool // follows all the keys of an object or all the items of a list
+	recursive bool // looks for the step at any depth (`..`)
+}
+
+// parseJsonPath splits a JSONPath expression, such as `$.values[*].name` or `$..href`, into steps.
+// Supported syntax: root `$`, child `.key` or `['key']`, index `[n]`, wildcards `.*` and `[*]`, and recursive descent `..`
+func parseJsonPath(expression string) ([]jsonPathStep, error) {
+	if !strings.HasPrefix(expression, "$") {
+		return nil, fmt.Errorf("JSONPath expression '%s' must start with '$'", expression)
+	}
+	var steps []jsonPathStep
+	rest := expression[1:]
+	for rest != "" {
+		step := jsonPathStep{}
+		switch {
+		case strings.HasPrefix(rest, ".."):
+			step.recursive = true
+			rest = rest[2:]
+		case strings.HasPrefix(rest, "."):
+			rest = rest[1:]
+		case strings.HasPrefix(rest, "["):
+		default:
+			return nil, fmt.Errorf("unexpected '%s' in JSONPath expression '%s'", rest, expression)
+		}
+
+		if strings.HasPrefix(rest, "[") {
+			end := strings.Index(rest, "]")
+			if end < 0 {
+				return nil, fmt.Errorf("missing ']' in JSONPath expression '%s'", expression)
+			}
+			selector := strings.TrimSpace(rest[1:end])
+			rest = rest[end+1:]
+			switch {
+			case selector == "*":
+				step.wildcard = true
+			case len(selector) > 1 && (selector[0] == '\'' || selector[0] == '"') && selector[len(selector)-1] == selector[0]:
+				step.key = selector[1 : len(selector)-1]
+			default:
+				index, err := strconv.Atoi(selector)
+				if err != nil {
+					return nil, fmt.Errorf("invalid selector '[%s]' in JSONPath expression '%s'", selector, expression)
+				}
+				step.index = index
+				step.isIndex = true
+			}
+		} else {
+			end := strings.IndexAny(rest, ".[")
+			if end < 0 {
+				end = len(rest)
+			}
+			name := rest[:end]
+			rest = rest[end:]
+			if name == "" {
+				return nil, fmt.Errorf("empty key in JSONPath expression '%s'", expression)
+			}
+			if name == "*" {
+				step.wildcard = true
+			} else {
+				step.key = name
+			}
+		}
+		steps = append(steps, step)
+	}
+	return steps, nil
+}
+
+// apply returns the values selected by the step from the given value
+func (step jsonPathStep) apply(value interface{}) []interface{} {
+	var result []interface{}
+	switch typedValue := value.(type) {
+	case map[string]interface{}:
+		switch {
+		case step.wildcard:
+			for _, key := range sortedKeys(typedValue) {
+				result = append(result, typedValue[key])
+			}
+		case !step.isIndex:
+			if item, ok := typedValue[step.key]; ok {
+				result = append(result, item)
+			}
+		}
+	case []interface{}:
+		switch {
+		case step.wildcard:
+			result = append(result, typedValue...)
+		case step.isIndex:
+			index := step.index
+			if index < 0 {
+				index += len(typedValue)
+			}
+			if index >= 0 && index < len(typedValue) {
+				result = append(result, typedValue[index])
+			}
+		}
+	}
+	if !step.recursive {
+		return result
+	}
+	// With recursive descent, the step is also applied to all the descendants
+	switch typedValue := value.(type) {
+	case map[string]interface{}:
+		for _, key := range sortedKeys(typedValue) {
+			result = append(result, step.apply(typedValue[key])...)
+		}
+	case []interface{}:
+		for _, item := range typedValue {
+			result = append(result, step.apply(item)...)
+		}
+	}
+	return result
+}
+
+// sortedKeys returns the keys of a decoded JSON object in alphabetical order, to return results in a stable order
+func sortedKeys(object map[string]interface{}) []string {
+	keys := make([]string, 0, len(object))
+	for key := range object {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// extractJsonPath returns the values selected by a JSONPath expression in a JSON text.
+// Strings are returned as they are, while any other value is returned as JSON
+func extractJsonPath(text, expression string) ([]string, error) {
+	steps, err := parseJsonPath(expression)
+	if err != nil {
+		return nil, err
+	}
+	var document interface{}
+	err = json.Unmarshal([]byte(text), &document)
+	if err != nil {
+		return nil, fmt.Errorf("error decoding JSON: %s", err)
+	}
+	current := []interface{}{document}
+	for _, step := range steps {
+		var next []interface{}
+		for _, value := range current {
+			next = append(next, step.apply(value)...)
+		}
+		current = next
+	}
+	values := make([]string, 0, len(current))
+	for _, value := range current {
+		if stringValue, ok := value.(string); ok {
+			values = append(values, stringValue)
+			continue
+		}
+		encoded, err := json.Marshal(value)
+		if err != nil {
+			return nil, fmt.Errorf("error encoding JSONPath result: %s", err)
+		}
+		values = append(values, string(encoded))
+	}
+	return values, nil
+}
+
+// xmlNode is a generic element of an XML document
+type xmlNode struct {
+	name       string // local name, without namespace
+	attributes map[string]string
+	children   []*xmlNode
+	text       strings.Builder
+	parent     *xmlNode
+}
+
+// textContent returns the text of the node and all its descendants
+func (node *xmlNode) textContent() string {
+	var text strings.Builder
+	text.WriteString(node.text.String())
+	for _, child := range node.children {
+		text.WriteString(child.textContent())
+	}
+	return text.String()
+}
+
+// descendants returns the node and all its descendants, in document order
+func (node *xmlNode) descendants() []*xmlNode {
+	result := []*xmlNode{node}
+	for _, child := range node.children {
+		result = append(result, child.descendants()...)
+	}
+	return result
+}
+
+// parseXmlDocument decodes an XML text into a tree of nodes, returning a document node whose only child is the root
+func parseXmlDocument(text string) (*xmlNode, error) {
+	document := &xmlNode{}
+	current := document
+	decoder := xml.NewDecoder(bytes.NewReader([]byte(text)))
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("error decoding XML: %s", err)
+		}
+		switch typedToken := token.(type) {
+		case xml.StartElement:
+			node := &xmlNode{
+				name:       typedToken.Name.Local,
+				attributes: make(map[string]string),
+				parent:     current,
+			}
+			for _, attribute := range typedToken.Attr {
+				if attribute.Name.Space == "xmlns" || attribute.Name.Local == "xmlns" {
+					continue
+				}
+				node.attributes[attribute.Name.Local] = attribute.Value
+			}
+			current.children = append(current.children, node)
+			current = node
+		case xml.EndElement:
+			current = current.parent
+		case xml.CharData:
+			current.text.Write(typedToken)
+		}
+	}
+	if len(document.children) == 0 {
+		return nil, fmt.Errorf("XML document is empty")
+	}
+	return document, nil
+}
+
+// xpathStepRegex matches a single XPath step, such as `VApp`, `*`, `Link[@rel='nextPage']` or `Vm[2]`
+var xpathStepRegex = regexp.MustCompile(`^([A-Za-z_*][\w.\-:]*|\*)(?:\[(?:@([\w.\-:]+)\s*=\s*['"]([^'"]*)['"]|(\d+))\])?$`)
+
+// extractXPath returns the values selected by an XPath expression in an XML text.
+// Supported syntax: absolute (`/a/b`) and descendant (`//b`) steps, `*`, the predicates `[@attr='value']` and `[n]`,
+// and a final `@attr` or `text()` step. Namespace prefixes are ignored. The value of an element is its text content
+func extractXPath(text, expression string) ([]string, error) {
+	if !strings.HasPrefix(expression, "/") {
+		return nil, fmt.Errorf("XPath expression '%s' must start with '/'", expression)
+	}
+	document, err := parseXmlDocument(text)
+	if err != nil {
+		return nil, err
+	}
+
+	current := []*xmlNode{document}
+	rest := expression
+	for rest != "" {
+		descendant := strings.HasPrefix(rest, "//")
+		rest = strings.TrimPrefix(strings.TrimPrefix(rest, "/"), "/")
+		end := strings.Index(rest, "/")
+		if end < 0 {
+			end = len(rest)
+		}
+		step := rest[:end]
+		rest = rest[end:]
+
+		// Final steps, which return values instead of nodes
+		if step == "text()" || strings.HasPrefix(step, "@") {
+			if rest != "" || descendant {
+				return nil, fmt.Errorf("'%s' must be the last step of XPath expression '%s'", step, expression)
+			}
+			values := make([]string, 0, len(current))
+			for _, node := range current {
+				if step == "text()" {
+					values = append(values, strings.TrimSpace(node.text.String()))
+				} else if value, ok := node.attributes[strings.TrimPrefix(step, "@")]; ok {
+					values = append(values, value)
+				}
+			}
+			return values, nil
+		}
+
+		matches := xpathStepRegex.FindStringSubmatch(step)
+		if matches == nil {
+			return nil, fmt.Errorf("unsupported step '%s' in XPath expression '%s'", step, expression)
+		}
+		name := matches[1]
+		if colon := strings.LastIndex(name, ":"); colon >= 0 {
+			name = name[colon+1:]
+		}
+		attributeName, attributeValue, position := matches[2], matches[3], matches[4]
+
+		var next []*xmlNode
+		// With descendant steps, the same node can be reached from several ancestors
+		seen := make(map[*xmlNode]bool)
+		for _, node := range current {
+			var candidates []*xmlNode
+			if descendant {
+				candidates = node.descendants()[1:]
+			} else {
+				candidates = node.children
+			}
+			var selected []*xmlNode
+			for _, candidate := range candidates {
+				if name != "*" && candidate.name != name {
+					continue
+				}
+				if attributeName != "" && candidate.attributes[attributeName] != attributeValue {
+					continue
+				}
+				selected = append(selected, candidate)
+			}
+			if position != "" {
+				index, _ := strconv.Atoi(position)
+				if index < 1 || index > len(selected) {
+					continue
+				}
+				selected = selected[index-1 : index]
+			}
+			for _, item := range selected {
+				if !seen[item] {
+					seen[item] = true
+					next = append(next, item)
+				}
+			}
+		}
+		current = next
+	}
+
+	values := make([]string, 0, len(current))
+	for _, node := range current {
+		values = append(values, strings.TrimSpace(node.textContent()))
+	}
+	return values, nil
+}
@@ -0,0 +1,103 @@
+//go:build unit || ALL
+
+package vcd
+
+import (
+	"reflect"
+	"testing"
+)
+
+func Test_extractJsonPath(t *testing.T) {
+	document := `{
+  "resultTotal": 2,
+  "values": [
+    {"name": "vdc1", "id": "urn:vcloud:vdc:1", "org": {"name": "org1"}},
+    {"name": "vdc2", "id": "urn:vcloud:vdc:2", "org": {"name": "org2"}, "count": 3}
+  ]
+}`
+	tests := []struct {
+		expression string
+		want       []string
+		wantErr    bool
+	}{
+		{expression: "$.resultTotal", want: []string{"2"}},
+		{expression: "$.values[*].name", want: []string{"vdc1", "vdc2"}},
+		{expression: "$.values[1].id", want: []string{"urn:vcloud:vdc:2"}},
+		{expression: "$.values[-1].count", want: []string{"3"}},
+		{expression: "$['values'][0]['org'].name", want: []string{"org1"}},
+		{expression: "$..org.name", want: []string{"org1", "org2"}},
+		{expression: "$.values[0].org", want: []string{`{"name":"org1"}`}},
+		{expression: "$.values[5].name", want: []string{}},
+		{expression: "$.missing", want: []string{}},
+		{expression: "values", wantErr: true},
+		{expression: "$.values[a]", wantErr: true},
+		{expression: "$.values[0", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.expression, func(t *testing.T) {
+			got, err := extractJsonPath(document, tt.expression)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("extractJsonPath() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("extractJsonPath() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_extractXPath(t *testing.T) {
+	document := `<?xml version="1.0" encoding="UTF-8"?>
+<QueryResultRecords xmlns="http://www.vmware.com/vcloud/v1.5" xmlns:vmext="http://www.vmware.com/vcloud/extension/v1.5" page="1">
+  <Link rel="nextPage" href="https://vcd.example.com/api/query?type=vm&amp;page=2"/>
+  <Link rel="lastPage" href="https://vcd.example.com/api/query?type=vm&amp;page=3"/>
+  <VMRecord name="vm1" status="POWERED_ON">
+    <vmext:GuestCustomizationStatus>GC_COMPLETE</vmext:GuestCustomizationStatus>
+  </VMRecord>
+  <VMRecord name="vm2" status="POWERED_OFF">
+    <vmext:GuestCustomizationStatus>GC_PENDING</vmext:GuestCustomizationStatus>
+  </VMRecord>
+</QueryResultRecords>`
+	tests := []struct {
+		expression string
+		want       []string
+		wantErr    bool
+	}{
+		{expression: "/QueryResultRecords/@page", want: []string{"1"}},
+		{expression: "/*/Link[@rel='nextPage']/@href", want: []string{"https://vcd.example.com/api/query?type=vm&page=2"}},
+		{expression: "//VMRecord/@name", want: []string{"vm1", "vm2"}},
+		{expression: "//VMRecord[2]/@status", want: []string{"POWERED_OFF"}},
+		{expression: "//VMRecord[@name='vm1']/vmext:GuestCustomizationStatus", want: []string{"GC_COMPLETE"}},
+		{expression: "//GuestCustomizationStatus/text()", want: []string{"GC_COMPLETE", "GC_PENDING"}},
+		{expression: "//VMRecord[3]/@name", want: []string{}},
+		{expression: "QueryResultRecords", wantErr: true},
+		{expression: "//VMRecord/@name/text()", wantErr: true},
+		{expression: "//VMRecord[name()]", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.expression, func(t *testing.T) {
+			got, err := extractXPath(document, tt.expression)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("extractXPath() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("extractXPath() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_mergeOpenApiPages(t *testing.T) {
+	pages := []string{
+		`{"resultTotal":3,"pageCount":2,"page":1,"values":[{"name":"a"},{"name":"b"}]}`,
+		`{"resultTotal":3,"pageCount":2,"page":2,"values":[{"name":"c"}]}`,
+	}
+	got, err := mergeOpenApiPages(pages)
+	if err != nil {
+		t.Fatalf("mergeOpenApiPages() error: %s", err)
+	}
+	want := `{"page":1,"pageCount":2,"resultTotal":3,"values":[{"name":"a"},{"name":"b"},{"name":"c"}]}`
+	if got != want {
+		t.Errorf("mergeOpenApiPages() = %s, want %s", got, want)
+	}
+}
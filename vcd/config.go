@@ -1,6 +1,7 @@
 package vcd
 
 import (
+	"context"
 	"crypto/sha256"
 	"fmt"
 	"net/url"
@@ -57,6 +58,18 @@ type Config struct {
 	// IgnoredMetadata allows to configure a set of metadata entries that should be ignored by all the
 	// API operations related to metadata.
 	IgnoredMetadata []govcd.IgnoredMetadata
+
+	// ApiRecordingMode can be "record", to save the API interactions to ApiRecordingFile, or "replay", to serve
+	// them from ApiRecordingFile without contacting VCD. When empty, the API interactions are not recorded
+	ApiRecordingMode string
+	ApiRecordingFile string
+
+	// DistributedLocks enables the leases that protect the operations on Edge Gateways, VDC Groups and vApps from
+	// other provider processes. DistributedLockTtl and DistributedLockTimeout are the duration of a lease and the
+	// maximum wait for it, in seconds (0 for the defaults)
+	DistributedLocks       bool
+	DistributedLockTtl     int
+	DistributedLockTimeout int
 }
 
 type VCDClient struct {
@@ -66,6 +79,12 @@ type VCDClient struct {
 	Vdc             string // name of default VDC
 	MaxRetryTimeout int
 	InsecureFlag    bool
+
+	// distributedLocks is nil when distributed locks are disabled
+	distributedLocks *distributedLockManager
+	// operationCtx is the context of the traced operation using this copy of the client. It is nil in the client
+	// shared by all operations
+	operationCtx context.Context
 }
 
 // StringMap type is used to simplify reading resource definitions
@@ -154,13 +173,23 @@ func debugPrintf(format string, args ...interface{}) {
 // This is a global mutexKV for all resources
 var vcdMutexKV = newMutexKV()
 
+// vappLockKey returns the key used to lock a vApp
+func vappLockKey(org, vdc, vappName string) string {
+	return fmt.Sprintf("org:%s|vdc:%s|vapp:%s", org, vdc, vappName)
+}
+
+// vappVmLockKey returns the key used to lock a VM within a vApp
+func vappVmLockKey(org, vdc, vappName, vmName string) string {
+	return fmt.Sprintf("%s|vm:%s", vappLockKey(org, vdc, vappName), vmName)
+}
+
 func (cli *VCDClient) lockVapp(d *schema.ResourceData) {
 	vappName := d.Get("name").(string)
 	if vappName == "" {
 		panic("vApp name not found")
 	}
-	key := fmt.Sprintf("org:%s|vdc:%s|vapp:%s", cli.getOrgName(d), cli.getVdcName(d), vappName)
-	vcdMutexKV.kvLock(key)
+	key := vappLockKey(cli.getOrgName(d), cli.getVdcName(d), vappName)
+	vcdMutexKV.kvLockContext(cli.operationContext(), key)
 }
 
 func (cli *VCDClient) unLockVapp(d *schema.ResourceData) {
@@ -168,7 +197,7 @@ func (cli *VCDClient) unLockVapp(d *schema.ResourceData) {
 	if vappName == "" {
 		panic("vApp name not found")
 	}
-	key := fmt.Sprintf("org:%s|vdc:%s|vapp:%s", cli.getOrgName(d), cli.getVdcName(d), vappName)
+	key := vappLockKey(cli.getOrgName(d), cli.getVdcName(d), vappName)
 	vcdMutexKV.kvUnlock(key)
 }
 
@@ -180,7 +209,7 @@ func (cli *VCDClient) lockEdgeGateway(d *schema.ResourceData) {
 		panic("edge gateway ID not found")
 	}
 
-	vcdMutexKV.kvLock(edgeGatewayId)
+	vcdMutexKV.kvLockContext(cli.operationContext(), edgeGatewayId)
 }
 
 // unlockEdgeGateway unlocks an Edge Gateway resource
@@ -200,15 +229,15 @@ func (cli *VCDClient) lockParentVappWithName(d *schema.ResourceData, vappName st
 	if vappName == "" {
 		panic("vApp name not found")
 	}
-	key := fmt.Sprintf("org:%s|vdc:%s|vapp:%s", cli.getOrgName(d), cli.getVdcName(d), vappName)
-	vcdMutexKV.kvLock(key)
+	key := vappLockKey(cli.getOrgName(d), cli.getVdcName(d), vappName)
+	vcdMutexKV.kvLockContext(cli.operationContext(), key)
 }
 
 func (cli *VCDClient) unLockParentVappWithName(d *schema.ResourceData, vappName string) {
 	if vappName == "" {
 		panic("vApp name not found")
 	}
-	key := fmt.Sprintf("org:%s|vdc:%s|vapp:%s", cli.getOrgName(d), cli.getVdcName(d), vappName)
+	key := vappLockKey(cli.getOrgName(d), cli.getVdcName(d), vappName)
 	vcdMutexKV.kvUnlock(key)
 }
 
@@ -219,8 +248,8 @@ func (cli *VCDClient) lockParentVapp(d *schema.ResourceData) {
 	if vappName == "" {
 		panic("vApp name not found")
 	}
-	key := fmt.Sprintf("org:%s|vdc:%s|vapp:%s", cli.getOrgName(d), cli.getVdcName(d), vappName)
-	vcdMutexKV.kvLock(key)
+	key := vappLockKey(cli.getOrgName(d), cli.getVdcName(d), vappName)
+	vcdMutexKV.kvLockContext(cli.operationContext(), key)
 }
 
 func (cli *VCDClient) unLockParentVapp(d *schema.ResourceData) {
@@ -228,7 +257,7 @@ func (cli *VCDClient) unLockParentVapp(d *schema.ResourceData) {
 	if vappName == "" {
 		panic("vApp name not found")
 	}
-	key := fmt.Sprintf("org:%s|vdc:%s|vapp:%s", cli.getOrgName(d), cli.getVdcName(d), vappName)
+	key := vappLockKey(cli.getOrgName(d), cli.getVdcName(d), vappName)
 	vcdMutexKV.kvUnlock(key)
 }
 
@@ -236,14 +265,47 @@ func (cli *VCDClient) lockVappWithName(org, vdc, vappName string) func() {
 	if vappName == "" {
 		panic("vApp name not found")
 	}
-	key := fmt.Sprintf("org:%s|vdc:%s|vapp:%s", org, vdc, vappName)
-	vcdMutexKV.kvLock(key)
+	key := vappLockKey(org, vdc, vappName)
+	vcdMutexKV.kvLockContext(cli.operationContext(), key)
+
+	return func() {
+		vcdMutexKV.kvUnlock(key)
+	}
+}
+
+// lockVappStartupSection locks the startup section of a vApp, which is shared by all its VMs. It returns the function
+// that releases the lock
+func (cli *VCDClient) lockVappStartupSection(org, vdc, vappName string) func() {
+	key := vappLockKey(org, vdc, vappName) + "|startup"
+	vcdMutexKV.kvLockContext(cli.operationContext(), key)
 
 	return func() {
 		vcdMutexKV.kvUnlock(key)
 	}
 }
 
+// lockVappVm locks a VM of a vApp for operations that change only the VM itself. The vApp is locked in shared mode,
+// so that operations on different VMs of the same vApp run concurrently, while operations that change the vApp
+// composition or go through the vApp (locked with lockParentVapp or lockVapp) wait for them and vice versa. It returns
+// the function that releases both locks
+func (cli *VCDClient) lockVappVm(d *schema.ResourceData, vappName, vmName string) func() {
+	if vappName == "" {
+		panic("vApp name not found")
+	}
+	if vmName == "" {
+		panic("VM name not found")
+	}
+	vappKey := vappLockKey(cli.getOrgName(d), cli.getVdcName(d), vappName)
+	vmKey := vappVmLockKey(cli.getOrgName(d), cli.getVdcName(d), vappName, vmName)
+	vcdMutexKV.kvRLockContext(cli.operationContext(), vappKey)
+	vcdMutexKV.kvLockContext(cli.operationContext(), vmKey)
+
+	return func() {
+		vcdMutexKV.kvUnlock(vmKey)
+		vcdMutexKV.kvRUnlock(vappKey)
+	}
+}
+
 // lockParentVm locks using vapp_name and vm_name names existing in resource parameters.
 // Parent means the resource belongs to the VM being locked
 //
@@ -257,8 +319,8 @@ func (cli *VCDClient) lockParentVm(d *schema.ResourceData) {
 	if vmName == "" {
 		panic("vmName name not found")
 	}
-	key := fmt.Sprintf("org:%s|vdc:%s|vapp:%s|vm:%s", cli.getOrgName(d), cli.getVdcName(d), vappName, vmName)
-	vcdMutexKV.kvLock(key)
+	key := vappVmLockKey(cli.getOrgName(d), cli.getVdcName(d), vappName, vmName)
+	vcdMutexKV.kvLockContext(cli.operationContext(), key)
 }
 
 //lint:ignore U1000 For future use
@@ -271,13 +333,13 @@ func (cli *VCDClient) unLockParentVm(d *schema.ResourceData) {
 	if vmName == "" {
 		panic("vmName name not found")
 	}
-	key := fmt.Sprintf("org:%s|vdc:%s|vapp:%s|vm:%s", cli.getOrgName(d), cli.getVdcName(d), vappName, vmName)
+	key := vappVmLockKey(cli.getOrgName(d), cli.getVdcName(d), vappName, vmName)
 	vcdMutexKV.kvUnlock(key)
 }
 
 // lockById locks on supplied ID field
 func (cli *VCDClient) lockById(id string) {
-	vcdMutexKV.kvLock(id)
+	vcdMutexKV.kvLockContext(cli.operationContext(), id)
 }
 
 // unlockById unlocks on supplied ID field
@@ -292,7 +354,7 @@ func (cli *VCDClient) lockParentVdcGroup(d *schema.ResourceData) {
 		panic("'vdc_group_id' is empty")
 	}
 
-	vcdMutexKV.kvLock(vdcGroupId)
+	vcdMutexKV.kvLockContext(cli.operationContext(), vdcGroupId)
 }
 
 // unlockParentVdcGroup unlocks on VDC Group ID using 'vdc_group_id' field
@@ -312,7 +374,7 @@ func (cli *VCDClient) lockParentExternalNetwork(d *schema.ResourceData) {
 		panic("'external_network_id' is empty")
 	}
 
-	vcdMutexKV.kvLock(externalNetworkId)
+	vcdMutexKV.kvLockContext(cli.operationContext(), externalNetworkId)
 }
 
 // unlockParentVdcGroup unlocks on External Network using 'external_network_id' field
@@ -330,7 +392,7 @@ func (cli *VCDClient) lockIfOwnerIsVdcGroup(d *schema.ResourceData) {
 	vdcGroupId := d.Get("owner_id")
 	vdcGroupIdValue := vdcGroupId.(string)
 	if govcd.OwnerIsVdcGroup(vdcGroupIdValue) {
-		vcdMutexKV.kvLock(vdcGroupIdValue)
+		vcdMutexKV.kvLockContext(cli.operationContext(), vdcGroupIdValue)
 	}
 }
 
@@ -378,7 +440,7 @@ func (cli *VCDClient) lockParentEdgeGtw(d *schema.ResourceData) {
 		panic("edge gateway ID not found")
 	}
 
-	vcdMutexKV.kvLock(edgeGtwIdValue)
+	vcdMutexKV.kvLockContext(cli.operationContext(), edgeGtwIdValue)
 }
 
 func (cli *VCDClient) unLockParentEdgeGtw(d *schema.ResourceData) {
@@ -420,8 +482,8 @@ func (cli *VCDClient) unLockParentEdgeGtw(d *schema.ResourceData) {
 // lockParentVdcGroupOrEdgeGateway handles lock of parent Edge Gateway or parent VDC group, depending
 // if the parent Edge Gateway is in a VDC or a VDC group. Returns a function that contains the needed
 // unlock function, so that it can be deferred and called after the work with the resource has been
-// done.
-func (cli *VCDClient) lockParentVdcGroupOrEdgeGateway(d *schema.ResourceData) (func(), error) {
+// done. The unlock function fails when the distributed lock was lost while the resource was handled.
+func (cli *VCDClient) lockParentVdcGroupOrEdgeGateway(d *schema.ResourceData) (func() error, error) {
 	parentEdgeGatewayOwnerId, _, err := getParentEdgeGatewayOwnerId(cli, d)
 	if err != nil {
 		return nil, fmt.Errorf("error finding parent Edge Gateway: %s", err)
@@ -432,22 +494,76 @@ func (cli *VCDClient) lockParentVdcGroupOrEdgeGateway(d *schema.ResourceData) (f
 	// * When the parent Edge Gateway is in a VDC Group - a lock on parent VDC Group must be acquired
 	// To find out parent lock object, Edge Gateway must be looked up and its OwnerRef must be checked
 	// Note. It is not safe to do multiple locks in the same resource as it can result in a deadlock
+	var parentUrn string
+	var unlock func()
 	if govcd.OwnerIsVdcGroup(parentEdgeGatewayOwnerId) {
+		parentUrn = parentEdgeGatewayOwnerId
 		cli.lockById(parentEdgeGatewayOwnerId)
-		return func() {
+		unlock = func() {
 			cli.unlockById(parentEdgeGatewayOwnerId)
-		}, nil
+		}
 	} else {
+		parentUrn = d.Get("edge_gateway_id").(string)
 		cli.lockParentEdgeGtw(d)
-		return func() {
+		unlock = func() {
 			cli.unLockParentEdgeGtw(d)
-		}, nil
+		}
 	}
+
+	unlockDistributed, err := cli.lockDistributed(parentUrn)
+	if err != nil {
+		unlock()
+		return nil, err
+	}
+	return func() error {
+		defer unlock()
+		return unlockDistributed()
+	}, nil
+}
+
+// lockParentVdcGroupOrEdgeGatewayForRuleType is the variant of lockParentVdcGroupOrEdgeGateway for NSX-T Edge Gateway
+// rules that do not interfere with rules of other types (e.g. NAT rules and static routes). The parent Edge Gateway
+// or VDC Group is locked in shared mode, and the rule type (e.g. "nat") within the parent in exclusive mode. This way,
+// rules of different types are managed concurrently, while rules of the same type and operations that lock the parent
+// with lockParentVdcGroupOrEdgeGateway are still serialized.
+func (cli *VCDClient) lockParentVdcGroupOrEdgeGatewayForRuleType(d *schema.ResourceData, ruleType string) (func() error, error) {
+	parentEdgeGatewayOwnerId, _, err := getParentEdgeGatewayOwnerId(cli, d)
+	if err != nil {
+		return nil, fmt.Errorf("error finding parent Edge Gateway: %s", err)
+	}
+
+	// Same parent lock object as lockParentVdcGroupOrEdgeGateway
+	parentKey := parentEdgeGatewayOwnerId
+	if !govcd.OwnerIsVdcGroup(parentEdgeGatewayOwnerId) {
+		parentKey = d.Get("edge_gateway_id").(string)
+		if parentKey == "" {
+			panic("edge gateway ID not found")
+		}
+	}
+	ruleTypeKey := fmt.Sprintf("%s|rules:%s", parentKey, ruleType)
+
+	vcdMutexKV.kvRLockContext(cli.operationContext(), parentKey)
+	vcdMutexKV.kvLockContext(cli.operationContext(), ruleTypeKey)
+	unlock := func() {
+		vcdMutexKV.kvUnlock(ruleTypeKey)
+		vcdMutexKV.kvRUnlock(parentKey)
+	}
+
+	// Other processes are kept out of the whole parent, as they cannot see which rule types are being changed here
+	unlockDistributed, err := cli.lockDistributed(parentKey)
+	if err != nil {
+		unlock()
+		return nil, err
+	}
+	return func() error {
+		defer unlock()
+		return unlockDistributed()
+	}, nil
 }
 
 func (cli *VCDClient) lockParentOrgNetwork(d *schema.ResourceData) {
 	orgNetworkId := d.Get("org_network_id").(string)
-	vcdMutexKV.kvLock(orgNetworkId)
+	vcdMutexKV.kvLockContext(cli.operationContext(), orgNetworkId)
 }
 
 func (cli *VCDClient) unLockParentOrgNetwork(d *schema.ResourceData) {
@@ -722,7 +838,10 @@ func (c *Config) Client() (*VCDClient, error) {
 		c.ServiceAccountTokenFile + "#" +
 		c.SysOrg + "#" +
 		c.Vdc + "#" +
-		c.Href
+		c.Href + "#" +
+		c.ApiRecordingMode + "#" +
+		c.ApiRecordingFile + "#" +
+		fmt.Sprintf("%t#%d#%d", c.DistributedLocks, c.DistributedLockTtl, c.DistributedLockTimeout)
 	checksum := fmt.Sprintf("%x", sha256.Sum256([]byte(rawData)))
 
 	// The cached connection is served only if the variable VCD_CACHE is set
@@ -765,10 +884,27 @@ func (c *Config) Client() (*VCDClient, error) {
 		MaxRetryTimeout: c.MaxRetryTimeout,
 		InsecureFlag:    c.InsecureFlag}
 
+	// The recording transport must be in place before authenticating, so that the login is recorded too
+	if c.ApiRecordingMode != "" {
+		transport, err := newApiRecordingTransport(c.ApiRecordingMode, c.ApiRecordingFile, vcdClient.Client.Http.Transport)
+		if err != nil {
+			return nil, fmt.Errorf("error setting up API recording: %s", err)
+		}
+		vcdClient.Client.Http.Transport = transport
+	}
+	// The tracing transport wraps the recording one, so that replayed interactions are traced too
+	if tracer != nil {
+		vcdClient.Client.Http.Transport = newTracingTransport(vcdClient.Client.Http.Transport)
+	}
+
 	err = ProviderAuthenticate(vcdClient.VCDClient, c.User, c.Password, c.Token, c.SysOrg, c.ApiToken, c.ApiTokenFile, c.ServiceAccountTokenFile)
 	if err != nil {
 		return nil, fmt.Errorf("something went wrong during authentication: %s", err)
 	}
+	if c.DistributedLocks {
+		vcdClient.distributedLocks = newDistributedLockManager(&vcdClient.Client,
+			time.Duration(c.DistributedLockTtl)*time.Second, time.Duration(c.DistributedLockTimeout)*time.Second)
+	}
 	cachedVCDClients.Lock()
 	cachedVCDClients.conMap[checksum] = cachedConnection{initTime: time.Now(), connection: vcdClient}
 	cachedVCDClients.Unlock()
@@ -5,6 +5,8 @@ package vcd
 import (
 	"testing"
 	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 )
 
 func Test_isScalar(t *testing.T) {
@@ -128,3 +130,41 @@ func Test_isScalar(t *testing.T) {
 		})
 	}
 }
+
+// Test_lockVappVm checks that VMs of the same vApp are locked concurrently, while an operation that locks the whole
+// vApp waits for them
+func Test_lockVappVm(t *testing.T) {
+	vcdClient := &VCDClient{Org: "org1", Vdc: "vdc1"}
+	d := schema.TestResourceDataRaw(t, resourceVcdVAppVm().Schema, map[string]interface{}{"vapp_name": "vapp1", "name": "web"})
+
+	unlockWeb := vcdClient.lockVappVm(d, "vapp1", "web")
+	dbLocked := make(chan struct{})
+	go func() {
+		unlockDb := vcdClient.lockVappVm(d, "vapp1", "db")
+		close(dbLocked)
+		unlockDb()
+	}()
+	select {
+	case <-dbLocked:
+	case <-time.After(5 * time.Second):
+		t.Fatalf("the lock of a VM waited for another VM of the same vApp")
+	}
+
+	vappLocked := make(chan struct{})
+	go func() {
+		vcdClient.lockParentVapp(d)
+		close(vappLocked)
+		vcdClient.unLockParentVapp(d)
+	}()
+	select {
+	case <-vappLocked:
+		t.Fatalf("the vApp was locked while one of its VMs was locked")
+	case <-time.After(100 * time.Millisecond):
+	}
+	unlockWeb()
+	select {
+	case <-vappLocked:
+	case <-time.After(5 * time.Second):
+		t.Fatalf("the vApp was not locked after the VM was released")
+	}
+}
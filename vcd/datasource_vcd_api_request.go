@@ -0,0 +1,323 @@
+package vcd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+	"github.com/vmware/go-vcloud-director/v3/govcd"
+	"github.com/vmware/go-vcloud-director/v3/types/v56"
+)
+
+const labelApiRequest = "API request"
+
+func datasourceVcdApiRequest() *schema.Resource {
+	return &schema.Resource{
+		ReadContext: datasourceVcdApiRequestRead,
+		Schema: map[string]*schema.Schema{
+			"path": {
+				Type:     schema.TypeString,
+				Required: true,
+				Description: "Path of the request, starting with '/api/' for the XML API or with '/cloudapi/' for the OpenAPI " +
+					"(e.g. '/cloudapi/1.0.0/vdcs'). A full URL of the same VCD, such as an 'href', is also accepted",
+				ValidateFunc: validation.StringIsNotEmpty,
+			},
+			"org": {
+				Type:     schema.TypeString,
+				Optional: true,
+				Description: "The name of the organization used as tenant context for the request. " +
+					"If empty, the request is sent in the context of the logged in user",
+			},
+			"api_version": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "API version used for the request. If empty, the API version of the provider is used",
+			},
+			"query_parameters": {
+				Type:        schema.TypeMap,
+				Optional:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: "Query parameters added to the request (e.g. 'type' for '/api/query')",
+			},
+			"filter": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "FIQL filter added to the request as 'filter' query parameter (e.g. 'name==my-vdc')",
+			},
+			"paginate": {
+				Type:     schema.TypeBool,
+				Optional: true,
+				Default:  true,
+				Description: "Whether to retrieve all the pages of a paginated response. " +
+					"OpenAPI pages are merged into 'body', while XML pages are only available in 'pages'",
+			},
+			"json_path": {
+				Type:          schema.TypeString,
+				Optional:      true,
+				ConflictsWith: []string{"xpath"},
+				Description:   "JSONPath expression used to extract 'values' from a JSON response (e.g. '$.values[*].name')",
+			},
+			"xpath": {
+				Type:          schema.TypeString,
+				Optional:      true,
+				ConflictsWith: []string{"json_path"},
+				Description:   "XPath expression used to extract 'values' from an XML response (e.g. '//Vm/@name')",
+			},
+			"url": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "Full URL of the first request",
+			},
+			"body": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "Body of the response. For paginated OpenAPI responses, it contains the values of all the pages",
+			},
+			"pages": {
+				Type:        schema.TypeList,
+				Computed:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: "Bodies of all the retrieved pages, in order",
+			},
+			"values": {
+				Type:        schema.TypeList,
+				Computed:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: "Values extracted with 'json_path' or 'xpath'. Values that are not strings are returned as JSON",
+			},
+		},
+	}
+}
+
+func datasourceVcdApiRequestRead(_ context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	vcdClient := meta.(*VCDClient)
+
+	requestUrl, isOpenApi, err := getApiRequestUrl(vcdClient, d)
+	if err != nil {
+		return diag.Errorf("error building %s: %s", labelApiRequest, err)
+	}
+
+	apiVersion := d.Get("api_version").(string)
+	if apiVersion == "" {
+		apiVersion = vcdClient.Client.APIVersion
+	}
+	headers := make(map[string]string)
+	orgName := d.Get("org").(string)
+	if orgName != "" {
+		org, err := vcdClient.GetOrg(orgName)
+		if err != nil {
+			return diag.Errorf("error retrieving Org '%s': %s", orgName, err)
+		}
+		headers[types.HeaderTenantContext] = org.Org.ID
+		headers[types.HeaderAuthContext] = org.Org.Name
+	}
+
+	var pages []string
+	if isOpenApi {
+		pages, err = getApiRequestOpenApiPages(vcdClient, requestUrl, apiVersion, headers, d.Get("paginate").(bool))
+	} else {
+		pages, err = getApiRequestXmlPages(vcdClient, requestUrl, apiVersion, headers, d.Get("paginate").(bool))
+	}
+	if err != nil {
+		return diag.Errorf("error performing %s to '%s': %s", labelApiRequest, requestUrl.String(), err)
+	}
+
+	body := pages[0]
+	if isOpenApi && len(pages) > 1 {
+		body, err = mergeOpenApiPages(pages)
+		if err != nil {
+			return diag.Errorf("error merging pages of %s to '%s': %s", labelApiRequest, requestUrl.String(), err)
+		}
+	}
+
+	var values []string
+	if jsonPath := d.Get("json_path").(string); jsonPath != "" {
+		values, err = extractJsonPath(body, jsonPath)
+		if err != nil {
+			return diag.Errorf("error extracting values with JSONPath '%s': %s", jsonPath, err)
+		}
+	}
+	if xpath := d.Get("xpath").(string); xpath != "" {
+		// XML pages can't be merged, so the expression is evaluated in each page
+		for _, page := range pages {
+			pageValues, err := extractXPath(page, xpath)
+			if err != nil {
+				return diag.Errorf("error extracting values with XPath '%s': %s", xpath, err)
+			}
+			values = append(values, pageValues...)
+		}
+	}
+
+	dSet(d, "url", requestUrl.String())
+	dSet(d, "body", body)
+	err = d.Set("pages", pages)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	err = d.Set("values", values)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	d.SetId(requestUrl.String())
+	return nil
+}
+
+// getApiRequestUrl builds the URL of the request from 'path', 'query_parameters' and 'filter'.
+// It also returns whether the path belongs to the OpenAPI
+func getApiRequestUrl(vcdClient *VCDClient, d *schema.ResourceData) (*url.URL, bool, error) {
+	path := d.Get("path").(string)
+	requestUrl := vcdClient.Client.VCDHREF
+	if strings.HasPrefix(path, "http://") || strings.HasPrefix(path, "https://") {
+		parsedUrl, err := url.Parse(path)
+		if err != nil {
+			return nil, false, fmt.Errorf("invalid URL '%s': %s", path, err)
+		}
+		// Only requests to the configured VCD are allowed, as they carry the authentication token
+		if parsedUrl.Host != requestUrl.Host {
+			return nil, false, fmt.Errorf("URL '%s' does not belong to VCD '%s'", path, requestUrl.Host)
+		}
+		requestUrl = *parsedUrl
+	} else {
+		pathAndQuery := strings.SplitN(path, "?", 2)
+		requestUrl.Path = "/" + strings.TrimPrefix(pathAndQuery[0], "/")
+		requestUrl.RawQuery = ""
+		if len(pathAndQuery) > 1 {
+			requestUrl.RawQuery = pathAndQuery[1]
+		}
+	}
+
+	isOpenApi := strings.HasPrefix(requestUrl.Path, "/cloudapi/")
+	if !isOpenApi && !strings.HasPrefix(requestUrl.Path, "/api/") {
+		return nil, false, fmt.Errorf("path '%s' must start with '/api/' or '/cloudapi/'", requestUrl.Path)
+	}
+
+	queryParameters := requestUrl.Query()
+	for key, value := range d.Get("query_parameters").(map[string]interface{}) {
+		queryParameters.Set(key, value.(string))
+	}
+	if filter := d.Get("filter").(string); filter != "" {
+		queryParameters.Set("filter", filter)
+	}
+	requestUrl.RawQuery = queryParameters.Encode()
+	return &requestUrl, isOpenApi, nil
+}
+
+// performApiRequest runs a GET request and returns the body of the response
+func performApiRequest(vcdClient *VCDClient, requestUrl *url.URL, isOpenApi bool, apiVersion string, headers map[string]string) (string, error) {
+	req := vcdClient.Client.NewRequestWithApiVersion(nil, http.MethodGet, *requestUrl, nil, apiVersion)
+	// The query is built in advance, and must not be replaced by the empty parameters given above
+	req.URL.RawQuery = requestUrl.RawQuery
+	if isOpenApi {
+		req.Header.Set("Accept", types.JSONMime+";version="+apiVersion)
+	}
+	for key, value := range headers {
+		req.Header.Set(key, value)
+	}
+
+	resp, err := vcdClient.Client.Http.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", fmt.Errorf("error reading response: %s", err)
+	}
+	switch {
+	case resp.StatusCode == http.StatusNotFound || resp.StatusCode == http.StatusForbidden:
+		return "", fmt.Errorf("%s [%s]: %s", govcd.ErrorEntityNotFound, resp.Status, string(body))
+	case resp.StatusCode >= http.StatusBadRequest:
+		return "", fmt.Errorf("[%s]: %s", resp.Status, string(body))
+	}
+	return string(body), nil
+}
+
+// getApiRequestOpenApiPages retrieves an OpenAPI response. When 'paginate' is set and the response has more
+// pages, they are retrieved incrementing the 'page' query parameter
+func getApiRequestOpenApiPages(vcdClient *VCDClient, requestUrl *url.URL, apiVersion string, headers map[string]string, paginate bool) ([]string, error) {
+	pageUrl := *requestUrl
+	var pages []string
+	for {
+		body, err := performApiRequest(vcdClient, &pageUrl, true, apiVersion, headers)
+		if err != nil {
+			return nil, err
+		}
+		pages = append(pages, body)
+		if !paginate {
+			return pages, nil
+		}
+
+		var page struct {
+			Page      int `json:"page"`
+			PageCount int `json:"pageCount"`
+		}
+		// Responses that are not paginated lists don't have these fields
+		if json.Unmarshal([]byte(body), &page) != nil || page.Page == 0 || page.Page >= page.PageCount {
+			return pages, nil
+		}
+		queryParameters := pageUrl.Query()
+		queryParameters.Set("page", strconv.Itoa(page.Page+1))
+		pageUrl.RawQuery = queryParameters.Encode()
+	}
+}
+
+// getApiRequestXmlPages retrieves an XML response. When 'paginate' is set and the response has a 'nextPage' link,
+// as query responses do, the following pages are retrieved
+func getApiRequestXmlPages(vcdClient *VCDClient, requestUrl *url.URL, apiVersion string, headers map[string]string, paginate bool) ([]string, error) {
+	pageUrl := requestUrl
+	var pages []string
+	for {
+		body, err := performApiRequest(vcdClient, pageUrl, false, apiVersion, headers)
+		if err != nil {
+			return nil, err
+		}
+		pages = append(pages, body)
+		if !paginate {
+			return pages, nil
+		}
+
+		nextPages, err := extractXPath(body, "/*/Link[@rel='nextPage']/@href")
+		if err != nil || len(nextPages) == 0 {
+			return pages, nil
+		}
+		pageUrl, err = url.Parse(nextPages[0])
+		if err != nil {
+			return nil, fmt.Errorf("invalid next page URL '%s': %s", nextPages[0], err)
+		}
+	}
+}
+
+// mergeOpenApiPages returns the first page of an OpenAPI response with the 'values' of all the pages
+func mergeOpenApiPages(pages []string) (string, error) {
+	var merged map[string]interface{}
+	var values []interface{}
+	for i, page := range pages {
+		var decoded map[string]interface{}
+		err := json.Unmarshal([]byte(page), &decoded)
+		if err != nil {
+			return "", fmt.Errorf("error decoding page %d: %s", i+1, err)
+		}
+		if pageValues, ok := decoded["values"].([]interface{}); ok {
+			values = append(values, pageValues...)
+		}
+		if merged == nil {
+			merged = decoded
+		}
+	}
+	merged["values"] = values
+	body, err := json.Marshal(merged)
+	if err != nil {
+		return "", err
+	}
+	return string(body), nil
+}
@@ -0,0 +1,69 @@
+//go:build functional || ALL
+
+package vcd
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+)
+
+// TestAccVcdDatasourceApiRequest retrieves the test Org with an OpenAPI request and the test VDC with an XML query
+func TestAccVcdDatasourceApiRequest(t *testing.T) {
+	preTestChecks(t)
+
+	var params = StringMap{
+		"Org":      testConfig.VCD.Org,
+		"Vdc":      testConfig.Nsxt.Vdc,
+		"FuncName": t.Name(),
+	}
+	testParamsNotEmpty(t, params)
+
+	configText := templateFill(testAccVcdDatasourceApiRequest, params)
+	debugPrintf("#[DEBUG] CONFIGURATION: %s", configText)
+
+	if vcdShortTest {
+		t.Skip(acceptanceTestsSkipped)
+		return
+	}
+
+	resource.Test(t, resource.TestCase{
+		ProviderFactories: testAccProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: configText,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("data.vcd_api_request.orgs", "values.#", "1"),
+					resource.TestCheckResourceAttr("data.vcd_api_request.orgs", "values.0", params["Org"].(string)),
+					resource.TestMatchResourceAttr("data.vcd_api_request.orgs", "url", regexp.MustCompile(`/cloudapi/1.0.0/orgs\?filter=`)),
+					resource.TestMatchResourceAttr("data.vcd_api_request.orgs", "body", regexp.MustCompile(`"resultTotal":1`)),
+					resource.TestCheckResourceAttr("data.vcd_api_request.vdcs", "values.#", "1"),
+					resource.TestCheckResourceAttr("data.vcd_api_request.vdcs", "values.0", params["Vdc"].(string)),
+					resource.TestMatchResourceAttr("data.vcd_api_request.vdcs", "body", regexp.MustCompile(`QueryResultRecords`)),
+					resource.TestCheckResourceAttr("data.vcd_api_request.vdcs", "pages.#", "1"),
+				),
+			},
+		},
+	})
+	postTestChecks(t)
+}
+
+const testAccVcdDatasourceApiRequest = `
+data "vcd_api_request" "orgs" {
+  path      = "/cloudapi/1.0.0/orgs"
+  filter    = "name=={{.Org}}"
+  json_path = "$.values[*].name"
+}
+
+data "vcd_api_request" "vdcs" {
+  org    = "{{.Org}}"
+  path   = "/api/query"
+  filter = "name=={{.Vdc}}"
+  xpath  = "//OrgVdcRecord/@name"
+  query_parameters = {
+    type   = "orgVdc"
+    format = "records"
+  }
+}
+`
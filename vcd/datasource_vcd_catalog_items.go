@@ -0,0 +1,70 @@
+package vcd
+
+import (
+	"context"
+	"net/url"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/vmware/go-vcloud-director/v3/types/v56"
+)
+
+func datasourceVcdCatalogItems() *schema.Resource {
+	return &schema.Resource{
+		ReadContext: datasourceVcdCatalogItemsRead,
+		Schema: listDatasourceSchema("catalog item", datasourceVcdCatalogItem(), map[string]*schema.Schema{
+			"org": {
+				Type:     schema.TypeString,
+				Optional: true,
+				Description: "The name of organization to use, optional if defined at provider " +
+					"level. Useful when connected as sysadmin working across different organizations",
+			},
+			"catalog": {
+				Type:        schema.TypeString,
+				Required:    true,
+				Description: "Catalog containing the items",
+			},
+		}),
+	}
+}
+
+func datasourceVcdCatalogItemsRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	vcdClient := meta.(*VCDClient)
+	org, err := vcdClient.GetOrgFromResource(d)
+	if err != nil {
+		return diag.Errorf(errorRetrievingOrg, err)
+	}
+	catalogName := d.Get("catalog").(string)
+	catalog, err := org.GetCatalogByName(catalogName, false)
+	if err != nil {
+		return diag.Errorf("error retrieving catalog '%s': %s", catalogName, err)
+	}
+
+	getCatalogItems := func(queryParameters url.Values) ([]*types.QueryResultCatalogItemType, error) {
+		// Only vApp templates are catalog items for this provider, as media items are managed by vcd_catalog_media.
+		// The query service identifies the catalog by HREF, as in the queries of go-vcloud-director
+		filter := joinFiqlFilters("catalog=="+catalog.Catalog.HREF, "entityType==vapptemplate", queryParameters.Get("filter"))
+		return queryAllRecords(&vcdClient.Client, vcdClient.Client.GetQueryType(types.QtCatalogItem), filter,
+			func(results *types.QueryResultRecordsType) []*types.QueryResultCatalogItemType {
+				if vcdClient.Client.IsSysAdmin {
+					return results.AdminCatalogItemRecord
+				}
+				return results.CatalogItemRecord
+			})
+	}
+
+	itemDatasource := datasourceVcdCatalogItem()
+	c := dsListConfig[*types.QueryResultCatalogItemType]{
+		entityLabel:     "catalog item",
+		itemDatasource:  itemDatasource,
+		getEntitiesFunc: getCatalogItems,
+		stateStoreFunc: readWithItemDatasource(itemDatasource, func(catalogItem *types.QueryResultCatalogItemType) map[string]string {
+			return map[string]string{
+				"org":     org.Org.Name,
+				"catalog": catalogName,
+				"name":    catalogItem.Name,
+			}
+		}),
+	}
+	return readDatasourceList(ctx, d, meta, c)
+}
@@ -3,6 +3,7 @@ package vcd
 import (
 	"context"
 	"log"
+	"net/url"
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
@@ -20,10 +21,12 @@ func datasourceVcdIpSpace() *schema.Resource {
 				Description: "Org ID for 'SHARED' IP spaces",
 			},
 			"name": {
-				Type:        schema.TypeString,
-				Required:    true,
-				Description: "Name of IP space",
+				Type:         schema.TypeString,
+				Optional:     true,
+				ExactlyOneOf: []string{"name", "filter"},
+				Description:  "Name of IP space (optional if 'filter' is used)",
 			},
+			"filter": openApiFilterSchema("IP Space", false, false),
 			"type": {
 				Type:        schema.TypeString,
 				Computed:    true,
@@ -153,7 +156,28 @@ func datasourceVcdIpSpaceRead(ctx context.Context, d *schema.ResourceData, meta
 	var ipSpace *govcd.IpSpace
 	var err error
 
-	if orgId != "" { // in case org_id is provided (PRIVATE IP Space)
+	if filter, hasFilter := d.GetOk("filter"); hasFilter {
+		getIpSpaces := func(queryParameters url.Values) ([]*govcd.IpSpace, error) {
+			if orgId != "" {
+				queryParameters = fiqlFilterAnd(queryParameters, "orgRef.id=="+orgId)
+			}
+			return vcdClient.GetAllIpSpaceSummaries(queryParameters)
+		}
+		ipSpaceSummary, err := getOpenApiEntityByFilter(&vcdClient.Client, filter, "IP Space", openApiFilterConfig[*govcd.IpSpace]{
+			getAllEntitiesFunc: getIpSpaces,
+			getIdAndNameFunc: func(e *govcd.IpSpace) (string, string) {
+				return e.IpSpace.ID, e.IpSpace.Name
+			},
+		})
+		if err != nil {
+			return diag.Errorf("error retrieving IP Space by filter: %s", err)
+		}
+		// Summaries do not contain all the IP Space fields
+		ipSpace, err = vcdClient.GetIpSpaceById(ipSpaceSummary.IpSpace.ID)
+		if err != nil {
+			return diag.Errorf("error retrieving IP Space '%s': %s", ipSpaceSummary.IpSpace.Name, err)
+		}
+	} else if orgId != "" { // in case org_id is provided (PRIVATE IP Space)
 		ipSpace, err = vcdClient.GetIpSpaceByNameAndOrgId(ipSpaceName, orgId)
 		if err != nil {
 			return diag.Errorf("error retrieving IP Space '%s' in Org ID '%s': %s", ipSpaceName, orgId, err)
@@ -0,0 +1,80 @@
+package vcd
+
+import (
+	"context"
+	"net/url"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/vmware/go-vcloud-director/v3/govcd"
+)
+
+func datasourceVcdNetworkRoutedV2s() *schema.Resource {
+	return &schema.Resource{
+		ReadContext: datasourceVcdNetworkRoutedV2sRead,
+		Schema: listDatasourceSchema("routed network", datasourceVcdNetworkRoutedV2(), map[string]*schema.Schema{
+			"org": {
+				Type:     schema.TypeString,
+				Optional: true,
+				Description: "The name of organization to use, optional if defined at provider " +
+					"level. Useful when connected as sysadmin working across different organizations",
+			},
+			"owner_id": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "ID of the VDC or VDC Group that owns the networks. If empty, the networks of the whole Org are retrieved",
+			},
+			"edge_gateway_id": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "ID of the Edge Gateway to which the networks are connected",
+			},
+		}),
+	}
+}
+
+func datasourceVcdNetworkRoutedV2sRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	vcdClient := meta.(*VCDClient)
+	org, err := vcdClient.GetOrgFromResource(d)
+	if err != nil {
+		return diag.Errorf(errorRetrievingOrg, err)
+	}
+
+	getNetworks := func(queryParameters url.Values) ([]*govcd.OpenApiOrgVdcNetwork, error) {
+		ownerFilter := ""
+		if ownerId := d.Get("owner_id").(string); ownerId != "" {
+			ownerFilter = "ownerRef.id==" + ownerId
+		}
+		networks, err := org.GetAllOpenApiOrgVdcNetworks(fiqlFilterAnd(queryParameters, ownerFilter))
+		if err != nil {
+			return nil, err
+		}
+		edgeGatewayId := d.Get("edge_gateway_id").(string)
+		var routedNetworks []*govcd.OpenApiOrgVdcNetwork
+		for _, network := range networks {
+			if !network.IsRouted() || network.OpenApiOrgVdcNetwork.Connection == nil {
+				continue
+			}
+			if edgeGatewayId != "" && network.OpenApiOrgVdcNetwork.Connection.RouterRef.ID != edgeGatewayId {
+				continue
+			}
+			routedNetworks = append(routedNetworks, network)
+		}
+		return routedNetworks, nil
+	}
+
+	itemDatasource := datasourceVcdNetworkRoutedV2()
+	c := dsListConfig[*govcd.OpenApiOrgVdcNetwork]{
+		entityLabel:     "routed network",
+		itemDatasource:  itemDatasource,
+		getEntitiesFunc: getNetworks,
+		stateStoreFunc: readWithItemDatasource(itemDatasource, func(network *govcd.OpenApiOrgVdcNetwork) map[string]string {
+			return map[string]string{
+				"org":             org.Org.Name,
+				"edge_gateway_id": network.OpenApiOrgVdcNetwork.Connection.RouterRef.ID,
+				"name":            network.OpenApiOrgVdcNetwork.Name,
+			}
+		}),
+	}
+	return readDatasourceList(ctx, d, meta, c)
+}
@@ -2,9 +2,11 @@ package vcd
 
 import (
 	"context"
+	"net/url"
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/vmware/go-vcloud-director/v3/govcd"
 )
 
 func datasourceVcdAlbPool() *schema.Resource {
@@ -33,10 +35,12 @@ func datasourceVcdAlbPool() *schema.Resource {
 				Description: "Edge gateway ID in which ALB Pool should be created",
 			},
 			"name": {
-				Type:        schema.TypeString,
-				Required:    true,
-				Description: "Name of ALB Pool",
+				Type:         schema.TypeString,
+				Optional:     true,
+				ExactlyOneOf: []string{"name", "filter"},
+				Description:  "Name of ALB Pool (optional if 'filter' is used)",
 			},
+			"filter": openApiFilterSchema("ALB Pool", false, false),
 			"enabled": {
 				Type:        schema.TypeBool,
 				Computed:    true,
@@ -245,9 +249,29 @@ func datasourceVcdAlbPoolRead(_ context.Context, d *schema.ResourceData, meta in
 		return diag.Errorf("could not retrieve NSX-T nsxtEdge gateway with ID '%s': %s", d.Id(), err)
 	}
 
-	albPool, err := vcdClient.GetAlbPoolByName(nsxtEdge.EdgeGateway.ID, d.Get("name").(string))
-	if err != nil {
-		return diag.Errorf("could not retrieve NSX-T ALB Pool '%s': %s", d.Get("name").(string), err)
+	var albPool *govcd.NsxtAlbPool
+	if filter, hasFilter := d.GetOk("filter"); hasFilter {
+		albPoolSummary, err := getOpenApiEntityByFilter(&vcdClient.Client, filter, "NSX-T ALB Pool", openApiFilterConfig[*govcd.NsxtAlbPool]{
+			getAllEntitiesFunc: func(queryParameters url.Values) ([]*govcd.NsxtAlbPool, error) {
+				return vcdClient.GetAllAlbPoolSummaries(nsxtEdge.EdgeGateway.ID, queryParameters)
+			},
+			getIdAndNameFunc: func(e *govcd.NsxtAlbPool) (string, string) {
+				return e.NsxtAlbPool.ID, e.NsxtAlbPool.Name
+			},
+		})
+		if err != nil {
+			return diag.Errorf("could not retrieve NSX-T ALB Pool by filter: %s", err)
+		}
+		// Summaries do not contain all the ALB Pool fields
+		albPool, err = vcdClient.GetAlbPoolById(albPoolSummary.NsxtAlbPool.ID)
+		if err != nil {
+			return diag.Errorf("could not retrieve NSX-T ALB Pool '%s': %s", albPoolSummary.NsxtAlbPool.Name, err)
+		}
+	} else {
+		albPool, err = vcdClient.GetAlbPoolByName(nsxtEdge.EdgeGateway.ID, d.Get("name").(string))
+		if err != nil {
+			return diag.Errorf("could not retrieve NSX-T ALB Pool '%s': %s", d.Get("name").(string), err)
+		}
 	}
 
 	err = setNsxtAlbPoolData(d, albPool.NsxtAlbPool)
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"log"
+	"net/url"
 
 	"github.com/vmware/go-vcloud-director/v3/govcd"
 
@@ -31,10 +32,12 @@ func datasourceVcdNsxtEdgeGateway() *schema.Resource {
 				Deprecated:    "This field is deprecated in favor of 'owner_id' which supports both - VDC and VDC Group IDs",
 			},
 			"name": {
-				Type:        schema.TypeString,
-				Required:    true,
-				Description: "Edge Gateway name",
+				Type:         schema.TypeString,
+				Optional:     true,
+				ExactlyOneOf: []string{"name", "filter"},
+				Description:  "Edge Gateway name (optional if 'filter' is used)",
 			},
+			"filter": openApiFilterSchema("NSX-T Edge Gateway", false, false),
 			"owner_id": {
 				Type:          schema.TypeString,
 				Optional:      true,
@@ -263,7 +266,24 @@ func datasourceVcdNsxtEdgeGatewayRead(_ context.Context, d *schema.ResourceData,
 
 	var edge *govcd.NsxtEdgeGateway
 	edgeGatewayName := d.Get("name").(string)
+	filter, hasFilter := d.GetOk("filter")
 	switch {
+	case hasFilter:
+		ownerId, _, err := pickVdcIdByPriority(org, inheritedVdcField, vdcField, ownerIdField)
+		if err != nil {
+			return diag.Errorf("error getting VDC ID: %s", err)
+		}
+		edge, err = getOpenApiEntityByFilter(&vcdClient.Client, filter, "NSX-T Edge Gateway", openApiFilterConfig[*govcd.NsxtEdgeGateway]{
+			getAllEntitiesFunc: func(queryParameters url.Values) ([]*govcd.NsxtEdgeGateway, error) {
+				return org.GetAllNsxtEdgeGateways(fiqlFilterAnd(queryParameters, "ownerRef.id=="+ownerId))
+			},
+			getIdAndNameFunc: func(e *govcd.NsxtEdgeGateway) (string, string) {
+				return e.EdgeGateway.ID, e.EdgeGateway.Name
+			},
+		})
+		if err != nil {
+			return diag.Errorf("error getting NSX-T Edge Gateway by filter: %s", err)
+		}
 	case ownerIdField != "":
 		edge, err = org.GetNsxtEdgeGatewayByNameAndOwnerId(edgeGatewayName, ownerIdField)
 		if err != nil {
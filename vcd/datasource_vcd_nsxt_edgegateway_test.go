@@ -287,3 +287,73 @@ data "vcd_nsxt_edgegateway" "nsxv-try" {
   name = "{{.NsxvEdgeGatewayName}}"
 }
 `
+
+// TestAccVcdNsxtEdgeGatewayDSFilter checks that an Edge Gateway found with an OpenAPI filter block is the same found by name
+func TestAccVcdNsxtEdgeGatewayDSFilter(t *testing.T) {
+	preTestChecks(t)
+
+	var params = StringMap{
+		"Org":             testConfig.VCD.Org,
+		"NsxtVdc":         testConfig.Nsxt.Vdc,
+		"NsxtEdgeGateway": testConfig.Nsxt.EdgeGateway,
+		"Tags":            "gateway nsxt",
+	}
+	testParamsNotEmpty(t, params)
+
+	configText := templateFill(testAccVcdNsxtEdgeGatewayDSFilter, params)
+	params["FuncName"] = t.Name() + "-not-found"
+	configTextNotFound := templateFill(testAccVcdNsxtEdgeGatewayDSFilter+testAccVcdNsxtEdgeGatewayDSFilterNotFound, params)
+	debugPrintf("#[DEBUG] CONFIGURATION: %s", configText)
+	debugPrintf("#[DEBUG] CONFIGURATION: %s", configTextNotFound)
+	if vcdShortTest {
+		t.Skip(acceptanceTestsSkipped)
+		return
+	}
+
+	resource.Test(t, resource.TestCase{
+		ProviderFactories: testAccProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: configText,
+				Check: resource.ComposeTestCheckFunc(
+					resourceFieldsEqual("data.vcd_nsxt_edgegateway.by-name", "data.vcd_nsxt_edgegateway.by-filter", []string{"filter.#", "filter.0.%", "filter.0.name", "filter.0.fiql"}),
+					resource.TestCheckResourceAttr("data.vcd_nsxt_edgegateway.by-filter", "name", params["NsxtEdgeGateway"].(string)),
+				),
+			},
+			{
+				Config:      configTextNotFound,
+				ExpectError: regexp.MustCompile("no NSX-T Edge Gateway found with given criteria"),
+			},
+		},
+	})
+	postTestChecks(t)
+}
+
+const testAccVcdNsxtEdgeGatewayDSFilter = `
+data "vcd_nsxt_edgegateway" "by-name" {
+  org  = "{{.Org}}"
+  vdc  = "{{.NsxtVdc}}"
+  name = "{{.NsxtEdgeGateway}}"
+}
+
+data "vcd_nsxt_edgegateway" "by-filter" {
+  org = "{{.Org}}"
+  vdc = "{{.NsxtVdc}}"
+  filter {
+    name = "{{.NsxtEdgeGateway}}"
+    fiql = "id==${data.vcd_nsxt_edgegateway.by-name.id}"
+  }
+}
+`
+
+const testAccVcdNsxtEdgeGatewayDSFilterNotFound = `
+# skip-binary-test: should fail on purpose because no Edge Gateway matches both criteria
+data "vcd_nsxt_edgegateway" "not-found" {
+  org = "{{.Org}}"
+  vdc = "{{.NsxtVdc}}"
+  filter {
+    name = "{{.NsxtEdgeGateway}}"
+    fiql = "description=={{.FuncName}}"
+  }
+}
+`
@@ -0,0 +1,74 @@
+package vcd
+
+import (
+	"context"
+	"net/url"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/vmware/go-vcloud-director/v3/govcd"
+)
+
+func datasourceVcdNsxtEdgeGateways() *schema.Resource {
+	return &schema.Resource{
+		ReadContext: datasourceVcdNsxtEdgeGatewaysRead,
+		Schema: listDatasourceSchema("NSX-T Edge Gateway", datasourceVcdNsxtEdgeGateway(), map[string]*schema.Schema{
+			"org": {
+				Type:     schema.TypeString,
+				Optional: true,
+				Description: "The name of organization to use, optional if defined at provider " +
+					"level. Useful when connected as sysadmin working across different organizations",
+			},
+			"owner_id": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "ID of the VDC or VDC Group that owns the Edge Gateways. If empty, the Edge Gateways of the whole Org are retrieved",
+			},
+		}),
+	}
+}
+
+func datasourceVcdNsxtEdgeGatewaysRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	vcdClient := meta.(*VCDClient)
+	org, err := vcdClient.GetOrgFromResource(d)
+	if err != nil {
+		return diag.Errorf(errorRetrievingOrg, err)
+	}
+
+	getEdgeGateways := func(queryParameters url.Values) ([]*govcd.NsxtEdgeGateway, error) {
+		ownerFilter := ""
+		if ownerId := d.Get("owner_id").(string); ownerId != "" {
+			ownerFilter = "ownerRef.id==" + ownerId
+		}
+		// System administrators can see the Edge Gateways of all the Orgs
+		queryParameters.Set("filter", joinFiqlFilters("orgRef.id=="+org.Org.ID, ownerFilter, queryParameters.Get("filter")))
+		edgeGateways, err := org.GetAllNsxtEdgeGateways(queryParameters)
+		if err != nil {
+			return nil, err
+		}
+		// The endpoint also returns NSX-V Edge Gateways
+		var nsxtEdgeGateways []*govcd.NsxtEdgeGateway
+		for _, edge := range edgeGateways {
+			if edge.EdgeGateway.GatewayBacking != nil && edge.EdgeGateway.GatewayBacking.GatewayType == "NSXT_BACKED" {
+				nsxtEdgeGateways = append(nsxtEdgeGateways, edge)
+			}
+		}
+		return nsxtEdgeGateways, nil
+	}
+
+	c := dsListConfig[*govcd.NsxtEdgeGateway]{
+		entityLabel:     "NSX-T Edge Gateway",
+		itemDatasource:  datasourceVcdNsxtEdgeGateway(),
+		getEntitiesFunc: getEdgeGateways,
+		stateStoreFunc: func(vcdClient *VCDClient, d *schema.ResourceData, edge *govcd.NsxtEdgeGateway) error {
+			dSet(d, "org", org.Org.Name)
+			err := setNsxtEdgeGatewayData(vcdClient, edge, d)
+			if err != nil {
+				return err
+			}
+			d.SetId(edge.EdgeGateway.ID)
+			return nil
+		},
+	}
+	return readDatasourceList(ctx, d, meta, c)
+}
@@ -0,0 +1,94 @@
+//go:build network || nsxt || ALL || functional
+
+package vcd
+
+import (
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+)
+
+// TestAccVcdDatasourceNsxtLists checks the list data sources of NSX-T Edge Gateways, routed networks and NAT rules
+func TestAccVcdDatasourceNsxtLists(t *testing.T) {
+	preTestChecks(t)
+
+	var params = StringMap{
+		"Org":         testConfig.VCD.Org,
+		"NsxtVdc":     testConfig.Nsxt.Vdc,
+		"EdgeGateway": testConfig.Nsxt.EdgeGateway,
+		"FuncName":    t.Name(),
+	}
+	testParamsNotEmpty(t, params)
+
+	configText := templateFill(testAccVcdDatasourceNsxtLists, params)
+	debugPrintf("#[DEBUG] CONFIGURATION: %s", configText)
+
+	if vcdShortTest {
+		t.Skip(acceptanceTestsSkipped)
+		return
+	}
+
+	resource.Test(t, resource.TestCase{
+		ProviderFactories: testAccProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: configText,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("data.vcd_nsxt_edgegateways.filtered", "items.#", "1"),
+					resource.TestCheckResourceAttrPair("data.vcd_nsxt_edgegateways.filtered", "items.0.id", "data.vcd_nsxt_edgegateway.single", "id"),
+					resource.TestCheckResourceAttrPair("data.vcd_nsxt_edgegateways.filtered", "items.0.external_network_id", "data.vcd_nsxt_edgegateway.single", "external_network_id"),
+					resource.TestCheckResourceAttr("data.vcd_network_routed_v2s.filtered", "items.#", "1"),
+					resource.TestCheckResourceAttrPair("data.vcd_network_routed_v2s.filtered", "items.0.id", "vcd_network_routed_v2.net", "id"),
+					resource.TestCheckResourceAttr("data.vcd_network_routed_v2s.filtered", "items.0.gateway", "10.10.102.1"),
+					resource.TestCheckResourceAttr("data.vcd_nsxt_nat_rules.all", "items.#", "1"),
+					resource.TestCheckResourceAttrPair("data.vcd_nsxt_nat_rules.all", "items.0.id", "vcd_nsxt_nat_rule.dnat", "id"),
+					resource.TestCheckResourceAttr("data.vcd_nsxt_nat_rules.all", "items.0.rule_type", "DNAT"),
+				),
+			},
+		},
+	})
+	postTestChecks(t)
+}
+
+const testAccVcdDatasourceNsxtLists = `
+data "vcd_nsxt_edgegateway" "single" {
+  org  = "{{.Org}}"
+  name = "{{.EdgeGateway}}"
+}
+
+data "vcd_nsxt_edgegateways" "filtered" {
+  org    = "{{.Org}}"
+  filter = "name=={{.EdgeGateway}}"
+}
+
+resource "vcd_network_routed_v2" "net" {
+  org             = "{{.Org}}"
+  name            = "{{.FuncName}}"
+  edge_gateway_id = data.vcd_nsxt_edgegateway.single.id
+  gateway         = "10.10.102.1"
+  prefix_length   = 24
+}
+
+resource "vcd_nsxt_nat_rule" "dnat" {
+  org             = "{{.Org}}"
+  edge_gateway_id = data.vcd_nsxt_edgegateway.single.id
+  name            = "{{.FuncName}}"
+  rule_type       = "DNAT"
+
+  # Using primary_ip from edge gateway
+  external_address = tolist(data.vcd_nsxt_edgegateway.single.subnet)[0].primary_ip
+  internal_address = "10.10.102.10"
+}
+
+data "vcd_network_routed_v2s" "filtered" {
+  org             = "{{.Org}}"
+  edge_gateway_id = data.vcd_nsxt_edgegateway.single.id
+  filter          = "name==${vcd_network_routed_v2.net.name}"
+}
+
+data "vcd_nsxt_nat_rules" "all" {
+  org             = "{{.Org}}"
+  edge_gateway_id = data.vcd_nsxt_edgegateway.single.id
+  filter          = "name==${vcd_nsxt_nat_rule.dnat.name}"
+}
+`
@@ -0,0 +1,60 @@
+package vcd
+
+import (
+	"context"
+	"net/url"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/vmware/go-vcloud-director/v3/govcd"
+)
+
+func datasourceVcdNsxtNatRules() *schema.Resource {
+	return &schema.Resource{
+		ReadContext: datasourceVcdNsxtNatRulesRead,
+		Schema: listDatasourceSchema("NSX-T NAT rule", datasourceVcdNsxtNatRule(), map[string]*schema.Schema{
+			"org": {
+				Type:     schema.TypeString,
+				Optional: true,
+				Description: "The name of organization to use, optional if defined at provider " +
+					"level. Useful when connected as sysadmin working across different organizations",
+			},
+			"edge_gateway_id": {
+				Type:        schema.TypeString,
+				Required:    true,
+				Description: "ID of the Edge Gateway that contains the NAT rules",
+			},
+		}),
+	}
+}
+
+func datasourceVcdNsxtNatRulesRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	vcdClient := meta.(*VCDClient)
+	orgName := d.Get("org").(string)
+	edgeGatewayId := d.Get("edge_gateway_id").(string)
+	nsxtEdge, err := vcdClient.GetNsxtEdgeGatewayById(orgName, edgeGatewayId)
+	if err != nil {
+		return diag.Errorf("error retrieving Edge Gateway: %s", err)
+	}
+
+	getNatRules := func(queryParameters url.Values) ([]*govcd.NsxtNatRule, error) {
+		return nsxtEdge.GetAllNatRules(queryParameters)
+	}
+
+	c := dsListConfig[*govcd.NsxtNatRule]{
+		entityLabel:     "NSX-T NAT rule",
+		itemDatasource:  datasourceVcdNsxtNatRule(),
+		getEntitiesFunc: getNatRules,
+		stateStoreFunc: func(vcdClient *VCDClient, d *schema.ResourceData, rule *govcd.NsxtNatRule) error {
+			dSet(d, "org", orgName)
+			dSet(d, "edge_gateway_id", edgeGatewayId)
+			err := setNsxtNatRuleData(rule.NsxtNatRule, d, vcdClient)
+			if err != nil {
+				return err
+			}
+			d.SetId(rule.NsxtNatRule.ID)
+			return nil
+		},
+	}
+	return readDatasourceList(ctx, d, meta, c)
+}
@@ -0,0 +1,62 @@
+package vcd
+
+import (
+	"context"
+	"net/url"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/vmware/go-vcloud-director/v3/types/v56"
+)
+
+func datasourceVcdOrgVdcs() *schema.Resource {
+	return &schema.Resource{
+		ReadContext: datasourceVcdOrgVdcsRead,
+		Schema: listDatasourceSchema("VDC", datasourceVcdOrgVdc(), map[string]*schema.Schema{
+			"org": {
+				Type:     schema.TypeString,
+				Optional: true,
+				Description: "The name of organization to use, optional if defined at provider " +
+					"level. Useful when connected as sysadmin working across different organizations",
+			},
+		}),
+	}
+}
+
+func datasourceVcdOrgVdcsRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	vcdClient := meta.(*VCDClient)
+	adminOrg, err := vcdClient.GetAdminOrgFromResource(d)
+	if err != nil {
+		return diag.Errorf(errorRetrievingOrg, err)
+	}
+
+	getVdcs := func(queryParameters url.Values) ([]*types.QueryResultOrgVdcRecordType, error) {
+		orgFilter := ""
+		// Tenants can only see the VDCs of their own Org, and the API returns an error when filtering by Org
+		if vcdClient.Client.IsSysAdmin {
+			orgFilter = "org==" + adminOrg.AdminOrg.HREF
+		}
+		filter := joinFiqlFilters(orgFilter, queryParameters.Get("filter"))
+		return queryAllRecords(&vcdClient.Client, vcdClient.Client.GetQueryType(types.QtOrgVdc), filter,
+			func(results *types.QueryResultRecordsType) []*types.QueryResultOrgVdcRecordType {
+				if vcdClient.Client.IsSysAdmin {
+					return results.OrgVdcAdminRecord
+				}
+				return results.OrgVdcRecord
+			})
+	}
+
+	itemDatasource := datasourceVcdOrgVdc()
+	c := dsListConfig[*types.QueryResultOrgVdcRecordType]{
+		entityLabel:     "VDC",
+		itemDatasource:  itemDatasource,
+		getEntitiesFunc: getVdcs,
+		stateStoreFunc: readWithItemDatasource(itemDatasource, func(vdc *types.QueryResultOrgVdcRecordType) map[string]string {
+			return map[string]string{
+				"org":  adminOrg.AdminOrg.Name,
+				"name": vdc.Name,
+			}
+		}),
+	}
+	return readDatasourceList(ctx, d, meta, c)
+}
@@ -0,0 +1,87 @@
+//go:build functional || ALL
+
+package vcd
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+)
+
+// TestAccVcdDatasourceLegacyLists checks the list data sources backed by the query service, using existing
+// infrastructure
+func TestAccVcdDatasourceLegacyLists(t *testing.T) {
+	preTestChecks(t)
+
+	var params = StringMap{
+		"Org":         testConfig.VCD.Org,
+		"Vdc":         testConfig.Nsxt.Vdc,
+		"Catalog":     testConfig.VCD.Catalog.NsxtBackedCatalogName,
+		"CatalogItem": testConfig.VCD.Catalog.NsxtCatalogItem,
+		"FuncName":    t.Name(),
+	}
+	testParamsNotEmpty(t, params)
+
+	configText := templateFill(testAccVcdDatasourceLegacyLists, params)
+	debugPrintf("#[DEBUG] CONFIGURATION: %s", configText)
+
+	if vcdShortTest {
+		t.Skip(acceptanceTestsSkipped)
+		return
+	}
+
+	resource.Test(t, resource.TestCase{
+		ProviderFactories: testAccProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: configText,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("data.vcd_org_vdcs.filtered", "items.#", "1"),
+					resource.TestCheckResourceAttrPair("data.vcd_org_vdcs.filtered", "items.0.id", "data.vcd_org_vdc.single", "id"),
+					resource.TestCheckResourceAttrPair("data.vcd_org_vdcs.filtered", "items.0.allocation_model", "data.vcd_org_vdc.single", "allocation_model"),
+					resource.TestMatchResourceAttr("data.vcd_org_vdcs.all", "items.#", regexp.MustCompile(`^[1-9]\d*$`)),
+					resource.TestCheckResourceAttr("data.vcd_catalog_items.filtered", "items.#", "1"),
+					resource.TestCheckResourceAttrPair("data.vcd_catalog_items.filtered", "items.0.id", "data.vcd_catalog_item.single", "id"),
+					resource.TestCheckResourceAttrPair("data.vcd_catalog_items.filtered", "items.0.created", "data.vcd_catalog_item.single", "created"),
+					resource.TestCheckResourceAttr("data.vcd_vms.none", "items.#", "0"),
+				),
+			},
+		},
+	})
+	postTestChecks(t)
+}
+
+const testAccVcdDatasourceLegacyLists = `
+data "vcd_org_vdcs" "all" {
+  org = "{{.Org}}"
+}
+
+data "vcd_org_vdcs" "filtered" {
+  org    = "{{.Org}}"
+  filter = "name=={{.Vdc}}"
+}
+
+data "vcd_org_vdc" "single" {
+  org  = "{{.Org}}"
+  name = "{{.Vdc}}"
+}
+
+data "vcd_catalog_items" "filtered" {
+  org     = "{{.Org}}"
+  catalog = "{{.Catalog}}"
+  filter  = "name=={{.CatalogItem}}"
+}
+
+data "vcd_catalog_item" "single" {
+  org     = "{{.Org}}"
+  catalog = "{{.Catalog}}"
+  name    = "{{.CatalogItem}}"
+}
+
+data "vcd_vms" "none" {
+  org    = "{{.Org}}"
+  vdc    = "{{.Vdc}}"
+  filter = "name=={{.FuncName}}-non-existing"
+}
+`
@@ -2,9 +2,12 @@ package vcd
 
 import (
 	"context"
+	"fmt"
+	"net/url"
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/vmware/go-vcloud-director/v3/govcd"
 )
 
 func datasourceVcdRde() *schema.Resource {
@@ -18,10 +21,12 @@ func datasourceVcdRde() *schema.Resource {
 					"level. Useful when connected as sysadmin working across different organizations",
 			},
 			"name": {
-				Type:        schema.TypeString,
-				Required:    true,
-				Description: "The name of the Runtime Defined Entity",
+				Type:         schema.TypeString,
+				Optional:     true,
+				ExactlyOneOf: []string{"name", "filter"},
+				Description:  "The name of the Runtime Defined Entity (optional if 'filter' is used)",
 			},
+			"filter": openApiFilterSchema("Runtime Defined Entity", false, true),
 			"rde_type_id": {
 				Type:        schema.TypeString,
 				Required:    true,
@@ -59,7 +64,13 @@ func datasourceVcdRde() *schema.Resource {
 
 func datasourceVcdRdeRead(_ context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	vcdClient := meta.(*VCDClient)
-	rde, err := getRde(d, vcdClient, "datasource")
+	var rde *govcd.DefinedEntity
+	var err error
+	if filter, hasFilter := d.GetOk("filter"); hasFilter {
+		rde, err = getRdeByFilter(d, vcdClient, filter)
+	} else {
+		rde, err = getRde(d, vcdClient, "datasource")
+	}
 	if err != nil {
 		return diag.FromErr(err)
 	}
@@ -97,3 +108,38 @@ func datasourceVcdRdeRead(_ context.Context, d *schema.ResourceData, meta interf
 
 	return nil
 }
+
+// getRdeByFilter retrieves the only Runtime Defined Entity of the RDE Type that matches the given filter block.
+// As in getRde, the RDEs that belong to a different Organization than 'org' are discarded
+func getRdeByFilter(d *schema.ResourceData, vcdClient *VCDClient, filter interface{}) (*govcd.DefinedEntity, error) {
+	rdeTypeId := d.Get("rde_type_id").(string)
+	rdeType, err := vcdClient.GetRdeTypeById(rdeTypeId)
+	if err != nil {
+		return nil, fmt.Errorf("could not get RDE Type with ID '%s': %s", rdeTypeId, err)
+	}
+
+	getRdes := func(queryParameters url.Values) ([]*govcd.DefinedEntity, error) {
+		rdes, err := rdeType.GetAllRdes(queryParameters)
+		if err != nil {
+			return nil, err
+		}
+		orgName, hasOrg := d.GetOk("org")
+		if !hasOrg {
+			return rdes, nil
+		}
+		var filteredRdes []*govcd.DefinedEntity
+		for _, rde := range rdes {
+			if rde.DefinedEntity.Org == nil || orgName == rde.DefinedEntity.Org.Name {
+				filteredRdes = append(filteredRdes, rde)
+			}
+		}
+		return filteredRdes, nil
+	}
+
+	return getOpenApiEntityByFilter(&vcdClient.Client, filter, "Runtime Defined Entity", openApiFilterConfig[*govcd.DefinedEntity]{
+		getAllEntitiesFunc: getRdes,
+		getIdAndNameFunc: func(e *govcd.DefinedEntity) (string, string) {
+			return e.DefinedEntity.ID, e.DefinedEntity.Name
+		},
+	})
+}
@@ -12,6 +12,7 @@ import (
 	"os"
 	"regexp"
 	"sort"
+	"strconv"
 	"strings"
 	"time"
 )
@@ -23,6 +24,71 @@ type resourceRef struct {
 	href         string
 	parent       string
 	importId     bool
+	// importIdentifier, when set, is used as last element of the import ID instead of name or ID
+	importIdentifier string
+	// attributes contains type specific values, exposed in the 'items' attribute of the data source
+	attributes map[string]string
+}
+
+// resourceListEntry is a resource found by a listing function, with its final
+// resource type and the names of its ancestors
+type resourceListEntry struct {
+	ref          resourceRef
+	resourceType string
+	ancestors    []string
+}
+
+var illegalHclNameCharsRegex = regexp.MustCompile(`[^a-zA-Z0-9_\-]+`)
+
+// sanitizedName returns the entity name, stripped of the characters that are not allowed in HCL resource names
+func (entry resourceListEntry) sanitizedName() string {
+	// Names can have special characters in VCD, but must not have in HCL resource names
+	return illegalHclNameCharsRegex.ReplaceAllString(entry.ref.name, "_")
+}
+
+// address returns the name of the resource block that will hold this entry in generated HCL
+func (entry resourceListEntry) address() string {
+	return entry.sanitizedName() + "-" + idTail(entry.ref.id)
+}
+
+// identifier returns the last element of the import ID, which is usually either the name or the ID of the entity
+func (entry resourceListEntry) identifier() string {
+	if entry.ref.importIdentifier != "" {
+		return entry.ref.importIdentifier
+	}
+	if entry.ref.importId {
+		return entry.ref.id
+	}
+	return entry.ref.name
+}
+
+// importId returns the full import ID, including the ancestors
+func (entry resourceListEntry) importId() string {
+	if len(entry.ancestors) > 0 {
+		return strings.Join(entry.ancestors, ImportSeparator) + ImportSeparator + entry.identifier()
+	}
+	return entry.identifier()
+}
+
+// importCommandId returns the import ID used in the "terraform import" commands of the list, where the identifier
+// always follows a separator, even without ancestors
+func (entry resourceListEntry) importCommandId() string {
+	return strings.Join(entry.ancestors, ImportSeparator) + ImportSeparator + entry.identifier()
+}
+
+// importBlock returns the HCL import block for this entry
+func (entry resourceListEntry) importBlock() string {
+	ancestorsText := ""
+	if len(entry.ancestors) > 0 {
+		ancestorsText = strings.Join(entry.ancestors, ImportSeparator) + ImportSeparator
+	}
+	var importData strings.Builder
+	importData.WriteString(fmt.Sprintf("# Import directive for %s %s%s \n", entry.resourceType, ancestorsText, entry.sanitizedName()))
+	importData.WriteString("import {\n")
+	importData.WriteString(fmt.Sprintf("  to = %s.%s\n", entry.resourceType, entry.address()))
+	importData.WriteString(fmt.Sprintf("  id = \"%s\"\n", entry.importId()))
+	importData.WriteString("}\n\n")
+	return importData.String()
 }
 
 type vappNetworkType int
@@ -78,6 +144,61 @@ func datasourceVcdResourceList() *schema.Resource {
 					Type: schema.TypeString,
 				},
 			},
+			"items": {
+				Type:        schema.TypeList,
+				Computed:    true,
+				Description: "Holds the list of requested resources as structured objects",
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"name": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "Name of the resource",
+						},
+						"id": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "ID of the resource",
+						},
+						"href": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "HREF of the resource, when available",
+						},
+						"resource_type": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "Terraform resource type of the resource",
+						},
+						"parent": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "Name of the direct parent of the resource, when available",
+						},
+						"ancestors": {
+							Type:        schema.TypeList,
+							Computed:    true,
+							Description: "Names of the ancestors of the resource, as used in its import ID",
+							Elem: &schema.Schema{
+								Type: schema.TypeString,
+							},
+						},
+						"import_id": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "ID to use when importing the resource",
+						},
+						"attributes": {
+							Type:        schema.TypeMap,
+							Computed:    true,
+							Description: "Type specific attributes of the resource",
+							Elem: &schema.Schema{
+								Type: schema.TypeString,
+							},
+						},
+					},
+				},
+			},
 			"list_mode": {
 				Type:        schema.TypeString,
 				Optional:    true,
@@ -90,12 +211,13 @@ func datasourceVcdResourceList() *schema.Resource {
 					"import",    // The list will contain the terraform import command
 					"name_id",   // The list will contain name + ID for each item
 					"hierarchy", // The list will contain parent names + resource name for each item
+					"hcl",       // The list will contain the resource addresses, and the import file will contain full resource definitions
 				}, true),
 			},
 			"import_file_name": {
 				Type:        schema.TypeString,
 				Optional:    true,
-				Description: "File where to store the import info - Only used with 'import' list mode ",
+				Description: "File where to store the import info - Only used with 'import' and 'hcl' list modes",
 			},
 			"name_regex": {
 				Type:         schema.TypeString,
@@ -109,11 +231,34 @@ func datasourceVcdResourceList() *schema.Resource {
 				Default:     "  ",
 				Description: "Separator for name_id combination",
 			},
+			"recursive": {
+				Type:     schema.TypeBool,
+				Optional: true,
+				Default:  false,
+				Description: "If true, lists the given organization or VDC group (in 'parent') and all the entities they contain, " +
+					"walking their hierarchy. Only 'vcd_org' and 'vcd_vdc_group' are supported as 'resource_type'",
+			},
+			"include_types": {
+				Type:        schema.TypeSet,
+				Optional:    true,
+				Description: "Only used with 'recursive'. If set, only resources of these types will be included in the list",
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+				},
+			},
+			"exclude_types": {
+				Type:        schema.TypeSet,
+				Optional:    true,
+				Description: "Only used with 'recursive'. Resources of these types will be excluded from the list",
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+				},
+			},
 		},
 	}
 }
 
-func getSiteAssociationList(d *schema.ResourceData, meta interface{}, resType string) (list []string, err error) {
+func getSiteAssociationList(d *schema.ResourceData, meta interface{}, resType string) (list []resourceListEntry, err error) {
 	client := meta.(*VCDClient)
 
 	siteAssociationList, err := client.VCDClient.Client.QueryAllSiteAssociations(nil, nil)
@@ -136,7 +281,7 @@ func getSiteAssociationList(d *schema.ResourceData, meta interface{}, resType st
 	return genericResourceList(d, resType, nil, items)
 }
 
-func getOrgAssociationList(d *schema.ResourceData, meta interface{}, resType string) (list []string, err error) {
+func getOrgAssociationList(d *schema.ResourceData, meta interface{}, resType string) (list []resourceListEntry, err error) {
 	client := meta.(*VCDClient)
 
 	orgAssociationList, err := client.VCDClient.Client.QueryAllOrgAssociations(nil, nil)
@@ -159,7 +304,7 @@ func getOrgAssociationList(d *schema.ResourceData, meta interface{}, resType str
 	return genericResourceList(d, resType, nil, items)
 }
 
-func getOrgList(d *schema.ResourceData, meta interface{}, resType string) (list []string, err error) {
+func getOrgList(d *schema.ResourceData, meta interface{}, resType string) (list []resourceListEntry, err error) {
 	client := meta.(*VCDClient)
 
 	orgList, err := client.VCDClient.GetOrgList()
@@ -179,7 +324,7 @@ func getOrgList(d *schema.ResourceData, meta interface{}, resType string) (list
 	return genericResourceList(d, resType, nil, items)
 }
 
-func getPvdcList(d *schema.ResourceData, meta interface{}) (list []string, err error) {
+func getPvdcList(d *schema.ResourceData, meta interface{}) (list []resourceListEntry, err error) {
 	client := meta.(*VCDClient)
 
 	pvdcList, err := client.QueryProviderVdcs()
@@ -200,7 +345,7 @@ func getPvdcList(d *schema.ResourceData, meta interface{}) (list []string, err e
 	return genericResourceList(d, "vcd_provider_vdc", nil, items)
 }
 
-func getVdcGroups(d *schema.ResourceData, meta interface{}) (list []string, err error) {
+func getVdcGroups(d *schema.ResourceData, meta interface{}) (list []resourceListEntry, err error) {
 	client := meta.(*VCDClient)
 
 	org, err := client.GetAdminOrg(firstNonEmpty(d.Get("org").(string), d.Get("parent").(string)))
@@ -225,11 +370,11 @@ func getVdcGroups(d *schema.ResourceData, meta interface{}) (list []string, err
 	return genericResourceList(d, "vcd_vdc_group", []string{org.AdminOrg.Name}, items)
 }
 
-func externalNetworkList(d *schema.ResourceData, meta interface{}) (list []string, err error) {
+func externalNetworkList(d *schema.ResourceData, meta interface{}) (list []resourceListEntry, err error) {
 	client := meta.(*VCDClient)
 
 	if !client.VCDClient.Client.IsSysAdmin {
-		return []string{}, fmt.Errorf("external network list requires system administrator privileges")
+		return nil, fmt.Errorf("external network list requires system administrator privileges")
 	}
 	externalNetworks, err := client.GetExternalNetworks()
 
@@ -242,7 +387,7 @@ func externalNetworkList(d *schema.ResourceData, meta interface{}) (list []strin
 		externalNetwork.ExternalNetwork.HREF = en.HREF
 		err = externalNetwork.Refresh()
 		if err != nil {
-			return []string{}, err
+			return nil, err
 		}
 
 		items = append(items, resourceRef{
@@ -256,7 +401,7 @@ func externalNetworkList(d *schema.ResourceData, meta interface{}) (list []strin
 	return genericResourceList(d, "vcd_external_network", nil, items)
 }
 
-func rightsList(d *schema.ResourceData, meta interface{}) (list []string, err error) {
+func rightsList(d *schema.ResourceData, meta interface{}) (list []resourceListEntry, err error) {
 	client := meta.(*VCDClient)
 
 	org, err := client.GetAdminOrg(firstNonEmpty(d.Get("org").(string), d.Get("parent").(string)))
@@ -282,7 +427,7 @@ func rightsList(d *schema.ResourceData, meta interface{}) (list []string, err er
 	return genericResourceList(d, "vcd_right", []string{org.AdminOrg.Name}, items)
 }
 
-func rolesList(d *schema.ResourceData, meta interface{}) (list []string, err error) {
+func rolesList(d *schema.ResourceData, meta interface{}) (list []resourceListEntry, err error) {
 	client := meta.(*VCDClient)
 
 	org, err := client.GetAdminOrg(firstNonEmpty(d.Get("org").(string), d.Get("parent").(string)))
@@ -309,7 +454,7 @@ func rolesList(d *schema.ResourceData, meta interface{}) (list []string, err err
 
 }
 
-func globalRolesList(d *schema.ResourceData, meta interface{}) (list []string, err error) {
+func globalRolesList(d *schema.ResourceData, meta interface{}) (list []resourceListEntry, err error) {
 	client := meta.(*VCDClient)
 	globalRoles, err := client.Client.GetAllGlobalRoles(nil)
 	if err != nil {
@@ -329,7 +474,7 @@ func globalRolesList(d *schema.ResourceData, meta interface{}) (list []string, e
 	return genericResourceList(d, "vcd_global_role", nil, items)
 }
 
-func libraryCertificateList(d *schema.ResourceData, meta interface{}) (list []string, err error) {
+func libraryCertificateList(d *schema.ResourceData, meta interface{}) (list []resourceListEntry, err error) {
 	client := meta.(*VCDClient)
 
 	adminOrg, err := client.GetAdminOrg(firstNonEmpty(d.Get("org").(string), d.Get("parent").(string)))
@@ -365,7 +510,7 @@ func libraryCertificateList(d *schema.ResourceData, meta interface{}) (list []st
 	return genericResourceList(d, "vcd_certificate_library", ancestors, items)
 }
 
-func rightsBundlesList(d *schema.ResourceData, meta interface{}) (list []string, err error) {
+func rightsBundlesList(d *schema.ResourceData, meta interface{}) (list []resourceListEntry, err error) {
 	client := meta.(*VCDClient)
 
 	rightsBundles, err := client.Client.GetAllRightsBundles(nil)
@@ -386,7 +531,7 @@ func rightsBundlesList(d *schema.ResourceData, meta interface{}) (list []string,
 	return genericResourceList(d, "vcd_rights_bundle", nil, items)
 }
 
-func catalogList(d *schema.ResourceData, meta interface{}, resType string) (list []string, err error) {
+func catalogList(d *schema.ResourceData, meta interface{}, resType string) (list []resourceListEntry, err error) {
 	client := meta.(*VCDClient)
 	org, err := client.GetAdminOrg(firstNonEmpty(d.Get("org").(string), d.Get("parent").(string)))
 	if err != nil {
@@ -398,7 +543,7 @@ func catalogList(d *schema.ResourceData, meta interface{}, resType string) (list
 	for _, catRef := range org.AdminOrg.Catalogs.Catalog {
 		catalog, err := org.GetCatalogByHref(catRef.HREF)
 		if err != nil {
-			return []string{}, err
+			return nil, err
 		}
 		items = append(items, resourceRef{
 			name: catRef.Name,
@@ -410,7 +555,7 @@ func catalogList(d *schema.ResourceData, meta interface{}, resType string) (list
 }
 
 // catalogItemList finds either catalogItem or mediaItem
-func catalogItemList(d *schema.ResourceData, meta interface{}, wantResource string) (list []string, err error) {
+func catalogItemList(d *schema.ResourceData, meta interface{}, wantResource string) (list []resourceListEntry, err error) {
 	client := meta.(*VCDClient)
 
 	org, err := client.GetAdminOrg(d.Get("org").(string))
@@ -466,7 +611,7 @@ func catalogItemList(d *schema.ResourceData, meta interface{}, wantResource stri
 }
 
 // vappTemplateList finds all vApp Templates
-func vappTemplateList(d *schema.ResourceData, meta interface{}) (list []string, err error) {
+func vappTemplateList(d *schema.ResourceData, meta interface{}) (list []resourceListEntry, err error) {
 	client := meta.(*VCDClient)
 	org, err := client.GetOrg(d.Get("org").(string))
 	if err != nil {
@@ -501,7 +646,7 @@ func vappTemplateList(d *schema.ResourceData, meta interface{}) (list []string,
 	return genericResourceList(d, "vcd_catalog_vapp_template", []string{org.Org.Name, catalogName}, items)
 }
 
-func vdcList(d *schema.ResourceData, meta interface{}, resType string) (list []string, err error) {
+func vdcList(d *schema.ResourceData, meta interface{}, resType string) (list []resourceListEntry, err error) {
 	client := meta.(*VCDClient)
 
 	org, err := client.GetAdminOrg(firstNonEmpty(d.Get("org").(string), d.Get("parent").(string)))
@@ -521,7 +666,7 @@ func vdcList(d *schema.ResourceData, meta interface{}, resType string) (list []s
 	return genericResourceList(d, resType, []string{org.AdminOrg.Name}, items)
 }
 
-func orgUserList(d *schema.ResourceData, meta interface{}) (list []string, err error) {
+func orgUserList(d *schema.ResourceData, meta interface{}) (list []resourceListEntry, err error) {
 	client := meta.(*VCDClient)
 
 	org, err := client.GetAdminOrg(firstNonEmpty(d.Get("org").(string), d.Get("parent").(string)))
@@ -541,7 +686,7 @@ func orgUserList(d *schema.ResourceData, meta interface{}) (list []string, err e
 	return genericResourceList(d, "vcd_org_user", []string{org.AdminOrg.Name}, items)
 }
 
-func networkList(d *schema.ResourceData, meta interface{}) (list []string, err error) {
+func networkList(d *schema.ResourceData, meta interface{}) (list []resourceListEntry, err error) {
 	client := meta.(*VCDClient)
 
 	vdcName, err := getVdcName(client, d)
@@ -582,7 +727,7 @@ func networkList(d *schema.ResourceData, meta interface{}) (list []string, err e
 		}
 		network, err := vdc.GetOrgVdcNetworkByHref(net.HREF)
 		if err != nil {
-			return []string{}, err
+			return nil, err
 		}
 		items = append(items, resourceRef{
 			name:         network.OrgVDCNetwork.Name,
@@ -591,6 +736,9 @@ func networkList(d *schema.ResourceData, meta interface{}) (list []string, err e
 			parent:       vdcName,
 			resourceType: trueResourceType,
 			importId:     false,
+			attributes: map[string]string{
+				"network_type": networkType,
+			},
 		})
 	}
 
@@ -598,13 +746,12 @@ func networkList(d *schema.ResourceData, meta interface{}) (list []string, err e
 }
 
 // orgNetworkListV2 uses OpenAPI endpoint to query Org VDC networks and return their list
-func orgNetworkListV2(d *schema.ResourceData, meta interface{}) (list []string, err error) {
+func orgNetworkListV2(d *schema.ResourceData, meta interface{}) (list []resourceListEntry, err error) {
 	client := meta.(*VCDClient)
 	vdcName, err := getVdcName(client, d)
 	if err != nil {
 		return list, err
 	}
-	wantedType := d.Get("resource_type").(string)
 	org, vdc, err := client.GetOrgAndVdc(d.Get("org").(string), vdcName)
 	if err != nil {
 		return list, err
@@ -614,6 +761,37 @@ func orgNetworkListV2(d *schema.ResourceData, meta interface{}) (list []string,
 	if err != nil {
 		return list, err
 	}
+	return openApiOrgNetworkList(d, client, orgVdcNetworkList, []string{org.Org.Name, vdc.Vdc.Name})
+}
+
+// vdcGroupNetworkListV2 uses OpenAPI endpoint to query the Org VDC networks owned by the VDC group given as "parent"
+// and return their list
+func vdcGroupNetworkListV2(d *schema.ResourceData, meta interface{}) (list []resourceListEntry, err error) {
+	client := meta.(*VCDClient)
+	vdcGroupName := d.Get("parent").(string)
+	if vdcGroupName == "" {
+		return nil, fmt.Errorf(`no VDC group name (as "parent") given`)
+	}
+	adminOrg, err := client.GetAdminOrgFromResource(d)
+	if err != nil {
+		return list, err
+	}
+	vdcGroup, err := adminOrg.GetVdcGroupByName(vdcGroupName)
+	if err != nil {
+		return list, fmt.Errorf("error retrieving VDC group '%s': %s", vdcGroupName, err)
+	}
+
+	orgVdcNetworkList, err := vdcGroup.GetAllOpenApiOrgVdcNetworks(nil)
+	if err != nil {
+		return list, err
+	}
+	return openApiOrgNetworkList(d, client, orgVdcNetworkList, []string{adminOrg.AdminOrg.Name, vdcGroup.VdcGroup.Name})
+}
+
+// openApiOrgNetworkList returns the list of the given OpenAPI Org VDC networks with the requested resource type.
+// The last ancestor is the owner of the networks
+func openApiOrgNetworkList(d *schema.ResourceData, client *VCDClient, orgVdcNetworkList []*govcd.OpenApiOrgVdcNetwork, ancestors []string) ([]resourceListEntry, error) {
+	wantedType := d.Get("resource_type").(string)
 	var items []resourceRef
 
 	var resourceType string
@@ -641,13 +819,16 @@ func orgNetworkListV2(d *schema.ResourceData, meta interface{}) (list []string,
 			name:         net.OpenApiOrgVdcNetwork.Name,
 			id:           net.OpenApiOrgVdcNetwork.ID,
 			href:         href.Path,
-			parent:       vdcName,
+			parent:       ancestors[len(ancestors)-1],
 			importId:     false,
 			resourceType: trueResourceType,
+			attributes: map[string]string{
+				"network_type": net.OpenApiOrgVdcNetwork.NetworkType,
+			},
 		})
 	}
 
-	return genericResourceList(d, resourceType, []string{org.Org.Name, vdc.Vdc.Name}, items)
+	return genericResourceList(d, resourceType, ancestors, items)
 }
 
 func getVdcName(client *VCDClient, d *schema.ResourceData) (string, error) {
@@ -664,7 +845,7 @@ func getVdcName(client *VCDClient, d *schema.ResourceData) (string, error) {
 	return vdcName, nil
 }
 
-func getEdgeGatewayList(d *schema.ResourceData, meta interface{}, resType string) (list []string, err error) {
+func getEdgeGatewayList(d *schema.ResourceData, meta interface{}, resType string) (list []resourceListEntry, err error) {
 	client := meta.(*VCDClient)
 
 	vdcName, err := getVdcName(client, d)
@@ -685,7 +866,7 @@ func getEdgeGatewayList(d *schema.ResourceData, meta interface{}, resType string
 
 		edgeGateway, err := vdc.GetEdgeGatewayByName(ert.Name, false)
 		if err != nil {
-			return []string{}, err
+			return nil, err
 		}
 		items = append(items, resourceRef{
 			name:   edgeGateway.EdgeGateway.Name,
@@ -697,7 +878,7 @@ func getEdgeGatewayList(d *schema.ResourceData, meta interface{}, resType string
 	return genericResourceList(d, resType, []string{org.Org.Name, vdc.Vdc.Name}, items)
 }
 
-func distributedSwitchList(d *schema.ResourceData, meta interface{}) (list []string, err error) {
+func distributedSwitchList(d *schema.ResourceData, meta interface{}) (list []resourceListEntry, err error) {
 	client := meta.(*VCDClient)
 
 	vCenterName := d.Get("parent").(string)
@@ -726,7 +907,7 @@ func distributedSwitchList(d *schema.ResourceData, meta interface{}) (list []str
 	return genericResourceList(d, "vcd_distributed_switch", []string{vCenter.VSphereVCenter.Name}, items)
 }
 
-func transportZoneList(d *schema.ResourceData, meta interface{}) (list []string, err error) {
+func transportZoneList(d *schema.ResourceData, meta interface{}) (list []resourceListEntry, err error) {
 	client := meta.(*VCDClient)
 
 	nsxtManagerName := d.Get("parent").(string)
@@ -765,7 +946,7 @@ func transportZoneList(d *schema.ResourceData, meta interface{}) (list []string,
 	return genericResourceList(d, "vcd_nsxt_transport_zone", []string{manager.Name}, items)
 }
 
-func importablePortGroupList(d *schema.ResourceData, meta interface{}) (list []string, err error) {
+func importablePortGroupList(d *schema.ResourceData, meta interface{}) (list []resourceListEntry, err error) {
 	client := meta.(*VCDClient)
 
 	vCenterName := d.Get("parent").(string)
@@ -794,7 +975,7 @@ func importablePortGroupList(d *schema.ResourceData, meta interface{}) (list []s
 	return genericResourceList(d, "vcd_importable_port_group", []string{vCenter.VSphereVCenter.Name}, items)
 }
 
-func networkPoolList(d *schema.ResourceData, meta interface{}) (list []string, err error) {
+func networkPoolList(d *schema.ResourceData, meta interface{}) (list []resourceListEntry, err error) {
 	client := meta.(*VCDClient)
 
 	networkPools, err := client.QueryNetworkPools()
@@ -814,7 +995,7 @@ func networkPoolList(d *schema.ResourceData, meta interface{}) (list []string, e
 	return genericResourceList(d, "vcd_network_pool", nil, items)
 }
 
-func nsxtManagerList(d *schema.ResourceData, meta interface{}) (list []string, err error) {
+func nsxtManagerList(d *schema.ResourceData, meta interface{}) (list []resourceListEntry, err error) {
 	client := meta.(*VCDClient)
 
 	managers, err := client.QueryNsxtManagers()
@@ -835,7 +1016,7 @@ func nsxtManagerList(d *schema.ResourceData, meta interface{}) (list []string, e
 	return genericResourceList(d, "vcd_nsxt_manager", nil, items)
 }
 
-func vcenterList(d *schema.ResourceData, meta interface{}) (list []string, err error) {
+func vcenterList(d *schema.ResourceData, meta interface{}) (list []resourceListEntry, err error) {
 	client := meta.(*VCDClient)
 
 	vcenters, err := client.GetAllVCenters(nil)
@@ -860,7 +1041,7 @@ func vcenterList(d *schema.ResourceData, meta interface{}) (list []string, err e
 	return genericResourceList(d, "vcd_vcenter", nil, items)
 }
 
-func getNsxtEdgeGatewayList(d *schema.ResourceData, meta interface{}) (list []string, err error) {
+func getNsxtEdgeGatewayList(d *schema.ResourceData, meta interface{}) (list []resourceListEntry, err error) {
 	client := meta.(*VCDClient)
 
 	// A NSX-T edge gateway could belong to either a VDC or a VDC group
@@ -928,7 +1109,7 @@ func getNsxtEdgeGatewayList(d *schema.ResourceData, meta interface{}) (list []st
 	return genericResourceList(d, "vcd_nsxt_edgegateway", ancestors, items)
 }
 
-func diskList(d *schema.ResourceData, meta interface{}) (list []string, err error) {
+func diskList(d *schema.ResourceData, meta interface{}) (list []resourceListEntry, err error) {
 	client := meta.(*VCDClient)
 	vdcName, err := getVdcName(client, d)
 	if err != nil {
@@ -951,12 +1132,17 @@ func diskList(d *schema.ResourceData, meta interface{}) (list []string, err erro
 			id:     extractUuid(diskRef.HREF),
 			href:   diskRef.HREF,
 			parent: vdc.Vdc.Name,
+			attributes: map[string]string{
+				"size_mb":         strconv.FormatInt(diskRef.SizeMb, 10),
+				"storage_profile": diskRef.StorageProfileName,
+				"is_attached":     strconv.FormatBool(diskRef.IsAttached),
+			},
 		})
 	}
 	return genericResourceList(d, "vcd_independent_disk", []string{org.Org.Name, vdc.Vdc.Name}, items)
 }
 
-func vappList(d *schema.ResourceData, meta interface{}, resType string) (list []string, err error) {
+func vappList(d *schema.ResourceData, meta interface{}, resType string) (list []resourceListEntry, err error) {
 	client := meta.(*VCDClient)
 	vdcName, err := getVdcName(client, d)
 	if err != nil {
@@ -984,7 +1170,7 @@ func vappList(d *schema.ResourceData, meta interface{}, resType string) (list []
 	return genericResourceList(d, resType, []string{org.Org.Name, vdc.Vdc.Name}, items)
 }
 
-func vmList(d *schema.ResourceData, meta interface{}, vmType typeOfVm) (list []string, err error) {
+func vmList(d *schema.ResourceData, meta interface{}, vmType typeOfVm) (list []resourceListEntry, err error) {
 	client := meta.(*VCDClient)
 
 	org, vdc, err := client.GetOrgAndVdc(d.Get("org").(string), d.Get("vdc").(string))
@@ -1014,6 +1200,12 @@ func vmList(d *schema.ResourceData, meta interface{}, vmType typeOfVm) (list []s
 			href:     vm.HREF,
 			parent:   vm.ContainerName,           // name of the hidden vApp
 			importId: vmType == standaloneVmType, // import should use entity ID rather than name
+			attributes: map[string]string{
+				"status":          vm.Status,
+				"ip_address":      vm.IpAddress,
+				"memory_mb":       strconv.Itoa(vm.MemoryMB),
+				"storage_profile": vm.StorageProfileName,
+			},
 		})
 	}
 	if vmType == vappVmType {
@@ -1022,7 +1214,7 @@ func vmList(d *schema.ResourceData, meta interface{}, vmType typeOfVm) (list []s
 	return genericResourceList(d, "vcd_vm", []string{org.Org.Name, vdc.Vdc.Name}, items)
 }
 
-func vappNetworkList(d *schema.ResourceData, vnt vappNetworkType, meta interface{}) (list []string, err error) {
+func vappNetworkList(d *schema.ResourceData, vnt vappNetworkType, meta interface{}) (list []resourceListEntry, err error) {
 	client := meta.(*VCDClient)
 
 	org, vdc, err := client.GetOrgAndVdc(d.Get("org").(string), d.Get("vdc").(string))
@@ -1064,7 +1256,7 @@ func vappNetworkList(d *schema.ResourceData, vnt vappNetworkType, meta interface
 	return genericResourceList(d, "vcd_vapp_network", []string{org.Org.Name, vdc.Vdc.Name, vappName}, items)
 }
 
-func vdcTemplateList(d *schema.ResourceData, meta interface{}) (list []string, err error) {
+func vdcTemplateList(d *schema.ResourceData, meta interface{}) (list []resourceListEntry, err error) {
 	client := meta.(*VCDClient)
 
 	parentOrg := d.Get("parent").(string)
@@ -1110,7 +1302,7 @@ func vdcTemplateList(d *schema.ResourceData, meta interface{}) (list []string, e
 	return genericResourceList(d, "vcd_org_vdc_template", ancestors, items)
 }
 
-func nsxtAlbServiceEngineGroup(d *schema.ResourceData, meta interface{}) (list []string, err error) {
+func nsxtAlbServiceEngineGroup(d *schema.ResourceData, meta interface{}) (list []resourceListEntry, err error) {
 	client := meta.(*VCDClient)
 	allSegs, err := client.GetAllAlbServiceEngineGroups("", nil)
 	if err != nil {
@@ -1128,21 +1320,59 @@ func nsxtAlbServiceEngineGroup(d *schema.ResourceData, meta interface{}) (list [
 	return genericResourceList(d, "vcd_nsxt_alb_service_engine_group", nil, items)
 }
 
-func nsxtAlbServiceEngineGroupAssignment(d *schema.ResourceData, meta interface{}) (list []string, err error) {
+// getNsxtEdgeGatewayFromParent retrieves the NSX-T edge gateway named in 'parent', which belongs to the VDC or VDC group
+// named in 'vdc' (or to the VDC set in the provider). It also returns the ancestors to use for the edge gateway children
+func getNsxtEdgeGatewayFromParent(d *schema.ResourceData, meta interface{}) (*govcd.NsxtEdgeGateway, []string, error) {
 	client := meta.(*VCDClient)
 	edgeGatewayName := d.Get("parent").(string)
 	if edgeGatewayName == "" {
-		return nil, fmt.Errorf(`edge gateway name (as "parent") is required for this task`)
+		return nil, nil, fmt.Errorf(`edge gateway name (as "parent") is required for this task`)
 	}
-	org, vdc, err := client.GetOrgAndVdc(d.Get("org").(string), d.Get("vdc").(string))
+	ownerName := d.Get("vdc").(string)
+	if ownerName == "" {
+		ownerName = client.Vdc
+	}
+	if ownerName == "" {
+		return nil, nil, fmt.Errorf("no VDC or VDC group name provided")
+	}
+	org, err := client.GetOrg(d.Get("org").(string))
+	if err != nil {
+		return nil, nil, err
+	}
+	adminOrg, err := client.GetAdminOrg(org.Org.Name)
 	if err != nil {
-		return nil, fmt.Errorf("could not retrieve Org '%s' and VDC '%s': %s", d.Get("org"), d.Get("vdc"), err)
+		return nil, nil, err
+	}
+
+	// Same as in getNsxtEdgeGatewayList, we first try with a VDC group, and then with a VDC
+	var ownerId string
+	vdcGroup, err := adminOrg.GetVdcGroupByName(ownerName)
+	switch {
+	case err == nil:
+		ownerId = vdcGroup.VdcGroup.Id
+	case govcd.ContainsNotFound(err):
+		vdc, err := adminOrg.GetVDCByName(ownerName, false)
+		if err != nil {
+			return nil, nil, fmt.Errorf("neither a VDC or a VDC group found with name '%s'", ownerName)
+		}
+		if vdc.IsNsxv() {
+			return nil, nil, fmt.Errorf("VDC '%s' is not backed by NSX-T", ownerName)
+		}
+		ownerId = vdc.Vdc.ID
+	default:
+		return nil, nil, fmt.Errorf("error retrieving VDC group '%s': %s", ownerName, err)
 	}
-	if vdc.IsNsxv() {
-		return nil, fmt.Errorf("can't list ALB Service Engine Groups from a NSX-V VDC")
+
+	nsxtEdgeGateway, err := org.GetNsxtEdgeGatewayByNameAndOwnerId(edgeGatewayName, ownerId)
+	if err != nil {
+		return nil, nil, fmt.Errorf("error retrieving NSX-T edge gateway '%s': %s", edgeGatewayName, err)
 	}
+	return nsxtEdgeGateway, []string{org.Org.Name, ownerName, nsxtEdgeGateway.EdgeGateway.Name}, nil
+}
 
-	nsxtEdgeGateway, err := vdc.GetNsxtEdgeGatewayByName(edgeGatewayName)
+func nsxtAlbServiceEngineGroupAssignment(d *schema.ResourceData, meta interface{}) (list []resourceListEntry, err error) {
+	client := meta.(*VCDClient)
+	nsxtEdgeGateway, ancestors, err := getNsxtEdgeGatewayFromParent(d, meta)
 	if err != nil {
 		return nil, err
 	}
@@ -1168,234 +1398,1022 @@ func nsxtAlbServiceEngineGroupAssignment(d *schema.ResourceData, meta interface{
 		}
 	}
 
-	return genericResourceList(d, "vcd_nsxt_alb_edgegateway_service_engine_group", []string{org.Org.Name, vdc.Vdc.Name, nsxtEdgeGateway.EdgeGateway.Name}, items)
+	return genericResourceList(d, "vcd_nsxt_alb_edgegateway_service_engine_group", ancestors, items)
 }
 
-func genericResourceList(d *schema.ResourceData, resType string, ancestors []string, refs []resourceRef) (list []string, err error) {
-	listMode := d.Get("list_mode").(string)
-	nameIdSeparator := d.Get("name_id_separator").(string)
-	importFile := d.Get("import_file_name").(string)
-	nameRegex := d.Get("name_regex").(string)
-	var importData strings.Builder
-	importData.WriteString(fmt.Sprintf("# Generated by vcd_resource_list - %s\n", time.Now().Format(time.RFC3339)))
-	var reName *regexp.Regexp
-	if nameRegex != "" {
-		reName, err = regexp.Compile(nameRegex)
-		if err != nil {
-			return nil, fmt.Errorf("[vcd_resource_list - %s] error compiling regular expression given with 'name_regex' '%s': %s",
-				d.Get("name").(string), nameRegex, err)
-		}
+func nsxtNatRuleList(d *schema.ResourceData, meta interface{}) (list []resourceListEntry, err error) {
+	nsxtEdgeGateway, ancestors, err := getNsxtEdgeGatewayFromParent(d, meta)
+	if err != nil {
+		return nil, err
 	}
-	for _, ref := range refs {
-		resourceType := resType
-		if ref.resourceType != "" {
-			resourceType = ref.resourceType
-		}
-		if reName != nil {
-			// If the regular expression doesn't match, the resource is skipped from the list
-			if reName.FindString(ref.name) == "" {
-				continue
-			}
-		}
-		switch listMode {
-		case "name":
-			list = append(list, ref.name)
-		case "id":
-			list = append(list, ref.id)
-		case "name_id":
-			list = append(list, ref.name+nameIdSeparator+ref.id)
-		case "hierarchy":
-			// If the parent is already present in the ancestors slice, don't repeat it
-			addParent := true
-			for _, ancestor := range ancestors {
-				if ancestor == ref.parent {
-					addParent = false
-				}
-			}
-			if ref.parent != "" && addParent {
-				list = append(list, strings.Join(ancestors, nameIdSeparator)+
-					nameIdSeparator+ref.parent+
-					nameIdSeparator+ref.name)
-			} else {
-				list = append(list, strings.Join(ancestors, nameIdSeparator)+nameIdSeparator+ref.name)
-			}
-		case "href":
-			list = append(list, ref.href)
-		case "import":
-			identifier := ref.name
-			if ref.importId {
-				identifier = ref.id
-			}
-			sanitizedName := ref.name
-			illegalHclNameCharsRegex := regexp.MustCompile(`[^a-zA-Z0-9_\-]+`)
-			if illegalHclNameCharsRegex.MatchString(ref.name) {
-				// Names can have special characters in VCD, but must not have in HCL resource names
-				sanitizedName = illegalHclNameCharsRegex.ReplaceAllString(ref.name, "_")
-			}
-			list = append(list, fmt.Sprintf("terraform import %s.%s '%s%s%s'",
-				resourceType,
-				sanitizedName,
-				strings.Join(ancestors, ImportSeparator),
-				ImportSeparator,
-				identifier))
-
-			ancestorsText := ""
-			if len(ancestors) > 0 {
-				ancestorsText = strings.Join(ancestors, ImportSeparator) + ImportSeparator
-			}
-			importData.WriteString(fmt.Sprintf("# Import directive for %s %s%s \n", resourceType, ancestorsText, sanitizedName))
-			importData.WriteString("import {\n")
-			importData.WriteString(fmt.Sprintf("  to = %s.%s-%s\n", resourceType, sanitizedName, idTail(ref.id)))
-			if len(ancestors) > 0 {
-				importData.WriteString(fmt.Sprintf("  id = \"%s%s%s\"\n",
-					strings.Join(ancestors, ImportSeparator), ImportSeparator, identifier))
-			} else {
-				importData.WriteString(fmt.Sprintf("  id = \"%s\"\n", identifier))
-			}
-			importData.WriteString("}\n\n")
-		}
+	natRules, err := nsxtEdgeGateway.GetAllNatRules(nil)
+	if err != nil {
+		return nil, err
 	}
-
-	if importFile != "" && listMode == "import" {
-		err = os.WriteFile(importFile, []byte(importData.String()), 0600)
-		if err != nil {
-			return nil, err
-		}
+	var items []resourceRef
+	for _, rule := range natRules {
+		items = append(items, resourceRef{
+			name:     rule.NsxtNatRule.Name,
+			id:       rule.NsxtNatRule.ID,
+			parent:   nsxtEdgeGateway.EdgeGateway.Name,
+			importId: true, // NAT rule names are not unique within an edge gateway
+			attributes: map[string]string{
+				"rule_type":          firstNonEmpty(rule.NsxtNatRule.Type, rule.NsxtNatRule.RuleType),
+				"enabled":            strconv.FormatBool(rule.NsxtNatRule.Enabled),
+				"external_addresses": rule.NsxtNatRule.ExternalAddresses,
+				"internal_addresses": rule.NsxtNatRule.InternalAddresses,
+			},
+		})
 	}
-	return list, nil
+	return genericResourceList(d, "vcd_nsxt_nat_rule", ancestors, items)
 }
 
-func idTail(id string) string {
-	if id == "" {
-		return ""
+func nsxtFirewallList(d *schema.ResourceData, meta interface{}) (list []resourceListEntry, err error) {
+	nsxtEdgeGateway, ancestors, err := getNsxtEdgeGatewayFromParent(d, meta)
+	if err != nil {
+		return nil, err
 	}
-	reTail := regexp.MustCompile(`([a-zA-z0-9]+)$`)
-	return reTail.FindString(id)
-}
-
-func getEdgeGatewayDetails(d *schema.ResourceData, meta interface{}) (orgName string, vdcName string, listMode string, separator string, egw *govcd.EdgeGateway, err error) {
-	client := meta.(*VCDClient)
-
-	listMode = d.Get("list_mode").(string)
-	separator = d.Get("name_id_separator").(string)
-	org, vdc, err := client.GetOrgAndVdc(d.Get("org").(string), d.Get("vdc").(string))
+	firewall, err := nsxtEdgeGateway.GetNsxtFirewall()
 	if err != nil {
-		return "", "", "", "", nil, err
+		return nil, err
 	}
-	edgeGatewayName := d.Get("parent").(string)
-	if edgeGatewayName == "" {
-		return "", "", "", "", nil, fmt.Errorf(`edge gateway name (as "parent") is required for this task`)
+	// There is only one firewall per edge gateway, which is listed only when it has user defined rules.
+	// Its import path ends with the edge gateway name
+	if firewall.NsxtFirewallRuleContainer == nil || len(firewall.NsxtFirewallRuleContainer.UserDefinedRules) == 0 {
+		return nil, nil
 	}
-	edgeGateway, err := vdc.GetEdgeGatewayByName(edgeGatewayName, false)
-	if err != nil {
-		return "", "", "", "", nil, fmt.Errorf("error retrieving edge gateway '%s': %s ", edgeGatewayName, err)
+	items := []resourceRef{
+		{
+			name: nsxtEdgeGateway.EdgeGateway.Name,
+			id:   nsxtEdgeGateway.EdgeGateway.ID,
+		},
 	}
-	return org.Org.Name, vdc.Vdc.Name, listMode, separator, edgeGateway, nil
+	return genericResourceList(d, "vcd_nsxt_firewall", ancestors[:len(ancestors)-1], items)
 }
 
-func lbServerPoolList(d *schema.ResourceData, meta interface{}) (list []string, err error) {
-	orgName, vdcName, _, _, edgeGateway, err := getEdgeGatewayDetails(d, meta)
+func nsxtFirewallGroupList(d *schema.ResourceData, meta interface{}, resType, firewallGroupType string) (list []resourceListEntry, err error) {
+	nsxtEdgeGateway, ancestors, err := getNsxtEdgeGatewayFromParent(d, meta)
 	if err != nil {
-		return list, fmt.Errorf("error retrieving edge gateway '%s': %s ", d.Get("parent").(string), err)
+		return nil, err
 	}
-	lbServerPoolList, err := edgeGateway.GetLbServerPools()
+	firewallGroups, err := nsxtEdgeGateway.GetAllNsxtFirewallGroups(nil, firewallGroupType)
 	if err != nil {
-		return list, fmt.Errorf("error retrieving edge gateway LB server pools '%s': %s ", d.Get("parent").(string), err)
+		return nil, err
 	}
 	var items []resourceRef
-	for _, service := range lbServerPoolList {
+	for _, firewallGroup := range firewallGroups {
 		items = append(items, resourceRef{
-			name:   service.Name,
-			id:     service.ID,
-			href:   "",
-			parent: edgeGateway.EdgeGateway.Name,
+			name:   firewallGroup.NsxtFirewallGroup.Name,
+			id:     firewallGroup.NsxtFirewallGroup.ID,
+			parent: nsxtEdgeGateway.EdgeGateway.Name,
 		})
 	}
-
-	return genericResourceList(d, "vcd_lb_server_pool", []string{orgName, vdcName, edgeGateway.EdgeGateway.Name}, items)
+	return genericResourceList(d, resType, ancestors, items)
 }
 
-func lbServiceMonitorList(d *schema.ResourceData, meta interface{}) (list []string, err error) {
-	orgName, vdcName, _, _, edgeGateway, err := getEdgeGatewayDetails(d, meta)
+func nsxtAlbPoolList(d *schema.ResourceData, meta interface{}) (list []resourceListEntry, err error) {
+	client := meta.(*VCDClient)
+	nsxtEdgeGateway, ancestors, err := getNsxtEdgeGatewayFromParent(d, meta)
 	if err != nil {
-		return list, fmt.Errorf("error retrieving edge gateway '%s': %s ", d.Get("parent").(string), err)
+		return nil, err
 	}
-
-	var items []resourceRef
-	lbServiceMonitorList, err := edgeGateway.GetLbServiceMonitors()
+	pools, err := client.GetAllAlbPoolSummaries(nsxtEdgeGateway.EdgeGateway.ID, nil)
 	if err != nil {
-		return list, fmt.Errorf("error retrieving LB service monitor list: %s ", err)
+		return nil, err
 	}
-	for _, sm := range lbServiceMonitorList {
+	var items []resourceRef
+	for _, pool := range pools {
 		items = append(items, resourceRef{
-			name:   sm.Name,
-			id:     sm.ID,
-			href:   sm.URL,
-			parent: edgeGateway.EdgeGateway.Name,
+			name:   pool.NsxtAlbPool.Name,
+			id:     pool.NsxtAlbPool.ID,
+			parent: nsxtEdgeGateway.EdgeGateway.Name,
 		})
 	}
-	return genericResourceList(d, "vcd_lb_service_monitor", []string{orgName, vdcName, edgeGateway.EdgeGateway.Name}, items)
+	return genericResourceList(d, "vcd_nsxt_alb_pool", ancestors, items)
 }
 
-func lbVirtualServerList(d *schema.ResourceData, meta interface{}) (list []string, err error) {
-
-	orgName, vdcName, _, _, edgeGateway, err := getEdgeGatewayDetails(d, meta)
+func nsxtAlbVirtualServiceList(d *schema.ResourceData, meta interface{}) (list []resourceListEntry, err error) {
+	client := meta.(*VCDClient)
+	nsxtEdgeGateway, ancestors, err := getNsxtEdgeGatewayFromParent(d, meta)
 	if err != nil {
-		return list, fmt.Errorf("error retrieving edge gateway '%s': %s ", d.Get("parent").(string), err)
+		return nil, err
 	}
-	var items []resourceRef
-	lbVirtualServerList, err := edgeGateway.GetLbVirtualServers()
+	virtualServices, err := client.GetAllAlbVirtualServiceSummaries(nsxtEdgeGateway.EdgeGateway.ID, nil)
 	if err != nil {
-		return list, fmt.Errorf("error retrieving LB virtual server list: %s ", err)
+		return nil, err
 	}
-	for _, vs := range lbVirtualServerList {
+	var items []resourceRef
+	for _, virtualService := range virtualServices {
 		items = append(items, resourceRef{
-			name:   vs.Name,
-			id:     vs.ID,
-			href:   "",
-			parent: edgeGateway.EdgeGateway.Name,
+			name:   virtualService.NsxtAlbVirtualService.Name,
+			id:     virtualService.NsxtAlbVirtualService.ID,
+			parent: nsxtEdgeGateway.EdgeGateway.Name,
 		})
 	}
-	return genericResourceList(d, "vcd_lb_virtual_server", []string{orgName, vdcName, edgeGateway.EdgeGateway.Name}, items)
+	return genericResourceList(d, "vcd_nsxt_alb_virtual_service", ancestors, items)
 }
 
-func nsxvFirewallList(d *schema.ResourceData, meta interface{}) (list []string, err error) {
-	orgName, vdcName, _, _, edgeGateway, err := getEdgeGatewayDetails(d, meta)
+func nsxtIpSecVpnTunnelList(d *schema.ResourceData, meta interface{}) (list []resourceListEntry, err error) {
+	nsxtEdgeGateway, ancestors, err := getNsxtEdgeGatewayFromParent(d, meta)
 	if err != nil {
-		return list, fmt.Errorf("error retrieving edge gateway '%s': %s ", d.Get("parent").(string), err)
+		return nil, err
 	}
-
-	var items []resourceRef
-	fwRuleList, err := edgeGateway.GetAllNsxvFirewallRules()
+	tunnels, err := nsxtEdgeGateway.GetAllIpSecVpnTunnels(nil)
 	if err != nil {
-		return list, fmt.Errorf("error retrieving NSXV firewall rule list: %s ", err)
+		return nil, err
 	}
-	for _, fw := range fwRuleList {
+	var items []resourceRef
+	for _, tunnel := range tunnels {
 		items = append(items, resourceRef{
-			name:   fw.Name,
-			id:     fw.ID,
-			href:   "",
-			parent: edgeGateway.EdgeGateway.Name,
+			name:     tunnel.NsxtIpSecVpn.Name,
+			id:       tunnel.NsxtIpSecVpn.ID,
+			parent:   nsxtEdgeGateway.EdgeGateway.Name,
+			importId: true, // tunnel names are not unique within an edge gateway
 		})
 	}
-	return genericResourceList(d, "vcd_nsxv_firewall_rule", []string{orgName, vdcName, edgeGateway.EdgeGateway.Name}, items)
+	return genericResourceList(d, "vcd_nsxt_ipsec_vpn_tunnel", ancestors, items)
 }
 
-func lbAppRuleList(d *schema.ResourceData, meta interface{}) (list []string, err error) {
-	orgName, vdcName, _, _, edgeGateway, err := getEdgeGatewayDetails(d, meta)
+// nsxtAppPortProfileList lists the tenant Application Port Profiles of the VDC or VDC group named in 'vdc'
+func nsxtAppPortProfileList(d *schema.ResourceData, meta interface{}) (list []resourceListEntry, err error) {
+	client := meta.(*VCDClient)
+	ownerName, err := getVdcName(client, d)
 	if err != nil {
-		return list, fmt.Errorf("error retrieving edge gateway '%s': %s ", d.Get("parent").(string), err)
+		return nil, err
+	}
+	org, err := client.GetOrgFromResource(d)
+	if err != nil {
+		return nil, err
+	}
+	vdcOrVdcGroup, err := lookupVdcOrVdcGroup(client, org.Org.Name, ownerName)
+	if err != nil {
+		return nil, err
+	}
+	var ownerId string
+	switch owner := vdcOrVdcGroup.(type) {
+	case *govcd.Vdc:
+		ownerId = owner.Vdc.ID
+	case *govcd.VdcGroup:
+		ownerId = owner.VdcGroup.Id
 	}
 
-	var items []resourceRef
-	ruleList, err := edgeGateway.GetLbAppRules()
+	queryParams := url.Values{}
+	queryParams.Add("filter", fmt.Sprintf("_context==%s", ownerId))
+	profiles, err := org.GetAllNsxtAppPortProfiles(queryParams, types.ApplicationPortProfileScopeTenant)
 	if err != nil {
-		return list, fmt.Errorf("error retrieving LB app rule list: %s ", err)
+		return nil, err
 	}
-	for _, fw := range ruleList {
+	var items []resourceRef
+	for _, profile := range profiles {
 		items = append(items, resourceRef{
-			name:   fw.Name,
-			id:     fw.ID,
+			name:   profile.NsxtAppPortProfile.Name,
+			id:     profile.NsxtAppPortProfile.ID,
+			parent: ownerName,
+		})
+	}
+	return genericResourceList(d, "vcd_nsxt_app_port_profile", []string{org.Org.Name, ownerName}, items)
+}
+
+// ipSpaceList lists all the IP Spaces. Private IP Spaces are imported using their organization as ancestor
+func ipSpaceList(d *schema.ResourceData, meta interface{}) (list []resourceListEntry, err error) {
+	client := meta.(*VCDClient)
+	ipSpaces, err := client.GetAllIpSpaceSummaries(nil)
+	if err != nil {
+		return nil, err
+	}
+	for _, ipSpace := range ipSpaces {
+		var ancestors []string
+		if ipSpace.IpSpace.OrgRef != nil && ipSpace.IpSpace.OrgRef.Name != "" {
+			ancestors = []string{ipSpace.IpSpace.OrgRef.Name}
+		}
+		entries, err := genericResourceList(d, "vcd_ip_space", ancestors, []resourceRef{
+			{
+				name: ipSpace.IpSpace.Name,
+				id:   ipSpace.IpSpace.ID,
+				attributes: map[string]string{
+					"type": ipSpace.IpSpace.Type,
+				},
+			},
+		})
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, entries...)
+	}
+	return list, nil
+}
+
+// ipSpaceUplinkList lists the IP Space Uplinks of the external network named in 'parent'
+func ipSpaceUplinkList(d *schema.ResourceData, meta interface{}) (list []resourceListEntry, err error) {
+	client := meta.(*VCDClient)
+	externalNetworkName := d.Get("parent").(string)
+	if externalNetworkName == "" {
+		return nil, fmt.Errorf(`external network name (as "parent") is required for this task`)
+	}
+	externalNetwork, err := govcd.GetExternalNetworkV2ByName(client.VCDClient, externalNetworkName)
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving external network '%s': %s", externalNetworkName, err)
+	}
+	uplinks, err := client.GetAllIpSpaceUplinks(externalNetwork.ExternalNetwork.ID, nil)
+	if err != nil {
+		return nil, err
+	}
+	var items []resourceRef
+	for _, uplink := range uplinks {
+		items = append(items, resourceRef{
+			name:   uplink.IpSpaceUplink.Name,
+			id:     uplink.IpSpaceUplink.ID,
+			parent: externalNetworkName,
+		})
+	}
+	return genericResourceList(d, "vcd_ip_space_uplink", []string{externalNetworkName}, items)
+}
+
+// getIpSpaceFromParent retrieves the IP Space named in 'parent'
+func getIpSpaceFromParent(d *schema.ResourceData, meta interface{}) (*govcd.IpSpace, error) {
+	client := meta.(*VCDClient)
+	ipSpaceName := d.Get("parent").(string)
+	if ipSpaceName == "" {
+		return nil, fmt.Errorf(`IP Space name (as "parent") is required for this task`)
+	}
+	ipSpace, err := client.GetIpSpaceByName(ipSpaceName)
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving IP Space '%s': %s", ipSpaceName, err)
+	}
+	return ipSpace, nil
+}
+
+// ipSpaceIpAllocationList lists the IP allocations of the IP Space named in 'parent'.
+// When 'org' is set, only the allocations of that organization are listed
+func ipSpaceIpAllocationList(d *schema.ResourceData, meta interface{}) (list []resourceListEntry, err error) {
+	ipSpace, err := getIpSpaceFromParent(d, meta)
+	if err != nil {
+		return nil, err
+	}
+	orgName := d.Get("org").(string)
+	for _, allocationType := range []string{types.IpSpaceIpAllocationTypeFloatingIp, types.IpSpaceIpAllocationTypeIpPrefix} {
+		allocations, err := ipSpace.GetAllIpSpaceAllocations(allocationType, nil)
+		if err != nil {
+			return nil, err
+		}
+		for _, allocation := range allocations {
+			if allocation.IpSpaceIpAllocation.OrgRef == nil {
+				continue
+			}
+			allocationOrg := allocation.IpSpaceIpAllocation.OrgRef.Name
+			if orgName != "" && allocationOrg != orgName {
+				continue
+			}
+			entries, err := genericResourceList(d, "vcd_ip_space_ip_allocation",
+				[]string{allocationOrg, ipSpace.IpSpace.Name, allocationType},
+				[]resourceRef{
+					{
+						name:   allocation.IpSpaceIpAllocation.Value,
+						id:     allocation.IpSpaceIpAllocation.ID,
+						parent: ipSpace.IpSpace.Name,
+						attributes: map[string]string{
+							"type":        allocationType,
+							"usage_state": allocation.IpSpaceIpAllocation.UsageState,
+						},
+					},
+				})
+			if err != nil {
+				return nil, err
+			}
+			list = append(list, entries...)
+		}
+	}
+	return list, nil
+}
+
+// ipSpaceCustomQuotaList lists the organizations with custom quotas in the IP Space named in 'parent'
+func ipSpaceCustomQuotaList(d *schema.ResourceData, meta interface{}) (list []resourceListEntry, err error) {
+	ipSpace, err := getIpSpaceFromParent(d, meta)
+	if err != nil {
+		return nil, err
+	}
+	assignments, err := ipSpace.GetAllOrgAssignments(nil)
+	if err != nil {
+		return nil, err
+	}
+	var items []resourceRef
+	for _, assignment := range assignments {
+		if assignment.IpSpaceOrgAssignment.CustomQuotas == nil || assignment.IpSpaceOrgAssignment.OrgRef == nil {
+			continue
+		}
+		items = append(items, resourceRef{
+			name:   assignment.IpSpaceOrgAssignment.OrgRef.Name,
+			id:     assignment.IpSpaceOrgAssignment.ID,
+			parent: ipSpace.IpSpace.Name,
+		})
+	}
+	return genericResourceList(d, "vcd_ip_space_custom_quota", []string{ipSpace.IpSpace.Name}, items)
+}
+
+// rdeTypeList lists the Runtime Defined Entity Types, which are imported as vendor.nss.version
+func rdeTypeList(d *schema.ResourceData, meta interface{}) (list []resourceListEntry, err error) {
+	client := meta.(*VCDClient)
+	rdeTypes, err := client.GetAllRdeTypes(nil)
+	if err != nil {
+		return nil, err
+	}
+	for _, rdeType := range rdeTypes {
+		entries, err := genericResourceList(d, "vcd_rde_type", []string{rdeType.DefinedEntityType.Vendor}, []resourceRef{
+			{
+				name:             rdeType.DefinedEntityType.Name,
+				id:               rdeType.DefinedEntityType.ID,
+				importIdentifier: rdeType.DefinedEntityType.Nss + ImportSeparator + rdeType.DefinedEntityType.Version,
+			},
+		})
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, entries...)
+	}
+	return list, nil
+}
+
+// rdeInterfaceList lists the Runtime Defined Entity Interfaces, which are imported as vendor.nss.version
+func rdeInterfaceList(d *schema.ResourceData, meta interface{}) (list []resourceListEntry, err error) {
+	client := meta.(*VCDClient)
+	interfaces, err := client.GetAllDefinedInterfaces(nil)
+	if err != nil {
+		return nil, err
+	}
+	for _, definedInterface := range interfaces {
+		entries, err := genericResourceList(d, "vcd_rde_interface", []string{definedInterface.DefinedInterface.Vendor}, []resourceRef{
+			{
+				name:             definedInterface.DefinedInterface.Name,
+				id:               definedInterface.DefinedInterface.ID,
+				importIdentifier: definedInterface.DefinedInterface.Nss + ImportSeparator + definedInterface.DefinedInterface.Version,
+			},
+		})
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, entries...)
+	}
+	return list, nil
+}
+
+// rdeTypeBehaviorList lists the Behaviors of all the Runtime Defined Entity Types, which are imported as
+// vendor.nss.version.behaviorName. It is used for both 'vcd_rde_type_behavior' and 'vcd_rde_type_behavior_acl'
+func rdeTypeBehaviorList(d *schema.ResourceData, meta interface{}, resType string) (list []resourceListEntry, err error) {
+	client := meta.(*VCDClient)
+	rdeTypes, err := client.GetAllRdeTypes(nil)
+	if err != nil {
+		return nil, err
+	}
+	for _, rdeType := range rdeTypes {
+		behaviors, err := rdeType.GetAllBehaviors(nil)
+		if err != nil {
+			return nil, err
+		}
+		var items []resourceRef
+		for _, behavior := range behaviors {
+			items = append(items, resourceRef{
+				name:   behavior.Name,
+				id:     behavior.ID,
+				parent: rdeType.DefinedEntityType.Name,
+			})
+		}
+		entries, err := genericResourceList(d, resType, []string{
+			rdeType.DefinedEntityType.Vendor,
+			rdeType.DefinedEntityType.Nss,
+			rdeType.DefinedEntityType.Version,
+		}, items)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, entries...)
+	}
+	return list, nil
+}
+
+// rdeInterfaceBehaviorList lists the Behaviors of all the Runtime Defined Entity Interfaces, which are imported as
+// vendor.nss.version.behaviorName
+func rdeInterfaceBehaviorList(d *schema.ResourceData, meta interface{}) (list []resourceListEntry, err error) {
+	client := meta.(*VCDClient)
+	interfaces, err := client.GetAllDefinedInterfaces(nil)
+	if err != nil {
+		return nil, err
+	}
+	for _, definedInterface := range interfaces {
+		behaviors, err := definedInterface.GetAllBehaviors(nil)
+		if err != nil {
+			return nil, err
+		}
+		var items []resourceRef
+		for _, behavior := range behaviors {
+			items = append(items, resourceRef{
+				name:   behavior.Name,
+				id:     behavior.ID,
+				parent: definedInterface.DefinedInterface.Name,
+			})
+		}
+		entries, err := genericResourceList(d, "vcd_rde_interface_behavior", []string{
+			definedInterface.DefinedInterface.Vendor,
+			definedInterface.DefinedInterface.Nss,
+			definedInterface.DefinedInterface.Version,
+		}, items)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, entries...)
+	}
+	return list, nil
+}
+
+// rdeList lists the Runtime Defined Entities, which are imported by ID.
+// When 'parent' is set, only the entities of the Runtime Defined Entity Types with that 'nss' are listed
+func rdeList(d *schema.ResourceData, meta interface{}) (list []resourceListEntry, err error) {
+	client := meta.(*VCDClient)
+	nss := d.Get("parent").(string)
+	var queryParams url.Values
+	if nss != "" {
+		queryParams = url.Values{}
+		queryParams.Add("filter", fmt.Sprintf("nss==%s", nss))
+	}
+	rdeTypes, err := client.GetAllRdeTypes(queryParams)
+	if err != nil {
+		return nil, err
+	}
+	var items []resourceRef
+	for _, rdeType := range rdeTypes {
+		rdes, err := rdeType.GetAllRdes(nil)
+		if err != nil {
+			return nil, err
+		}
+		for _, rde := range rdes {
+			items = append(items, resourceRef{
+				name:     rde.DefinedEntity.Name,
+				id:       rde.DefinedEntity.ID,
+				parent:   rdeType.DefinedEntityType.Name,
+				importId: true,
+				attributes: map[string]string{
+					"entity_type": rde.DefinedEntity.EntityType,
+					"state":       stringOnNotNil(rde.DefinedEntity.State),
+				},
+			})
+		}
+	}
+	return genericResourceList(d, "vcd_rde", nil, items)
+}
+
+// cseKubernetesClusterList lists the Kubernetes clusters created with Container Service Extension, which are
+// Runtime Defined Entities of type 'vmware:capvcdCluster'. When 'org' is set, only the clusters of that organization are listed
+func cseKubernetesClusterList(d *schema.ResourceData, meta interface{}) (list []resourceListEntry, err error) {
+	client := meta.(*VCDClient)
+	orgName := d.Get("org").(string)
+	queryParams := url.Values{}
+	queryParams.Add("filter", "vendor==vmware;nss==capvcdCluster")
+	rdeTypes, err := client.GetAllRdeTypes(queryParams)
+	if err != nil {
+		return nil, err
+	}
+	var items []resourceRef
+	for _, rdeType := range rdeTypes {
+		rdes, err := rdeType.GetAllRdes(nil)
+		if err != nil {
+			return nil, err
+		}
+		for _, rde := range rdes {
+			parent := ""
+			if rde.DefinedEntity.Org != nil {
+				parent = rde.DefinedEntity.Org.Name
+			}
+			if orgName != "" && parent != orgName {
+				continue
+			}
+			items = append(items, resourceRef{
+				name:     rde.DefinedEntity.Name,
+				id:       rde.DefinedEntity.ID,
+				parent:   parent,
+				importId: true,
+			})
+		}
+	}
+	return genericResourceList(d, "vcd_cse_kubernetes_cluster", nil, items)
+}
+
+// uiPluginList lists the UI Plugins, which are imported as vendor.pluginName.version
+func uiPluginList(d *schema.ResourceData, meta interface{}) (list []resourceListEntry, err error) {
+	client := meta.(*VCDClient)
+	plugins, err := client.GetAllUIPlugins()
+	if err != nil {
+		return nil, err
+	}
+	for _, plugin := range plugins {
+		entries, err := genericResourceList(d, "vcd_ui_plugin", []string{plugin.UIPluginMetadata.Vendor}, []resourceRef{
+			{
+				name:             plugin.UIPluginMetadata.PluginName,
+				id:               plugin.UIPluginMetadata.ID,
+				importIdentifier: plugin.UIPluginMetadata.PluginName + ImportSeparator + plugin.UIPluginMetadata.Version,
+			},
+		})
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, entries...)
+	}
+	return list, nil
+}
+
+// apiFilterList lists the API Filters, which are imported by ID. As API Filters have no name, the name
+// of their External Endpoint is used instead
+func apiFilterList(d *schema.ResourceData, meta interface{}) (list []resourceListEntry, err error) {
+	client := meta.(*VCDClient)
+	apiFilters, err := client.GetAllApiFilters(nil)
+	if err != nil {
+		return nil, err
+	}
+	var items []resourceRef
+	for _, apiFilter := range apiFilters {
+		name := apiFilter.ApiFilter.ID
+		if apiFilter.ApiFilter.ExternalSystem != nil && apiFilter.ApiFilter.ExternalSystem.Name != "" {
+			name = apiFilter.ApiFilter.ExternalSystem.Name
+		}
+		items = append(items, resourceRef{
+			name:     name,
+			id:       apiFilter.ApiFilter.ID,
+			importId: true,
+		})
+	}
+	return genericResourceList(d, "vcd_api_filter", nil, items)
+}
+
+func tmOrgList(d *schema.ResourceData, meta interface{}) (list []resourceListEntry, err error) {
+	client := meta.(*VCDClient)
+	orgs, err := client.GetAllTmOrgs(nil)
+	if err != nil {
+		return nil, err
+	}
+	var items []resourceRef
+	for _, org := range orgs {
+		items = append(items, resourceRef{
+			name: org.TmOrg.Name,
+			id:   org.TmOrg.ID,
+		})
+	}
+	return genericResourceList(d, "vcd_tm_org", nil, items)
+}
+
+func tmVcenterList(d *schema.ResourceData, meta interface{}) (list []resourceListEntry, err error) {
+	client := meta.(*VCDClient)
+	vcenters, err := client.GetAllVCenters(nil)
+	if err != nil {
+		return nil, err
+	}
+	var items []resourceRef
+	for _, vc := range vcenters {
+		items = append(items, resourceRef{
+			name: vc.VSphereVCenter.Name,
+			id:   vc.VSphereVCenter.VcId,
+		})
+	}
+	return genericResourceList(d, "vcd_tm_vcenter", nil, items)
+}
+
+func tmNsxtManagerList(d *schema.ResourceData, meta interface{}) (list []resourceListEntry, err error) {
+	client := meta.(*VCDClient)
+	managers, err := client.GetAllNsxtManagersOpenApi(nil)
+	if err != nil {
+		return nil, err
+	}
+	var items []resourceRef
+	for _, manager := range managers {
+		items = append(items, resourceRef{
+			name: manager.NsxtManagerOpenApi.Name,
+			id:   manager.NsxtManagerOpenApi.ID,
+		})
+	}
+	return genericResourceList(d, "vcd_tm_nsxt_manager", nil, items)
+}
+
+func tmRegionList(d *schema.ResourceData, meta interface{}) (list []resourceListEntry, err error) {
+	client := meta.(*VCDClient)
+	regions, err := client.GetAllRegions(nil)
+	if err != nil {
+		return nil, err
+	}
+	var items []resourceRef
+	for _, region := range regions {
+		items = append(items, resourceRef{
+			name: region.Region.Name,
+			id:   region.Region.ID,
+		})
+	}
+	return genericResourceList(d, "vcd_tm_region", nil, items)
+}
+
+func tmOrgVdcList(d *schema.ResourceData, meta interface{}) (list []resourceListEntry, err error) {
+	client := meta.(*VCDClient)
+	vdcs, err := client.GetAllTmVdcs(nil)
+	if err != nil {
+		return nil, err
+	}
+	var items []resourceRef
+	for _, vdc := range vdcs {
+		items = append(items, resourceRef{
+			name: vdc.TmVdc.Name,
+			id:   vdc.TmVdc.ID,
+		})
+	}
+	return genericResourceList(d, "vcd_tm_org_vdc", nil, items)
+}
+
+func tmContentLibraryList(d *schema.ResourceData, meta interface{}) (list []resourceListEntry, err error) {
+	client := meta.(*VCDClient)
+	libraries, err := client.GetAllContentLibraries(nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	var items []resourceRef
+	for _, library := range libraries {
+		items = append(items, resourceRef{
+			name: library.ContentLibrary.Name,
+			id:   library.ContentLibrary.ID,
+		})
+	}
+	return genericResourceList(d, "vcd_tm_content_library", nil, items)
+}
+
+// tmContentLibraryItemList lists the items of the Content Library named in 'parent', or of all
+// Content Libraries when 'parent' is empty
+func tmContentLibraryItemList(d *schema.ResourceData, meta interface{}) (list []resourceListEntry, err error) {
+	client := meta.(*VCDClient)
+	libraryName := d.Get("parent").(string)
+	var libraries []*govcd.ContentLibrary
+	if libraryName != "" {
+		library, err := client.GetContentLibraryByName(libraryName, nil)
+		if err != nil {
+			return nil, fmt.Errorf("error retrieving Content Library '%s': %s", libraryName, err)
+		}
+		libraries = append(libraries, library)
+	} else {
+		libraries, err = client.GetAllContentLibraries(nil, nil)
+		if err != nil {
+			return nil, err
+		}
+	}
+	for _, library := range libraries {
+		libraryItems, err := library.GetAllContentLibraryItems(nil)
+		if err != nil {
+			return nil, err
+		}
+		var items []resourceRef
+		for _, item := range libraryItems {
+			items = append(items, resourceRef{
+				name:   item.ContentLibraryItem.Name,
+				id:     item.ContentLibraryItem.ID,
+				parent: library.ContentLibrary.Name,
+			})
+		}
+		entries, err := genericResourceList(d, "vcd_tm_content_library_item", []string{library.ContentLibrary.Name}, items)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, entries...)
+	}
+	return list, nil
+}
+
+// tmRegionChildrenList turns references to entities contained in a Region into list entries, using
+// their parent (the Region name) as ancestor
+func tmRegionChildrenList(d *schema.ResourceData, resType string, refs []resourceRef) (list []resourceListEntry, err error) {
+	for _, ref := range refs {
+		entries, err := genericResourceList(d, resType, []string{ref.parent}, []resourceRef{ref})
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, entries...)
+	}
+	return list, nil
+}
+
+func tmIpSpaceList(d *schema.ResourceData, meta interface{}) (list []resourceListEntry, err error) {
+	client := meta.(*VCDClient)
+	ipSpaces, err := client.GetAllTmIpSpaces(nil)
+	if err != nil {
+		return nil, err
+	}
+	var items []resourceRef
+	for _, ipSpace := range ipSpaces {
+		items = append(items, resourceRef{
+			name:   ipSpace.TmIpSpace.Name,
+			id:     ipSpace.TmIpSpace.ID,
+			parent: ipSpace.TmIpSpace.RegionRef.Name,
+		})
+	}
+	return tmRegionChildrenList(d, "vcd_tm_ip_space", items)
+}
+
+func tmProviderGatewayList(d *schema.ResourceData, meta interface{}) (list []resourceListEntry, err error) {
+	client := meta.(*VCDClient)
+	providerGateways, err := client.GetAllTmProviderGateways(nil)
+	if err != nil {
+		return nil, err
+	}
+	var items []resourceRef
+	for _, providerGateway := range providerGateways {
+		items = append(items, resourceRef{
+			name:   providerGateway.TmProviderGateway.Name,
+			id:     providerGateway.TmProviderGateway.ID,
+			parent: providerGateway.TmProviderGateway.RegionRef.Name,
+		})
+	}
+	return tmRegionChildrenList(d, "vcd_tm_provider_gateway", items)
+}
+
+func tmEdgeClusterQosList(d *schema.ResourceData, meta interface{}) (list []resourceListEntry, err error) {
+	client := meta.(*VCDClient)
+	edgeClusters, err := client.GetAllTmEdgeClusters(nil)
+	if err != nil {
+		return nil, err
+	}
+	var items []resourceRef
+	for _, edgeCluster := range edgeClusters {
+		if edgeCluster.TmEdgeCluster.RegionRef == nil {
+			continue
+		}
+		items = append(items, resourceRef{
+			name:   edgeCluster.TmEdgeCluster.Name,
+			id:     edgeCluster.TmEdgeCluster.ID,
+			parent: edgeCluster.TmEdgeCluster.RegionRef.Name,
+		})
+	}
+	return tmRegionChildrenList(d, "vcd_tm_edge_cluster_qos", items)
+}
+
+// openApiEntityList lists the entities of the OpenAPI endpoint given in 'parent'.
+// Entities are expected to have 'name' and 'id' fields, as most OpenAPI entities do
+func openApiEntityList(d *schema.ResourceData, meta interface{}) (list []resourceListEntry, err error) {
+	client := meta.(*VCDClient)
+	endpoint := d.Get("parent").(string)
+	if endpoint == "" {
+		return nil, fmt.Errorf(`OpenAPI endpoint (as "parent") is required for this task`)
+	}
+	endpoint = strings.TrimSuffix(endpoint, "/") + "/"
+	urlRef, err := client.Client.OpenApiBuildEndpoint(endpoint)
+	if err != nil {
+		return nil, err
+	}
+	var entities []map[string]interface{}
+	err = client.Client.OpenApiGetAllItems(client.Client.APIVersion, urlRef, nil, &entities, nil)
+	if err != nil {
+		return nil, err
+	}
+	var items []resourceRef
+	for _, entity := range entities {
+		id, err := getJsonValueByPath(entity, "id")
+		if err != nil {
+			continue
+		}
+		name, err := getJsonValueByPath(entity, "name")
+		if err != nil {
+			name = id
+		}
+		items = append(items, resourceRef{
+			name:             name,
+			id:               id,
+			parent:           endpoint,
+			importIdentifier: endpoint + id,
+		})
+	}
+	return genericResourceList(d, "vcd_openapi_entity", nil, items)
+}
+
+// genericResourceList filters the references collected by a listing function and turns them into list entries
+// carrying the final resource type and the ancestors of each item
+func genericResourceList(d *schema.ResourceData, resType string, ancestors []string, refs []resourceRef) (list []resourceListEntry, err error) {
+	nameRegex := d.Get("name_regex").(string)
+	var reName *regexp.Regexp
+	if nameRegex != "" {
+		reName, err = regexp.Compile(nameRegex)
+		if err != nil {
+			return nil, fmt.Errorf("[vcd_resource_list - %s] error compiling regular expression given with 'name_regex' '%s': %s",
+				d.Get("name").(string), nameRegex, err)
+		}
+	}
+	for _, ref := range refs {
+		resourceType := resType
+		if ref.resourceType != "" {
+			resourceType = ref.resourceType
+		}
+		if reName != nil {
+			// If the regular expression doesn't match, the resource is skipped from the list
+			if reName.FindString(ref.name) == "" {
+				continue
+			}
+		}
+		list = append(list, resourceListEntry{
+			ref:          ref,
+			resourceType: resourceType,
+			ancestors:    ancestors,
+		})
+	}
+	return list, nil
+}
+
+// formatResourceList builds the output list from the entries, according to the requested list mode.
+// When an import file was requested, it also writes the import (and, in 'hcl' mode, the resource) definitions
+func formatResourceList(ctx context.Context, d *schema.ResourceData, meta interface{}, entries []resourceListEntry) (list []string, err error) {
+	listMode := d.Get("list_mode").(string)
+	nameIdSeparator := d.Get("name_id_separator").(string)
+	importFile := d.Get("import_file_name").(string)
+	var importData strings.Builder
+	importData.WriteString(fmt.Sprintf("# Generated by vcd_resource_list - %s\n", time.Now().Format(time.RFC3339)))
+	for _, entry := range entries {
+		ref := entry.ref
+		ancestors := entry.ancestors
+		switch listMode {
+		case "name":
+			list = append(list, ref.name)
+		case "id":
+			list = append(list, ref.id)
+		case "name_id":
+			list = append(list, ref.name+nameIdSeparator+ref.id)
+		case "hierarchy":
+			// If the parent is already present in the ancestors slice, don't repeat it
+			addParent := true
+			for _, ancestor := range ancestors {
+				if ancestor == ref.parent {
+					addParent = false
+				}
+			}
+			if ref.parent != "" && addParent {
+				list = append(list, strings.Join(ancestors, nameIdSeparator)+
+					nameIdSeparator+ref.parent+
+					nameIdSeparator+ref.name)
+			} else {
+				list = append(list, strings.Join(ancestors, nameIdSeparator)+nameIdSeparator+ref.name)
+			}
+		case "href":
+			list = append(list, ref.href)
+		case "import":
+			list = append(list, fmt.Sprintf("terraform import %s.%s '%s'",
+				entry.resourceType,
+				entry.sanitizedName(),
+				entry.importCommandId()))
+			importData.WriteString(entry.importBlock())
+		case "hcl":
+			list = append(list, entry.resourceType+"."+entry.address())
+		}
+	}
+
+	if importFile != "" && listMode == "import" {
+		err = os.WriteFile(importFile, []byte(importData.String()), 0600)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if listMode == "hcl" {
+		if importFile == "" {
+			return nil, fmt.Errorf("[vcd_resource_list - %s] 'import_file_name' is required with list mode 'hcl'", d.Get("name").(string))
+		}
+		hclText, err := generateResourceListHcl(ctx, meta, entries)
+		if err != nil {
+			return nil, err
+		}
+		err = os.WriteFile(importFile, []byte(importData.String()+hclText), 0600)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return list, nil
+}
+
+func idTail(id string) string {
+	if id == "" {
+		return ""
+	}
+	reTail := regexp.MustCompile(`([a-zA-z0-9]+)$`)
+	return reTail.FindString(id)
+}
+
+func getEdgeGatewayDetails(d *schema.ResourceData, meta interface{}) (orgName string, vdcName string, listMode string, separator string, egw *govcd.EdgeGateway, err error) {
+	client := meta.(*VCDClient)
+
+	listMode = d.Get("list_mode").(string)
+	separator = d.Get("name_id_separator").(string)
+	org, vdc, err := client.GetOrgAndVdc(d.Get("org").(string), d.Get("vdc").(string))
+	if err != nil {
+		return "", "", "", "", nil, err
+	}
+	edgeGatewayName := d.Get("parent").(string)
+	if edgeGatewayName == "" {
+		return "", "", "", "", nil, fmt.Errorf(`edge gateway name (as "parent") is required for this task`)
+	}
+	edgeGateway, err := vdc.GetEdgeGatewayByName(edgeGatewayName, false)
+	if err != nil {
+		return "", "", "", "", nil, fmt.Errorf("error retrieving edge gateway '%s': %s ", edgeGatewayName, err)
+	}
+	return org.Org.Name, vdc.Vdc.Name, listMode, separator, edgeGateway, nil
+}
+
+func lbServerPoolList(d *schema.ResourceData, meta interface{}) (list []resourceListEntry, err error) {
+	orgName, vdcName, _, _, edgeGateway, err := getEdgeGatewayDetails(d, meta)
+	if err != nil {
+		return list, fmt.Errorf("error retrieving edge gateway '%s': %s ", d.Get("parent").(string), err)
+	}
+	lbServerPoolList, err := edgeGateway.GetLbServerPools()
+	if err != nil {
+		return list, fmt.Errorf("error retrieving edge gateway LB server pools '%s': %s ", d.Get("parent").(string), err)
+	}
+	var items []resourceRef
+	for _, service := range lbServerPoolList {
+		items = append(items, resourceRef{
+			name:   service.Name,
+			id:     service.ID,
+			href:   "",
+			parent: edgeGateway.EdgeGateway.Name,
+		})
+	}
+
+	return genericResourceList(d, "vcd_lb_server_pool", []string{orgName, vdcName, edgeGateway.EdgeGateway.Name}, items)
+}
+
+func lbServiceMonitorList(d *schema.ResourceData, meta interface{}) (list []resourceListEntry, err error) {
+	orgName, vdcName, _, _, edgeGateway, err := getEdgeGatewayDetails(d, meta)
+	if err != nil {
+		return list, fmt.Errorf("error retrieving edge gateway '%s': %s ", d.Get("parent").(string), err)
+	}
+
+	var items []resourceRef
+	lbServiceMonitorList, err := edgeGateway.GetLbServiceMonitors()
+	if err != nil {
+		return list, fmt.Errorf("error retrieving LB service monitor list: %s ", err)
+	}
+	for _, sm := range lbServiceMonitorList {
+		items = append(items, resourceRef{
+			name:   sm.Name,
+			id:     sm.ID,
+			href:   sm.URL,
+			parent: edgeGateway.EdgeGateway.Name,
+		})
+	}
+	return genericResourceList(d, "vcd_lb_service_monitor", []string{orgName, vdcName, edgeGateway.EdgeGateway.Name}, items)
+}
+
+func lbVirtualServerList(d *schema.ResourceData, meta interface{}) (list []resourceListEntry, err error) {
+
+	orgName, vdcName, _, _, edgeGateway, err := getEdgeGatewayDetails(d, meta)
+	if err != nil {
+		return list, fmt.Errorf("error retrieving edge gateway '%s': %s ", d.Get("parent").(string), err)
+	}
+	var items []resourceRef
+	lbVirtualServerList, err := edgeGateway.GetLbVirtualServers()
+	if err != nil {
+		return list, fmt.Errorf("error retrieving LB virtual server list: %s ", err)
+	}
+	for _, vs := range lbVirtualServerList {
+		items = append(items, resourceRef{
+			name:   vs.Name,
+			id:     vs.ID,
+			href:   "",
+			parent: edgeGateway.EdgeGateway.Name,
+		})
+	}
+	return genericResourceList(d, "vcd_lb_virtual_server", []string{orgName, vdcName, edgeGateway.EdgeGateway.Name}, items)
+}
+
+func nsxvFirewallList(d *schema.ResourceData, meta interface{}) (list []resourceListEntry, err error) {
+	orgName, vdcName, _, _, edgeGateway, err := getEdgeGatewayDetails(d, meta)
+	if err != nil {
+		return list, fmt.Errorf("error retrieving edge gateway '%s': %s ", d.Get("parent").(string), err)
+	}
+
+	var items []resourceRef
+	fwRuleList, err := edgeGateway.GetAllNsxvFirewallRules()
+	if err != nil {
+		return list, fmt.Errorf("error retrieving NSXV firewall rule list: %s ", err)
+	}
+	for _, fw := range fwRuleList {
+		items = append(items, resourceRef{
+			name:   fw.Name,
+			id:     fw.ID,
+			href:   "",
+			parent: edgeGateway.EdgeGateway.Name,
+		})
+	}
+	return genericResourceList(d, "vcd_nsxv_firewall_rule", []string{orgName, vdcName, edgeGateway.EdgeGateway.Name}, items)
+}
+
+func lbAppRuleList(d *schema.ResourceData, meta interface{}) (list []resourceListEntry, err error) {
+	orgName, vdcName, _, _, edgeGateway, err := getEdgeGatewayDetails(d, meta)
+	if err != nil {
+		return list, fmt.Errorf("error retrieving edge gateway '%s': %s ", d.Get("parent").(string), err)
+	}
+
+	var items []resourceRef
+	ruleList, err := edgeGateway.GetLbAppRules()
+	if err != nil {
+		return list, fmt.Errorf("error retrieving LB app rule list: %s ", err)
+	}
+	for _, fw := range ruleList {
+		items = append(items, resourceRef{
+			name:   fw.Name,
+			id:     fw.ID,
 			href:   "",
 			parent: edgeGateway.EdgeGateway.Name,
 		})
@@ -1403,7 +2421,7 @@ func lbAppRuleList(d *schema.ResourceData, meta interface{}) (list []string, err
 	return genericResourceList(d, "vcd_lb_app_rule", []string{orgName, vdcName, edgeGateway.EdgeGateway.Name}, items)
 }
 
-func lbAppProfileList(d *schema.ResourceData, meta interface{}) (list []string, err error) {
+func lbAppProfileList(d *schema.ResourceData, meta interface{}) (list []resourceListEntry, err error) {
 	orgName, vdcName, _, _, edgeGateway, err := getEdgeGatewayDetails(d, meta)
 	if err != nil {
 		return list, fmt.Errorf("error retrieving edge gateway '%s': %s ", d.Get("parent").(string), err)
@@ -1425,7 +2443,7 @@ func lbAppProfileList(d *schema.ResourceData, meta interface{}) (list []string,
 	return genericResourceList(d, "vcd_lb_app_profile", []string{orgName, vdcName, edgeGateway.EdgeGateway.Name}, items)
 }
 
-func ipsetList(d *schema.ResourceData, meta interface{}) (list []string, err error) {
+func ipsetList(d *schema.ResourceData, meta interface{}) (list []resourceListEntry, err error) {
 
 	client := meta.(*VCDClient)
 
@@ -1453,7 +2471,8 @@ func ipsetList(d *schema.ResourceData, meta interface{}) (list []string, err err
 	return genericResourceList(d, "vcd_ipset", []string{org.Org.Name, vdc.Vdc.Name}, items)
 }
 
-func nsxvNatRuleList(natType string, d *schema.ResourceData, meta interface{}) (list []string, err error) {
+// nsxvNatRuleList returns the NSX-V NAT rules of the given type ("dnat" or "snat"), as vcd_nsxv_dnat or vcd_nsxv_snat
+func nsxvNatRuleList(natType string, d *schema.ResourceData, meta interface{}) (list []resourceListEntry, err error) {
 	orgName, vdcName, _, _, edgeGateway, err := getEdgeGatewayDetails(d, meta)
 	if err != nil {
 		return list, fmt.Errorf("error retrieving edge gateway '%s': %s ", d.Get("parent").(string), err)
@@ -1475,10 +2494,10 @@ func nsxvNatRuleList(natType string, d *schema.ResourceData, meta interface{}) (
 			})
 		}
 	}
-	return genericResourceList(d, "vcd_lb_app_profile", []string{orgName, vdcName, edgeGateway.EdgeGateway.Name}, items)
+	return genericResourceList(d, "vcd_nsxv_"+natType, []string{orgName, vdcName, edgeGateway.EdgeGateway.Name}, items)
 }
 
-func getResourcesList() ([]string, error) {
+func getResourcesList() ([]string, []resourceListEntry, error) {
 	var list []string
 	resources := globalResourceMap
 	for resource := range resources {
@@ -1486,129 +2505,217 @@ func getResourcesList() ([]string, error) {
 	}
 	// Returns the list of resources in alphabetical order, to keep a consistent state
 	sort.Strings(list)
-	return list, nil
+
+	// Each item describes a resource type of the provider. Resource types have neither an ID nor an import ID:
+	// 'importId' with an empty ID leaves the import ID empty
+	entries := make([]resourceListEntry, len(list))
+	for i, resource := range list {
+		entries[i] = resourceListEntry{
+			ref:          resourceRef{name: resource, importId: true},
+			resourceType: resource,
+		}
+	}
+	return list, entries, nil
 }
 
-func datasourceVcdResourceListRead(_ context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+func datasourceVcdResourceListRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
 
 	requested := d.Get("resource_type").(string)
 	var err error
 	var list []string
+	var entries []resourceListEntry
+	if d.Get("recursive").(bool) {
+		entries, err = recursiveResourceList(d, meta)
+		if err != nil {
+			return diag.FromErr(err)
+		}
+		list, err = formatResourceList(ctx, d, meta, entries)
+		if err != nil {
+			return diag.FromErr(err)
+		}
+		return setResourceList(d, list, entries)
+	}
 	switch requested {
 	// Note: do not try to get the data sources list, as it would result in a circular reference
 	case "resource", "resources":
-		list, err = getResourcesList()
+		list, entries, err = getResourcesList()
+		if err != nil {
+			return diag.FromErr(err)
+		}
+		return setResourceList(d, list, entries)
 	case "vcd_multisite_site_association":
-		list, err = getSiteAssociationList(d, meta, "vcd_multisite_site_association")
+		entries, err = getSiteAssociationList(d, meta, "vcd_multisite_site_association")
 	case "vcd_multisite_org_association":
-		list, err = getOrgAssociationList(d, meta, "vcd_multisite_org_association")
+		entries, err = getOrgAssociationList(d, meta, "vcd_multisite_org_association")
 	case "vcd_org", "org", "orgs":
-		list, err = getOrgList(d, meta, "vcd_org")
+		entries, err = getOrgList(d, meta, "vcd_org")
 	case "vcd_org_ldap", "vcd_org_saml":
-		list, err = getOrgList(d, meta, requested)
+		entries, err = getOrgList(d, meta, requested)
 	case "vcd_provider_vdc", "provider_vdc":
-		list, err = getPvdcList(d, meta)
+		entries, err = getPvdcList(d, meta)
 	case "vcd_distributed_switch":
-		list, err = distributedSwitchList(d, meta)
+		entries, err = distributedSwitchList(d, meta)
 	case "vcd_nsxt_transport_zone":
-		list, err = transportZoneList(d, meta)
+		entries, err = transportZoneList(d, meta)
 	case "vcd_importable_port_group":
-		list, err = importablePortGroupList(d, meta)
+		entries, err = importablePortGroupList(d, meta)
 	case "vcd_network_pool":
-		list, err = networkPoolList(d, meta)
+		entries, err = networkPoolList(d, meta)
 	case "vcd_vcenter":
-		list, err = vcenterList(d, meta)
+		entries, err = vcenterList(d, meta)
 	case "vcd_nsxt_manager":
-		list, err = nsxtManagerList(d, meta)
+		entries, err = nsxtManagerList(d, meta)
 	case "vcd_external_network", "external_network", "external_networks":
-		list, err = externalNetworkList(d, meta)
+		entries, err = externalNetworkList(d, meta)
 	case "vcd_org_vdc", "vdc", "vdcs":
-		list, err = vdcList(d, meta, "vcd_org_vdc")
+		entries, err = vdcList(d, meta, "vcd_org_vdc")
 	case "vcd_vdc_group":
-		list, err = getVdcGroups(d, meta)
+		entries, err = getVdcGroups(d, meta)
 	case "vcd_org_vdc_access_control":
-		list, err = vdcList(d, meta, "vcd_org_vdc_access_control")
+		entries, err = vdcList(d, meta, "vcd_org_vdc_access_control")
 	case "vcd_catalog", "catalog", "catalogs", "vcd_subscribed_catalog":
-		list, err = catalogList(d, meta, "vcd_catalog")
+		entries, err = catalogList(d, meta, "vcd_catalog")
 	case "vcd_catalog_access_control":
-		list, err = catalogList(d, meta, "vcd_catalog_access_control")
+		entries, err = catalogList(d, meta, "vcd_catalog_access_control")
 	case "vcd_catalog_item", "catalog_item", "catalog_items", "catalogitem", "catalogitems":
-		list, err = catalogItemList(d, meta, "vcd_catalog_item")
+		entries, err = catalogItemList(d, meta, "vcd_catalog_item")
 	case "vcd_catalog_vapp_template", "vapp_template":
-		list, err = vappTemplateList(d, meta)
+		entries, err = vappTemplateList(d, meta)
 	case "vcd_catalog_media", "catalog_media", "media_items", "mediaitems", "mediaitem":
-		list, err = catalogItemList(d, meta, "vcd_catalog_media")
+		entries, err = catalogItemList(d, meta, "vcd_catalog_media")
 	case "vcd_independent_disk", "disk", "disks":
-		list, err = diskList(d, meta)
+		entries, err = diskList(d, meta)
 	case "vcd_vapp", "vapp", "vapps", "vcd_cloned_vapp":
-		list, err = vappList(d, meta, "vcd_vapp")
+		entries, err = vappList(d, meta, "vcd_vapp")
 	case "vcd_vapp_access_control":
-		list, err = vappList(d, meta, "vcd_vapp_access_control")
+		entries, err = vappList(d, meta, "vcd_vapp_access_control")
 	case "vcd_vapp_vm", "vapp_vm", "vapp_vms":
-		list, err = vmList(d, meta, vappVmType)
+		entries, err = vmList(d, meta, vappVmType)
 	case "vcd_vapp_network", "vapp_network", "vapp_networks":
-		list, err = vappNetworkList(d, vntVappNetwork, meta)
+		entries, err = vappNetworkList(d, vntVappNetwork, meta)
 	case "vcd_vapp_org_network", "vapp_org_network", "vapp_org_networks":
-		list, err = vappNetworkList(d, vntVappOrgNetwork, meta)
+		entries, err = vappNetworkList(d, vntVappOrgNetwork, meta)
 	case "vcd_vapp_all_network", "vapp_all_network", "vapp_all_networks":
-		list, err = vappNetworkList(d, vntVappAllNetworks, meta)
+		entries, err = vappNetworkList(d, vntVappAllNetworks, meta)
 	case "vcd_vm", "standalone_vm":
-		list, err = vmList(d, meta, standaloneVmType)
+		entries, err = vmList(d, meta, standaloneVmType)
 	case "vcd_all_vm", "vm", "vms":
-		list, err = vmList(d, meta, "all")
+		entries, err = vmList(d, meta, "all")
 	case "vcd_org_user", "org_user", "user", "users":
-		list, err = orgUserList(d, meta)
+		entries, err = orgUserList(d, meta)
 	case "vcd_edgegateway", "edge_gateway", "edge", "edgegateway":
-		list, err = getEdgeGatewayList(d, meta, "vcd_edgegateway")
+		entries, err = getEdgeGatewayList(d, meta, "vcd_edgegateway")
 	case "vcd_edgegateway_settings":
-		list, err = getEdgeGatewayList(d, meta, "vcd_edgegateway_settings")
+		entries, err = getEdgeGatewayList(d, meta, "vcd_edgegateway_settings")
 	case "vcd_nsxt_edgegateway", "nsxt_edge_gateway", "nsxt_edge", "nsxt_edgegateway":
-		list, err = getNsxtEdgeGatewayList(d, meta)
+		entries, err = getNsxtEdgeGatewayList(d, meta)
 	case "vcd_lb_server_pool", "lb_server_pool":
-		list, err = lbServerPoolList(d, meta)
+		entries, err = lbServerPoolList(d, meta)
 	case "vcd_lb_service_monitor", "lb_service_monitor":
-		list, err = lbServiceMonitorList(d, meta)
+		entries, err = lbServiceMonitorList(d, meta)
 	case "vcd_lb_virtual_server", "lb_virtual_server":
-		list, err = lbVirtualServerList(d, meta)
+		entries, err = lbVirtualServerList(d, meta)
 	case "vcd_lb_app_rule", "lb_app_rule":
-		list, err = lbAppRuleList(d, meta)
+		entries, err = lbAppRuleList(d, meta)
 	case "vcd_lb_app_profile", "lb_app_profile":
-		list, err = lbAppProfileList(d, meta)
+		entries, err = lbAppProfileList(d, meta)
 	case "vcd_nsxv_firewall_rule", "nsxv_firewall_rule":
-		list, err = nsxvFirewallList(d, meta)
+		entries, err = nsxvFirewallList(d, meta)
 	case "vcd_ipset", "ipset":
-		list, err = ipsetList(d, meta)
+		entries, err = ipsetList(d, meta)
 	case "vcd_nsxv_dnat", "nsxv_dnat":
-		list, err = nsxvNatRuleList("dnat", d, meta)
+		entries, err = nsxvNatRuleList("dnat", d, meta)
 	case "vcd_nsxv_snat", "nsxv_snat":
-		list, err = nsxvNatRuleList("snat", d, meta)
+		entries, err = nsxvNatRuleList("snat", d, meta)
 	case "vcd_network_isolated", "vcd_network_direct", "vcd_network_routed",
 		"network", "networks", "network_direct", "network_routed", "network_isolated":
-		list, err = networkList(d, meta)
+		entries, err = networkList(d, meta)
 	case "vcd_network_routed_v2", "vcd_network_isolated_v2", "vcd_nsxt_network_imported":
-		list, err = orgNetworkListV2(d, meta)
+		entries, err = orgNetworkListV2(d, meta)
 	case "vcd_right", "rights":
-		list, err = rightsList(d, meta)
+		entries, err = rightsList(d, meta)
 	case "vcd_rights_bundle", "rights_bundle":
-		list, err = rightsBundlesList(d, meta)
+		entries, err = rightsBundlesList(d, meta)
 	case "vcd_role", "roles":
-		list, err = rolesList(d, meta)
+		entries, err = rolesList(d, meta)
 	case "vcd_global_role", "global_roles":
-		list, err = globalRolesList(d, meta)
+		entries, err = globalRolesList(d, meta)
 	case "vcd_library_certificate":
-		list, err = libraryCertificateList(d, meta)
+		entries, err = libraryCertificateList(d, meta)
 	case "vcd_org_vdc_template":
-		list, err = vdcTemplateList(d, meta)
+		entries, err = vdcTemplateList(d, meta)
 	case "vcd_nsxt_alb_service_engine_group":
-		list, err = nsxtAlbServiceEngineGroup(d, meta)
+		entries, err = nsxtAlbServiceEngineGroup(d, meta)
 	case "vcd_nsxt_alb_edgegateway_service_engine_group":
-		list, err = nsxtAlbServiceEngineGroupAssignment(d, meta)
+		entries, err = nsxtAlbServiceEngineGroupAssignment(d, meta)
+	case "vcd_nsxt_nat_rule":
+		entries, err = nsxtNatRuleList(d, meta)
+	case "vcd_nsxt_firewall":
+		entries, err = nsxtFirewallList(d, meta)
+	case "vcd_nsxt_ip_set":
+		entries, err = nsxtFirewallGroupList(d, meta, "vcd_nsxt_ip_set", types.FirewallGroupTypeIpSet)
+	case "vcd_nsxt_security_group":
+		entries, err = nsxtFirewallGroupList(d, meta, "vcd_nsxt_security_group", types.FirewallGroupTypeSecurityGroup)
+	case "vcd_nsxt_app_port_profile":
+		entries, err = nsxtAppPortProfileList(d, meta)
+	case "vcd_nsxt_alb_pool":
+		entries, err = nsxtAlbPoolList(d, meta)
+	case "vcd_nsxt_alb_virtual_service":
+		entries, err = nsxtAlbVirtualServiceList(d, meta)
+	case "vcd_nsxt_ipsec_vpn_tunnel":
+		entries, err = nsxtIpSecVpnTunnelList(d, meta)
+	case "vcd_ip_space":
+		entries, err = ipSpaceList(d, meta)
+	case "vcd_ip_space_uplink":
+		entries, err = ipSpaceUplinkList(d, meta)
+	case "vcd_ip_space_ip_allocation":
+		entries, err = ipSpaceIpAllocationList(d, meta)
+	case "vcd_ip_space_custom_quota":
+		entries, err = ipSpaceCustomQuotaList(d, meta)
+	case "vcd_rde_type":
+		entries, err = rdeTypeList(d, meta)
+	case "vcd_rde_interface":
+		entries, err = rdeInterfaceList(d, meta)
+	case "vcd_rde_type_behavior", "vcd_rde_type_behavior_acl":
+		entries, err = rdeTypeBehaviorList(d, meta, requested)
+	case "vcd_rde_interface_behavior":
+		entries, err = rdeInterfaceBehaviorList(d, meta)
+	case "vcd_rde":
+		entries, err = rdeList(d, meta)
+	case "vcd_cse_kubernetes_cluster":
+		entries, err = cseKubernetesClusterList(d, meta)
+	case "vcd_ui_plugin":
+		entries, err = uiPluginList(d, meta)
+	case "vcd_api_filter":
+		entries, err = apiFilterList(d, meta)
+	case "vcd_tm_org":
+		entries, err = tmOrgList(d, meta)
+	case "vcd_tm_vcenter":
+		entries, err = tmVcenterList(d, meta)
+	case "vcd_tm_nsxt_manager":
+		entries, err = tmNsxtManagerList(d, meta)
+	case "vcd_tm_region":
+		entries, err = tmRegionList(d, meta)
+	case "vcd_tm_org_vdc":
+		entries, err = tmOrgVdcList(d, meta)
+	case "vcd_tm_content_library":
+		entries, err = tmContentLibraryList(d, meta)
+	case "vcd_tm_content_library_item":
+		entries, err = tmContentLibraryItemList(d, meta)
+	case "vcd_tm_ip_space":
+		entries, err = tmIpSpaceList(d, meta)
+	case "vcd_tm_provider_gateway":
+		entries, err = tmProviderGatewayList(d, meta)
+	case "vcd_tm_edge_cluster_qos":
+		entries, err = tmEdgeClusterQosList(d, meta)
+	case "vcd_openapi_entity":
+		entries, err = openApiEntityList(d, meta)
 
 		//// place holder to remind of what needs to be implemented
 		//	case "edgegateway_vpn",
 		//		"independent_disk",
 		//		"inserted_media":
-		//		list, err = []string{"not implemented yet"}, nil
+		//		entries, err = []string{"not implemented yet"}, nil
 	default:
 		return diag.FromErr(fmt.Errorf("unhandled resource type '%s'", requested))
 	}
@@ -1616,7 +2723,37 @@ func datasourceVcdResourceListRead(_ context.Context, d *schema.ResourceData, me
 	if err != nil {
 		return diag.FromErr(err)
 	}
-	err = d.Set("list", list)
+	list, err = formatResourceList(ctx, d, meta, entries)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	return setResourceList(d, list, entries)
+}
+
+// resourceListItems converts the entries into the structured values of the 'items' attribute
+func resourceListItems(entries []resourceListEntry) []interface{} {
+	items := make([]interface{}, len(entries))
+	for i, entry := range entries {
+		items[i] = map[string]interface{}{
+			"name":          entry.ref.name,
+			"id":            entry.ref.id,
+			"href":          entry.ref.href,
+			"resource_type": entry.resourceType,
+			"parent":        entry.ref.parent,
+			"ancestors":     entry.ancestors,
+			"import_id":     entry.importId(),
+			"attributes":    entry.ref.attributes,
+		}
+	}
+	return items
+}
+
+func setResourceList(d *schema.ResourceData, list []string, entries []resourceListEntry) diag.Diagnostics {
+	err := d.Set("list", list)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	err = d.Set("items", resourceListItems(entries))
 	if err != nil {
 		return diag.FromErr(err)
 	}
@@ -24,6 +24,7 @@ type listDef struct {
 	listMode     string
 	importFile   bool
 	excludeItem  bool
+	fileContents []string // Text that the import file must contain
 }
 
 func TestAccVcdDatasourceResourceList(t *testing.T) {
@@ -43,6 +44,12 @@ func TestAccVcdDatasourceResourceList(t *testing.T) {
 	if usingSysAdmin() {
 		lists = append(lists, listDef{name: "admin-vdc-template", resourceType: "vcd_org_vdc_template"})
 		lists = append(lists, listDef{name: "alb-service-engine-group", resourceType: "vcd_nsxt_alb_service_engine_group"})
+		lists = append(lists, listDef{name: "ip-space", resourceType: "vcd_ip_space"})
+		lists = append(lists, listDef{name: "rde-interface", resourceType: "vcd_rde_interface"})
+		lists = append(lists, listDef{name: "rde-type", resourceType: "vcd_rde_type"})
+		lists = append(lists, listDef{name: "rde-type-behavior", resourceType: "vcd_rde_type_behavior"})
+		lists = append(lists, listDef{name: "ui-plugin", resourceType: "vcd_ui_plugin"})
+		lists = append(lists, listDef{name: "api-filter", resourceType: "vcd_api_filter"})
 	}
 
 	knownNetworkPool1 := testConfig.VCD.ProviderVdc.NetworkPool
@@ -242,7 +249,17 @@ func TestAccVcdDatasourceResourceList(t *testing.T) {
 				vdc:          testConfig.Nsxt.Vdc,
 				parent:       testConfig.Nsxt.EdgeGateway,
 			})
+			for _, edgeChild := range []string{"vcd_nsxt_nat_rule", "vcd_nsxt_firewall", "vcd_nsxt_ip_set",
+				"vcd_nsxt_security_group", "vcd_nsxt_alb_pool", "vcd_nsxt_alb_virtual_service", "vcd_nsxt_ipsec_vpn_tunnel"} {
+				lists = append(lists, listDef{
+					name:         "vdc-" + strings.ReplaceAll(edgeChild, "_", "-"),
+					resourceType: edgeChild,
+					vdc:          testConfig.Nsxt.Vdc,
+					parent:       testConfig.Nsxt.EdgeGateway,
+				})
+			}
 		}
+		lists = append(lists, listDef{name: "vdc-nsxt-app-port-profile", resourceType: "vcd_nsxt_app_port_profile", vdc: testConfig.Nsxt.Vdc})
 	} else {
 		fmt.Print("`Nsxt.Vdc` value isn't configured, datasource test using this will be skipped\n")
 	}
@@ -314,6 +331,7 @@ func TestAccVcdDatasourceResourceList(t *testing.T) {
 				vdc:          testConfig.VCD.Vdc,
 				listMode:     "import",
 				importFile:   true,
+				fileContents: []string{"import {", "to = vcd_vapp_vm."},
 			},
 			// List with import
 			// Looking for standalone VM ldap-server
@@ -326,6 +344,23 @@ func TestAccVcdDatasourceResourceList(t *testing.T) {
 				listMode:     "import",
 				importFile:   true,
 			},
+			// List with full HCL generation
+			// Looking for the NSX-T edge gateway
+			// Expect to create a file with both import blocks and resource definitions
+			listDef{
+				name:         "nsxt-edge-hcl",
+				resourceType: "vcd_nsxt_edgegateway",
+				knownItem:    testConfig.Nsxt.EdgeGateway,
+				vdc:          testConfig.Nsxt.Vdc,
+				listMode:     "hcl",
+				importFile:   true,
+				fileContents: []string{
+					"import {",
+					"to = vcd_nsxt_edgegateway.",
+					`resource "vcd_nsxt_edgegateway" "`,
+					fmt.Sprintf("%q", testConfig.Nsxt.EdgeGateway),
+				},
+			},
 		)
 	}
 
@@ -407,23 +442,33 @@ func runResourceInfoTest(def listDef, t *testing.T) {
 				Check: resource.ComposeTestCheckFunc(
 					resource.TestCheckResourceAttr("data.vcd_resource_list."+def.name, "name", def.name),
 					checkListForKnownItem(def.name, def.knownItem, def.unwantedItem, !def.excludeItem, def.importFile),
-					checkImportFile(importFileName, def.importFile),
+					checkImportFile(importFileName, def.importFile, def.fileContents),
 				),
 			},
 		},
 	})
 }
 
-// checkImportFile returns an error if an import filename is expected (importing==true) but was not found.
-func checkImportFile(fileName string, importing bool) resource.TestCheckFunc {
+// checkImportFile returns an error if an import filename is expected (importing==true) but was not found,
+// or if it doesn't contain all the expected text.
+func checkImportFile(fileName string, importing bool, expectedContents []string) resource.TestCheckFunc {
 	return func(s *terraform.State) error {
 		if !importing {
 			return nil
 		}
-		if fileExists(fileName) {
-			return nil
+		if !fileExists(fileName) {
+			return fmt.Errorf("file %s not found", fileName)
 		}
-		return fmt.Errorf("file %s not found", fileName)
+		contents, err := os.ReadFile(fileName) // #nosec G304 -- the file name is built by the test
+		if err != nil {
+			return fmt.Errorf("error reading file %s: %s", fileName, err)
+		}
+		for _, expected := range expectedContents {
+			if !strings.Contains(string(contents), expected) {
+				return fmt.Errorf("file %s doesn't contain '%s':\n%s", fileName, expected, contents)
+			}
+		}
+		return nil
 	}
 }
 
@@ -527,3 +572,77 @@ data "vcd_resource_list" "rs" {
   depends_on = [vcd_catalog.cat1, vcd_catalog.cat2]
 }
 `
+
+func TestAccVcdDatasourceResourceListRecursive(t *testing.T) {
+	preTestChecks(t)
+
+	var params = StringMap{
+		"Org":        testConfig.VCD.Org,
+		"Vdc":        testConfig.Nsxt.Vdc,
+		"ImportFile": "import-recursive.tf",
+	}
+	testParamsNotEmpty(t, params)
+	configText := templateFill(testAccVcdDatasourceResourceListRecursive, params)
+	debugPrintf("#[DEBUG] CONFIGURATION: %s", configText)
+
+	if vcdShortTest {
+		t.Skip(acceptanceTestsSkipped)
+		return
+	}
+
+	resource.Test(t, resource.TestCase{
+		ProviderFactories: testAccProviders,
+		CheckDestroy: func(state *terraform.State) error {
+			if fileExists(params["ImportFile"].(string)) {
+				return os.Remove(params["ImportFile"].(string))
+			}
+			return nil
+		},
+		Steps: []resource.TestStep{
+			{
+				Config: configText,
+				Check: resource.ComposeTestCheckFunc(
+					// The organization comes first, followed by the entities it contains
+					resource.TestCheckResourceAttr("data.vcd_resource_list.all", "list.0", testConfig.VCD.Org),
+					resource.TestCheckResourceAttr("data.vcd_resource_list.all", "items.0.name", testConfig.VCD.Org),
+					resource.TestCheckResourceAttr("data.vcd_resource_list.all", "items.0.resource_type", "vcd_org"),
+					resource.TestCheckResourceAttrPair("data.vcd_resource_list.all", "items.#", "data.vcd_resource_list.all", "list.#"),
+					checkListForKnownItem("all", testConfig.Nsxt.Vdc, "", true, false),
+					checkListForKnownItem("all", testConfig.Nsxt.EdgeGateway, "", true, false),
+					checkListForKnownItem("vapps", testConfig.VCD.Org, "", false, false),
+					checkListForKnownItem("vapps", testConfig.Nsxt.Vdc, "", false, false),
+					checkListForKnownItem("import", testConfig.Nsxt.EdgeGateway, "", true, true),
+					checkImportFile(params["ImportFile"].(string), true, []string{"import {"}),
+				),
+			},
+		},
+	})
+	postTestChecks(t)
+}
+
+const testAccVcdDatasourceResourceListRecursive = `
+data "vcd_resource_list" "all" {
+  org           = "{{.Org}}"
+  name          = "all"
+  resource_type = "vcd_org"
+  recursive     = true
+}
+
+data "vcd_resource_list" "vapps" {
+  org           = "{{.Org}}"
+  name          = "vapps"
+  resource_type = "vcd_org"
+  recursive     = true
+  include_types = ["vcd_vapp", "vcd_vapp_vm"]
+}
+
+data "vcd_resource_list" "import" {
+  org              = "{{.Org}}"
+  name             = "import"
+  resource_type    = "vcd_org"
+  recursive        = true
+  exclude_types    = ["vcd_org", "vcd_org_user", "vcd_role"]
+  list_mode        = "import"
+  import_file_name = "{{.ImportFile}}"
+}
+`
@@ -0,0 +1,90 @@
+//go:build unit || ALL
+
+package vcd
+
+import (
+	"fmt"
+	"testing"
+)
+
+// Test_setResourceListItems checks that the entries are stored in the 'items' attribute with all their fields
+func Test_setResourceListItems(t *testing.T) {
+	entries := []resourceListEntry{
+		{
+			ref: resourceRef{
+				name:   "vm.with.dots",
+				id:     "urn:vcloud:vm:1111",
+				href:   "https://example.com/api/vApp/vm-1111",
+				parent: "vapp1",
+				attributes: map[string]string{
+					"status": "POWERED_ON",
+				},
+			},
+			resourceType: "vcd_vapp_vm",
+			ancestors:    []string{"org1", "vdc1", "vapp1"},
+		},
+		{
+			ref:          resourceRef{name: "region1", id: "urn:vcloud:region:2222"},
+			resourceType: "vcd_tm_region",
+		},
+	}
+	d := datasourceVcdResourceList().Data(nil)
+	dSet(d, "name", "test")
+	diags := setResourceList(d, []string{"vm.with.dots", "region1"}, entries)
+	if diags.HasError() {
+		t.Fatalf("error setting resource list: %v", diags)
+	}
+
+	if d.Get("items.#").(int) != 2 {
+		t.Fatalf("expected 2 items, got %d", d.Get("items.#").(int))
+	}
+	expected := map[string]string{
+		"items.0.name":              "vm.with.dots",
+		"items.0.id":                "urn:vcloud:vm:1111",
+		"items.0.href":              "https://example.com/api/vApp/vm-1111",
+		"items.0.resource_type":     "vcd_vapp_vm",
+		"items.0.parent":            "vapp1",
+		"items.0.ancestors.2":       "vapp1",
+		"items.0.import_id":         "org1.vdc1.vapp1.vm.with.dots",
+		"items.0.attributes.status": "POWERED_ON",
+		"items.1.name":              "region1",
+		"items.1.import_id":         "region1",
+	}
+	for key, want := range expected {
+		got := d.Get(key).(string)
+		if got != want {
+			t.Errorf("%s = '%s', want '%s'", key, got, want)
+		}
+	}
+	if d.Get("items.1.ancestors.#").(int) != 0 {
+		t.Errorf("expected no ancestors for the second item")
+	}
+}
+
+// Test_getResourcesListItems checks that the list of resource types also fills the 'items' attribute
+func Test_getResourcesListItems(t *testing.T) {
+	list, entries, err := getResourcesList()
+	if err != nil {
+		t.Fatalf("error listing resources: %s", err)
+	}
+	d := datasourceVcdResourceList().Data(nil)
+	dSet(d, "name", "resources")
+	diags := setResourceList(d, list, entries)
+	if diags.HasError() {
+		t.Fatalf("error setting resource list: %v", diags)
+	}
+	if d.Get("items.#").(int) != len(list) {
+		t.Fatalf("expected %d items, got %d", len(list), d.Get("items.#").(int))
+	}
+	for i, resource := range list {
+		if got := d.Get(fmt.Sprintf("items.%d.name", i)).(string); got != resource {
+			t.Errorf("items.%d.name = '%s', want '%s'", i, got, resource)
+		}
+		if got := d.Get(fmt.Sprintf("items.%d.resource_type", i)).(string); got != resource {
+			t.Errorf("items.%d.resource_type = '%s', want '%s'", i, got, resource)
+		}
+		if got := d.Get(fmt.Sprintf("items.%d.import_id", i)).(string); got != "" {
+			t.Errorf("items.%d.import_id = '%s', want an empty import ID", i, got)
+		}
+	}
+}
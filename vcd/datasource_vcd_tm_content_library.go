@@ -11,10 +11,12 @@ func datasourceVcdTmContentLibrary() *schema.Resource {
 		ReadContext: datasourceVcdTmContentLibraryRead,
 		Schema: map[string]*schema.Schema{
 			"name": {
-				Type:        schema.TypeString,
-				Required:    true,
-				Description: "The name of the Content Library",
+				Type:         schema.TypeString,
+				Optional:     true,
+				ExactlyOneOf: []string{"name", "filter"},
+				Description:  "The name of the Content Library",
 			},
+			"filter": openApiFilterSchema("Content Library", true, false),
 			"storage_class_ids": {
 				Type:        schema.TypeSet,
 				Computed:    true,
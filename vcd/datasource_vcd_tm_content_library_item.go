@@ -6,6 +6,7 @@ import (
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/vmware/go-vcloud-director/v3/govcd"
 )
 
 func datasourceVcdTmContentLibraryItem() *schema.Resource {
@@ -13,10 +14,12 @@ func datasourceVcdTmContentLibraryItem() *schema.Resource {
 		ReadContext: datasourceTmContentLibraryItemRead,
 		Schema: map[string]*schema.Schema{
 			"name": {
-				Type:        schema.TypeString,
-				Required:    true,
-				Description: fmt.Sprintf("Name of the %s", labelTmContentLibraryItem),
+				Type:         schema.TypeString,
+				Optional:     true,
+				ExactlyOneOf: []string{"name", "filter"},
+				Description:  fmt.Sprintf("Name of the %s", labelTmContentLibraryItem),
 			},
+			"filter": openApiFilterSchema(labelTmContentLibraryItem, true, false),
 			"content_library_id": {
 				Type:        schema.TypeString,
 				Required:    true,
@@ -81,7 +84,18 @@ func datasourceTmContentLibraryItemRead(_ context.Context, d *schema.ResourceDat
 		return diag.Errorf("error retrieving Content Library: %s", err)
 	}
 
-	cli, err := cl.GetContentLibraryItemByName(d.Get("name").(string))
+	var cli *govcd.ContentLibraryItem
+	if filter, hasFilter := d.GetOk("filter"); hasFilter {
+		cli, err = getOpenApiEntityByFilter(&vcdClient.Client, filter, labelTmContentLibraryItem, openApiFilterConfig[*govcd.ContentLibraryItem]{
+			getAllEntitiesFunc: cl.GetAllContentLibraryItems,
+			getIdAndNameFunc: func(e *govcd.ContentLibraryItem) (string, string) {
+				return e.ContentLibraryItem.ID, e.ContentLibraryItem.Name
+			},
+			dateField: "creationDate",
+		})
+	} else {
+		cli, err = cl.GetContentLibraryItemByName(d.Get("name").(string))
+	}
 	if err != nil {
 		return diag.Errorf("error retrieving Content Library Item: %s", err)
 	}
@@ -3,6 +3,7 @@ package vcd
 import (
 	"context"
 	"fmt"
+	"net/url"
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
@@ -19,10 +20,12 @@ func datasourceVcdTmEdgeCluster() *schema.Resource {
 
 		Schema: map[string]*schema.Schema{
 			"name": {
-				Type:        schema.TypeString,
-				Required:    true,
-				Description: fmt.Sprintf("Name %s", labelTmEdgeCluster),
+				Type:         schema.TypeString,
+				Optional:     true,
+				ExactlyOneOf: []string{"name", "filter"},
+				Description:  fmt.Sprintf("Name %s", labelTmEdgeCluster),
 			},
+			"filter": openApiFilterSchema(labelTmEdgeCluster, false, false),
 			"region_id": {
 				Type:        schema.TypeString,
 				Required:    true,
@@ -91,6 +94,12 @@ func datasourceVcdTmEdgeClusterRead(ctx context.Context, d *schema.ResourceData,
 		getEntityFunc:  getByName,
 		stateStoreFunc: setTmEdgeClusterData,
 		preReadHooks:   []schemaHook{syncTmEdgeClustersBeforeReadHook},
+		filterConfig: &openApiFilterConfig[*govcd.TmEdgeCluster]{
+			getAllEntitiesFunc: func(queryParameters url.Values) ([]*govcd.TmEdgeCluster, error) {
+				return vcdClient.GetAllTmEdgeClusters(fiqlFilterAnd(queryParameters, "regionRef.id=="+regionId))
+			},
+			getIdAndNameFunc: func(e *govcd.TmEdgeCluster) (string, string) { return e.TmEdgeCluster.ID, e.TmEdgeCluster.Name },
+		},
 	}
 	return readDatasource(ctx, d, meta, c)
 }
@@ -101,6 +110,7 @@ func setTmEdgeClusterData(_ *VCDClient, d *schema.ResourceData, t *govcd.TmEdgeC
 	}
 
 	d.SetId(t.TmEdgeCluster.ID)
+	dSet(d, "name", t.TmEdgeCluster.Name)
 	dSet(d, "status", t.TmEdgeCluster.Status)
 	dSet(d, "health_status", t.TmEdgeCluster.HealthStatus)
 
@@ -3,6 +3,7 @@ package vcd
 import (
 	"context"
 	"fmt"
+	"net/url"
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
@@ -36,10 +37,12 @@ func datasourceVcdTmIpSpace() *schema.Resource {
 
 		Schema: map[string]*schema.Schema{
 			"name": {
-				Type:        schema.TypeString,
-				Required:    true,
-				Description: fmt.Sprintf("Name of %s", labelTmIpSpace),
+				Type:         schema.TypeString,
+				Optional:     true,
+				ExactlyOneOf: []string{"name", "filter"},
+				Description:  fmt.Sprintf("Name of %s", labelTmIpSpace),
 			},
+			"filter": openApiFilterSchema(labelTmIpSpace, false, false),
 			"region_id": {
 				Type:        schema.TypeString,
 				Required:    true,
@@ -96,6 +99,12 @@ func datasourceVcdTmIpSpaceRead(ctx context.Context, d *schema.ResourceData, met
 		entityLabel:    labelTmIpSpace,
 		getEntityFunc:  getTmIpSpaceByName,
 		stateStoreFunc: setTmIpSpaceData,
+		filterConfig: &openApiFilterConfig[*govcd.TmIpSpace]{
+			getAllEntitiesFunc: func(queryParameters url.Values) ([]*govcd.TmIpSpace, error) {
+				return vcdClient.GetAllTmIpSpaces(fiqlFilterAnd(queryParameters, "regionRef.id=="+d.Get("region_id").(string)))
+			},
+			getIdAndNameFunc: func(e *govcd.TmIpSpace) (string, string) { return e.TmIpSpace.ID, e.TmIpSpace.Name },
+		},
 	}
 	return readDatasource(ctx, d, meta, c)
 }
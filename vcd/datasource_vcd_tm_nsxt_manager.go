@@ -16,10 +16,12 @@ func datasourceVcdTmNsxtManager() *schema.Resource {
 
 		Schema: map[string]*schema.Schema{
 			"name": {
-				Type:        schema.TypeString,
-				Required:    true,
-				Description: fmt.Sprintf("Name of %s", labelNsxtManager),
+				Type:         schema.TypeString,
+				Optional:     true,
+				ExactlyOneOf: []string{"name", "filter"},
+				Description:  fmt.Sprintf("Name of %s", labelNsxtManager),
 			},
+			"filter": openApiFilterSchema(labelNsxtManager, false, false),
 			"description": {
 				Type:        schema.TypeString,
 				Computed:    true,
@@ -60,6 +62,12 @@ func datasourceVcdNsxtManagerRead(ctx context.Context, d *schema.ResourceData, m
 		entityLabel:    labelNsxtManager,
 		getEntityFunc:  vcdClient.GetNsxtManagerOpenApiByName,
 		stateStoreFunc: setNsxtManagerData,
+		filterConfig: &openApiFilterConfig[*govcd.NsxtManagerOpenApi]{
+			getAllEntitiesFunc: vcdClient.GetAllNsxtManagersOpenApi,
+			getIdAndNameFunc: func(e *govcd.NsxtManagerOpenApi) (string, string) {
+				return e.NsxtManagerOpenApi.ID, e.NsxtManagerOpenApi.Name
+			},
+		},
 	}
 	return readDatasource(ctx, d, meta, c)
 }
@@ -16,10 +16,12 @@ func datasourceVcdTmOrg() *schema.Resource {
 
 		Schema: map[string]*schema.Schema{
 			"name": {
-				Type:        schema.TypeString,
-				Required:    true,
-				Description: fmt.Sprintf("The unique identifier in the full URL with which users log in to this %s", labelTmOrg),
+				Type:         schema.TypeString,
+				Optional:     true,
+				ExactlyOneOf: []string{"name", "filter"},
+				Description:  fmt.Sprintf("The unique identifier in the full URL with which users log in to this %s", labelTmOrg),
 			},
+			"filter": openApiFilterSchema(labelTmOrg, false, false),
 			"display_name": {
 				Type:        schema.TypeString,
 				Computed:    true,
@@ -105,6 +107,10 @@ func datasourceVcdTmOrgRead(ctx context.Context, d *schema.ResourceData, meta in
 		entityLabel:    labelTmOrg,
 		getEntityFunc:  vcdClient.GetTmOrgByName,
 		stateStoreFunc: setTmOrgData,
+		filterConfig: &openApiFilterConfig[*govcd.TmOrg]{
+			getAllEntitiesFunc: vcdClient.GetAllTmOrgs,
+			getIdAndNameFunc:   func(e *govcd.TmOrg) (string, string) { return e.TmOrg.ID, e.TmOrg.Name },
+		},
 	}
 	return readDatasource(ctx, d, meta, c)
 }
@@ -3,6 +3,7 @@ package vcd
 import (
 	"context"
 	"fmt"
+	"net/url"
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
@@ -16,10 +17,12 @@ func datasourceVcdTmOrgVdc() *schema.Resource {
 
 		Schema: map[string]*schema.Schema{
 			"name": {
-				Type:        schema.TypeString,
-				Required:    true,
-				Description: fmt.Sprintf("Name of the %s", labelTmOrgVdc),
+				Type:         schema.TypeString,
+				Optional:     true,
+				ExactlyOneOf: []string{"name", "filter"},
+				Description:  fmt.Sprintf("Name of the %s", labelTmOrgVdc),
 			},
+			"filter": openApiFilterSchema(labelTmOrgVdc, false, false),
 			"org_id": {
 				Type:        schema.TypeString,
 				Required:    true,
@@ -106,6 +109,12 @@ func datasourceVcdTmOrgVdcRead(ctx context.Context, d *schema.ResourceData, meta
 		entityLabel:    labelTmOrgVdc,
 		getEntityFunc:  getByNameAndOrgId,
 		stateStoreFunc: setTmVdcData,
+		filterConfig: &openApiFilterConfig[*govcd.TmVdc]{
+			getAllEntitiesFunc: func(queryParameters url.Values) ([]*govcd.TmVdc, error) {
+				return vcdClient.GetAllTmVdcs(fiqlFilterAnd(queryParameters, "org.id=="+d.Get("org_id").(string)))
+			},
+			getIdAndNameFunc: func(e *govcd.TmVdc) (string, string) { return e.TmVdc.ID, e.TmVdc.Name },
+		},
 	}
 	return readDatasource(ctx, d, meta, c)
 }
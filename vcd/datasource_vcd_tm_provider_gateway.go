@@ -3,6 +3,7 @@ package vcd
 import (
 	"context"
 	"fmt"
+	"net/url"
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
@@ -16,10 +17,12 @@ func datasourceVcdTmProviderGateway() *schema.Resource {
 
 		Schema: map[string]*schema.Schema{
 			"name": {
-				Type:        schema.TypeString,
-				Required:    true,
-				Description: fmt.Sprintf("Name of %s", labelTmProviderGateway),
+				Type:         schema.TypeString,
+				Optional:     true,
+				ExactlyOneOf: []string{"name", "filter"},
+				Description:  fmt.Sprintf("Name of %s", labelTmProviderGateway),
 			},
+			"filter": openApiFilterSchema(labelTmProviderGateway, false, false),
 			"region_id": {
 				Type:        schema.TypeString,
 				Required:    true,
@@ -61,6 +64,14 @@ func datasourceVcdTmProviderGatewayRead(ctx context.Context, d *schema.ResourceD
 		entityLabel:    labelTmProviderGateway,
 		getEntityFunc:  getProviderGateway,
 		stateStoreFunc: setTmProviderGatewayData,
+		filterConfig: &openApiFilterConfig[*govcd.TmProviderGateway]{
+			getAllEntitiesFunc: func(queryParameters url.Values) ([]*govcd.TmProviderGateway, error) {
+				return vcdClient.GetAllTmProviderGateways(fiqlFilterAnd(queryParameters, "regionRef.id=="+d.Get("region_id").(string)))
+			},
+			getIdAndNameFunc: func(e *govcd.TmProviderGateway) (string, string) {
+				return e.TmProviderGateway.ID, e.TmProviderGateway.Name
+			},
+		},
 	}
 	return readDatasource(ctx, d, meta, c)
 }
@@ -16,10 +16,12 @@ func datasourceVcdTmRegion() *schema.Resource {
 
 		Schema: map[string]*schema.Schema{
 			"name": {
-				Type:        schema.TypeString,
-				Required:    true,
-				Description: fmt.Sprintf("%s name", labelTmRegion),
+				Type:         schema.TypeString,
+				Optional:     true,
+				ExactlyOneOf: []string{"name", "filter"},
+				Description:  fmt.Sprintf("%s name", labelTmRegion),
 			},
+			"filter": openApiFilterSchema(labelTmRegion, false, false),
 			"description": {
 				Type:        schema.TypeString,
 				Computed:    true,
@@ -86,6 +88,10 @@ func datasourceVcdTmRegionRead(ctx context.Context, d *schema.ResourceData, meta
 		entityLabel:    labelTmRegion,
 		getEntityFunc:  vcdClient.GetRegionByName,
 		stateStoreFunc: setRegionData,
+		filterConfig: &openApiFilterConfig[*govcd.Region]{
+			getAllEntitiesFunc: vcdClient.GetAllRegions,
+			getIdAndNameFunc:   func(e *govcd.Region) (string, string) { return e.Region.ID, e.Region.Name },
+		},
 	}
 	return readDatasource(ctx, d, meta, c)
 }
@@ -23,10 +23,12 @@ func datasourceVcdTmRegionZone() *schema.Resource {
 				Description: fmt.Sprintf("Parent Region ID for %s", labelTmRegionZone),
 			},
 			"name": {
-				Type:        schema.TypeString,
-				Required:    true,
-				Description: fmt.Sprintf("Name of %s", labelTmRegionZone),
+				Type:         schema.TypeString,
+				Optional:     true,
+				ExactlyOneOf: []string{"name", "filter"},
+				Description:  fmt.Sprintf("Name of %s", labelTmRegionZone),
 			},
+			"filter": openApiFilterSchema(labelTmRegionZone, false, false),
 			"memory_limit_mib": {
 				Type:        schema.TypeInt,
 				Computed:    true,
@@ -75,6 +77,10 @@ func resourceVcdTmRegionZoneRead(ctx context.Context, d *schema.ResourceData, me
 		entityLabel:    labelTmRegionZone,
 		getEntityFunc:  getRegionZone,
 		stateStoreFunc: setZoneData,
+		filterConfig: &openApiFilterConfig[*govcd.Zone]{
+			getAllEntitiesFunc: region.GetAllZones,
+			getIdAndNameFunc:   func(e *govcd.Zone) (string, string) { return e.Zone.ID, e.Zone.Name },
+		},
 	}
 	return readDatasource(ctx, d, meta, c)
 }
@@ -84,6 +90,7 @@ func setZoneData(_ *VCDClient, d *schema.ResourceData, z *govcd.Zone) error {
 		return fmt.Errorf("nil %s", labelTmRegionZone)
 	}
 	d.SetId(z.Zone.ID)
+	dSet(d, "name", z.Zone.Name)
 	dSet(d, "memory_limit_mib", z.Zone.MemoryLimitMiB)
 	dSet(d, "memory_reservation_used_mib", z.Zone.MemoryReservationUsedMiB)
 	dSet(d, "memory_reservation_mib", z.Zone.MemoryReservationMiB)
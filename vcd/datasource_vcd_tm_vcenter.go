@@ -16,10 +16,12 @@ func datasourceVcdTmVcenter() *schema.Resource {
 
 		Schema: map[string]*schema.Schema{
 			"name": {
-				Type:        schema.TypeString,
-				Required:    true,
-				Description: fmt.Sprintf("Name of %s", labelTmVirtualCenter),
+				Type:         schema.TypeString,
+				Optional:     true,
+				ExactlyOneOf: []string{"name", "filter"},
+				Description:  fmt.Sprintf("Name of %s", labelTmVirtualCenter),
 			},
+			"filter": openApiFilterSchema(labelTmVirtualCenter, false, false),
 			"url": {
 				Type:        schema.TypeString,
 				Computed:    true,
@@ -96,6 +98,12 @@ func datasourceVcdVcenterRead(ctx context.Context, d *schema.ResourceData, meta
 		entityLabel:    labelTmVirtualCenter,
 		getEntityFunc:  vcdClient.GetVCenterByName,
 		stateStoreFunc: setTmVcenterData,
+		filterConfig: &openApiFilterConfig[*govcd.VCenter]{
+			getAllEntitiesFunc: vcdClient.GetAllVCenters,
+			getIdAndNameFunc: func(e *govcd.VCenter) (string, string) {
+				return "urn:vcloud:vimserver:" + e.VSphereVCenter.VcId, e.VSphereVCenter.Name
+			},
+		},
 	}
 	return readDatasource(ctx, d, meta, c)
 }
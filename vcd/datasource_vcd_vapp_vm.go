@@ -284,6 +284,43 @@ func vcdVmDS(vmType typeOfVm) map[string]*schema.Schema {
 				},
 			}},
 		},
+		"startup": {
+			Type:        schema.TypeList,
+			Computed:    true,
+			Description: "A block defining the start and stop behavior of the VM when its vApp is powered on or off",
+			Elem: &schema.Resource{Schema: map[string]*schema.Schema{
+				"order": {
+					Type:        schema.TypeInt,
+					Computed:    true,
+					Description: "Start order of the VM in the vApp. VMs are started in ascending order and stopped in descending order",
+				},
+				"start_action": {
+					Type:        schema.TypeString,
+					Computed:    true,
+					Description: "Action performed when the vApp is powered on",
+				},
+				"start_delay": {
+					Type:        schema.TypeInt,
+					Computed:    true,
+					Description: "Seconds to wait after starting the VM before starting the next VM in the order",
+				},
+				"wait_for_guest": {
+					Type:        schema.TypeBool,
+					Computed:    true,
+					Description: "Whether to wait for the guest operating system to be ready before starting the next VM in the order",
+				},
+				"stop_action": {
+					Type:        schema.TypeString,
+					Computed:    true,
+					Description: "Action performed when the vApp is powered off",
+				},
+				"stop_delay": {
+					Type:        schema.TypeInt,
+					Computed:    true,
+					Description: "Seconds to wait after stopping the VM before stopping the next VM in the order",
+				},
+			}},
+		},
 		"boot_options": {
 			Type:        schema.TypeList,
 			Computed:    true,
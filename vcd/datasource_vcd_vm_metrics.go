@@ -0,0 +1,390 @@
+package vcd
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"log"
+	"net/http"
+	"regexp"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+	"github.com/vmware/go-vcloud-director/v3/govcd"
+)
+
+// vmCurrentUsage is the response of the current metrics of a VM
+type vmCurrentUsage struct {
+	XMLName xml.Name   `xml:"CurrentUsage"`
+	Metric  []vmMetric `xml:"Metric"`
+}
+
+// vmMetric is a single value of a metric
+type vmMetric struct {
+	Name  string `xml:"name,attr"`
+	Unit  string `xml:"unit,attr"`
+	Value string `xml:"value,attr"`
+}
+
+// vmHistoricUsage is the response of the historic metrics of a VM, which covers the last 24 hours
+type vmHistoricUsage struct {
+	XMLName      xml.Name                 `xml:"HistoricUsage"`
+	MetricSeries []vmHistoricMetricSeries `xml:"MetricSeries"`
+}
+
+// vmHistoricMetricSeries holds the samples of a metric
+type vmHistoricMetricSeries struct {
+	Name   string             `xml:"name,attr"`
+	Unit   string             `xml:"unit,attr"`
+	Sample []vmHistoricSample `xml:"Sample"`
+}
+
+type vmHistoricSample struct {
+	Timestamp string `xml:"timestamp,attr"`
+	Value     string `xml:"value,attr"`
+}
+
+// vmMetricsMaxHistoricHours is the time window covered by the historic metrics of VCD
+const vmMetricsMaxHistoricHours = 24
+
+// vmMetricAttribute is an attribute of the data source set from a metric, with the unit of the attribute
+type vmMetricAttribute struct {
+	name string
+	unit string
+}
+
+// vmMetricAttributes maps the metrics reported by VCD to the attributes of the data source
+var vmMetricAttributes = map[string]vmMetricAttribute{
+	"cpu.usage.average":           {name: "cpu_usage_percent", unit: "PERCENT"},
+	"cpu.usagemhz.average":        {name: "cpu_usage_mhz", unit: "MEGAHERTZ"},
+	"mem.usage.average":           {name: "memory_usage_percent", unit: "PERCENT"},
+	"mem.consumed.average":        {name: "memory_consumed_kb", unit: "KILOBYTE"},
+	"disk.maxTotalLatency.latest": {name: "disk_latency_ms", unit: "MILLISECOND"},
+	"disk.read.average":           {name: "disk_read_kbps", unit: "KILOBYTES_PER_SECOND"},
+	"disk.write.average":          {name: "disk_write_kbps", unit: "KILOBYTES_PER_SECOND"},
+	"net.usage.average":           {name: "network_throughput_kbps", unit: "KILOBYTES_PER_SECOND"},
+}
+
+// vmDiskMetricRegexp matches the per-disk metrics, e.g. 'disk.0.provisioned.latest'
+var vmDiskMetricRegexp = regexp.MustCompile(`^disk\.([^.]+)\.(provisioned|used)\.latest$`)
+
+// vmMetricUnit is a unit of the metrics, with its quantity and its factor to the base unit of the quantity
+type vmMetricUnit struct {
+	quantity string
+	factor   float64
+}
+
+// vmMetricUnits contains the units that the metrics can be converted from and to
+var vmMetricUnits = map[string]vmMetricUnit{
+	"PERCENT":              {quantity: "percentage", factor: 1},
+	"HERTZ":                {quantity: "frequency", factor: 1},
+	"KILOHERTZ":            {quantity: "frequency", factor: 1e3},
+	"MEGAHERTZ":            {quantity: "frequency", factor: 1e6},
+	"GIGAHERTZ":            {quantity: "frequency", factor: 1e9},
+	"BYTE":                 {quantity: "size", factor: 1},
+	"KILOBYTE":             {quantity: "size", factor: 1024},
+	"MEGABYTE":             {quantity: "size", factor: 1024 * 1024},
+	"GIGABYTE":             {quantity: "size", factor: 1024 * 1024 * 1024},
+	"BYTES_PER_SECOND":     {quantity: "rate", factor: 1},
+	"KILOBYTES_PER_SECOND": {quantity: "rate", factor: 1024},
+	"MEGABYTES_PER_SECOND": {quantity: "rate", factor: 1024 * 1024},
+	"MICROSECOND":          {quantity: "time", factor: 1e-6},
+	"MILLISECOND":          {quantity: "time", factor: 1e-3},
+	"SECOND":               {quantity: "time", factor: 1},
+}
+
+// convertVmMetric converts the value of a metric from the unit reported by VCD to the given unit. It fails when the
+// unit is unknown or measures another quantity
+func convertVmMetric(value float64, fromUnit, toUnit string) (float64, error) {
+	from, ok := vmMetricUnits[fromUnit]
+	if !ok {
+		return 0, fmt.Errorf("unknown unit '%s'", fromUnit)
+	}
+	to := vmMetricUnits[toUnit]
+	if from.quantity != to.quantity {
+		return 0, fmt.Errorf("unit '%s' can't be converted to '%s'", fromUnit, toUnit)
+	}
+	return value * from.factor / to.factor, nil
+}
+
+func datasourceVcdVmMetrics() *schema.Resource {
+	metricSchema := func(description string) *schema.Schema {
+		return &schema.Schema{
+			Type:        schema.TypeFloat,
+			Computed:    true,
+			Description: description,
+		}
+	}
+	return &schema.Resource{
+		ReadContext: datasourceVcdVmMetricsRead,
+		Schema: map[string]*schema.Schema{
+			"org": {
+				Type:     schema.TypeString,
+				Optional: true,
+				Description: "The name of organization to use, optional if defined at provider " +
+					"level. Useful when connected as sysadmin working across different organizations",
+			},
+			"vdc": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "The name of VDC to use, optional if defined at provider level",
+			},
+			"vm_id": {
+				Type:        schema.TypeString,
+				Required:    true,
+				Description: "The ID of the VM",
+			},
+			"historic_hours": {
+				Type:         schema.TypeInt,
+				Optional:     true,
+				ValidateFunc: validation.IntBetween(0, vmMetricsMaxHistoricHours),
+				Description:  "When set, the historic samples of the last given hours are retrieved in 'historic_sample'. Up to 24",
+			},
+			"cpu_usage_percent":       metricSchema("CPU usage, in percent"),
+			"cpu_usage_mhz":           metricSchema("CPU usage, in MHz"),
+			"memory_usage_percent":    metricSchema("Memory usage, in percent"),
+			"memory_consumed_kb":      metricSchema("Memory consumed, in KB"),
+			"disk_latency_ms":         metricSchema("Highest disk latency, in milliseconds"),
+			"disk_read_kbps":          metricSchema("Disk read rate, in KB per second"),
+			"disk_write_kbps":         metricSchema("Disk write rate, in KB per second"),
+			"network_throughput_kbps": metricSchema("Network throughput, in KB per second"),
+			"disk": {
+				Type:        schema.TypeList,
+				Computed:    true,
+				Description: "Provisioned and used space of each disk",
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"instance": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "The disk instance, as reported by VCD",
+						},
+						"provisioned_bytes": {
+							Type:        schema.TypeInt,
+							Computed:    true,
+							Description: "Provisioned space of the disk, in bytes",
+						},
+						"used_bytes": {
+							Type:        schema.TypeInt,
+							Computed:    true,
+							Description: "Used space of the disk, in bytes",
+						},
+					},
+				},
+			},
+			"metric": {
+				Type:        schema.TypeList,
+				Computed:    true,
+				Description: "All the current metrics reported by VCD",
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"name": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "Name of the metric",
+						},
+						"unit": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "Unit of the metric",
+						},
+						"value": {
+							Type:        schema.TypeFloat,
+							Computed:    true,
+							Description: "Value of the metric",
+						},
+					},
+				},
+			},
+			"historic_sample": {
+				Type:        schema.TypeList,
+				Computed:    true,
+				Description: "Historic samples of the metrics in the window set by 'historic_hours', sorted by metric and time",
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"name": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "Name of the metric",
+						},
+						"unit": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "Unit of the metric",
+						},
+						"timestamp": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "Time of the sample",
+						},
+						"value": {
+							Type:        schema.TypeFloat,
+							Computed:    true,
+							Description: "Value of the metric",
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func datasourceVcdVmMetricsRead(_ context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	vcdClient := meta.(*VCDClient)
+	_, vdc, err := vcdClient.GetOrgAndVdcFromResource(d)
+	if err != nil {
+		return diag.Errorf(errorRetrievingOrgAndVdc, err)
+	}
+	vm, err := vdc.QueryVmById(d.Get("vm_id").(string))
+	if err != nil {
+		return diag.Errorf("[VM metrics read] error retrieving VM %s: %s", d.Get("vm_id"), err)
+	}
+
+	currentUsage := &vmCurrentUsage{}
+	_, err = vcdClient.Client.ExecuteRequest(vm.VM.HREF+"/metrics/current", http.MethodGet, "",
+		"error retrieving current metrics: %s", nil, currentUsage)
+	if err != nil {
+		return diag.Errorf("[VM metrics read] error retrieving metrics of VM %s: %s", vm.VM.Name, err)
+	}
+	err = setVmCurrentMetrics(d, currentUsage)
+	if err != nil {
+		return diag.Errorf("[VM metrics read] error setting metrics of VM %s: %s", vm.VM.Name, err)
+	}
+
+	var samples []interface{}
+	if hours := d.Get("historic_hours").(int); hours > 0 {
+		samples, err = getVmHistoricSamples(&vcdClient.Client, vm, time.Now().Add(-time.Duration(hours)*time.Hour))
+		if err != nil {
+			return diag.Errorf("[VM metrics read] error retrieving historic metrics of VM %s: %s", vm.VM.Name, err)
+		}
+	}
+	err = d.Set("historic_sample", samples)
+	if err != nil {
+		return diag.Errorf("[VM metrics read] error setting historic metrics of VM %s: %s", vm.VM.Name, err)
+	}
+
+	d.SetId(vm.VM.ID)
+	return nil
+}
+
+// setVmCurrentMetrics sets the known metrics in their attributes, the per-disk ones in `disk` and all of them in
+// `metric`. The values of the attributes are converted from the unit reported by VCD. Metrics that VCD doesn't
+// report, e.g. for a powered off VM, or reports in an unknown unit, are left at 0
+func setVmCurrentMetrics(d *schema.ResourceData, currentUsage *vmCurrentUsage) error {
+	values := make(map[string]float64)
+	disks := make(map[string]map[string]interface{})
+	var metrics []interface{}
+	for _, metric := range currentUsage.Metric {
+		value, err := strconv.ParseFloat(metric.Value, 64)
+		if err != nil {
+			log.Printf("[DEBUG] skipping metric %s with value '%s': %s", metric.Name, metric.Value, err)
+			continue
+		}
+		metrics = append(metrics, map[string]interface{}{
+			"name":  metric.Name,
+			"unit":  metric.Unit,
+			"value": value,
+		})
+
+		if attribute, ok := vmMetricAttributes[metric.Name]; ok {
+			converted, err := convertVmMetric(value, metric.Unit, attribute.unit)
+			if err != nil {
+				log.Printf("[DEBUG] not setting %s from metric %s: %s", attribute.name, metric.Name, err)
+				continue
+			}
+			values[attribute.name] = converted
+			continue
+		}
+		if match := vmDiskMetricRegexp.FindStringSubmatch(metric.Name); match != nil {
+			instance, kind := match[1], match[2]
+			bytes, err := convertVmMetric(value, metric.Unit, "BYTE")
+			if err != nil {
+				log.Printf("[DEBUG] not setting the %s space of disk %s from metric %s: %s", kind, instance, metric.Name, err)
+				continue
+			}
+			if disks[instance] == nil {
+				disks[instance] = map[string]interface{}{"instance": instance}
+			}
+			disks[instance][kind+"_bytes"] = int(bytes)
+		}
+	}
+
+	for _, attribute := range vmMetricAttributes {
+		dSet(d, attribute.name, values[attribute.name])
+	}
+	instances := make([]string, 0, len(disks))
+	for instance := range disks {
+		instances = append(instances, instance)
+	}
+	sort.Strings(instances)
+	diskList := make([]interface{}, 0, len(instances))
+	for _, instance := range instances {
+		diskList = append(diskList, disks[instance])
+	}
+	err := d.Set("disk", diskList)
+	if err != nil {
+		return err
+	}
+	return d.Set("metric", metrics)
+}
+
+// getVmHistoricSamples returns the historic samples of the VM metrics taken from the given time on
+func getVmHistoricSamples(client *govcd.Client, vm *govcd.VM, from time.Time) ([]interface{}, error) {
+	historicUsage := &vmHistoricUsage{}
+	_, err := client.ExecuteRequest(vm.VM.HREF+"/metrics/historic", http.MethodGet, "",
+		"error retrieving historic metrics: %s", nil, historicUsage)
+	if err != nil {
+		return nil, err
+	}
+
+	type historicSample struct {
+		series    *vmHistoricMetricSeries
+		timestamp time.Time
+		sample    vmHistoricSample
+		value     float64
+	}
+	var historicSamples []historicSample
+	for i, series := range historicUsage.MetricSeries {
+		for _, sample := range series.Sample {
+			timestamp, err := time.Parse(time.RFC3339, sample.Timestamp)
+			if err != nil {
+				return nil, fmt.Errorf("invalid timestamp '%s' of metric %s: %s", sample.Timestamp, series.Name, err)
+			}
+			if timestamp.Before(from) {
+				continue
+			}
+			value, err := strconv.ParseFloat(sample.Value, 64)
+			if err != nil {
+				log.Printf("[DEBUG] skipping sample of metric %s with value '%s': %s", series.Name, sample.Value, err)
+				continue
+			}
+			historicSamples = append(historicSamples, historicSample{
+				series:    &historicUsage.MetricSeries[i],
+				timestamp: timestamp,
+				sample:    sample,
+				value:     value,
+			})
+		}
+	}
+	sort.SliceStable(historicSamples, func(i, j int) bool {
+		if historicSamples[i].series.Name != historicSamples[j].series.Name {
+			return historicSamples[i].series.Name < historicSamples[j].series.Name
+		}
+		return historicSamples[i].timestamp.Before(historicSamples[j].timestamp)
+	})
+
+	samples := make([]interface{}, 0, len(historicSamples))
+	for _, historic := range historicSamples {
+		samples = append(samples, map[string]interface{}{
+			"name":      historic.series.Name,
+			"unit":      historic.series.Unit,
+			"timestamp": historic.sample.Timestamp,
+			"value":     historic.value,
+		})
+	}
+	return samples, nil
+}
@@ -0,0 +1,81 @@
+//go:build vapp || vm || ALL || functional
+
+package vcd
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+)
+
+// TestAccVcdVmMetricsDS retrieves the metrics of a powered on VM. Metrics need some time to be collected after the power
+// on, so only their presence is checked
+func TestAccVcdVmMetricsDS(t *testing.T) {
+	preTestChecks(t)
+
+	var params = StringMap{
+		"Org":         testConfig.VCD.Org,
+		"Vdc":         testConfig.VCD.Vdc,
+		"VappName":    t.Name(),
+		"Catalog":     testSuiteCatalogName,
+		"CatalogItem": testSuiteCatalogOVAItem,
+		"Tags":        "vapp vm",
+		"FuncName":    t.Name(),
+	}
+	testParamsNotEmpty(t, params)
+
+	configText := templateFill(testAccVcdVmMetricsDS, params)
+	debugPrintf("#[DEBUG] CONFIGURATION: %s\n", configText)
+
+	if vcdShortTest {
+		t.Skip(acceptanceTestsSkipped)
+		return
+	}
+
+	resource.Test(t, resource.TestCase{
+		ProviderFactories: testAccProviders,
+		CheckDestroy:      testAccCheckVcdVAppDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: configText,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttrPair("data.vcd_vm_metrics.vm", "id", "vcd_vapp_vm.vm", "id"),
+					resource.TestMatchResourceAttr("data.vcd_vm_metrics.vm", "cpu_usage_percent", regexp.MustCompile(`^[\d.]+$`)),
+					resource.TestMatchResourceAttr("data.vcd_vm_metrics.vm", "memory_usage_percent", regexp.MustCompile(`^[\d.]+$`)),
+					resource.TestCheckResourceAttrSet("data.vcd_vm_metrics.vm", "metric.#"),
+					resource.TestCheckResourceAttrSet("data.vcd_vm_metrics.vm", "disk.#"),
+					resource.TestCheckResourceAttrSet("data.vcd_vm_metrics.vm", "historic_sample.#"),
+				),
+			},
+		},
+	})
+	postTestChecks(t)
+}
+
+const testAccVcdVmMetricsDS = `
+resource "vcd_vapp" "vapp" {
+  org  = "{{.Org}}"
+  vdc  = "{{.Vdc}}"
+  name = "{{.VappName}}"
+}
+
+resource "vcd_vapp_vm" "vm" {
+  org           = "{{.Org}}"
+  vdc           = "{{.Vdc}}"
+  vapp_name     = vcd_vapp.vapp.name
+  name          = "vm"
+  catalog_name  = "{{.Catalog}}"
+  template_name = "{{.CatalogItem}}"
+  memory        = 512
+  cpus          = 1
+  power_on      = true
+}
+
+data "vcd_vm_metrics" "vm" {
+  org            = "{{.Org}}"
+  vdc            = "{{.Vdc}}"
+  vm_id          = vcd_vapp_vm.vm.id
+  historic_hours = 1
+}
+`
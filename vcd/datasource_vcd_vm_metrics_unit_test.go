@@ -0,0 +1,164 @@
+//go:build unit || ALL
+
+package vcd
+
+import (
+	"encoding/xml"
+	"fmt"
+	"math"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/vmware/go-vcloud-director/v3/govcd"
+	"github.com/vmware/go-vcloud-director/v3/types/v56"
+	"github.com/vmware/terraform-provider-vcd/v4/vcd/mockvcd"
+)
+
+// Test_setVmCurrentMetrics checks that the known metrics are set in their attributes and that the per-disk ones are
+// converted to bytes
+func Test_setVmCurrentMetrics(t *testing.T) {
+	d := schema.TestResourceDataRaw(t, datasourceVcdVmMetrics().Schema, map[string]interface{}{"vm_id": "vm"})
+	currentUsage := &vmCurrentUsage{Metric: []vmMetric{
+		{Name: "cpu.usage.average", Unit: "PERCENT", Value: "12.5"},
+		{Name: "cpu.usagemhz.average", Unit: "MEGAHERTZ", Value: "300"},
+		{Name: "mem.usage.average", Unit: "PERCENT", Value: "40"},
+		{Name: "disk.1.provisioned.latest", Unit: "KILOBYTE", Value: "2048"},
+		{Name: "disk.0.provisioned.latest", Unit: "KILOBYTE", Value: "1024"},
+		{Name: "disk.0.used.latest", Unit: "KILOBYTE", Value: "512"},
+		{Name: "disk.provisioned.latest", Unit: "KILOBYTE", Value: "3072"},
+		{Name: "cpu.usage.maximum", Unit: "PERCENT", Value: ""},
+	}}
+
+	err := setVmCurrentMetrics(d, currentUsage)
+	if err != nil {
+		t.Fatalf("error setting metrics: %s", err)
+	}
+	for attribute, expected := range map[string]float64{
+		"cpu_usage_percent":       12.5,
+		"cpu_usage_mhz":           300,
+		"memory_usage_percent":    40,
+		"network_throughput_kbps": 0,
+	} {
+		if value := d.Get(attribute).(float64); value != expected {
+			t.Errorf("expected %s to be %f, got %f", attribute, expected, value)
+		}
+	}
+
+	disks := d.Get("disk").([]interface{})
+	if len(disks) != 2 {
+		t.Fatalf("expected 2 disks, got %v", disks)
+	}
+	first := disks[0].(map[string]interface{})
+	if first["instance"] != "0" || first["provisioned_bytes"] != 1024*1024 || first["used_bytes"] != 512*1024 {
+		t.Errorf("unexpected first disk: %v", first)
+	}
+	if second := disks[1].(map[string]interface{}); second["instance"] != "1" || second["used_bytes"] != 0 {
+		t.Errorf("unexpected second disk: %v", second)
+	}
+	// The metric without value is skipped
+	if count := len(d.Get("metric").([]interface{})); count != len(currentUsage.Metric)-1 {
+		t.Errorf("expected %d metrics, got %d", len(currentUsage.Metric)-1, count)
+	}
+}
+
+// Test_setVmCurrentMetricsResponse checks the attributes set from a full current metrics response, in the format of
+// the example of the VCD API reference for GET /vApp/{id}/metrics/current. Some metrics are reported in other units
+// than the ones of their attributes, to check the conversions
+func Test_setVmCurrentMetricsResponse(t *testing.T) {
+	response := `<?xml version="1.0" encoding="UTF-8"?>
+<CurrentUsage xmlns="http://www.vmware.com/vcloud/v1.5" type="application/vnd.vmware.vcloud.metrics.currentUsageSpec+xml" href="https://vcd.example.com/api/vApp/vm-11111111-2222-3333-4444-555555555555/metrics/current">
+    <Link rel="up" href="https://vcd.example.com/api/vApp/vm-11111111-2222-3333-4444-555555555555" type="application/vnd.vmware.vcloud.vm+xml"/>
+    <Metric name="cpu.usage.average" unit="PERCENT" value="0.38"/>
+    <Metric name="cpu.usagemhz.average" unit="MEGAHERTZ" value="9.0"/>
+    <Metric name="cpu.usage.maximum" unit="PERCENT" value="0.38"/>
+    <Metric name="mem.usage.average" unit="PERCENT" value="1.99"/>
+    <Metric name="mem.consumed.average" unit="MEGABYTE" value="81.5"/>
+    <Metric name="disk.provisioned.latest" unit="KILOBYTE" value="16777216"/>
+    <Metric name="disk.used.latest" unit="KILOBYTE" value="1423360"/>
+    <Metric name="disk.read.average" unit="KILOBYTES_PER_SECOND" value="0.0"/>
+    <Metric name="disk.write.average" unit="BYTES_PER_SECOND" value="3072"/>
+    <Metric name="disk.maxTotalLatency.latest" unit="MICROSECOND" value="2500"/>
+    <Metric name="net.usage.average" unit="GIGAHERTZ" value="1"/>
+    <Metric name="disk.0.provisioned.latest" unit="KILOBYTE" value="16777216"/>
+    <Metric name="disk.0.used.latest" unit="KILOBYTE" value="1423360"/>
+    <Metric name="disk.0.read.average" unit="KILOBYTES_PER_SECOND" value="0.0"/>
+    <Metric name="disk.0.write.average" unit="KILOBYTES_PER_SECOND" value="3.0"/>
+</CurrentUsage>`
+	currentUsage := &vmCurrentUsage{}
+	err := xml.Unmarshal([]byte(response), currentUsage)
+	if err != nil {
+		t.Fatalf("error decoding the response: %s", err)
+	}
+	d := schema.TestResourceDataRaw(t, datasourceVcdVmMetrics().Schema, map[string]interface{}{"vm_id": "vm"})
+	err = setVmCurrentMetrics(d, currentUsage)
+	if err != nil {
+		t.Fatalf("error setting metrics: %s", err)
+	}
+
+	for attribute, expected := range map[string]float64{
+		"cpu_usage_percent":    0.38,
+		"cpu_usage_mhz":        9,
+		"memory_usage_percent": 1.99,
+		"memory_consumed_kb":   81.5 * 1024,
+		"disk_read_kbps":       0,
+		"disk_write_kbps":      3,
+		"disk_latency_ms":      2.5,
+		// A unit of another quantity is not converted
+		"network_throughput_kbps": 0,
+	} {
+		if value := d.Get(attribute).(float64); math.Abs(value-expected) > 1e-9 {
+			t.Errorf("expected %s to be %f, got %f", attribute, expected, value)
+		}
+	}
+	disks := d.Get("disk").([]interface{})
+	if len(disks) != 1 {
+		t.Fatalf("expected 1 disk, got %v", disks)
+	}
+	if disk := disks[0].(map[string]interface{}); disk["provisioned_bytes"] != 16777216*1024 || disk["used_bytes"] != 1423360*1024 {
+		t.Errorf("unexpected disk: %v", disk)
+	}
+	if count := len(d.Get("metric").([]interface{})); count != len(currentUsage.Metric) {
+		t.Errorf("expected %d metrics, got %d", len(currentUsage.Metric), count)
+	}
+}
+
+// Test_getVmHistoricSamples checks that only the samples in the time window are returned, sorted by metric and time
+func Test_getVmHistoricSamples(t *testing.T) {
+	server := mockvcd.NewServer()
+	defer server.Close()
+	vcdClient := newMockVcdClient(t, server)
+
+	vmPath := "/api/vApp/vm-11111111-2222-3333-4444-555555555555"
+	now := time.Now().UTC()
+	sampleTime := func(hoursAgo float64) string {
+		return now.Add(-time.Duration(hoursAgo * float64(time.Hour))).Format(time.RFC3339)
+	}
+	server.HandleFunc(http.MethodGet, vmPath+"/metrics/historic", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/vnd.vmware.vcloud.metrics.historicUsageSpec+xml")
+		_, _ = fmt.Fprintf(w, `<HistoricUsage xmlns="%s">`+
+			`<MetricSeries name="mem.usage.average" unit="PERCENT"><Sample timestamp="%s" value="30"/></MetricSeries>`+
+			`<MetricSeries name="cpu.usage.average" unit="PERCENT">`+
+			`<Sample timestamp="%s" value="20"/><Sample timestamp="%s" value="10"/><Sample timestamp="%s" value="5"/>`+
+			`</MetricSeries></HistoricUsage>`,
+			types.XMLNamespaceVCloud, sampleTime(0.5), sampleTime(0.2), sampleTime(0.7), sampleTime(3))
+	})
+
+	vm := govcd.NewVM(&vcdClient.Client)
+	vm.VM = &types.Vm{HREF: server.URL + vmPath, Name: "web"}
+
+	samples, err := getVmHistoricSamples(&vcdClient.Client, vm, now.Add(-time.Hour))
+	if err != nil {
+		t.Fatalf("error retrieving historic samples: %s", err)
+	}
+	var got []string
+	for _, sample := range samples {
+		sampleMap := sample.(map[string]interface{})
+		got = append(got, fmt.Sprintf("%s=%v", sampleMap["name"], sampleMap["value"]))
+	}
+	expected := []string{"cpu.usage.average=10", "cpu.usage.average=20", "mem.usage.average=30"}
+	if fmt.Sprint(got) != fmt.Sprint(expected) {
+		t.Errorf("expected samples %v, got %v", expected, got)
+	}
+}
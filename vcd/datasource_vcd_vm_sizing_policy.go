@@ -19,9 +19,11 @@ func datasourceVcdVmSizingPolicy() *schema.Resource {
 				Description: "The name of organization to use - Deprecated and unneeded: will be ignored if used ",
 			},
 			"name": {
-				Type:     schema.TypeString,
-				Required: true,
+				Type:         schema.TypeString,
+				Optional:     true,
+				ExactlyOneOf: []string{"name", "filter"},
 			},
+			"filter": openApiFilterSchema("VM sizing policy", false, false),
 			"description": {
 				Type:     schema.TypeString,
 				Computed: true,
@@ -0,0 +1,73 @@
+package vcd
+
+import (
+	"context"
+	"net/url"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/vmware/go-vcloud-director/v3/types/v56"
+)
+
+func datasourceVcdVms() *schema.Resource {
+	return &schema.Resource{
+		ReadContext: datasourceVcdVmsRead,
+		Schema: listDatasourceSchema("VM", datasourceVcdVAppVm(), map[string]*schema.Schema{
+			"org": {
+				Type:     schema.TypeString,
+				Optional: true,
+				Description: "The name of organization to use, optional if defined at provider " +
+					"level. Useful when connected as sysadmin working across different organizations",
+			},
+			"vdc": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "The name of VDC to use, optional if defined at provider level",
+			},
+			"vapp_name": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "The name of the vApp that contains the VMs. If empty, the VMs of all the vApps are retrieved, including standalone VMs",
+			},
+		}),
+	}
+}
+
+func datasourceVcdVmsRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	vcdClient := meta.(*VCDClient)
+	_, vdc, err := vcdClient.GetOrgAndVdcFromResource(d)
+	if err != nil {
+		return diag.Errorf(errorRetrievingOrgAndVdc, err)
+	}
+
+	getVms := func(queryParameters url.Values) ([]*types.QueryResultVMRecordType, error) {
+		vappFilter := ""
+		if vappName := d.Get("vapp_name").(string); vappName != "" {
+			vappFilter = "containerName==" + vappName
+		}
+		filter := joinFiqlFilters(types.VmQueryFilterOnlyDeployed.String(), "vdc=="+vdc.Vdc.HREF, vappFilter, queryParameters.Get("filter"))
+		return queryAllRecords(&vcdClient.Client, vcdClient.Client.GetQueryType(types.QtVm), filter,
+			func(results *types.QueryResultRecordsType) []*types.QueryResultVMRecordType {
+				if vcdClient.Client.IsSysAdmin {
+					return results.AdminVMRecord
+				}
+				return results.VMRecord
+			})
+	}
+
+	itemDatasource := datasourceVcdVAppVm()
+	c := dsListConfig[*types.QueryResultVMRecordType]{
+		entityLabel:     "VM",
+		itemDatasource:  itemDatasource,
+		getEntitiesFunc: getVms,
+		stateStoreFunc: readWithItemDatasource(itemDatasource, func(vm *types.QueryResultVMRecordType) map[string]string {
+			return map[string]string{
+				"org":       d.Get("org").(string),
+				"vdc":       vdc.Vdc.Name,
+				"vapp_name": vm.ContainerName,
+				"name":      vm.Name,
+			}
+		}),
+	}
+	return readDatasourceList(ctx, d, meta, c)
+}
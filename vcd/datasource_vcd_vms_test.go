@@ -0,0 +1,102 @@
+//go:build vapp || vm || ALL || functional
+
+package vcd
+
+import (
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+)
+
+// TestAccVcdDatasourceVms checks that vcd_vms retrieves the VMs of a vApp with the same attributes of vcd_vapp_vm,
+// and that the FIQL filter is applied
+func TestAccVcdDatasourceVms(t *testing.T) {
+	preTestChecks(t)
+
+	var params = StringMap{
+		"Org":      testConfig.VCD.Org,
+		"Vdc":      testConfig.VCD.Vdc,
+		"VappName": t.Name(),
+		"Tags":     "vapp vm",
+		"FuncName": t.Name(),
+	}
+	testParamsNotEmpty(t, params)
+
+	configText := templateFill(testAccVcdDatasourceVms, params)
+	debugPrintf("#[DEBUG] CONFIGURATION: %s", configText)
+
+	if vcdShortTest {
+		t.Skip(acceptanceTestsSkipped)
+		return
+	}
+
+	resource.Test(t, resource.TestCase{
+		ProviderFactories: testAccProviders,
+		CheckDestroy:      testAccCheckVcdVAppDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: configText,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("data.vcd_vms.all", "items.#", "2"),
+					resource.TestCheckResourceAttr("data.vcd_vms.filtered", "items.#", "1"),
+					resource.TestCheckResourceAttrPair("data.vcd_vms.filtered", "items.0.id", "vcd_vapp_vm.vm2", "id"),
+					resource.TestCheckResourceAttrPair("data.vcd_vms.filtered", "items.0.vapp_name", "vcd_vapp.vapp", "name"),
+					resource.TestCheckResourceAttr("data.vcd_vms.filtered", "items.0.memory", "1024"),
+					resource.TestCheckResourceAttr("data.vcd_vms.filtered", "items.0.os_type", "sles10_64Guest"),
+				),
+			},
+		},
+	})
+	postTestChecks(t)
+}
+
+const testAccVcdDatasourceVms = `
+resource "vcd_vapp" "vapp" {
+  org  = "{{.Org}}"
+  vdc  = "{{.Vdc}}"
+  name = "{{.VappName}}"
+}
+
+resource "vcd_vapp_vm" "vm1" {
+  org              = "{{.Org}}"
+  vdc              = "{{.Vdc}}"
+  vapp_name        = vcd_vapp.vapp.name
+  name             = "vm1"
+  computer_name    = "vm1"
+  memory           = 512
+  cpus             = 1
+  cpu_cores        = 1
+  os_type          = "sles10_64Guest"
+  hardware_version = "vmx-11"
+  power_on         = false
+}
+
+resource "vcd_vapp_vm" "vm2" {
+  org              = "{{.Org}}"
+  vdc              = "{{.Vdc}}"
+  vapp_name        = vcd_vapp.vapp.name
+  name             = "vm2"
+  computer_name    = "vm2"
+  memory           = 1024
+  cpus             = 1
+  cpu_cores        = 1
+  os_type          = "sles10_64Guest"
+  hardware_version = "vmx-11"
+  power_on         = false
+}
+
+data "vcd_vms" "all" {
+  org       = "{{.Org}}"
+  vdc       = "{{.Vdc}}"
+  vapp_name = vcd_vapp.vapp.name
+
+  depends_on = [vcd_vapp_vm.vm1, vcd_vapp_vm.vm2]
+}
+
+data "vcd_vms" "filtered" {
+  org       = "{{.Org}}"
+  vdc       = "{{.Vdc}}"
+  vapp_name = vcd_vapp.vapp.name
+  filter    = "name==${vcd_vapp_vm.vm2.name}"
+}
+`
@@ -0,0 +1,468 @@
+package vcd
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/vmware/go-vcloud-director/v3/govcd"
+	"github.com/vmware/go-vcloud-director/v3/types/v56"
+)
+
+// Distributed locks protect the mutating operations on a parent entity (Edge Gateway, VDC Group or vApp) from other
+// provider processes, such as pipelines of different workspaces that manage the same Edge Gateway. vcdMutexKV only
+// serializes the operations within one process.
+//
+// The lock is a lease stored as a legacy metadata entry, whose value contains the holder ID, a token of the
+// acquisition and the expiration time. vApps and Edge Gateways store their own lease. VDC Groups have no metadata,
+// and their lease is stored in the Organization that owns them, with a key that includes the ID of the VDC Group.
+// The lease is acquired by writing the entry, when it is missing or expired, and is renewed by a heartbeat until the
+// operation finishes. The legacy metadata API has no conditional updates: after writing the lease, the acquisition
+// waits for a settle delay and reads it again, so that only the last of the processes competing for the lease gets it.
+// The token, rather than the holder ID, identifies the owner of the lease: a lease of a previous acquisition of the
+// same process is never taken over nor deleted before it expires.
+//
+// Within a process, the lease of an entity is shared by all the operations that need it, and it is released when
+// the last of them finishes. These operations are still serialized among them by vcdMutexKV. When the heartbeat
+// cannot renew the lease before it expires, or another process takes it over, the lease is lost and the operations
+// that hold it fail when they release it.
+//
+// The leases are not regular metadata: they are filtered out wherever the legacy metadata of an entity is read or
+// deleted.
+
+const (
+	// distributedLockKey is the key of the metadata entries used as lease. It includes the name of the provider, as
+	// the legacy metadata API has no namespaces
+	distributedLockKey = "terraform-provider-vcd-lock"
+
+	defaultDistributedLockTtl     = 2 * time.Minute
+	defaultDistributedLockTimeout = 30 * time.Minute
+	defaultDistributedLockPoll    = 5 * time.Second
+	defaultDistributedLockSettle  = 2 * time.Second
+)
+
+// distributedLockLease is the value of the metadata entry used as lease
+type distributedLockLease struct {
+	Holder    string    `json:"holder"`
+	Token     string    `json:"token"`
+	ExpiresAt time.Time `json:"expiresAt"`
+}
+
+// distributedLockTarget is the metadata entry that stores the lease of an entity
+type distributedLockTarget struct {
+	href string // HREF of the entity that holds the metadata entry
+	key  string
+}
+
+// distributedLockManager acquires and renews the leases of a provider process
+type distributedLockManager struct {
+	client       *govcd.Client
+	holderId     string
+	ttl          time.Duration
+	timeout      time.Duration
+	pollInterval time.Duration
+	settleDelay  time.Duration
+
+	mutex  sync.Mutex
+	leases map[string]*distributedLockHandle
+}
+
+// distributedLockHandle is a lease held (or being acquired) by the process
+type distributedLockHandle struct {
+	references int
+	acquired   chan struct{} // closed when the acquisition finishes, with err set in case of failure
+	err        error
+	token      string // identifies this acquisition of the lease
+	target     *distributedLockTarget
+	stop       chan struct{}
+	stopped    chan struct{}
+	lost       error         // set when the heartbeat loses the lease, guarded by the mutex of the manager
+	released   chan struct{} // created when the last reference is released, and closed once the lease is deleted
+}
+
+// newDistributedLockManager returns a manager for the given client. A ttl or timeout of 0 uses the defaults
+func newDistributedLockManager(client *govcd.Client, ttl, timeout time.Duration) *distributedLockManager {
+	if ttl <= 0 {
+		ttl = defaultDistributedLockTtl
+	}
+	if timeout <= 0 {
+		timeout = defaultDistributedLockTimeout
+	}
+	return &distributedLockManager{
+		client:       client,
+		holderId:     newDistributedLockHolderId(),
+		ttl:          ttl,
+		timeout:      timeout,
+		pollInterval: min(defaultDistributedLockPoll, ttl/4),
+		settleDelay:  min(defaultDistributedLockSettle, ttl/4),
+		leases:       make(map[string]*distributedLockHandle),
+	}
+}
+
+// newDistributedLockHolderId returns an ID that identifies the current process among the lock holders
+func newDistributedLockHolderId() string {
+	hostname, err := os.Hostname()
+	if err != nil {
+		hostname = "unknown"
+	}
+	return fmt.Sprintf("%s:%d:%s", hostname, os.Getpid(), newDistributedLockToken())
+}
+
+// newDistributedLockToken returns a random token, which identifies an acquisition of a lease
+func newDistributedLockToken() string {
+	token := make([]byte, 4)
+	_, _ = rand.Read(token)
+	return hex.EncodeToString(token)
+}
+
+// lock acquires the lease of the entity with the given URN, waiting while other processes hold it. It returns the
+// function that releases the lease, which fails if the lease was lost while the operation was running
+func (m *distributedLockManager) lock(urn string) (func() error, error) {
+	m.mutex.Lock()
+	handle, found := m.leases[urn]
+	for found && handle.released != nil {
+		// The last operation is deleting the lease: a new one is acquired once it is gone
+		released := handle.released
+		m.mutex.Unlock()
+		<-released
+		m.mutex.Lock()
+		handle, found = m.leases[urn]
+	}
+	if !found {
+		handle = &distributedLockHandle{acquired: make(chan struct{}), token: newDistributedLockToken()}
+		m.leases[urn] = handle
+	}
+	handle.references++
+	m.mutex.Unlock()
+
+	if !found {
+		handle.target, handle.err = m.getTarget(urn)
+		if handle.err == nil {
+			handle.err = m.acquire(urn, handle.target, handle.token)
+		}
+		if handle.err == nil {
+			handle.stop = make(chan struct{})
+			handle.stopped = make(chan struct{})
+			go m.heartbeat(urn, handle)
+		}
+		close(handle.acquired)
+	}
+	<-handle.acquired
+	if handle.err != nil {
+		_ = m.release(urn, handle)
+		return nil, handle.err
+	}
+
+	return func() error {
+		return m.release(urn, handle)
+	}, nil
+}
+
+// release drops a reference to the lease, and deletes the lease when no operation of the process needs it anymore.
+// It returns an error if the lease was lost while it was held
+func (m *distributedLockManager) release(urn string, handle *distributedLockHandle) error {
+	m.mutex.Lock()
+	handle.references--
+	last := handle.references == 0
+	if last {
+		// The handle stays in the map until the lease is deleted, so that new operations of this process wait
+		// for the deletion instead of sharing a lease that is going away
+		handle.released = make(chan struct{})
+	}
+	lost := handle.lost
+	m.mutex.Unlock()
+	if !last {
+		return distributedLockLostError(urn, lost)
+	}
+	defer func() {
+		m.mutex.Lock()
+		delete(m.leases, urn)
+		close(handle.released)
+		m.mutex.Unlock()
+	}()
+	if handle.err != nil {
+		return nil
+	}
+
+	close(handle.stop)
+	<-handle.stopped
+	m.mutex.Lock()
+	lost = handle.lost
+	m.mutex.Unlock()
+	if lost != nil {
+		return distributedLockLostError(urn, lost)
+	}
+	lease, err := m.getLease(handle.target)
+	if err == nil && lease != nil && lease.Token == handle.token {
+		err = m.deleteLease(handle.target)
+	}
+	if err != nil {
+		log.Printf("[WARN] could not release distributed lock of %s (it will expire in %s): %s", urn, m.ttl, err)
+	}
+	return nil
+}
+
+// distributedLockLostError returns the error reported to the operations that held a lease that was lost, or nil
+func distributedLockLostError(urn string, lost error) error {
+	if lost == nil {
+		return nil
+	}
+	return fmt.Errorf("the distributed lock of %s was lost while the operation was running, and other processes "+
+		"may have changed the entity in the meantime: %s", urn, lost)
+}
+
+// acquire creates or takes over the lease of the entity with the given token, retrying until the timeout
+func (m *distributedLockManager) acquire(urn string, target *distributedLockTarget, token string) error {
+	deadline := time.Now().Add(m.timeout)
+	var current *distributedLockLease
+	for {
+		acquired, holder, err := m.tryAcquire(target, token)
+		if err != nil {
+			return fmt.Errorf("error acquiring distributed lock of %s: %s", urn, err)
+		}
+		if acquired {
+			log.Printf("[DEBUG] acquired distributed lock of %s as %s", urn, m.holderId)
+			return nil
+		}
+		if holder == nil {
+			holder = &distributedLockLease{Holder: "unknown"}
+		}
+		if current == nil || current.Holder != holder.Holder {
+			log.Printf("[INFO] waiting for distributed lock of %s, held by %s until %s", urn, holder.Holder, holder.ExpiresAt.Format(time.RFC3339))
+		}
+		current = holder
+		if time.Now().Add(m.pollInterval).After(deadline) {
+			return fmt.Errorf("timeout after %s waiting for distributed lock of %s, held by %s until %s",
+				m.timeout, urn, holder.Holder, holder.ExpiresAt.Format(time.RFC3339))
+		}
+		time.Sleep(m.pollInterval)
+	}
+}
+
+// tryAcquire makes one attempt to get the lease with the given token. It returns whether the lease was acquired, or
+// the current lease when it is held by another process or by another acquisition of this one
+func (m *distributedLockManager) tryAcquire(target *distributedLockTarget, token string) (bool, *distributedLockLease, error) {
+	lease, err := m.getLease(target)
+	if err != nil {
+		return false, nil, err
+	}
+	if lease != nil && time.Now().Before(lease.ExpiresAt) {
+		return false, lease, nil
+	}
+
+	// The lease is missing or expired. Other processes may be writing it at the same time: the last write wins, and
+	// the lease is read again after the settle delay to find out which one it was
+	if err = m.putLease(target, token); err != nil {
+		return false, nil, err
+	}
+	time.Sleep(m.settleDelay)
+	lease, err = m.getLease(target)
+	if err != nil {
+		return false, nil, err
+	}
+	if lease == nil || lease.Token != token {
+		return false, lease, nil
+	}
+	return true, nil, nil
+}
+
+// heartbeat renews the lease until the handle is stopped. Renewal errors are retried while the lease is valid. The
+// lease is lost when it is taken over by another holder, or when it expires before it can be renewed
+func (m *distributedLockManager) heartbeat(urn string, handle *distributedLockHandle) {
+	defer close(handle.stopped)
+	ticker := time.NewTicker(m.ttl / 3)
+	defer ticker.Stop()
+	renewedAt := time.Now()
+	for {
+		select {
+		case <-handle.stop:
+			return
+		case <-ticker.C:
+			lease, err := m.getLease(handle.target)
+			if err == nil && (lease == nil || lease.Token != handle.token) {
+				m.setLost(urn, handle, fmt.Errorf("the lease was deleted or taken over by another holder"))
+				return
+			}
+			if err == nil {
+				err = m.putLease(handle.target, handle.token)
+			}
+			if err == nil {
+				renewedAt = time.Now()
+				continue
+			}
+			if time.Since(renewedAt) >= m.ttl {
+				m.setLost(urn, handle, fmt.Errorf("the lease expired, as it could not be renewed: %s", err))
+				return
+			}
+			log.Printf("[WARN] could not renew distributed lock of %s, retrying: %s", urn, err)
+		}
+	}
+}
+
+// setLost records that the lease of the handle was lost, which makes its operations fail when they release it
+func (m *distributedLockManager) setLost(urn string, handle *distributedLockHandle, err error) {
+	log.Printf("[ERROR] lost distributed lock of %s: %s", urn, err)
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	handle.lost = err
+}
+
+// getTarget returns the metadata entry that stores the lease of the entity with the given URN
+func (m *distributedLockManager) getTarget(urn string) (*distributedLockTarget, error) {
+	apiHref := m.client.VCDHREF.String()
+	uuid := extractUuid(urn)
+	switch {
+	case uuid == "":
+		return nil, fmt.Errorf("invalid ID '%s' for a distributed lock", urn)
+	case strings.HasPrefix(urn, "urn:vcloud:vapp:"):
+		return &distributedLockTarget{href: fmt.Sprintf("%s/vApp/vapp-%s", apiHref, uuid), key: distributedLockKey}, nil
+	case strings.HasPrefix(urn, "urn:vcloud:gateway:"):
+		return &distributedLockTarget{href: fmt.Sprintf("%s/admin/edgeGateway/%s", apiHref, uuid), key: distributedLockKey}, nil
+	case govcd.OwnerIsVdcGroup(urn):
+		endpoint, err := m.client.OpenApiBuildEndpoint(types.OpenApiPathVersion1_0_0, types.OpenApiEndpointVdcGroups, urn)
+		if err != nil {
+			return nil, err
+		}
+		vdcGroup := &types.VdcGroup{}
+		err = m.client.OpenApiGetItem(m.client.APIVersion, endpoint, nil, vdcGroup, nil)
+		if err != nil {
+			return nil, fmt.Errorf("error retrieving VDC Group %s: %s", urn, err)
+		}
+		return &distributedLockTarget{
+			href: fmt.Sprintf("%s/admin/org/%s", apiHref, extractUuid(vdcGroup.OrgId)),
+			key:  fmt.Sprintf("%s-%s", distributedLockKey, uuid),
+		}, nil
+	}
+	return nil, fmt.Errorf("distributed locks are not supported for %s", urn)
+}
+
+// getLease returns the lease stored in the metadata entry of the target, or nil if the entry does not exist
+func (m *distributedLockManager) getLease(target *distributedLockTarget) (*distributedLockLease, error) {
+	metadata := &types.Metadata{}
+	_, err := m.client.ExecuteRequest(target.href+"/metadata", http.MethodGet, types.MimeMetaData,
+		"error retrieving metadata: %s", nil, metadata)
+	if err != nil {
+		return nil, err
+	}
+	for _, entry := range metadata.MetadataEntry {
+		if entry.Key != target.key || entry.TypedValue == nil {
+			continue
+		}
+		lease := &distributedLockLease{}
+		if err = json.Unmarshal([]byte(entry.TypedValue.Value), lease); err != nil {
+			// A corrupted lease is considered expired
+			return &distributedLockLease{Holder: "unknown"}, nil
+		}
+		return lease, nil
+	}
+	return nil, nil
+}
+
+// putLease writes a new lease held by this process, with the given acquisition token, in the metadata entry of the
+// target
+func (m *distributedLockManager) putLease(target *distributedLockTarget, token string) error {
+	value, err := json.Marshal(distributedLockLease{Holder: m.holderId, Token: token, ExpiresAt: time.Now().Add(m.ttl).UTC()})
+	if err != nil {
+		return err
+	}
+	metadataValue := &types.MetadataValue{
+		Xmlns: types.XMLNamespaceVCloud,
+		Xsi:   types.XMLNamespaceXSI,
+		TypedValue: &types.MetadataTypedValue{
+			XsiType: types.MetadataStringValue,
+			Value:   string(value),
+		},
+		Domain: &types.MetadataDomainTag{
+			Visibility: types.MetadataReadWriteVisibility,
+			Domain:     "GENERAL",
+		},
+	}
+	task, err := m.client.ExecuteTaskRequest(target.href+"/metadata/"+target.key, http.MethodPut, types.MimeMetaDataValue,
+		"error writing lease: %s", metadataValue)
+	if err != nil {
+		return err
+	}
+	return task.WaitTaskCompletion()
+}
+
+// deleteLease removes the metadata entry of the target
+func (m *distributedLockManager) deleteLease(target *distributedLockTarget) error {
+	task, err := m.client.ExecuteTaskRequest(target.href+"/metadata/"+target.key, http.MethodDelete, "",
+		"error deleting lease: %s", nil)
+	if err != nil {
+		return err
+	}
+	return task.WaitTaskCompletion()
+}
+
+// isDistributedLockKey checks whether a legacy metadata key is the one of a distributed lock lease, which must not be
+// managed as regular metadata
+func isDistributedLockKey(key string) bool {
+	return key == distributedLockKey || strings.HasPrefix(key, distributedLockKey+"-")
+}
+
+// filterDistributedLockMetadata removes the distributed lock leases from the given legacy metadata, so that they are
+// neither set in the state nor deleted as regular entries
+func filterDistributedLockMetadata(metadata *types.Metadata) {
+	if metadata == nil {
+		return
+	}
+	var entries []*types.MetadataEntry
+	for _, entry := range metadata.MetadataEntry {
+		if !isDistributedLockKey(entry.Key) {
+			entries = append(entries, entry)
+		}
+	}
+	metadata.MetadataEntry = entries
+}
+
+// releaseDistributedLock calls the given release function, adding its error to the diagnostics of the operation. It
+// is meant to be deferred, so that the operation fails when its distributed lock was lost while it was running
+func releaseDistributedLock(release func() error, diags *diag.Diagnostics) {
+	if err := release(); err != nil {
+		*diags = append(*diags, diag.FromErr(err)...)
+	}
+}
+
+// noDistributedLock is the release function used when nothing was locked
+func noDistributedLock() error {
+	return nil
+}
+
+// lockDistributed acquires the distributed lock of the entity with the given URN, when distributed locks are enabled
+// in the provider. It returns the function that releases the lock, which fails if the lock was lost meanwhile
+func (cli *VCDClient) lockDistributed(urn string) (func() error, error) {
+	if cli.distributedLocks == nil || urn == "" {
+		return noDistributedLock, nil
+	}
+	return cli.distributedLocks.lock(urn)
+}
+
+// lockDistributedVapp acquires the distributed lock of the vApp with the given name, when distributed locks are enabled
+// in the provider. Nothing is locked if the vApp does not exist yet
+func (cli *VCDClient) lockDistributedVapp(d *schema.ResourceData, vappName string) (func() error, error) {
+	if cli.distributedLocks == nil {
+		return noDistributedLock, nil
+	}
+	_, vdc, err := cli.GetOrgAndVdcFromResource(d)
+	if err != nil {
+		return nil, fmt.Errorf(errorRetrievingOrgAndVdc, err)
+	}
+	vapp, err := vdc.GetVAppByName(vappName, false)
+	if govcd.ContainsNotFound(err) {
+		return noDistributedLock, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving vApp '%s' for the distributed lock: %s", vappName, err)
+	}
+	return cli.lockDistributed(vapp.VApp.ID)
+}
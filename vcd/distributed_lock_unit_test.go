@@ -0,0 +1,223 @@
+//go:build unit || ALL
+
+package vcd
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/vmware/go-vcloud-director/v3/types/v56"
+	"github.com/vmware/terraform-provider-vcd/v4/vcd/mockvcd"
+)
+
+// Test_distributedLockManager checks the leases of a vApp and an Edge Gateway, which are stored in their own metadata
+func Test_distributedLockManager(t *testing.T) {
+	server := mockvcd.NewServer()
+	defer server.Close()
+	vcdClient := newMockVcdClient(t, server)
+
+	for urn, entityPath := range map[string]string{
+		"urn:vcloud:vapp:11111111-2222-3333-4444-555555555555":    "/api/vApp/vapp-11111111-2222-3333-4444-555555555555",
+		"urn:vcloud:gateway:11111111-2222-3333-4444-666666666666": "/api/admin/edgeGateway/11111111-2222-3333-4444-666666666666",
+	} {
+		t.Run(urn, func(t *testing.T) {
+			testDistributedLockManager(t, vcdClient, server, urn, entityPath, distributedLockKey)
+		})
+	}
+}
+
+// Test_distributedLockManagerVdcGroup checks that the lease of a VDC Group is stored in the metadata of its
+// Organization
+func Test_distributedLockManagerVdcGroup(t *testing.T) {
+	server := mockvcd.NewServer()
+	defer server.Close()
+	vcdClient := newMockVcdClient(t, server)
+
+	urn := server.AddEntity("1.0.0/vdcGroups", map[string]interface{}{
+		"name":  "group1",
+		"orgId": "urn:vcloud:org:11111111-2222-3333-4444-777777777777",
+	})
+	testDistributedLockManager(t, vcdClient, server, urn, "/api/admin/org/11111111-2222-3333-4444-777777777777",
+		distributedLockKey+"-"+extractUuid(urn))
+}
+
+// Test_distributedLockManagerUnsupported checks that entities without a lease storage are rejected
+func Test_distributedLockManagerUnsupported(t *testing.T) {
+	server := mockvcd.NewServer()
+	defer server.Close()
+	vcdClient := newMockVcdClient(t, server)
+
+	manager := newDistributedLockManager(&vcdClient.Client, time.Minute, time.Minute)
+	_, err := manager.lock("urn:vcloud:vm:11111111-2222-3333-4444-555555555555")
+	if err == nil || !strings.Contains(err.Error(), "not supported") {
+		t.Fatalf("expected an error about unsupported entity, got: %v", err)
+	}
+	if len(manager.leases) != 0 {
+		t.Errorf("expected no lease after a failed lock, got %v", manager.leases)
+	}
+}
+
+// testDistributedLockManager checks the acquisition, sharing, waiting, take over and renewal of the lease of the
+// entity with the given URN, which is stored with the given key in the metadata of the given entity path
+func testDistributedLockManager(t *testing.T, vcdClient *VCDClient, server *mockvcd.Server, urn, entityPath, key string) {
+	first := newDistributedLockManager(&vcdClient.Client, time.Second, 5*time.Second)
+	first.pollInterval = 50 * time.Millisecond
+	second := newDistributedLockManager(&vcdClient.Client, time.Second, 300*time.Millisecond)
+	second.pollInterval = 50 * time.Millisecond
+
+	unlockFirst, err := first.lock(urn)
+	if err != nil {
+		t.Fatalf("error acquiring the lock: %s", err)
+	}
+	if holder := getTestLease(t, server, entityPath, key).Holder; holder != first.holderId {
+		t.Fatalf("expected lease held by %s, got %s", first.holderId, holder)
+	}
+
+	// Operations of the same process share the lease
+	unlockFirstAgain, err := first.lock(urn)
+	if err != nil {
+		t.Fatalf("error acquiring the lock a second time in the same process: %s", err)
+	}
+
+	// Other processes wait until the timeout
+	_, err = second.lock(urn)
+	if err == nil || !strings.Contains(err.Error(), "timeout") || !strings.Contains(err.Error(), first.holderId) {
+		t.Fatalf("expected timeout error mentioning %s, got: %v", first.holderId, err)
+	}
+
+	if err = unlockFirstAgain(); err != nil {
+		t.Fatalf("error releasing the lock: %s", err)
+	}
+	if _, found := server.Metadata(entityPath)[key]; !found {
+		t.Fatalf("the lease was released while still in use")
+	}
+	if err = unlockFirst(); err != nil {
+		t.Fatalf("error releasing the lock: %s", err)
+	}
+	if entries := server.Metadata(entityPath); len(entries) != 0 {
+		t.Fatalf("expected the lease to be deleted, got %v", entries)
+	}
+
+	// An expired lease of another process is taken over
+	setTestLease(server, entityPath, key, distributedLockLease{Holder: "crashed-process", ExpiresAt: time.Now().Add(-time.Minute)})
+	unlockSecond, err := second.lock(urn)
+	if err != nil {
+		t.Fatalf("error taking over an expired lease: %s", err)
+	}
+	if holder := getTestLease(t, server, entityPath, key).Holder; holder != second.holderId {
+		t.Fatalf("expected lease held by %s, got %s", second.holderId, holder)
+	}
+
+	// The heartbeat keeps the lease alive beyond its duration
+	time.Sleep(1500 * time.Millisecond)
+	lease := getTestLease(t, server, entityPath, key)
+	if !lease.ExpiresAt.After(time.Now()) {
+		t.Errorf("expected the lease to be renewed, but it expired at %s", lease.ExpiresAt)
+	}
+	if err = unlockSecond(); err != nil {
+		t.Fatalf("error releasing the lock: %s", err)
+	}
+	if entries := server.Metadata(entityPath); len(entries) != 0 {
+		t.Fatalf("expected the lease to be deleted, got %v", entries)
+	}
+}
+
+// Test_distributedLockManagerToken checks that a valid lease is identified by its token, so that a new acquisition of
+// the same process doesn't take over nor delete the lease of a previous one
+func Test_distributedLockManagerToken(t *testing.T) {
+	server := mockvcd.NewServer()
+	defer server.Close()
+	vcdClient := newMockVcdClient(t, server)
+
+	urn := "urn:vcloud:gateway:11111111-2222-3333-4444-555555555555"
+	entityPath := "/api/admin/edgeGateway/11111111-2222-3333-4444-555555555555"
+	manager := newDistributedLockManager(&vcdClient.Client, time.Minute, time.Minute)
+	target, err := manager.getTarget(urn)
+	if err != nil {
+		t.Fatalf("error getting the lease target: %s", err)
+	}
+
+	setTestLease(server, entityPath, distributedLockKey, distributedLockLease{Holder: manager.holderId, Token: "previous", ExpiresAt: time.Now().Add(time.Minute)})
+	acquired, current, err := manager.tryAcquire(target, "new")
+	if err != nil {
+		t.Fatalf("error trying to acquire the lock: %s", err)
+	}
+	if acquired || current == nil || current.Token != "previous" {
+		t.Fatalf("expected the lease of the previous acquisition to be kept, got acquired %t and lease %v", acquired, current)
+	}
+
+	// A release of another acquisition doesn't delete the lease
+	handle := &distributedLockHandle{references: 1, token: "new", target: target, stop: make(chan struct{}), stopped: make(chan struct{})}
+	close(handle.stopped)
+	manager.leases[urn] = handle
+	if err = manager.release(urn, handle); err != nil {
+		t.Fatalf("error releasing the lock: %s", err)
+	}
+	if lease := getTestLease(t, server, entityPath, distributedLockKey); lease.Token != "previous" {
+		t.Errorf("expected the lease of the previous acquisition to remain, got %v", lease)
+	}
+	if _, found := manager.leases[urn]; found {
+		t.Errorf("expected the handle to be removed after the release")
+	}
+}
+
+// Test_distributedLockManagerLost checks that the operations fail when their lease is taken by another holder
+func Test_distributedLockManagerLost(t *testing.T) {
+	server := mockvcd.NewServer()
+	defer server.Close()
+	vcdClient := newMockVcdClient(t, server)
+
+	urn := "urn:vcloud:vapp:11111111-2222-3333-4444-555555555555"
+	entityPath := "/api/vApp/vapp-11111111-2222-3333-4444-555555555555"
+	manager := newDistributedLockManager(&vcdClient.Client, 300*time.Millisecond, time.Second)
+
+	unlock, err := manager.lock(urn)
+	if err != nil {
+		t.Fatalf("error acquiring the lock: %s", err)
+	}
+	setTestLease(server, entityPath, distributedLockKey, distributedLockLease{Holder: "other-process", Token: "other", ExpiresAt: time.Now().Add(time.Minute)})
+
+	time.Sleep(500 * time.Millisecond)
+	err = unlock()
+	if err == nil || !strings.Contains(err.Error(), "lost") {
+		t.Fatalf("expected an error about the lost lock, got: %v", err)
+	}
+	if lease := getTestLease(t, server, entityPath, distributedLockKey); lease.Holder != "other-process" {
+		t.Errorf("expected the lease of the other process to remain, got %v", lease)
+	}
+}
+
+// Test_filterDistributedLockMetadata checks that the leases are removed from the legacy metadata
+func Test_filterDistributedLockMetadata(t *testing.T) {
+	metadata := &types.Metadata{MetadataEntry: []*types.MetadataEntry{
+		{Key: "env"},
+		{Key: distributedLockKey},
+		{Key: "lock"},
+		{Key: distributedLockKey + "-11111111-2222-3333-4444-555555555555"},
+	}}
+	filterDistributedLockMetadata(metadata)
+	if len(metadata.MetadataEntry) != 2 || metadata.MetadataEntry[0].Key != "env" || metadata.MetadataEntry[1].Key != "lock" {
+		t.Errorf("unexpected entries after filtering: %v", metadata.MetadataEntry)
+	}
+	filterDistributedLockMetadata(nil)
+}
+
+// setTestLease stores the given lease in the metadata of the given entity of the mock server
+func setTestLease(server *mockvcd.Server, entityPath, key string, lease distributedLockLease) {
+	value, _ := json.Marshal(lease)
+	server.SetMetadata(entityPath, key, string(value))
+}
+
+func getTestLease(t *testing.T, server *mockvcd.Server, entityPath, key string) distributedLockLease {
+	value, found := server.Metadata(entityPath)[key]
+	if !found {
+		t.Fatalf("expected a lease with key %s in %s", key, entityPath)
+	}
+	var lease distributedLockLease
+	if err := json.Unmarshal([]byte(value), &lease); err != nil {
+		t.Fatalf("invalid lease '%s': %s", value, err)
+	}
+	return lease
+}
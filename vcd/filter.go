@@ -2,6 +2,11 @@ package vcd
 
 import (
 	"fmt"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
@@ -183,3 +188,238 @@ func nameOrFilterIsSet(d *schema.ResourceData) bool {
 	_, filterOk := d.GetOk("filter")
 	return nameOk || filterOk
 }
+
+// These elements are used to compose a filter block for data sources backed by OpenAPI entities.
+// Their conditions are translated to FIQL, so that the search is performed by VCD.
+var (
+	// elementFiqlName is the OpenAPI counterpart of elementNameRegex
+	elementFiqlName = &schema.Schema{
+		Type:        schema.TypeString,
+		Optional:    true,
+		Description: "Search by name. The value can contain '*' wildcards",
+	}
+
+	// elementFiql allows any condition supported by the OpenAPI endpoint of the entity
+	elementFiql = &schema.Schema{
+		Type:        schema.TypeString,
+		Optional:    true,
+		Description: "FIQL expression added to the search (e.g. 'description==*test*;isEnabled==true')",
+	}
+
+	// elementOpenApiMetadata searches by OpenAPI metadata entries
+	elementOpenApiMetadata = &schema.Schema{
+		Type:        schema.TypeList,
+		Optional:    true,
+		Description: "OpenAPI metadata filter",
+		Elem: &schema.Resource{
+			Schema: map[string]*schema.Schema{
+				"key": {
+					Type:        schema.TypeString,
+					Required:    true,
+					Description: "Metadata key",
+				},
+				"value": {
+					Type:        schema.TypeString,
+					Required:    true,
+					Description: "Metadata value. Numbers and booleans are compared using their text representation",
+				},
+				"namespace": {
+					Type:        schema.TypeString,
+					Optional:    true,
+					Description: "Namespace of the metadata entry",
+				},
+				"domain": {
+					Type:         schema.TypeString,
+					Optional:     true,
+					ValidateFunc: validation.StringInSlice([]string{"TENANT", "PROVIDER"}, false),
+					Description:  "Domain of the metadata entry. One of 'TENANT', 'PROVIDER'",
+				},
+			},
+		},
+	}
+)
+
+// openApiFilterSchema returns a filter block for data sources backed by OpenAPI entities.
+// When withDate is true, the block also contains 'date', 'latest' and 'earliest', which need
+// a date field in the entity (see openApiFilterConfig).
+// When withMetadata is true, the block also contains 'metadata', which is only supported by entities with
+// OpenAPI metadata (see openApiMetadataMatches)
+func openApiFilterSchema(entityLabel string, withDate, withMetadata bool) *schema.Schema {
+	elements := map[string]*schema.Schema{
+		"name": elementFiqlName,
+		"fiql": elementFiql,
+	}
+	if withMetadata {
+		elements["metadata"] = elementOpenApiMetadata
+	}
+	if withDate {
+		elements["date"] = elementDate
+		elements["latest"] = elementLatest
+		elements["earliest"] = elementEarliest
+	}
+	return &schema.Schema{
+		Type:         schema.TypeList,
+		MaxItems:     1,
+		MinItems:     1,
+		Optional:     true,
+		ExactlyOneOf: []string{"name", "filter"},
+		Description:  fmt.Sprintf("Criteria for retrieving a %s by various attributes", entityLabel),
+		Elem: &schema.Resource{
+			Schema: elements,
+		},
+	}
+}
+
+// openApiMetadataCriterion is a condition on the OpenAPI metadata of an entity
+type openApiMetadataCriterion struct {
+	key       string
+	value     string
+	namespace string
+	domain    string
+}
+
+// openApiCriteria is the expansion of an OpenAPI filter block
+type openApiCriteria struct {
+	// queryParameters contain the FIQL filter and the sorting for the endpoint of the entity
+	queryParameters url.Values
+	// metadata contains the conditions that are checked on the metadata of each retrieved entity
+	metadata []openApiMetadataCriterion
+	// pickFirst is set when 'latest' or 'earliest' are used: the first of the sorted entities is returned
+	pickFirst bool
+	// explanation describes the criteria in error messages
+	explanation string
+}
+
+// fiqlDateOperators maps the operators of elementDate to FIQL operators
+var fiqlDateOperators = map[string]string{
+	"==": "==",
+	">":  "=gt=",
+	">=": "=ge=",
+	"<":  "=lt=",
+	"<=": "=le=",
+}
+
+var dateFilterRegexp = regexp.MustCompile(`^\s*(==|>=|<=|>|<)\s*(\S.*?)\s*$`)
+
+// buildFiqlDateCondition translates a date condition such as '>= 2024-01-31 10:30' into FIQL
+func buildFiqlDateCondition(dateField, condition string) (string, error) {
+	matches := dateFilterRegexp.FindStringSubmatch(condition)
+	if len(matches) == 0 {
+		return "", fmt.Errorf("date condition '%s' does not match '{>|>=|<|<=|==} yyyy-mm-dd[ hh[:mm[:ss]]]'", condition)
+	}
+	for _, layout := range []string{"2006-01-02 15:04:05", "2006-01-02 15:04", "2006-01-02 15", "2006-01-02"} {
+		date, err := time.Parse(layout, matches[2])
+		if err == nil {
+			return dateField + fiqlDateOperators[matches[1]] + date.UTC().Format("2006-01-02T15:04:05.000Z"), nil
+		}
+	}
+	return "", fmt.Errorf("invalid date '%s' in condition '%s'", matches[2], condition)
+}
+
+// buildOpenApiCriteria expands an OpenAPI filter block (see openApiFilterSchema) into FIQL query parameters
+// and metadata conditions. dateField is the entity field used by 'date', 'latest' and 'earliest'
+func buildOpenApiCriteria(filterBlock interface{}, dateField string) (*openApiCriteria, error) {
+	filterList, ok := filterBlock.([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("[buildOpenApiCriteria] filter is not a list")
+	}
+	if len(filterList) == 0 || filterList[0] == nil {
+		return nil, fmt.Errorf("[buildOpenApiCriteria] filter block is empty")
+	}
+	filterMap, ok := filterList[0].(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("[buildOpenApiCriteria] filter is not a map: %#v", filterList[0])
+	}
+
+	criteria := &openApiCriteria{queryParameters: url.Values{}}
+	var conditions []string
+	var latest, earliest bool
+	for key, value := range filterMap {
+		switch key {
+		case "name":
+			if value.(string) != "" {
+				conditions = append(conditions, "name=="+value.(string))
+			}
+		case "fiql":
+			if value.(string) != "" {
+				conditions = append(conditions, value.(string))
+			}
+		case types.FilterDate:
+			if value.(string) == "" {
+				continue
+			}
+			if dateField == "" {
+				return nil, fmt.Errorf("[buildOpenApiCriteria] filter '%s' is not supported by this entity", key)
+			}
+			condition, err := buildFiqlDateCondition(dateField, value.(string))
+			if err != nil {
+				return nil, fmt.Errorf("[buildOpenApiCriteria] error adding filter '%s': %s", key, err)
+			}
+			conditions = append(conditions, condition)
+		case types.FilterLatest:
+			latest = value.(bool)
+		case types.FilterEarliest:
+			earliest = value.(bool)
+		case "metadata":
+			metadataList, ok := value.([]interface{})
+			if !ok {
+				return nil, fmt.Errorf("[buildOpenApiCriteria] metadata block is not a list")
+			}
+			for _, raw := range metadataList {
+				metadataMap, ok := raw.(map[string]interface{})
+				if !ok {
+					return nil, fmt.Errorf("[buildOpenApiCriteria] metadata internal block is not a map")
+				}
+				criterion := openApiMetadataCriterion{
+					key:   metadataMap["key"].(string),
+					value: metadataMap["value"].(string),
+				}
+				if namespace, ok := metadataMap["namespace"].(string); ok {
+					criterion.namespace = namespace
+				}
+				if domain, ok := metadataMap["domain"].(string); ok {
+					criterion.domain = domain
+				}
+				criteria.metadata = append(criteria.metadata, criterion)
+			}
+		default:
+			return nil, fmt.Errorf("unsupported filter key '%s'", key)
+		}
+	}
+
+	if latest && earliest {
+		return nil, fmt.Errorf("[buildOpenApiCriteria] only one of '%s' and '%s' can be set", types.FilterLatest, types.FilterEarliest)
+	}
+	if (latest || earliest) && dateField == "" {
+		return nil, fmt.Errorf("[buildOpenApiCriteria] '%s' and '%s' are not supported by this entity", types.FilterLatest, types.FilterEarliest)
+	}
+	if len(conditions) == 0 && len(criteria.metadata) == 0 && !latest && !earliest {
+		return nil, fmt.Errorf("[buildOpenApiCriteria] filter block has no conditions")
+	}
+
+	// Conditions are sorted to send the same request regardless of the map order
+	sort.Strings(conditions)
+	filter := joinFiqlFilters(conditions...)
+	if filter != "" {
+		criteria.queryParameters.Set("filter", filter)
+	}
+	explanation := []string{}
+	if filter != "" {
+		explanation = append(explanation, "filter: "+filter)
+	}
+	switch {
+	case latest:
+		criteria.queryParameters.Set("sortDesc", dateField)
+		criteria.pickFirst = true
+		explanation = append(explanation, types.FilterLatest)
+	case earliest:
+		criteria.queryParameters.Set("sortAsc", dateField)
+		criteria.pickFirst = true
+		explanation = append(explanation, types.FilterEarliest)
+	}
+	for _, criterion := range criteria.metadata {
+		explanation = append(explanation, fmt.Sprintf("metadata: %s == %s", criterion.key, criterion.value))
+	}
+	criteria.explanation = strings.Join(explanation, ", ")
+	return criteria, nil
+}
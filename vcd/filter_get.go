@@ -2,6 +2,9 @@ package vcd
 
 import (
 	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
 
 	"github.com/vmware/go-vcloud-director/v3/govcd"
 	"github.com/vmware/go-vcloud-director/v3/types/v56"
@@ -227,3 +230,159 @@ func getEdgeGatewayByFilter(vdc *govcd.Vdc, filter interface{}) (*govcd.EdgeGate
 	}
 	return egw, nil
 }
+
+// queryAllRecords runs a query of the given type with a FIQL filter, retrieving all the pages of the result.
+// The records of each page are extracted with getRecords, as every query type stores them in a different field
+func queryAllRecords[R any](client *govcd.Client, queryType, filter string, getRecords func(*types.QueryResultRecordsType) []R) ([]R, error) {
+	var records []R
+	for page := 1; ; page++ {
+		params := map[string]string{
+			"type":     queryType,
+			"pageSize": "128",
+			"page":     strconv.Itoa(page),
+		}
+		if filter != "" {
+			params["filter"] = filter
+		}
+		results, err := client.QueryWithNotEncodedParams(params, nil)
+		if err != nil {
+			return nil, fmt.Errorf("error running query of type %s with filter '%s': %s", queryType, filter, err)
+		}
+		pageRecords := getRecords(results.Results)
+		records = append(records, pageRecords...)
+		if len(pageRecords) == 0 || float64(len(records)) >= results.Results.Total {
+			return records, nil
+		}
+	}
+}
+
+// joinFiqlFilters joins the non-empty FIQL filters with a logical AND. Filters with a logical OR are enclosed in
+// parentheses, as the AND operator has higher precedence
+func joinFiqlFilters(filters ...string) string {
+	var nonEmpty []string
+	for _, filter := range filters {
+		if filter == "" {
+			continue
+		}
+		if strings.Contains(filter, ",") {
+			filter = "(" + filter + ")"
+		}
+		nonEmpty = append(nonEmpty, filter)
+	}
+	return strings.Join(nonEmpty, ";")
+}
+
+// openApiFilterConfig defines how a data source backed by OpenAPI entities is searched with a filter block
+// built by openApiFilterSchema
+type openApiFilterConfig[O any] struct {
+	// getAllEntitiesFunc retrieves all the entities matching the given query parameters (FIQL filter and sorting)
+	getAllEntitiesFunc func(queryParameters url.Values) ([]O, error)
+
+	// getIdAndNameFunc returns the ID and the name of an entity
+	getIdAndNameFunc func(O) (string, string)
+
+	// dateField is the entity field used by 'date', 'latest' and 'earliest'.
+	// It must be set if the filter block was built with date support
+	dateField string
+}
+
+// getOpenApiEntityByFilter builds FIQL criteria from an OpenAPI filter block and retrieves the entities
+// that match them. Metadata conditions are checked on the OpenAPI metadata of each retrieved entity.
+// Returns a single entity, and fails for more than one, unless 'latest' or 'earliest' were requested
+func getOpenApiEntityByFilter[O any](client *govcd.Client, filter interface{}, label string, c openApiFilterConfig[O]) (O, error) {
+	var found []O
+	var empty O
+	criteria, err := buildOpenApiCriteria(filter, c.dateField)
+	if err != nil {
+		return empty, err
+	}
+	entities, err := c.getAllEntitiesFunc(criteria.queryParameters)
+	if err != nil {
+		return empty, fmt.Errorf("error retrieving %s with criteria (%s): %s", label, criteria.explanation, err)
+	}
+	for _, entity := range entities {
+		id, _ := c.getIdAndNameFunc(entity)
+		matches, err := openApiMetadataMatches(client, id, criteria.metadata)
+		if err != nil {
+			return empty, fmt.Errorf("error retrieving metadata of %s '%s': %s", label, id, err)
+		}
+		if !matches {
+			continue
+		}
+		found = append(found, entity)
+		// Entities are sorted by date, so the first match is the wanted one
+		if criteria.pickFirst {
+			break
+		}
+	}
+	if len(found) == 0 {
+		return empty, fmt.Errorf("no %s found with given criteria (%s)", label, criteria.explanation)
+	}
+	if len(found) > 1 {
+		var names = make([]string, len(found))
+		for i, entity := range found {
+			_, names[i] = c.getIdAndNameFunc(entity)
+		}
+		return empty, fmt.Errorf("more than one %s found by given criteria: %v", label, names)
+	}
+	return found[0], nil
+}
+
+// openApiMetadataMatches checks whether the Runtime Defined Entity with the given ID has OpenAPI metadata entries that
+// satisfy all the criteria. Every criterion is a FIQL search by key on the metadata of the entity.
+// The metadata endpoint is the one used by go-vcloud-director for Runtime Defined Entities, which are the only
+// entities whose filter block offers 'metadata' (see openApiFilterSchema)
+func openApiMetadataMatches(client *govcd.Client, entityId string, criteria []openApiMetadataCriterion) (bool, error) {
+	if len(criteria) == 0 {
+		return true, nil
+	}
+	urlRef, err := client.OpenApiBuildEndpoint(types.OpenApiPathVersion1_0_0, types.OpenApiEndpointRdeEntities, entityId, "/metadata")
+	if err != nil {
+		return false, err
+	}
+	for _, criterion := range criteria {
+		queryParameters := url.Values{}
+		queryParameters.Set("filter", "keyValue.key=="+criterion.key)
+		var entries []*types.OpenApiMetadataEntry
+		err = client.OpenApiGetAllItems(client.APIVersion, urlRef, queryParameters, &entries, nil)
+		if err != nil {
+			return false, err
+		}
+		if !openApiMetadataCriterionMatches(entries, criterion) {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// openApiMetadataCriterionMatches checks whether any of the given metadata entries satisfies the criterion
+func openApiMetadataCriterionMatches(entries []*types.OpenApiMetadataEntry, criterion openApiMetadataCriterion) bool {
+	for _, entry := range entries {
+		if entry == nil || entry.KeyValue.Key != criterion.key {
+			continue
+		}
+		if criterion.namespace != "" && entry.KeyValue.Namespace != criterion.namespace {
+			continue
+		}
+		if criterion.domain != "" && entry.KeyValue.Domain != criterion.domain {
+			continue
+		}
+		if fmt.Sprintf("%v", entry.KeyValue.Value.Value) == criterion.value {
+			return true
+		}
+	}
+	return false
+}
+
+// fiqlFilterAnd returns a copy of the query parameters where the 'filter' is restricted with the given FIQL filters
+func fiqlFilterAnd(queryParameters url.Values, filters ...string) url.Values {
+	result := url.Values{}
+	for key, values := range queryParameters {
+		result[key] = append([]string{}, values...)
+	}
+	filter := joinFiqlFilters(append(filters, queryParameters.Get("filter"))...)
+	if filter != "" {
+		result.Set("filter", filter)
+	}
+	return result
+}
@@ -0,0 +1,207 @@
+//go:build unit || ALL
+
+package vcd
+
+import (
+	"net/url"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/vmware/go-vcloud-director/v3/govcd"
+	"github.com/vmware/go-vcloud-director/v3/types/v56"
+)
+
+func Test_buildOpenApiCriteria(t *testing.T) {
+	tests := []struct {
+		name           string
+		filter         map[string]interface{}
+		dateField      string
+		wantParameters url.Values
+		wantMetadata   []openApiMetadataCriterion
+		wantPickFirst  bool
+		wantErr        bool
+	}{
+		{
+			name:           "name",
+			filter:         map[string]interface{}{"name": "web*", "fiql": "", "metadata": []interface{}{}},
+			wantParameters: url.Values{"filter": {"name==web*"}},
+		},
+		{
+			name:           "name and fiql",
+			filter:         map[string]interface{}{"name": "web*", "fiql": "description==a,description==b"},
+			wantParameters: url.Values{"filter": {"(description==a,description==b);name==web*"}},
+		},
+		{
+			name:           "date and latest",
+			filter:         map[string]interface{}{"date": ">= 2024-01-31 10:30", "latest": true, "earliest": false},
+			dateField:      "creationDate",
+			wantParameters: url.Values{"filter": {"creationDate=ge=2024-01-31T10:30:00.000Z"}, "sortDesc": {"creationDate"}},
+			wantPickFirst:  true,
+		},
+		{
+			name:           "earliest",
+			filter:         map[string]interface{}{"earliest": true},
+			dateField:      "creationDate",
+			wantParameters: url.Values{"sortAsc": {"creationDate"}},
+			wantPickFirst:  true,
+		},
+		{
+			name: "metadata",
+			filter: map[string]interface{}{"metadata": []interface{}{
+				map[string]interface{}{"key": "env", "value": "prod", "namespace": "", "domain": "TENANT"},
+			}},
+			wantParameters: url.Values{},
+			wantMetadata:   []openApiMetadataCriterion{{key: "env", value: "prod", domain: "TENANT"}},
+		},
+		{
+			name:    "latest and earliest",
+			filter:  map[string]interface{}{"latest": true, "earliest": true},
+			wantErr: true,
+		},
+		{
+			name:    "latest without date field",
+			filter:  map[string]interface{}{"latest": true},
+			wantErr: true,
+		},
+		{
+			name:      "invalid date",
+			filter:    map[string]interface{}{"date": "> yesterday"},
+			dateField: "creationDate",
+			wantErr:   true,
+		},
+		{
+			name:    "no conditions",
+			filter:  map[string]interface{}{"name": "", "fiql": ""},
+			wantErr: true,
+		},
+		{
+			name:    "unsupported key",
+			filter:  map[string]interface{}{"name_regex": "web.*"},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			criteria, err := buildOpenApiCriteria([]interface{}{tt.filter}, tt.dateField)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("buildOpenApiCriteria() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if !reflect.DeepEqual(criteria.queryParameters, tt.wantParameters) {
+				t.Errorf("query parameters = %v, want %v", criteria.queryParameters, tt.wantParameters)
+			}
+			if !reflect.DeepEqual(criteria.metadata, tt.wantMetadata) {
+				t.Errorf("metadata = %v, want %v", criteria.metadata, tt.wantMetadata)
+			}
+			if criteria.pickFirst != tt.wantPickFirst {
+				t.Errorf("pickFirst = %v, want %v", criteria.pickFirst, tt.wantPickFirst)
+			}
+		})
+	}
+}
+
+func Test_getOpenApiEntityByFilter(t *testing.T) {
+	type entity struct{ id, name string }
+	entities := []*entity{
+		{id: "urn:vcloud:test:1", name: "newest"},
+		{id: "urn:vcloud:test:2", name: "oldest"},
+	}
+	var receivedParameters url.Values
+	c := openApiFilterConfig[*entity]{
+		getAllEntitiesFunc: func(queryParameters url.Values) ([]*entity, error) {
+			receivedParameters = queryParameters
+			return entities, nil
+		},
+		getIdAndNameFunc: func(e *entity) (string, string) { return e.id, e.name },
+		dateField:        "creationDate",
+	}
+
+	// Without metadata criteria the client is not used
+	found, err := getOpenApiEntityByFilter(&govcd.Client{}, []interface{}{map[string]interface{}{"latest": true}}, "test entity", c)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if found.name != "newest" {
+		t.Errorf("expected the first sorted entity, got '%s'", found.name)
+	}
+	if receivedParameters.Get("sortDesc") != "creationDate" {
+		t.Errorf("expected sorting by 'creationDate', got %v", receivedParameters)
+	}
+
+	_, err = getOpenApiEntityByFilter(&govcd.Client{}, []interface{}{map[string]interface{}{"name": "*est"}}, "test entity", c)
+	if err == nil || !strings.Contains(err.Error(), "more than one test entity") {
+		t.Errorf("expected error for more than one entity, got %v", err)
+	}
+
+	entities = nil
+	_, err = getOpenApiEntityByFilter(&govcd.Client{}, []interface{}{map[string]interface{}{"name": "none"}}, "test entity", c)
+	if err == nil || !strings.Contains(err.Error(), "no test entity found") {
+		t.Errorf("expected error for no entities, got %v", err)
+	}
+}
+
+func Test_openApiMetadataCriterionMatches(t *testing.T) {
+	entries := []*types.OpenApiMetadataEntry{
+		{KeyValue: types.OpenApiMetadataKeyValue{Key: "env", Domain: "TENANT", Namespace: "ns1",
+			Value: types.OpenApiMetadataTypedValue{Type: types.OpenApiMetadataStringEntry, Value: "prod"}}},
+		{KeyValue: types.OpenApiMetadataKeyValue{Key: "replicas", Domain: "PROVIDER",
+			Value: types.OpenApiMetadataTypedValue{Type: types.OpenApiMetadataNumberEntry, Value: float64(3)}}},
+	}
+	tests := []struct {
+		criterion openApiMetadataCriterion
+		want      bool
+	}{
+		{criterion: openApiMetadataCriterion{key: "env", value: "prod"}, want: true},
+		{criterion: openApiMetadataCriterion{key: "env", value: "prod", namespace: "ns1", domain: "TENANT"}, want: true},
+		{criterion: openApiMetadataCriterion{key: "env", value: "prod", namespace: "ns2"}, want: false},
+		{criterion: openApiMetadataCriterion{key: "env", value: "dev"}, want: false},
+		{criterion: openApiMetadataCriterion{key: "replicas", value: "3"}, want: true},
+		{criterion: openApiMetadataCriterion{key: "replicas", value: "3", domain: "TENANT"}, want: false},
+		{criterion: openApiMetadataCriterion{key: "missing", value: "prod"}, want: false},
+	}
+	for _, tt := range tests {
+		if got := openApiMetadataCriterionMatches(entries, tt.criterion); got != tt.want {
+			t.Errorf("openApiMetadataCriterionMatches(%v) = %v, want %v", tt.criterion, got, tt.want)
+		}
+	}
+}
+
+func Test_fiqlFilterAnd(t *testing.T) {
+	original := url.Values{"filter": {"name==a,name==b"}, "sortAsc": {"name"}}
+	got := fiqlFilterAnd(original, "ownerRef.id==urn:vcloud:vdc:1")
+	want := url.Values{"filter": {"ownerRef.id==urn:vcloud:vdc:1;(name==a,name==b)"}, "sortAsc": {"name"}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("fiqlFilterAnd() = %v, want %v", got, want)
+	}
+	if original.Get("filter") != "name==a,name==b" {
+		t.Errorf("fiqlFilterAnd() modified the original query parameters: %v", original)
+	}
+}
+
+// Test_openApiFilterSchema checks that the date and metadata criteria are only offered when requested
+func Test_openApiFilterSchema(t *testing.T) {
+	tests := []struct {
+		withDate     bool
+		withMetadata bool
+		want         []string
+	}{
+		{want: []string{"fiql", "name"}},
+		{withDate: true, want: []string{"date", "earliest", "fiql", "latest", "name"}},
+		{withMetadata: true, want: []string{"fiql", "metadata", "name"}},
+	}
+	for _, tt := range tests {
+		var got []string
+		for key := range openApiFilterSchema("test entity", tt.withDate, tt.withMetadata).Elem.(*schema.Resource).Schema {
+			got = append(got, key)
+		}
+		sort.Strings(got)
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("openApiFilterSchema(%t, %t) elements = %v, want %v", tt.withDate, tt.withMetadata, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,239 @@
+package vcd
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf16"
+)
+
+// This file contains a minimal ISO 9660 writer, used to build NoCloud seed images for cloud-init. The images contain
+// only files in the root directory, with ISO 9660 names in the primary volume descriptor and the original names in the
+// Joliet supplementary volume descriptor, which is the one used by Linux when mounting the image
+
+const isoSectorSize = 2048
+
+// isoFile is a file of the root directory of an ISO image
+type isoFile struct {
+	name    string
+	content []byte
+}
+
+// isoLayout holds the sector numbers of the parts of the image
+type isoLayout struct {
+	primaryPathTableL uint32
+	primaryPathTableM uint32
+	jolietPathTableL  uint32
+	jolietPathTableM  uint32
+	primaryRoot       uint32
+	jolietRoot        uint32
+	fileSectors       []uint32
+	totalSectors      uint32
+}
+
+// buildIsoImage returns an ISO 9660 image with Joliet extensions, containing the given files in its root directory
+func buildIsoImage(volumeId string, files []isoFile, modTime time.Time) ([]byte, error) {
+	layout := isoLayout{
+		primaryPathTableL: 19,
+		primaryPathTableM: 20,
+		jolietPathTableL:  21,
+		jolietPathTableM:  22,
+		primaryRoot:       23,
+		jolietRoot:        24,
+	}
+	next := uint32(25)
+	for _, file := range files {
+		layout.fileSectors = append(layout.fileSectors, next)
+		next += sectorsFor(len(file.content))
+	}
+	layout.totalSectors = next
+
+	primaryRoot, err := isoRootDirectory(layout, layout.primaryRoot, files, isoPrimaryName, modTime)
+	if err != nil {
+		return nil, err
+	}
+	jolietRoot, err := isoRootDirectory(layout, layout.jolietRoot, files, isoJolietName, modTime)
+	if err != nil {
+		return nil, err
+	}
+
+	image := make([]byte, int(layout.totalSectors)*isoSectorSize)
+	copy(image[16*isoSectorSize:], isoVolumeDescriptor(layout, false, volumeId, layout.primaryRoot, modTime))
+	copy(image[17*isoSectorSize:], isoVolumeDescriptor(layout, true, volumeId, layout.jolietRoot, modTime))
+	copy(image[18*isoSectorSize:], []byte{255, 'C', 'D', '0', '0', '1', 1})
+	copy(image[layout.primaryPathTableL*isoSectorSize:], isoPathTable(layout.primaryRoot, binary.LittleEndian))
+	copy(image[layout.primaryPathTableM*isoSectorSize:], isoPathTable(layout.primaryRoot, binary.BigEndian))
+	copy(image[layout.jolietPathTableL*isoSectorSize:], isoPathTable(layout.jolietRoot, binary.LittleEndian))
+	copy(image[layout.jolietPathTableM*isoSectorSize:], isoPathTable(layout.jolietRoot, binary.BigEndian))
+	copy(image[layout.primaryRoot*isoSectorSize:], primaryRoot)
+	copy(image[layout.jolietRoot*isoSectorSize:], jolietRoot)
+	for i, file := range files {
+		copy(image[layout.fileSectors[i]*isoSectorSize:], file.content)
+	}
+	return image, nil
+}
+
+// isoVolumeDescriptor returns the primary volume descriptor, or the Joliet supplementary volume descriptor
+func isoVolumeDescriptor(layout isoLayout, joliet bool, volumeId string, rootSector uint32, modTime time.Time) []byte {
+	descriptor := make([]byte, isoSectorSize)
+	textField := isoAText
+	descriptor[0] = 1
+	pathTableL, pathTableM := layout.primaryPathTableL, layout.primaryPathTableM
+	if joliet {
+		textField = isoJolietText
+		descriptor[0] = 2
+		pathTableL, pathTableM = layout.jolietPathTableL, layout.jolietPathTableM
+		// Escape sequence of UCS-2 level 3
+		copy(descriptor[88:], "%/E")
+	}
+	copy(descriptor[1:], "CD001")
+	descriptor[6] = 1
+	copy(descriptor[8:40], textField("", 32))
+	copy(descriptor[40:72], textField(volumeId, 32))
+	putBothEndian32(descriptor[80:], layout.totalSectors)
+	putBothEndian16(descriptor[120:], 1)
+	putBothEndian16(descriptor[124:], 1)
+	putBothEndian16(descriptor[128:], isoSectorSize)
+	putBothEndian32(descriptor[132:], uint32(len(isoPathTable(rootSector, binary.LittleEndian))))
+	binary.LittleEndian.PutUint32(descriptor[140:], pathTableL)
+	binary.BigEndian.PutUint32(descriptor[148:], pathTableM)
+	copy(descriptor[156:190], isoDirectoryRecord(rootSector, isoSectorSize, true, []byte{0}, modTime))
+	copy(descriptor[190:318], textField("", 128))
+	copy(descriptor[318:446], textField("", 128))
+	copy(descriptor[446:574], textField("", 128))
+	copy(descriptor[574:702], textField("TERRAFORM-PROVIDER-VCD", 128))
+	copy(descriptor[702:739], textField("", 37))
+	copy(descriptor[739:776], textField("", 37))
+	copy(descriptor[776:813], textField("", 37))
+	copy(descriptor[813:830], isoVolumeDate(modTime))
+	copy(descriptor[830:847], isoVolumeDate(modTime))
+	copy(descriptor[847:864], isoVolumeDate(time.Time{}))
+	copy(descriptor[864:881], isoVolumeDate(time.Time{}))
+	descriptor[881] = 1
+	return descriptor
+}
+
+// isoRootDirectory returns the extent of the root directory, with the records of the given files
+func isoRootDirectory(layout isoLayout, rootSector uint32, files []isoFile, name func(string) []byte, modTime time.Time) ([]byte, error) {
+	var directory bytes.Buffer
+	directory.Write(isoDirectoryRecord(rootSector, isoSectorSize, true, []byte{0}, modTime))
+	directory.Write(isoDirectoryRecord(rootSector, isoSectorSize, true, []byte{1}, modTime))
+	for i, file := range files {
+		directory.Write(isoDirectoryRecord(layout.fileSectors[i], uint32(len(file.content)), false, name(file.name), modTime))
+	}
+	if directory.Len() > isoSectorSize {
+		return nil, fmt.Errorf("too many files for an ISO image with a single directory sector")
+	}
+	return directory.Bytes(), nil
+}
+
+// isoDirectoryRecord returns a directory record of a file or directory
+func isoDirectoryRecord(sector, size uint32, isDirectory bool, identifier []byte, modTime time.Time) []byte {
+	length := 33 + len(identifier)
+	if length%2 != 0 {
+		length++
+	}
+	record := make([]byte, length)
+	record[0] = byte(length)
+	putBothEndian32(record[2:], sector)
+	putBothEndian32(record[10:], size)
+	utc := modTime.UTC()
+	record[18] = byte(utc.Year() - 1900)
+	record[19] = byte(utc.Month())
+	record[20] = byte(utc.Day())
+	record[21] = byte(utc.Hour())
+	record[22] = byte(utc.Minute())
+	record[23] = byte(utc.Second())
+	if isDirectory {
+		record[25] = 2
+	}
+	putBothEndian16(record[28:], 1)
+	record[32] = byte(len(identifier))
+	copy(record[33:], identifier)
+	return record
+}
+
+// isoPathTable returns a path table with only the root directory
+func isoPathTable(rootSector uint32, byteOrder binary.ByteOrder) []byte {
+	table := make([]byte, 10)
+	table[0] = 1
+	byteOrder.PutUint32(table[2:], rootSector)
+	byteOrder.PutUint16(table[6:], 1)
+	return table
+}
+
+// isoPrimaryName converts a file name to an ISO 9660 identifier, using only upper case letters, digits and '_'
+func isoPrimaryName(name string) []byte {
+	base, extension := name, ""
+	if dot := strings.LastIndex(name, "."); dot >= 0 {
+		base, extension = name[:dot], name[dot+1:]
+	}
+	convert := func(text string) string {
+		return strings.Map(func(r rune) rune {
+			switch {
+			case r >= 'a' && r <= 'z':
+				return r - 'a' + 'A'
+			case r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+				return r
+			}
+			return '_'
+		}, text)
+	}
+	return []byte(convert(base) + "." + convert(extension) + ";1")
+}
+
+// isoJolietName converts a file name to a Joliet identifier (UCS-2 big endian)
+func isoJolietName(name string) []byte {
+	return ucs2(name)
+}
+
+// isoAText returns a field of the primary volume descriptor, padded with spaces
+func isoAText(text string, length int) []byte {
+	return []byte(fmt.Sprintf("%-*s", length, strings.ToUpper(text))[:length])
+}
+
+// isoJolietText returns a field of the Joliet volume descriptor, padded with spaces
+func isoJolietText(text string, length int) []byte {
+	field := ucs2(fmt.Sprintf("%-*s", length/2, text)[:length/2])
+	if length%2 != 0 {
+		field = append(field, 0)
+	}
+	return field
+}
+
+// isoVolumeDate returns a date of a volume descriptor. The zero time means "not specified"
+func isoVolumeDate(date time.Time) []byte {
+	if date.IsZero() {
+		return append([]byte("0000000000000000"), 0)
+	}
+	return append([]byte(date.UTC().Format("20060102150405")+"00"), 0)
+}
+
+func ucs2(text string) []byte {
+	encoded := utf16.Encode([]rune(text))
+	result := make([]byte, 2*len(encoded))
+	for i, unit := range encoded {
+		binary.BigEndian.PutUint16(result[2*i:], unit)
+	}
+	return result
+}
+
+func putBothEndian16(field []byte, value uint16) {
+	binary.LittleEndian.PutUint16(field, value)
+	binary.BigEndian.PutUint16(field[2:], value)
+}
+
+func putBothEndian32(field []byte, value uint32) {
+	binary.LittleEndian.PutUint32(field, value)
+	binary.BigEndian.PutUint32(field[4:], value)
+}
+
+func sectorsFor(size int) uint32 {
+	sectors := (size + isoSectorSize - 1) / isoSectorSize
+	if sectors == 0 {
+		sectors = 1
+	}
+	return uint32(sectors)
+}
@@ -395,6 +395,7 @@ func updateMetadataInStateDeprecated(d *schema.ResourceData, vcdClient *VCDClien
 	if err != nil {
 		return diag.FromErr(err)
 	}
+	filterDistributedLockMetadata(deprecatedMetadata)
 
 	// VMs can have special metadata automatically set by VCD that require to be filtered out
 	if resourceType == "vcd_vapp" || resourceType == "vcd_vapp_vm" || resourceType == "vcd_vm" || resourceType == "vcd_catalog_vapp_template" {
@@ -432,6 +433,8 @@ func updateMetadataInState(d *schema.ResourceData, vcdClient *VCDClient, resourc
 	if err != nil {
 		return append(diags, diag.Errorf("error getting metadata to save in state: %s", err)...)
 	}
+	// Distributed lock leases are not managed by the resources
+	filterDistributedLockMetadata(metadata)
 
 	// VMs can have special metadata automatically set by VCD that require to be filtered out and
 	// set into a different attribute.
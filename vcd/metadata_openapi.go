@@ -287,36 +287,50 @@ func updateOpenApiMetadataInState(d *schema.ResourceData, vcdClient *VCDClient,
 		return append(diags, diag.FromErr(err)...)
 	}
 
-	metadata := make([]interface{}, len(allMetadata))
+	entries := make([]*types.OpenApiMetadataEntry, len(allMetadata))
 	for i, metadataEntryFromVcd := range allMetadata {
+		entries[i] = metadataEntryFromVcd.MetadataEntry
+	}
+	metadata, err := getOpenApiMetadataEntriesForState(entries)
+	if err != nil {
+		return append(diags, diag.FromErr(err)...)
+	}
+
+	err = d.Set("metadata_entry", metadata)
+	return append(diags, diag.FromErr(err)...)
+}
+
+// getOpenApiMetadataEntriesForState converts the given OpenAPI metadata entries retrieved from VCD to a structure
+// that can be set in the metadata_entry attribute of the Terraform state.
+func getOpenApiMetadataEntriesForState(entries []*types.OpenApiMetadataEntry) ([]interface{}, error) {
+	metadata := make([]interface{}, len(entries))
+	for i, metadataEntryFromVcd := range entries {
 		// We need to set the correct type, otherwise saving the state will fail
 		value := ""
-		switch metadataEntryFromVcd.MetadataEntry.KeyValue.Value.Type {
+		switch metadataEntryFromVcd.KeyValue.Value.Type {
 		case types.OpenApiMetadataBooleanEntry:
-			value = fmt.Sprintf("%t", metadataEntryFromVcd.MetadataEntry.KeyValue.Value.Value.(bool))
+			value = fmt.Sprintf("%t", metadataEntryFromVcd.KeyValue.Value.Value.(bool))
 		case types.OpenApiMetadataNumberEntry:
-			value = fmt.Sprintf("%.0f", metadataEntryFromVcd.MetadataEntry.KeyValue.Value.Value.(float64))
+			value = fmt.Sprintf("%.0f", metadataEntryFromVcd.KeyValue.Value.Value.(float64))
 		case types.OpenApiMetadataStringEntry:
-			value = metadataEntryFromVcd.MetadataEntry.KeyValue.Value.Value.(string)
+			value = metadataEntryFromVcd.KeyValue.Value.Value.(string)
 		default:
-			return append(diags, diag.Errorf("not supported metadata type %s", metadataEntryFromVcd.MetadataEntry.KeyValue.Value.Type)...)
+			return nil, fmt.Errorf("not supported metadata type %s", metadataEntryFromVcd.KeyValue.Value.Type)
 		}
 
 		metadataEntry := map[string]interface{}{
-			"id":         metadataEntryFromVcd.MetadataEntry.ID,
-			"key":        metadataEntryFromVcd.MetadataEntry.KeyValue.Key,
-			"readonly":   metadataEntryFromVcd.MetadataEntry.IsReadOnly,
-			"domain":     metadataEntryFromVcd.MetadataEntry.KeyValue.Domain,
-			"namespace":  metadataEntryFromVcd.MetadataEntry.KeyValue.Namespace,
-			"type":       metadataEntryFromVcd.MetadataEntry.KeyValue.Value.Type,
+			"id":         metadataEntryFromVcd.ID,
+			"key":        metadataEntryFromVcd.KeyValue.Key,
+			"readonly":   metadataEntryFromVcd.IsReadOnly,
+			"domain":     metadataEntryFromVcd.KeyValue.Domain,
+			"namespace":  metadataEntryFromVcd.KeyValue.Namespace,
+			"type":       metadataEntryFromVcd.KeyValue.Value.Type,
 			"value":      value,
-			"persistent": metadataEntryFromVcd.MetadataEntry.IsPersistent,
+			"persistent": metadataEntryFromVcd.IsPersistent,
 		}
 		metadata[i] = metadataEntry
 	}
-
-	err = d.Set("metadata_entry", metadata)
-	return append(diags, diag.FromErr(err)...)
+	return metadata, nil
 }
 
 // convertOpenApiMetadataValue converts a metadata value from plain string to a correct typed value that can be sent
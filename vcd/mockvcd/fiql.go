@@ -0,0 +1,201 @@
+package mockvcd
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// fiqlOperatorRegexp finds the operator of a FIQL comparison
+var fiqlOperatorRegexp = regexp.MustCompile(`==|!=|=(gt|ge|lt|le)=`)
+
+// fiqlExpression is a parsed FIQL filter, as used in the 'filter' query parameter of /cloudapi endpoints
+// (e.g. "(name==web*,name==db*);ownerRef.id==urn:vcloud:vdc:...")
+type fiqlExpression interface {
+	matches(entity map[string]interface{}) bool
+}
+
+// fiqlAnd matches when all its operands match. An empty fiqlAnd matches everything
+type fiqlAnd []fiqlExpression
+
+// fiqlOr matches when any of its operands matches
+type fiqlOr []fiqlExpression
+
+// fiqlComparison compares the value of a field with a literal. Supported operators are '==' (with '*' wildcards),
+// '!=', '=gt=', '=ge=', '=lt=' and '=le='
+type fiqlComparison struct {
+	field    string
+	operator string
+	value    string
+}
+
+func (and fiqlAnd) matches(entity map[string]interface{}) bool {
+	for _, expression := range and {
+		if !expression.matches(entity) {
+			return false
+		}
+	}
+	return true
+}
+
+func (or fiqlOr) matches(entity map[string]interface{}) bool {
+	for _, expression := range or {
+		if expression.matches(entity) {
+			return true
+		}
+	}
+	return false
+}
+
+func (comparison fiqlComparison) matches(entity map[string]interface{}) bool {
+	value := fieldValue(entity, comparison.field)
+	switch comparison.operator {
+	case "==":
+		return equalsWithWildcards(value, comparison.value)
+	case "!=":
+		return !equalsWithWildcards(value, comparison.value)
+	case "=gt=":
+		return compareValues(value, comparison.value) > 0
+	case "=ge=":
+		return compareValues(value, comparison.value) >= 0
+	case "=lt=":
+		return compareValues(value, comparison.value) < 0
+	case "=le=":
+		return compareValues(value, comparison.value) <= 0
+	}
+	return false
+}
+
+// parseFiql parses a FIQL filter. An empty filter matches all entities
+func parseFiql(filter string) (fiqlExpression, error) {
+	if filter == "" {
+		return fiqlAnd{}, nil
+	}
+	parser := &fiqlParser{input: filter}
+	expression, err := parser.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if parser.position < len(parser.input) {
+		return nil, fmt.Errorf("unexpected '%c' at position %d of filter '%s'", parser.input[parser.position], parser.position, filter)
+	}
+	return expression, nil
+}
+
+type fiqlParser struct {
+	input    string
+	position int
+}
+
+func (p *fiqlParser) parseOr() (fiqlExpression, error) {
+	var or fiqlOr
+	for {
+		and, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		or = append(or, and)
+		if !p.consume(',') {
+			break
+		}
+	}
+	if len(or) == 1 {
+		return or[0], nil
+	}
+	return or, nil
+}
+
+func (p *fiqlParser) parseAnd() (fiqlExpression, error) {
+	var and fiqlAnd
+	for {
+		term, err := p.parseTerm()
+		if err != nil {
+			return nil, err
+		}
+		and = append(and, term)
+		if !p.consume(';') {
+			break
+		}
+	}
+	if len(and) == 1 {
+		return and[0], nil
+	}
+	return and, nil
+}
+
+func (p *fiqlParser) parseTerm() (fiqlExpression, error) {
+	if p.consume('(') {
+		expression, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if !p.consume(')') {
+			return nil, fmt.Errorf("missing ')' at position %d of filter '%s'", p.position, p.input)
+		}
+		return expression, nil
+	}
+
+	end := p.position
+	for end < len(p.input) && !strings.ContainsRune(";,)", rune(p.input[end])) {
+		end++
+	}
+	text := p.input[p.position:end]
+	p.position = end
+
+	operator := fiqlOperatorRegexp.FindStringIndex(text)
+	if operator == nil || operator[0] == 0 {
+		return nil, fmt.Errorf("invalid comparison '%s' in filter '%s'", text, p.input)
+	}
+	return fiqlComparison{
+		field:    text[:operator[0]],
+		operator: text[operator[0]:operator[1]],
+		value:    text[operator[1]:],
+	}, nil
+}
+
+func (p *fiqlParser) consume(character byte) bool {
+	if p.position < len(p.input) && p.input[p.position] == character {
+		p.position++
+		return true
+	}
+	return false
+}
+
+// equalsWithWildcards checks whether a value is equal to a literal, where '*' in the literal matches any text
+func equalsWithWildcards(value interface{}, literal string) bool {
+	if !strings.Contains(literal, "*") {
+		return compareValues(value, literal) == 0
+	}
+	pattern := "^" + strings.ReplaceAll(regexp.QuoteMeta(literal), `\*`, ".*") + "$"
+	return regexp.MustCompile(pattern).MatchString(valueToString(value))
+}
+
+// compareValues compares two values numerically when both are numbers, and as text otherwise
+func compareValues(first, second interface{}) int {
+	firstText, secondText := valueToString(first), valueToString(second)
+	firstNumber, firstErr := strconv.ParseFloat(firstText, 64)
+	secondNumber, secondErr := strconv.ParseFloat(secondText, 64)
+	if firstErr == nil && secondErr == nil {
+		switch {
+		case firstNumber < secondNumber:
+			return -1
+		case firstNumber > secondNumber:
+			return 1
+		}
+		return 0
+	}
+	return strings.Compare(firstText, secondText)
+}
+
+func valueToString(value interface{}) string {
+	switch typedValue := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return typedValue
+	case float64:
+		return strconv.FormatFloat(typedValue, 'f', -1, 64)
+	}
+	return fmt.Sprintf("%v", value)
+}
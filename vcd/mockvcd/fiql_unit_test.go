@@ -0,0 +1,73 @@
+//go:build unit || ALL
+
+package mockvcd
+
+import "testing"
+
+func Test_parseFiql(t *testing.T) {
+	entity := map[string]interface{}{
+		"name":     "web-01",
+		"size":     float64(20),
+		"enabled":  true,
+		"ownerRef": map[string]interface{}{"id": "urn:vcloud:vdc:1", "name": "vdc1"},
+	}
+	tests := []struct {
+		filter  string
+		want    bool
+		wantErr bool
+	}{
+		{filter: "", want: true},
+		{filter: "name==web-01", want: true},
+		{filter: "name==web*", want: true},
+		{filter: "name==*01", want: true},
+		{filter: "name==db*", want: false},
+		{filter: "name!=db-01", want: true},
+		{filter: "size=ge=20", want: true},
+		{filter: "size=gt=20", want: false},
+		{filter: "size=lt=100", want: true},
+		{filter: "size=le=3", want: false},
+		{filter: "enabled==true", want: true},
+		{filter: "ownerRef.id==urn:vcloud:vdc:1", want: true},
+		{filter: "ownerRef.missing==x", want: false},
+		{filter: "name==db*;size==20", want: false},
+		{filter: "name==db*,size==20", want: true},
+		{filter: "(name==db*,name==web*);ownerRef.name==vdc1", want: true},
+		{filter: "(name==db*,name==web*);ownerRef.name==vdc2", want: false},
+		{filter: "name", wantErr: true},
+		{filter: "==web", wantErr: true},
+		{filter: "(name==web*", wantErr: true},
+		{filter: "name==web)", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.filter, func(t *testing.T) {
+			expression, err := parseFiql(tt.filter)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseFiql() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if got := expression.matches(entity); got != tt.want {
+				t.Errorf("matches() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_compareValues(t *testing.T) {
+	tests := []struct {
+		first, second interface{}
+		want          int
+	}{
+		{first: float64(9), second: "10", want: -1},
+		{first: "9", second: "10", want: -1},
+		{first: "b", second: "a", want: 1},
+		{first: "2024-01-31T10:30:00.000Z", second: "2024-01-31T10:30:00.000Z", want: 0},
+		{first: nil, second: "", want: 0},
+	}
+	for _, tt := range tests {
+		if got := compareValues(tt.first, tt.second); got != tt.want {
+			t.Errorf("compareValues(%v, %v) = %d, want %d", tt.first, tt.second, got, tt.want)
+		}
+	}
+}
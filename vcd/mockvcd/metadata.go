@@ -0,0 +1,103 @@
+package mockvcd
+
+import (
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"path"
+	"regexp"
+	"sort"
+
+	"github.com/vmware/go-vcloud-director/v3/types/v56"
+)
+
+// metadataPathRegexp matches the legacy metadata of an /api entity (e.g. /api/vApp/vapp-<uuid>/metadata), optionally
+// followed by the key of an entry
+var metadataPathRegexp = regexp.MustCompile(`^(/api/.+)/metadata(?:/([^/]+))?/?$`)
+
+// SetMetadata stores a string entry in the legacy metadata of the /api entity with the given path
+// (e.g. "/api/vApp/vapp-<uuid>")
+func (s *Server) SetMetadata(entityPath, key, value string) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.setMetadata(entityPath, key, types.MetadataTypedValue{XsiType: types.MetadataStringValue, Value: value})
+}
+
+// Metadata returns a copy of the legacy metadata entries of the /api entity with the given path, by key
+func (s *Server) Metadata(entityPath string) map[string]string {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	entries := make(map[string]string, len(s.metadata[entityPath]))
+	for key, value := range s.metadata[entityPath] {
+		entries[key] = value.Value
+	}
+	return entries
+}
+
+func (s *Server) setMetadata(entityPath, key string, value types.MetadataTypedValue) {
+	if s.metadata[entityPath] == nil {
+		s.metadata[entityPath] = make(map[string]types.MetadataTypedValue)
+	}
+	s.metadata[entityPath][key] = value
+}
+
+// serveMetadata implements the legacy metadata of any /api entity: GET on <entity>/metadata lists the entries, while
+// GET, PUT and DELETE on <entity>/metadata/<key> manage a single entry. As in VCD, changes are answered with a task
+func (s *Server) serveMetadata(w http.ResponseWriter, r *http.Request) {
+	match := metadataPathRegexp.FindStringSubmatch(r.URL.Path)
+	entityPath, key := match[1], match[2]
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	entries := s.metadata[entityPath]
+	switch {
+	case key == "" && r.Method == http.MethodGet:
+		metadata := types.Metadata{Xmlns: types.XMLNamespaceVCloud, HREF: s.URL + r.URL.Path}
+		var keys []string
+		for entryKey := range entries {
+			keys = append(keys, entryKey)
+		}
+		sort.Strings(keys)
+		for _, entryKey := range keys {
+			value := entries[entryKey]
+			metadata.MetadataEntry = append(metadata.MetadataEntry, &types.MetadataEntry{
+				HREF:       s.URL + entityPath + "/metadata/" + entryKey,
+				Key:        entryKey,
+				TypedValue: &value,
+			})
+		}
+		writeXml(w, http.StatusOK, metadata)
+	case key != "" && r.Method == http.MethodGet:
+		value, found := entries[key]
+		if !found {
+			writeXmlError(w, http.StatusForbidden, "ACCESS_TO_RESOURCE_IS_FORBIDDEN", fmt.Sprintf("no metadata entry with key '%s'", key))
+			return
+		}
+		writeXml(w, http.StatusOK, types.MetadataValue{Xmlns: types.XMLNamespaceVCloud, TypedValue: &value})
+	case key != "" && r.Method == http.MethodPut:
+		metadataValue := types.MetadataValue{}
+		if err := xml.NewDecoder(r.Body).Decode(&metadataValue); err != nil || metadataValue.TypedValue == nil {
+			writeXmlError(w, http.StatusBadRequest, "BAD_REQUEST", fmt.Sprintf("invalid metadata value: %v", err))
+			return
+		}
+		s.setMetadata(entityPath, key, *metadataValue.TypedValue)
+		s.writeXmlTaskAccepted(w, entityPath)
+	case key != "" && r.Method == http.MethodDelete:
+		if _, found := entries[key]; !found {
+			writeXmlError(w, http.StatusForbidden, "ACCESS_TO_RESOURCE_IS_FORBIDDEN", fmt.Sprintf("no metadata entry with key '%s'", key))
+			return
+		}
+		delete(entries, key)
+		s.writeXmlTaskAccepted(w, entityPath)
+	default:
+		writeXmlError(w, http.StatusMethodNotAllowed, "BAD_REQUEST", fmt.Sprintf("method %s not allowed in %s", r.Method, r.URL.Path))
+	}
+}
+
+// writeXmlTaskAccepted writes the response of an asynchronous /api operation, with a successful task on the given
+// entity in the body
+func (s *Server) writeXmlTaskAccepted(w http.ResponseWriter, entityPath string) {
+	taskHref := s.newTask("", s.URL+entityPath)
+	writeXml(w, http.StatusAccepted, s.tasks[path.Base(taskHref)])
+}
@@ -0,0 +1,248 @@
+package mockvcd
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// urnTypes contains the URN entity type of the collections whose name is not the plural of the type.
+// Other collections use their name without the final 's' (e.g. "roles" creates "urn:vcloud:role:...")
+var urnTypes = map[string]string{
+	"edgeGateways":       "gateway",
+	"entities":           "entity",
+	"entityTypes":        "type",
+	"orgVdcNetworks":     "network",
+	"metadata":           "metadata",
+	"policies":           "policy",
+	"vdcComputePolicies": "vdcComputePolicy",
+}
+
+var uuidRegexp = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+
+// AddEntity stores an entity in a /cloudapi collection, given with its version and without slashes at the ends
+// (e.g. "1.0.0/roles"). If the entity has no "id", a new URN is assigned. It returns the ID of the entity
+func (s *Server) AddEntity(collection string, entity map[string]interface{}) string {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.addEntity(collection, copyEntity(entity))
+}
+
+// GetEntity returns a copy of the entity with the given ID from a /cloudapi collection
+func (s *Server) GetEntity(collection, id string) (map[string]interface{}, bool) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	_, entity := s.findEntity(collection, id)
+	if entity == nil {
+		return nil, false
+	}
+	return copyEntity(entity), true
+}
+
+// Entities returns a copy of all the entities of a /cloudapi collection, in creation order
+func (s *Server) Entities(collection string) []map[string]interface{} {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	var entities []map[string]interface{}
+	for _, entity := range s.collections[collection] {
+		entities = append(entities, copyEntity(entity))
+	}
+	return entities
+}
+
+func (s *Server) addEntity(collection string, entity map[string]interface{}) string {
+	id, _ := entity["id"].(string)
+	if id == "" {
+		name := collection[strings.LastIndex(collection, "/")+1:]
+		urnType, ok := urnTypes[name]
+		if !ok {
+			urnType = strings.TrimSuffix(name, "s")
+		}
+		id = fmt.Sprintf("urn:vcloud:%s:%s", urnType, s.newUuid())
+		entity["id"] = id
+	}
+	s.collections[collection] = append(s.collections[collection], entity)
+	return id
+}
+
+func (s *Server) findEntity(collection, id string) (int, map[string]interface{}) {
+	for index, entity := range s.collections[collection] {
+		if entity["id"] == id {
+			return index, entity
+		}
+	}
+	return -1, nil
+}
+
+func (s *Server) isAsync(collection string) bool {
+	for _, asyncCollection := range s.asyncCollections {
+		if strings.Trim(asyncCollection, "/") == collection {
+			return true
+		}
+	}
+	return false
+}
+
+// serveOpenApi implements the in-memory store for /cloudapi endpoints. A path whose last element is a URN or a UUID
+// addresses an entity of the collection given by the rest of the path. Any other path addresses a collection
+func (s *Server) serveOpenApi(w http.ResponseWriter, r *http.Request) {
+	path := strings.Trim(strings.TrimPrefix(r.URL.Path, "/cloudapi/"), "/")
+	collection, id := path, ""
+	if lastSlash := strings.LastIndex(path, "/"); lastSlash > 0 {
+		last := path[lastSlash+1:]
+		if strings.HasPrefix(last, "urn:") || uuidRegexp.MatchString(last) {
+			collection, id = path[:lastSlash], last
+		}
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	switch {
+	case id == "" && r.Method == http.MethodGet:
+		s.listEntities(w, r, collection)
+	case id == "" && r.Method == http.MethodPost:
+		var entity map[string]interface{}
+		if err := json.NewDecoder(r.Body).Decode(&entity); err != nil || entity == nil {
+			writeOpenApiError(w, http.StatusBadRequest, "BAD_REQUEST", fmt.Sprintf("invalid JSON body: %v", err))
+			return
+		}
+		id = s.addEntity(collection, entity)
+		if s.isAsync(collection) {
+			s.writeTaskAccepted(w, id, strings.TrimSuffix(r.URL.Path, "/")+"/"+id)
+			return
+		}
+		writeJson(w, http.StatusCreated, entity)
+	case id != "":
+		s.serveOpenApiEntity(w, r, collection, id)
+	default:
+		writeOpenApiError(w, http.StatusMethodNotAllowed, "BAD_REQUEST", fmt.Sprintf("method %s not allowed in %s", r.Method, r.URL.Path))
+	}
+}
+
+func (s *Server) serveOpenApiEntity(w http.ResponseWriter, r *http.Request, collection, id string) {
+	index, entity := s.findEntity(collection, id)
+	if entity == nil {
+		// As VCD does, missing entities are reported as forbidden
+		writeOpenApiError(w, http.StatusForbidden, "ACCESS_TO_RESOURCE_IS_FORBIDDEN",
+			fmt.Sprintf("[ %s ] Either you need some or all of the following rights or the entity does not exist in %s", id, collection))
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		w.Header().Set("Etag", strconv.Quote(strconv.Itoa(s.counter)))
+		writeJson(w, http.StatusOK, entity)
+	case http.MethodPut:
+		var updated map[string]interface{}
+		if err := json.NewDecoder(r.Body).Decode(&updated); err != nil || updated == nil {
+			writeOpenApiError(w, http.StatusBadRequest, "BAD_REQUEST", fmt.Sprintf("invalid JSON body: %v", err))
+			return
+		}
+		updated["id"] = id
+		s.collections[collection][index] = updated
+		s.counter++
+		if s.isAsync(collection) {
+			s.writeTaskAccepted(w, id, r.URL.Path)
+			return
+		}
+		writeJson(w, http.StatusOK, updated)
+	case http.MethodDelete:
+		entities := s.collections[collection]
+		s.collections[collection] = append(entities[:index:index], entities[index+1:]...)
+		if s.isAsync(collection) {
+			s.writeTaskAccepted(w, id, r.URL.Path)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		writeOpenApiError(w, http.StatusMethodNotAllowed, "BAD_REQUEST", fmt.Sprintf("method %s not allowed in %s", r.Method, r.URL.Path))
+	}
+}
+
+// listEntities writes a page of the collection, applying the 'filter', 'sortAsc', 'sortDesc', 'page' and 'pageSize'
+// query parameters
+func (s *Server) listEntities(w http.ResponseWriter, r *http.Request, collection string) {
+	query := r.URL.Query()
+	filter, err := parseFiql(query.Get("filter"))
+	if err != nil {
+		writeOpenApiError(w, http.StatusBadRequest, "BAD_REQUEST", err.Error())
+		return
+	}
+
+	values := []map[string]interface{}{}
+	for _, entity := range s.collections[collection] {
+		if filter.matches(entity) {
+			values = append(values, entity)
+		}
+	}
+	if field := query.Get("sortAsc"); field != "" {
+		sort.SliceStable(values, func(i, j int) bool {
+			return compareValues(fieldValue(values[i], field), fieldValue(values[j], field)) < 0
+		})
+	}
+	if field := query.Get("sortDesc"); field != "" {
+		sort.SliceStable(values, func(i, j int) bool {
+			return compareValues(fieldValue(values[i], field), fieldValue(values[j], field)) > 0
+		})
+	}
+
+	page, pageSize := pageParameters(query.Get("page"), query.Get("pageSize"))
+	total := len(values)
+	start := min((page-1)*pageSize, total)
+	end := min(start+pageSize, total)
+	pageCount := (total + pageSize - 1) / pageSize
+	writeJson(w, http.StatusOK, map[string]interface{}{
+		"resultTotal": total,
+		"pageCount":   pageCount,
+		"page":        page,
+		"pageSize":    pageSize,
+		"values":      values[start:end],
+	})
+}
+
+// writeTaskAccepted writes the response of an asynchronous operation, with a successful task on the given entity
+func (s *Server) writeTaskAccepted(w http.ResponseWriter, ownerId, ownerPath string) {
+	w.Header().Set("Location", s.newTask(ownerId, s.URL+ownerPath))
+	w.WriteHeader(http.StatusAccepted)
+}
+
+func pageParameters(page, pageSize string) (int, int) {
+	pageNumber, err := strconv.Atoi(page)
+	if err != nil || pageNumber < 1 {
+		pageNumber = 1
+	}
+	size, err := strconv.Atoi(pageSize)
+	if err != nil || size < 1 {
+		size = 25
+	}
+	return pageNumber, size
+}
+
+// fieldValue returns the value found in an entity following a path of dot separated keys (e.g. "ownerRef.id")
+func fieldValue(entity map[string]interface{}, path string) interface{} {
+	var current interface{} = entity
+	for _, key := range strings.Split(path, ".") {
+		object, ok := current.(map[string]interface{})
+		if !ok {
+			return nil
+		}
+		current = object[key]
+	}
+	return current
+}
+
+// copyEntity returns a deep copy of an entity, so that the stored state is not modified by the callers
+func copyEntity(entity map[string]interface{}) map[string]interface{} {
+	var result map[string]interface{}
+	encoded, err := json.Marshal(entity)
+	if err != nil {
+		panic(fmt.Sprintf("entity cannot be encoded as JSON: %s", err))
+	}
+	_ = json.Unmarshal(encoded, &result)
+	return result
+}
@@ -0,0 +1,85 @@
+package mockvcd
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"sort"
+
+	"github.com/vmware/go-vcloud-director/v3/types/v56"
+)
+
+// queryRecord is a record returned by the query service, such as <OrgVdcRecord name="..." href="..."/>
+type queryRecord struct {
+	element    string
+	attributes map[string]string
+}
+
+// AddQueryRecord adds a record to the results of the query service for the given type. The record is an XML element
+// with the given name and attributes (e.g. AddQueryRecord("orgVdc", "OrgVdcRecord", map[string]string{"name": "vdc1"}))
+func (s *Server) AddQueryRecord(queryType, element string, attributes map[string]string) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	copied := make(map[string]string, len(attributes))
+	for key, value := range attributes {
+		copied[key] = value
+	}
+	s.queryRecords[queryType] = append(s.queryRecords[queryType], queryRecord{element: element, attributes: copied})
+}
+
+// serveQuery implements the query service, applying the 'filter', 'page' and 'pageSize' query parameters to the
+// records added with AddQueryRecord
+func (s *Server) serveQuery(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	filter, err := parseFiql(query.Get("filter"))
+	if err != nil {
+		writeXmlError(w, http.StatusBadRequest, "BAD_REQUEST", err.Error())
+		return
+	}
+
+	s.mutex.Lock()
+	var records []queryRecord
+	for _, record := range s.queryRecords[query.Get("type")] {
+		attributes := make(map[string]interface{}, len(record.attributes))
+		for key, value := range record.attributes {
+			attributes[key] = value
+		}
+		if filter.matches(attributes) {
+			records = append(records, record)
+		}
+	}
+	s.mutex.Unlock()
+
+	page, pageSize := pageParameters(query.Get("page"), query.Get("pageSize"))
+	start := min((page-1)*pageSize, len(records))
+	end := min(start+pageSize, len(records))
+
+	var body bytes.Buffer
+	body.WriteString(xml.Header)
+	_, _ = fmt.Fprintf(&body, `<QueryResultRecords xmlns="%s" name="%s" page="%d" pageSize="%d" total="%d">`,
+		types.XMLNamespaceVCloud, escapeXml(query.Get("type")), page, pageSize, len(records))
+	for _, record := range records[start:end] {
+		keys := make([]string, 0, len(record.attributes))
+		for key := range record.attributes {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		body.WriteString("<" + record.element)
+		for _, key := range keys {
+			_, _ = fmt.Fprintf(&body, ` %s="%s"`, key, escapeXml(record.attributes[key]))
+		}
+		body.WriteString("/>")
+	}
+	body.WriteString("</QueryResultRecords>")
+
+	w.Header().Set("Content-Type", types.MimeQueryRecords)
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write(body.Bytes())
+}
+
+func escapeXml(text string) string {
+	var escaped bytes.Buffer
+	_ = xml.EscapeText(&escaped, []byte(text))
+	return escaped.String()
+}
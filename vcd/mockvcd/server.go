@@ -0,0 +1,285 @@
+// Package mockvcd provides an in-process fake VMware Cloud Director, to be used in tests that need a working
+// connection but no infrastructure.
+//
+// The server implements the login flow (/api/versions and /cloudapi/1.0.0/sessions), the query service, tasks,
+// the legacy metadata of any /api entity and a generic in-memory store for /cloudapi endpoints, which supports
+// creation, retrieval, update, deletion, FIQL filters and sorting for any collection. Specific behaviors can be added
+// with Server.HandleFunc.
+//
+// Example:
+//
+//	server := mockvcd.NewServer()
+//	defer server.Close()
+//	config := Config{User: mockvcd.DefaultUser, Password: mockvcd.DefaultPassword, SysOrg: "System",
+//		Href: server.ApiHref(), InsecureFlag: true}
+//	vcdClient, err := config.Client()
+package mockvcd
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+
+	"github.com/vmware/go-vcloud-director/v3/govcd"
+	"github.com/vmware/go-vcloud-director/v3/types/v56"
+)
+
+const (
+	// DefaultUser is the user accepted by a server created without WithCredentials
+	DefaultUser = "administrator"
+	// DefaultPassword is the password accepted by a server created without WithCredentials
+	DefaultPassword = "mock-password"
+
+	// accessTokenHeader is the header where VCD returns the access token after a successful login
+	accessTokenHeader = "X-Vmware-Vcloud-Access-Token"
+)
+
+// defaultApiVersions are the API versions advertised by a server created without WithApiVersions
+var defaultApiVersions = []string{"37.0", "37.1", "37.2", "38.0", "38.1"}
+
+// Request is a summary of a request received by the server
+type Request struct {
+	Method string
+	Path   string
+	Query  string
+}
+
+// Server is an in-process fake VCD. Its state lives in memory and is lost when the server is closed
+type Server struct {
+	*httptest.Server
+
+	mutex            sync.Mutex
+	user             string
+	password         string
+	token            string
+	apiVersions      []string
+	asyncCollections []string
+	counter          int
+	collections      map[string][]map[string]interface{}
+	metadata         map[string]map[string]types.MetadataTypedValue
+	queryRecords     map[string][]queryRecord
+	tasks            map[string]*types.Task
+	handlers         map[string]http.HandlerFunc
+	requests         []Request
+}
+
+// Option customizes a Server created with NewServer
+type Option func(*Server)
+
+// WithCredentials sets the user and password accepted by the server. The organization is not checked
+func WithCredentials(user, password string) Option {
+	return func(s *Server) {
+		s.user = user
+		s.password = password
+	}
+}
+
+// WithApiVersions sets the API versions advertised by the server. The highest one determines which features the
+// clients consider available (e.g. versions from 40.0 are reported by Tenant Manager)
+func WithApiVersions(versions ...string) Option {
+	return func(s *Server) {
+		s.apiVersions = versions
+	}
+}
+
+// WithAsyncCollections makes the server answer creations, updates and deletions in the given /cloudapi collections
+// (e.g. "1.0.0/edgeGateways") with a task, as VCD does for long-running operations
+func WithAsyncCollections(collections ...string) Option {
+	return func(s *Server) {
+		s.asyncCollections = append(s.asyncCollections, collections...)
+	}
+}
+
+// NewServer creates and starts a TLS server. It must be stopped with Close
+func NewServer(options ...Option) *Server {
+	s := &Server{
+		user:         DefaultUser,
+		password:     DefaultPassword,
+		token:        "mock-token-0123456789abcdef0123456789abcdef",
+		apiVersions:  defaultApiVersions,
+		collections:  make(map[string][]map[string]interface{}),
+		metadata:     make(map[string]map[string]types.MetadataTypedValue),
+		queryRecords: make(map[string][]queryRecord),
+		tasks:        make(map[string]*types.Task),
+		handlers:     make(map[string]http.HandlerFunc),
+	}
+	for _, option := range options {
+		option(s)
+	}
+	s.Server = httptest.NewTLSServer(http.HandlerFunc(s.serveHTTP))
+	return s
+}
+
+// ApiHref returns the URL to use as VCD endpoint in the clients (e.g. https://127.0.0.1:12345/api)
+func (s *Server) ApiHref() string {
+	return s.URL + "/api"
+}
+
+// HandleFunc registers a handler for the given method and path (e.g. "GET", "/api/org"), which takes precedence
+// over the built-in behavior. The path must not contain query parameters
+func (s *Server) HandleFunc(method, path string, handler http.HandlerFunc) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.handlers[method+" "+path] = handler
+}
+
+// Requests returns the authenticated requests received so far, in order
+func (s *Server) Requests() []Request {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return append([]Request(nil), s.requests...)
+}
+
+// NewTask registers a task that completed successfully on the given owner, and returns its URL
+func (s *Server) NewTask(ownerId, ownerHref string) string {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.newTask(ownerId, ownerHref)
+}
+
+func (s *Server) newTask(ownerId, ownerHref string) string {
+	id := s.newUuid()
+	href := s.URL + "/api/task/" + id
+	s.tasks[id] = &types.Task{
+		HREF:   href,
+		ID:     "urn:vcloud:task:" + id,
+		Name:   "task",
+		Status: "success",
+		Type:   types.MimeTask,
+		Owner:  &types.Reference{ID: ownerId, HREF: ownerHref},
+	}
+	return href
+}
+
+// newUuid returns a new identifier with UUID format. Identifiers are sequential, so that tests are repeatable
+func (s *Server) newUuid() string {
+	s.counter++
+	return fmt.Sprintf("%08x-0000-4000-8000-%012x", s.counter, s.counter)
+}
+
+func (s *Server) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	switch {
+	case r.Method == http.MethodGet && r.URL.Path == "/api/versions":
+		s.serveVersions(w)
+		return
+	case r.Method == http.MethodPost && (r.URL.Path == "/cloudapi/1.0.0/sessions" || r.URL.Path == "/cloudapi/1.0.0/sessions/provider"):
+		s.serveLogin(w, r)
+		return
+	}
+
+	if !s.isAuthenticated(r) {
+		writeOpenApiError(w, http.StatusUnauthorized, "UNAUTHORIZED", "missing or invalid access token")
+		return
+	}
+
+	s.mutex.Lock()
+	s.requests = append(s.requests, Request{Method: r.Method, Path: r.URL.Path, Query: r.URL.RawQuery})
+	handler := s.handlers[r.Method+" "+r.URL.Path]
+	s.mutex.Unlock()
+	if handler != nil {
+		handler(w, r)
+		return
+	}
+
+	switch {
+	case r.URL.Path == "/cloudapi/1.0.0/sessions/current":
+		s.serveCurrentSession(w)
+	case strings.HasPrefix(r.URL.Path, "/cloudapi/"):
+		s.serveOpenApi(w, r)
+	case r.Method == http.MethodGet && r.URL.Path == "/api/query":
+		s.serveQuery(w, r)
+	case metadataPathRegexp.MatchString(r.URL.Path):
+		s.serveMetadata(w, r)
+	case r.Method == http.MethodGet && strings.HasPrefix(r.URL.Path, "/api/task/"):
+		s.serveTask(w, strings.TrimPrefix(r.URL.Path, "/api/task/"))
+	default:
+		writeXmlError(w, http.StatusNotFound, "RESOURCE_NOT_FOUND", fmt.Sprintf("%s %s is not supported by the mock server", r.Method, r.URL.Path))
+	}
+}
+
+func (s *Server) serveVersions(w http.ResponseWriter) {
+	versions := govcd.SupportedVersions{}
+	for _, version := range s.apiVersions {
+		versions.VersionInfos = append(versions.VersionInfos, govcd.VersionInfo{
+			Version:          version,
+			LoginUrl:         s.URL + "/cloudapi/1.0.0/sessions",
+			ProviderLoginUrl: s.URL + "/cloudapi/1.0.0/sessions/provider",
+		})
+	}
+	writeXml(w, http.StatusOK, versions)
+}
+
+func (s *Server) serveLogin(w http.ResponseWriter, r *http.Request) {
+	user, password, ok := r.BasicAuth()
+	// The user comes as user@org
+	if at := strings.LastIndex(user, "@"); at >= 0 {
+		user = user[:at]
+	}
+	if !ok || user != s.user || password != s.password {
+		writeOpenApiError(w, http.StatusUnauthorized, "UNAUTHORIZED", "invalid credentials")
+		return
+	}
+	w.Header().Set(accessTokenHeader, s.token)
+	writeJson(w, http.StatusOK, s.session())
+}
+
+func (s *Server) serveCurrentSession(w http.ResponseWriter) {
+	writeJson(w, http.StatusOK, s.session())
+}
+
+func (s *Server) session() map[string]interface{} {
+	return map[string]interface{}{
+		"id":       "urn:vcloud:session:00000000-0000-4000-8000-000000000000",
+		"user":     map[string]interface{}{"name": s.user, "id": "urn:vcloud:user:00000000-0000-4000-8000-000000000001"},
+		"org":      map[string]interface{}{"name": "System", "id": "urn:vcloud:org:00000000-0000-4000-8000-000000000002"},
+		"roles":    []string{"System Administrator"},
+		"location": "mock",
+	}
+}
+
+func (s *Server) isAuthenticated(r *http.Request) bool {
+	return r.Header.Get(accessTokenHeader) == s.token ||
+		strings.EqualFold(r.Header.Get("Authorization"), "bearer "+s.token)
+}
+
+func (s *Server) serveTask(w http.ResponseWriter, id string) {
+	s.mutex.Lock()
+	task, ok := s.tasks[id]
+	s.mutex.Unlock()
+	if !ok {
+		writeXmlError(w, http.StatusNotFound, "RESOURCE_NOT_FOUND", fmt.Sprintf("task %s not found", id))
+		return
+	}
+	writeXml(w, http.StatusOK, task)
+}
+
+func writeJson(w http.ResponseWriter, status int, value interface{}) {
+	w.Header().Set("Content-Type", types.JSONMime)
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(value)
+}
+
+func writeXml(w http.ResponseWriter, status int, value interface{}) {
+	w.Header().Set("Content-Type", types.AnyXMLMime)
+	w.WriteHeader(status)
+	_, _ = w.Write([]byte(xml.Header))
+	_ = xml.NewEncoder(w).Encode(value)
+}
+
+// writeOpenApiError writes an error with the format used by /cloudapi endpoints
+func writeOpenApiError(w http.ResponseWriter, status int, code, message string) {
+	writeJson(w, status, types.OpenApiError{MinorErrorCode: code, Message: message})
+}
+
+// writeXmlError writes an error with the format used by /api endpoints
+func writeXmlError(w http.ResponseWriter, status int, code, message string) {
+	writeXml(w, status, types.Error{
+		MajorErrorCode: status,
+		MinorErrorCode: code,
+		Message:        message,
+	})
+}
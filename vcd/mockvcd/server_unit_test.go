@@ -0,0 +1,156 @@
+//go:build unit || ALL
+
+package mockvcd
+
+import (
+	"net/url"
+	"testing"
+
+	"github.com/vmware/go-vcloud-director/v3/govcd"
+	"github.com/vmware/go-vcloud-director/v3/types/v56"
+)
+
+// newTestClient returns a client authenticated as System administrator in the given server
+func newTestClient(t *testing.T, server *Server) *govcd.VCDClient {
+	href, err := url.ParseRequestURI(server.ApiHref())
+	if err != nil {
+		t.Fatalf("error parsing server URL: %s", err)
+	}
+	vcdClient := govcd.NewVCDClient(*href, true)
+	err = vcdClient.Authenticate(DefaultUser, DefaultPassword, "System")
+	if err != nil {
+		t.Fatalf("error authenticating: %s", err)
+	}
+	return vcdClient
+}
+
+func TestServer_Authenticate(t *testing.T) {
+	server := NewServer(WithCredentials("user1", "secret"))
+	defer server.Close()
+
+	href, _ := url.ParseRequestURI(server.ApiHref())
+	vcdClient := govcd.NewVCDClient(*href, true)
+	if err := vcdClient.Authenticate("user1", "wrong", "org1"); err == nil {
+		t.Fatalf("expected authentication error with wrong password")
+	}
+	if err := vcdClient.Authenticate("user1", "secret", "org1"); err != nil {
+		t.Fatalf("error authenticating: %s", err)
+	}
+	if !vcdClient.Client.APIVCDMaxVersionIs("= 38.1") {
+		t.Errorf("expected maximum API version 38.1")
+	}
+	if vcdClient.Client.IsTm() {
+		t.Errorf("expected a VCD, got Tenant Manager")
+	}
+}
+
+func TestServer_OpenApiCrud(t *testing.T) {
+	for _, async := range []bool{false, true} {
+		var options []Option
+		if async {
+			options = append(options, WithAsyncCollections("1.0.0/globalRoles"))
+		}
+		server := NewServer(options...)
+		vcdClient := newTestClient(t, server)
+
+		client := &vcdClient.Client
+		role, err := client.CreateGlobalRole(&types.GlobalRole{Name: "role1", Description: "first"})
+		if err != nil {
+			t.Fatalf("[async %v] error creating role: %s", async, err)
+		}
+		if _, found := server.GetEntity("1.0.0/globalRoles", role.GlobalRole.Id); !found {
+			t.Fatalf("[async %v] role %s not stored", async, role.GlobalRole.Id)
+		}
+		_, err = client.CreateGlobalRole(&types.GlobalRole{Name: "role2", Description: "second"})
+		if err != nil {
+			t.Fatalf("[async %v] error creating role: %s", async, err)
+		}
+
+		role.GlobalRole.Description = "updated"
+		role, err = role.Update()
+		if err != nil {
+			t.Fatalf("[async %v] error updating role: %s", async, err)
+		}
+		if role.GlobalRole.Description != "updated" {
+			t.Errorf("[async %v] expected updated description, got '%s'", async, role.GlobalRole.Description)
+		}
+
+		found, err := client.GetGlobalRoleByName("role1")
+		if err != nil {
+			t.Fatalf("[async %v] error retrieving role by name: %s", async, err)
+		}
+		if found.GlobalRole.Id != role.GlobalRole.Id {
+			t.Errorf("[async %v] expected role %s, got %s", async, role.GlobalRole.Id, found.GlobalRole.Id)
+		}
+		roles, err := client.GetAllGlobalRoles(url.Values{"filter": {"name==role*"}, "sortDesc": {"name"}, "pageSize": {"1"}})
+		if err != nil {
+			t.Fatalf("[async %v] error retrieving roles: %s", async, err)
+		}
+		if len(roles) != 2 || roles[0].GlobalRole.Name != "role2" {
+			t.Errorf("[async %v] expected role2 and role1, got %d roles", async, len(roles))
+		}
+
+		err = role.Delete()
+		if err != nil {
+			t.Fatalf("[async %v] error deleting role: %s", async, err)
+		}
+		_, err = client.GetGlobalRoleById(role.GlobalRole.Id)
+		if !govcd.ContainsNotFound(err) {
+			t.Errorf("[async %v] expected not found error, got %v", async, err)
+		}
+		server.Close()
+	}
+}
+
+func TestServer_Query(t *testing.T) {
+	server := NewServer()
+	defer server.Close()
+	server.AddQueryRecord(types.QtOrg, "OrgRecord", map[string]string{"name": "org1", "href": server.URL + "/api/org/1"})
+	server.AddQueryRecord(types.QtOrg, "OrgRecord", map[string]string{"name": "org2", "href": server.URL + "/api/org/2"})
+
+	vcdClient := newTestClient(t, server)
+	results, err := vcdClient.Client.QueryWithNotEncodedParams(nil, map[string]string{
+		"type":   types.QtOrg,
+		"filter": "name==org2",
+	})
+	if err != nil {
+		t.Fatalf("error querying orgs: %s", err)
+	}
+	records := results.Results.OrgRecord
+	if len(records) != 1 || records[0].Name != "org2" {
+		t.Errorf("expected only org2, got %d records", len(records))
+	}
+}
+
+func TestServer_Metadata(t *testing.T) {
+	server := NewServer()
+	defer server.Close()
+	vcdClient := newTestClient(t, server)
+
+	vappHref := server.URL + "/api/vApp/vapp-11111111-2222-3333-4444-555555555555"
+	err := vcdClient.AddMetadataEntryByHref(vappHref, types.MetadataStringValue, "env", "test")
+	if err != nil {
+		t.Fatalf("error adding metadata: %s", err)
+	}
+	server.SetMetadata("/api/vApp/vapp-11111111-2222-3333-4444-555555555555", "owner", "team1")
+
+	metadata, err := vcdClient.GetMetadataByHref(vappHref)
+	if err != nil {
+		t.Fatalf("error retrieving metadata: %s", err)
+	}
+	if len(metadata.MetadataEntry) != 2 || metadata.MetadataEntry[0].Key != "env" || metadata.MetadataEntry[0].TypedValue.Value != "test" {
+		t.Fatalf("unexpected metadata entries %v", metadata.MetadataEntry)
+	}
+
+	err = vcdClient.DeleteMetadataEntryByHref(vappHref, "env")
+	if err != nil {
+		t.Fatalf("error deleting metadata: %s", err)
+	}
+	entries := server.Metadata("/api/vApp/vapp-11111111-2222-3333-4444-555555555555")
+	if len(entries) != 1 || entries["owner"] != "team1" {
+		t.Errorf("unexpected metadata after deletion %v", entries)
+	}
+	if err = vcdClient.DeleteMetadataEntryByHref(vappHref, "env"); err == nil {
+		t.Errorf("expected an error deleting a missing entry")
+	}
+}
@@ -0,0 +1,43 @@
+//go:build unit || ALL
+
+package vcd
+
+import (
+	"testing"
+
+	"github.com/vmware/terraform-provider-vcd/v4/vcd/mockvcd"
+)
+
+// newMockVcdClient returns a provider client connected as System administrator to the given mock server
+func newMockVcdClient(t *testing.T, server *mockvcd.Server) *VCDClient {
+	config := Config{
+		User:            mockvcd.DefaultUser,
+		Password:        mockvcd.DefaultPassword,
+		SysOrg:          "System",
+		Org:             "System",
+		Href:            server.ApiHref(),
+		InsecureFlag:    true,
+		MaxRetryTimeout: 10,
+	}
+	vcdClient, err := config.Client()
+	if err != nil {
+		t.Fatalf("error connecting to the mock VCD: %s", err)
+	}
+	return vcdClient
+}
+
+func TestConfig_ClientWithMockVcd(t *testing.T) {
+	server := mockvcd.NewServer()
+	defer server.Close()
+
+	vcdClient := newMockVcdClient(t, server)
+	if vcdClient.Client.IsTm() {
+		t.Errorf("expected a VCD, got Tenant Manager")
+	}
+
+	config := Config{User: mockvcd.DefaultUser, Password: "wrong", SysOrg: "System", Href: server.ApiHref(), InsecureFlag: true}
+	_, err := config.Client()
+	if err == nil {
+		t.Errorf("expected authentication error with wrong password")
+	}
+}
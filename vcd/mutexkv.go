@@ -1,70 +1,252 @@
 package vcd
 
 import (
+	"context"
+	"fmt"
 	"log"
+	"os"
+	"runtime"
+	"sort"
+	"strings"
 	"sync"
+	"time"
 )
 
 // Imported from Hashicorp (https://www.terraform.io/docs/extend/guides/v2-upgrade-guide.html)
 
+// lockWaitWarning is the time waited for a lock after which a warning with the lock holders is logged. It is set with
+// the VCD_LOCK_WAIT_WARNING environment variable (e.g. "2m"). The default value of 0 disables the warnings, together
+// with the tracking of the lock holders
+var lockWaitWarning = getLockWaitWarning()
+
 // mutexKV is a simple key/value store for arbitrary mutexes. It can be used to
 // serialize changes across arbitrary collaborators that share knowledge of the
 // keys they must serialize on.
 //
 // The initial use case is to let aws_security_group_rule resources serialize
 // their access to individual security groups based on SG ID.
+//
+// The mutexes can be acquired in exclusive (kvLock) or shared (kvRLock) mode. When lock wait warnings are enabled, the
+// current holders of each key are tracked, so that long waits can be logged together with the operations that caused
+// them.
 type mutexKV struct {
-	lock   sync.Mutex
-	store  map[string]*sync.Mutex
-	silent bool
+	lock    sync.Mutex
+	store   map[string]*sync.RWMutex
+	holders map[string][]lockHolder
+	silent  bool
+}
+
+// lockHolder describes the operation holding a lock. The caller is the function that requested the lock
+type lockHolder struct {
+	caller    string
+	exclusive bool
+	since     time.Time
+}
+
+func (h lockHolder) String() string {
+	mode := "shared"
+	if h.exclusive {
+		mode = "exclusive"
+	}
+	return fmt.Sprintf("%s (%s, held for %s)", h.caller, mode, time.Since(h.since).Round(time.Second))
 }
 
 // Locks the mutex for the given key. Caller is responsible for calling kvUnlock
 // for the same key
 func (m *mutexKV) kvLock(key string) {
+	m.acquire(context.Background(), key, true, m.holder())
+}
+
+// kvLockContext is kvLock for a traced operation, whose context is used to trace the wait for the lock
+func (m *mutexKV) kvLockContext(ctx context.Context, key string) {
+	m.acquire(ctx, key, true, m.holder())
+}
+
+// kvUnlock the mutex for the given key. Caller must have called kvLock for the same key first
+func (m *mutexKV) kvUnlock(key string) {
+	m.release(key, true, m.holder())
+}
+
+// kvRLock locks the mutex for the given key in shared mode: other kvRLock calls for the same key can proceed, while
+// kvLock calls wait until all shared holders have called kvRUnlock. A goroutine must not call kvRLock twice for the
+// same key without releasing it in between
+func (m *mutexKV) kvRLock(key string) {
+	m.acquire(context.Background(), key, false, m.holder())
+}
+
+// kvRLockContext is kvRLock for a traced operation, whose context is used to trace the wait for the lock
+func (m *mutexKV) kvRLockContext(ctx context.Context, key string) {
+	m.acquire(ctx, key, false, m.holder())
+}
+
+// kvRUnlock unlocks the mutex for the given key. Caller must have called kvRLock for the same key first
+func (m *mutexKV) kvRUnlock(key string) {
+	m.release(key, false, m.holder())
+}
+
+// holder returns the function that requests a lock or its release, which identifies the holder of the lock. It is
+// empty when the lock wait warnings are disabled, as finding the caller is not free
+func (m *mutexKV) holder() string {
+	if lockWaitWarning <= 0 {
+		return ""
+	}
+	return lockCaller()
+}
+
+// acquire locks the given key on behalf of the given holder. An empty holder is not tracked
+func (m *mutexKV) acquire(ctx context.Context, key string, exclusive bool, holder string) {
+	mode := "shared"
+	if exclusive {
+		mode = "exclusive"
+	}
 	if !m.silent {
-		log.Printf("[DEBUG] Locking %q", key)
+		log.Printf("[DEBUG] Locking %q (%s)", key, mode)
+	}
+	var holdersBefore []string
+	if holder != "" {
+		holdersBefore = m.holderDescriptions(key)
 	}
-	m.get(key).Lock()
+	start := time.Now()
+	endWait := traceLockWait(ctx, key, mode)
+
+	mutex := m.get(key)
+	if exclusive {
+		mutex.Lock()
+	} else {
+		mutex.RLock()
+	}
+
+	endWait()
 	if !m.silent {
-		log.Printf("[DEBUG] Locked %q", key)
+		log.Printf("[DEBUG] Locked %q (%s)", key, mode)
+	}
+	if holder == "" {
+		return
+	}
+	waited := time.Since(start)
+	m.lock.Lock()
+	m.holders[key] = append(m.holders[key], lockHolder{caller: holder, exclusive: exclusive, since: time.Now()})
+	m.lock.Unlock()
+
+	if !m.silent && waited >= lockWaitWarning {
+		log.Printf("[WARN] %s waited %s for lock %q (%s). Holders when the wait started: %s",
+			holder, waited.Round(time.Second), key, mode, strings.Join(holdersBefore, ", "))
 	}
 }
 
-// kvUnlock the mutex for the given key. Caller must have called kvLock for the same key first
-func (m *mutexKV) kvUnlock(key string) {
+// release unlocks the given key on behalf of the given holder. Locks are usually released by the function that
+// acquired them: the latest acquisition of the holder in the same mode is removed from the tracked holders or, when
+// the holder has none, the oldest one in that mode
+func (m *mutexKV) release(key string, exclusive bool, holder string) {
 	if !m.silent {
 		log.Printf("[DEBUG] Unlocking %q", key)
 	}
-	m.get(key).Unlock()
+	m.lock.Lock()
+	if holders := m.holders[key]; len(holders) > 0 {
+		found := -1
+		for i := len(holders) - 1; i >= 0; i-- {
+			if holders[i].exclusive != exclusive {
+				continue
+			}
+			found = i
+			if holders[i].caller == holder {
+				break
+			}
+		}
+		if found >= 0 {
+			holders = append(holders[:found:found], holders[found+1:]...)
+		}
+		if len(holders) == 0 {
+			delete(m.holders, key)
+		} else {
+			m.holders[key] = holders
+		}
+	}
+	m.lock.Unlock()
+
+	if exclusive {
+		m.get(key).Unlock()
+	} else {
+		m.get(key).RUnlock()
+	}
 	if !m.silent {
 		log.Printf("[DEBUG] Unlocked %q", key)
 	}
 }
 
+// holderDescriptions returns a sorted description of the current holders of the given key
+func (m *mutexKV) holderDescriptions(key string) []string {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	descriptions := make([]string, 0, len(m.holders[key]))
+	for _, holder := range m.holders[key] {
+		descriptions = append(descriptions, holder.String())
+	}
+	if len(descriptions) == 0 {
+		return []string{"none"}
+	}
+	sort.Strings(descriptions)
+	return descriptions
+}
+
 // Returns a mutex for the given key, no guarantee of its lock status
-func (m *mutexKV) get(key string) *sync.Mutex {
+func (m *mutexKV) get(key string) *sync.RWMutex {
 	m.lock.Lock()
 	defer m.lock.Unlock()
 	mutex, ok := m.store[key]
 	if !ok {
-		mutex = &sync.Mutex{}
+		mutex = &sync.RWMutex{}
 		m.store[key] = mutex
 	}
 	return mutex
 }
 
+// lockCaller returns the name of the function that requested a lock, skipping the mutexKV methods and the
+// VCDClient lock helpers
+func lockCaller() string {
+	pcs := make([]uintptr, 16)
+	frames := runtime.CallersFrames(pcs[:runtime.Callers(2, pcs)])
+	for {
+		frame, more := frames.Next()
+		function := frame.Function[strings.LastIndex(frame.Function, "/")+1:]
+		lowerFunction := strings.ToLower(function)
+		if !strings.Contains(function, "(*mutexKV)") && !strings.Contains(lowerFunction, ").lock") &&
+			!strings.Contains(lowerFunction, ").unlock") {
+			return function
+		}
+		if !more {
+			return "unknown"
+		}
+	}
+}
+
+// getLockWaitWarning reads the lock wait warning threshold from VCD_LOCK_WAIT_WARNING
+func getLockWaitWarning() time.Duration {
+	value := os.Getenv("VCD_LOCK_WAIT_WARNING")
+	if value == "" {
+		return 0
+	}
+	threshold, err := time.ParseDuration(value)
+	if err != nil {
+		log.Printf("[WARN] invalid VCD_LOCK_WAIT_WARNING '%s', lock wait warnings are disabled: %s", value, err)
+		return 0
+	}
+	return threshold
+}
+
 // Returns a properly initalized mutexKV
 func newMutexKV() *mutexKV {
 	return &mutexKV{
-		store: make(map[string]*sync.Mutex),
+		store:   make(map[string]*sync.RWMutex),
+		holders: make(map[string][]lockHolder),
 	}
 }
 
 // newMutexKVSilent returns a properly initalized mutexKV with the silent property set
 func newMutexKVSilent() *mutexKV {
 	return &mutexKV{
-		store:  make(map[string]*sync.Mutex),
-		silent: true,
+		store:   make(map[string]*sync.RWMutex),
+		holders: make(map[string][]lockHolder),
+		silent:  true,
 	}
 }
@@ -0,0 +1,159 @@
+//go:build unit || ALL
+
+package vcd
+
+import (
+	"bytes"
+	"log"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+// Test_mutexKVSharedLocks checks that shared locks on the same key are held concurrently, and that an exclusive lock
+// waits for them
+func Test_mutexKVSharedLocks(t *testing.T) {
+	previousWarning := lockWaitWarning
+	lockWaitWarning = time.Minute
+	defer func() {
+		lockWaitWarning = previousWarning
+	}()
+
+	m := newMutexKVSilent()
+	key := "org:org1|vdc:vdc1|vapp:vapp1"
+
+	m.kvRLock(key)
+	sharedAcquired := make(chan struct{})
+	go func() {
+		m.kvRLock(key)
+		close(sharedAcquired)
+		m.kvRUnlock(key)
+	}()
+	select {
+	case <-sharedAcquired:
+	case <-time.After(5 * time.Second):
+		t.Fatalf("shared lock not acquired while another shared lock is held")
+	}
+
+	exclusiveAcquired := make(chan struct{})
+	go func() {
+		m.kvLock(key)
+		close(exclusiveAcquired)
+		m.kvUnlock(key)
+	}()
+	select {
+	case <-exclusiveAcquired:
+		t.Fatalf("exclusive lock acquired while a shared lock is held")
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	holders := m.holderDescriptions(key)
+	if len(holders) != 1 || !strings.Contains(holders[0], "Test_mutexKVSharedLocks (shared") {
+		t.Errorf("expected the test as shared holder, got %v", holders)
+	}
+
+	m.kvRUnlock(key)
+	select {
+	case <-exclusiveAcquired:
+	case <-time.After(5 * time.Second):
+		t.Fatalf("exclusive lock not acquired after the shared lock was released")
+	}
+	if holders := m.holderDescriptions(key); len(holders) != 1 || holders[0] != "none" {
+		t.Errorf("expected no holders, got %v", holders)
+	}
+}
+
+// Test_mutexKVWaitWarning checks that a long wait for a lock is logged with the holder that caused it
+func Test_mutexKVWaitWarning(t *testing.T) {
+	var output bytes.Buffer
+	log.SetOutput(&output)
+	previousWarning := lockWaitWarning
+	lockWaitWarning = 50 * time.Millisecond
+	defer func() {
+		log.SetOutput(os.Stderr)
+		lockWaitWarning = previousWarning
+	}()
+
+	m := newMutexKV()
+	key := "urn:vcloud:gateway:test"
+	m.kvLock(key)
+	go func() {
+		time.Sleep(100 * time.Millisecond)
+		m.kvUnlock(key)
+	}()
+	m.kvLock(key)
+	m.kvUnlock(key)
+
+	logged := output.String()
+	if !strings.Contains(logged, `[WARN] vcd.Test_mutexKVWaitWarning waited`) ||
+		!strings.Contains(logged, "vcd.Test_mutexKVWaitWarning (exclusive") {
+		t.Errorf("expected lock wait warning with holder, got:\n%s", logged)
+	}
+}
+
+// Test_mutexKVUntracked checks that the holders are not tracked when the lock wait warnings are disabled
+func Test_mutexKVUntracked(t *testing.T) {
+	previousWarning := lockWaitWarning
+	lockWaitWarning = 0
+	defer func() {
+		lockWaitWarning = previousWarning
+	}()
+
+	m := newMutexKVSilent()
+	key := "urn:vcloud:gateway:test"
+	m.kvLock(key)
+	if holders := m.holderDescriptions(key); len(holders) != 1 || holders[0] != "none" {
+		t.Errorf("expected no tracked holders, got %v", holders)
+	}
+	m.kvUnlock(key)
+	if len(m.holders) != 0 {
+		t.Errorf("expected no holders, got %v", m.holders)
+	}
+}
+
+// Test_mutexKVHolderRelease checks that a release removes the acquisition of the function that releases the lock, even
+// when it is not the oldest one
+func Test_mutexKVHolderRelease(t *testing.T) {
+	previousWarning := lockWaitWarning
+	lockWaitWarning = time.Minute
+	defer func() {
+		lockWaitWarning = previousWarning
+	}()
+
+	m := newMutexKVSilent()
+	key := "org:org1|vdc:vdc1|vapp:vapp1"
+	lockAsFirstHolder(m, key, false)
+	lockAsSecondHolder(m, key, false)
+	if holders := m.holderDescriptions(key); len(holders) != 2 {
+		t.Fatalf("expected two holders, got %v", holders)
+	}
+
+	lockAsSecondHolder(m, key, true)
+	holders := m.holderDescriptions(key)
+	if len(holders) != 1 || !strings.Contains(holders[0], "lockAsFirstHolder (shared") {
+		t.Errorf("expected the first function as remaining holder, got %v", holders)
+	}
+
+	// A release from another function removes the oldest acquisition
+	m.kvRUnlock(key)
+	if len(m.holders) != 0 {
+		t.Errorf("expected no holders, got %v", m.holders)
+	}
+}
+
+func lockAsFirstHolder(m *mutexKV, key string, unlock bool) {
+	if unlock {
+		m.kvRUnlock(key)
+		return
+	}
+	m.kvRLock(key)
+}
+
+func lockAsSecondHolder(m *mutexKV, key string, unlock bool) {
+	if unlock {
+		m.kvRUnlock(key)
+		return
+	}
+	m.kvRLock(key)
+}
@@ -192,6 +192,14 @@ var globalDataSourceMap = map[string]*schema.Resource{
 	"vcd_tm_provider_gateway":                          datasourceVcdTmProviderGateway(),                       // 4.0
 	"vcd_tm_edge_cluster":                              datasourceVcdTmEdgeCluster(),                           // 4.0
 	"vcd_tm_edge_cluster_qos":                          datasourceVcdTmEdgeClusterQos(),                        // 4.0
+	"vcd_api_request":                                  datasourceVcdApiRequest(),                              // 4.0
+	"vcd_vms":                                          datasourceVcdVms(),                                     // 4.0
+	"vcd_org_vdcs":                                     datasourceVcdOrgVdcs(),                                 // 4.0
+	"vcd_network_routed_v2s":                           datasourceVcdNetworkRoutedV2s(),                        // 4.0
+	"vcd_nsxt_edgegateways":                            datasourceVcdNsxtEdgeGateways(),                        // 4.0
+	"vcd_nsxt_nat_rules":                               datasourceVcdNsxtNatRules(),                            // 4.0
+	"vcd_catalog_items":                                datasourceVcdCatalogItems(),                            // 4.0
+	"vcd_vm_metrics":                                   datasourceVcdVmMetrics(),                               // 4.0
 }
 
 var globalResourceMap = map[string]*schema.Resource{
@@ -328,10 +336,25 @@ var globalResourceMap = map[string]*schema.Resource{
 	"vcd_tm_ip_space":                                  resourceVcdTmIpSpace(),                               // 4.0
 	"vcd_tm_provider_gateway":                          resourceVcdTmProviderGateway(),                       // 4.0
 	"vcd_tm_edge_cluster_qos":                          resourceVcdTmEdgeClusterQos(),                        // 4.0
+	"vcd_openapi_entity":                               resourceVcdOpenApiEntity(),                           // 4.0
+	"vcd_metadata":                                     resourceVcdMetadata(),                                // 4.0
+	"vcd_openapi_metadata":                             resourceVcdOpenApiMetadata(),                         // 4.0
+	"vcd_vm_snapshot":                                  resourceVcdVmSnapshot(),                              // 4.0
+	"vcd_vapp_snapshot":                                resourceVcdVappSnapshot(),                            // 4.0
+	"vcd_vapp_template_export":                         resourceVcdVappTemplateExport(),                      // 4.0
+	"vcd_vm_network_adapter":                           resourceVcdVmNetworkAdapter(),                        // 4.0
+	"vcd_vm_independent_disk_attachment":               resourceVcdVmIndependentDiskAttachment(),             // 4.0
 }
 
 // Provider returns a terraform.ResourceProvider.
 func Provider() *schema.Provider {
+	initTracing()
+	resources, dataSources := globalResourceMap, globalDataSourceMap
+	if tracer != nil {
+		resources = tracedResourceMap("resource", globalResourceMap)
+		dataSources = tracedResourceMap("data_source", globalDataSourceMap)
+	}
+
 	return &schema.Provider{
 		Schema: map[string]*schema.Schema{
 			"user": {
@@ -466,6 +489,40 @@ func Provider() *schema.Provider {
 				DefaultFunc: schema.EnvDefaultFunc("VCD_API_LOGGING_FILE", "go-vcloud-director.log"),
 				Description: "Defines the full name of the logging file for API calls (requires 'logging')",
 			},
+			"api_recording_mode": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				DefaultFunc:  schema.EnvDefaultFunc("VCD_API_RECORDING_MODE", ""),
+				ValidateFunc: validation.StringInSlice([]string{"", apiRecordingModeRecord, apiRecordingModeReplay}, false),
+				Description: "If set to 'record', the API requests and responses are saved to 'api_recording_file', with " +
+					"credentials removed. If set to 'replay', the responses are served from 'api_recording_file' without contacting VCD",
+			},
+			"api_recording_file": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				DefaultFunc: schema.EnvDefaultFunc("VCD_API_RECORDING_FILE", "vcd-api-recording.jsonl"),
+				Description: "Defines the file where the API interactions are recorded or replayed from (requires 'api_recording_mode')",
+			},
+			"distributed_locks": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				DefaultFunc: schema.EnvDefaultFunc("VCD_DISTRIBUTED_LOCKS", false),
+				Description: "If true, operations on Edge Gateways, VDC Groups and vApps are also serialized with other provider processes, using leases stored as metadata of the entities",
+			},
+			"distributed_lock_ttl": {
+				Type:         schema.TypeInt,
+				Optional:     true,
+				DefaultFunc:  schema.EnvDefaultFunc("VCD_DISTRIBUTED_LOCK_TTL", int(defaultDistributedLockTtl.Seconds())),
+				Description:  "Duration in seconds of the leases used by 'distributed_locks'. The leases are renewed while the operations run",
+				ValidateFunc: validation.IntAtLeast(10),
+			},
+			"distributed_lock_timeout": {
+				Type:         schema.TypeInt,
+				Optional:     true,
+				DefaultFunc:  schema.EnvDefaultFunc("VCD_DISTRIBUTED_LOCK_TIMEOUT", int(defaultDistributedLockTimeout.Seconds())),
+				Description:  "Maximum time in seconds to wait for a lease held by another process when 'distributed_locks' is enabled",
+				ValidateFunc: validation.IntAtLeast(1),
+			},
 			"import_separator": {
 				Type:        schema.TypeString,
 				Optional:    true,
@@ -474,8 +531,8 @@ func Provider() *schema.Provider {
 			},
 			"ignore_metadata_changes": ignoreMetadataSchema(),
 		},
-		ResourcesMap:         globalResourceMap,
-		DataSourcesMap:       globalDataSourceMap,
+		ResourcesMap:         resources,
+		DataSourcesMap:       dataSources,
 		ConfigureContextFunc: providerConfigure,
 	}
 }
@@ -509,6 +566,11 @@ func providerConfigure(_ context.Context, d *schema.ResourceData) (interface{},
 		Href:                    d.Get("url").(string),
 		MaxRetryTimeout:         maxRetryTimeout,
 		InsecureFlag:            d.Get("allow_unverified_ssl").(bool),
+		ApiRecordingMode:        d.Get("api_recording_mode").(string),
+		ApiRecordingFile:        d.Get("api_recording_file").(string),
+		DistributedLocks:        d.Get("distributed_locks").(bool),
+		DistributedLockTtl:      d.Get("distributed_lock_ttl").(int),
+		DistributedLockTimeout:  d.Get("distributed_lock_timeout").(int),
 	}
 
 	// auth_type dependent configuration
@@ -67,6 +67,7 @@ func createTemporaryVCDConnection(acceptNil bool) *VCDClient {
 		InsecureFlag:    testConfig.Provider.AllowInsecure,
 		MaxRetryTimeout: testConfig.Provider.MaxRetryTimeout,
 	}
+	config.ApiRecordingMode, config.ApiRecordingFile = getTestApiRecordingSettings()
 	conn, err := config.Client()
 	if err != nil {
 		if acceptNil {
@@ -110,6 +111,7 @@ func createSystemTemporaryVCDConnection() *VCDClient {
 		InsecureFlag:    configStruct.Provider.AllowInsecure,
 		MaxRetryTimeout: configStruct.Provider.MaxRetryTimeout,
 	}
+	config.ApiRecordingMode, config.ApiRecordingFile = getTestApiRecordingSettings()
 	conn, err := config.Client()
 	if err != nil {
 		panic("unable to initialize VCD connection :" + err.Error())
@@ -117,6 +119,16 @@ func createSystemTemporaryVCDConnection() *VCDClient {
 	return conn
 }
 
+// getTestApiRecordingSettings returns the API recording mode and file set in the environment, so that the connections
+// created by the tests are recorded or replayed together with the ones of the provider
+func getTestApiRecordingSettings() (string, string) {
+	fileName := os.Getenv("VCD_API_RECORDING_FILE")
+	if fileName == "" {
+		fileName = "vcd-api-recording.jsonl"
+	}
+	return os.Getenv("VCD_API_RECORDING_MODE"), fileName
+}
+
 // minIfLess returns:
 // `min` if `value` is less than min
 // `value` if `value` > `min`
@@ -3,6 +3,10 @@ package vcd
 import (
 	"context"
 	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
@@ -236,6 +240,10 @@ type dsReadConfig[O any, I any] struct {
 	// overrideDefaultNameField permits to override default field ('name') that passed to
 	// getEntityFunc. The field must be a string (schema.TypeString)
 	overrideDefaultNameField string
+
+	// filterConfig enables the lookup by a 'filter' block built with openApiFilterSchema. When the
+	// block is set, it is used instead of getEntityFunc
+	filterConfig *openApiFilterConfig[O]
 }
 
 // readDatasource will read a data source by a 'name' field in Terraform schema
@@ -246,6 +254,18 @@ func readDatasource[O any, I any](_ context.Context, d *schema.ResourceData, met
 		return diag.Errorf("error executing pre-read %s hooks: %s", c.entityLabel, err)
 	}
 
+	if filter, hasFilter := d.GetOk("filter"); hasFilter && c.filterConfig != nil {
+		retrievedEntity, err := getOpenApiEntityByFilter(&vcdClient.Client, filter, c.entityLabel, *c.filterConfig)
+		if err != nil {
+			return diag.Errorf("error getting %s by filter: %s", c.entityLabel, err)
+		}
+		err = c.stateStoreFunc(vcdClient, d, retrievedEntity)
+		if err != nil {
+			return diag.Errorf("error storing %s to state during data source read: %s", c.entityLabel, err)
+		}
+		return nil
+	}
+
 	fieldName := "name"
 	if c.overrideDefaultNameField != "" {
 		fieldName = c.overrideDefaultNameField
@@ -264,3 +284,182 @@ func readDatasource[O any, I any](_ context.Context, d *schema.ResourceData, met
 
 	return nil
 }
+
+// listDatasourceWorkers is the maximum number of entities of a list data source whose details are read at the same time
+const listDatasourceWorkers = 8
+
+// dsListConfig is the list variant of dsReadConfig. It is used by data sources that retrieve all the entities matching
+// an optional server-side FIQL filter, and store each of them as an item with the same fields of the data source that
+// retrieves a single entity.
+type dsListConfig[O any] struct {
+	// entityLabel to use
+	entityLabel string
+
+	// itemDatasource is the data source that retrieves a single entity. Its schema defines the fields of each item
+	itemDatasource *schema.Resource
+
+	// stateStoreFunc is responsible for storing a single entity into a ResourceData of 'itemDatasource'
+	stateStoreFunc func(vcdClient *VCDClient, d *schema.ResourceData, outerType O) error
+
+	// getEntitiesFunc is a function that retrieves all the entities. The FIQL filter of the data source, if set,
+	// is passed in the 'filter' query parameter
+	getEntitiesFunc func(queryParameters url.Values) ([]O, error)
+
+	// preReadHooks will be executed before the entities are retrieved
+	preReadHooks []schemaHook
+}
+
+// listDatasourceSchema returns the schema of a list data source, made of the given scope fields, an optional FIQL
+// 'filter' and the computed 'items', which have the same fields as the data source that retrieves a single entity
+func listDatasourceSchema(entityLabel string, itemDatasource *schema.Resource, scope map[string]*schema.Schema) map[string]*schema.Schema {
+	itemSchema := computedSchemaCopy(itemDatasource.Schema)
+	itemSchema["id"] = &schema.Schema{
+		Type:        schema.TypeString,
+		Computed:    true,
+		Description: fmt.Sprintf("ID of the %s", entityLabel),
+	}
+	result := map[string]*schema.Schema{
+		"filter": {
+			Type:        schema.TypeString,
+			Optional:    true,
+			Description: fmt.Sprintf("FIQL filter applied by VCD when retrieving the %ss (e.g. 'name==web*')", entityLabel),
+		},
+		"items": {
+			Type:        schema.TypeList,
+			Computed:    true,
+			Description: fmt.Sprintf("List of %ss, with the same attributes of the data source that retrieves a single one", entityLabel),
+			Elem: &schema.Resource{
+				Schema: itemSchema,
+			},
+		},
+	}
+	for key, value := range scope {
+		result[key] = value
+	}
+	return result
+}
+
+// computedSchemaCopy returns a copy of the given schema in which all the fields are computed, so that it can be used
+// to store the attributes of an entity. 'filter' blocks are removed, as they only make sense as lookup arguments
+func computedSchemaCopy(source map[string]*schema.Schema) map[string]*schema.Schema {
+	result := make(map[string]*schema.Schema)
+	for key, value := range source {
+		if key == "filter" {
+			continue
+		}
+		field := &schema.Schema{
+			Type:        value.Type,
+			Computed:    true,
+			Description: value.Description,
+			Elem:        value.Elem,
+			Set:         value.Set,
+		}
+		if nested, ok := value.Elem.(*schema.Resource); ok {
+			field.Elem = &schema.Resource{Schema: computedSchemaCopy(nested.Schema)}
+		}
+		result[key] = field
+	}
+	return result
+}
+
+// readDatasourceList will read all the entities of a list data source and store them in 'items'
+func readDatasourceList[O any](_ context.Context, d *schema.ResourceData, meta interface{}, c dsListConfig[O]) diag.Diagnostics {
+	vcdClient := meta.(*VCDClient)
+	err := execSchemaHook(vcdClient, d, c.preReadHooks)
+	if err != nil {
+		return diag.Errorf("error executing pre-read %s hooks: %s", c.entityLabel, err)
+	}
+
+	queryParameters := url.Values{}
+	if filter := d.Get("filter").(string); filter != "" {
+		queryParameters.Set("filter", filter)
+	}
+	retrievedEntities, err := c.getEntitiesFunc(queryParameters)
+	if err != nil {
+		return diag.Errorf("error getting list of %s: %s", c.entityLabel, err)
+	}
+
+	// The details of some entities need further requests, which are run in parallel to reduce the time of the read
+	itemsData := make([]*schema.ResourceData, len(retrievedEntities))
+	itemErrors := make([]error, len(retrievedEntities))
+	var wg sync.WaitGroup
+	workers := make(chan struct{}, listDatasourceWorkers)
+	for index, entity := range retrievedEntities {
+		wg.Add(1)
+		workers <- struct{}{}
+		go func(index int, entity O) {
+			defer func() {
+				<-workers
+				wg.Done()
+			}()
+			itemsData[index], itemErrors[index] = readDatasourceListItem(vcdClient, c, entity)
+		}(index, entity)
+	}
+	wg.Wait()
+
+	itemSchema := computedSchemaCopy(c.itemDatasource.Schema)
+	var ids []string
+	var items []interface{}
+	for index, itemData := range itemsData {
+		if itemErrors[index] != nil {
+			return diag.Errorf("error storing %s to state during data source read: %s", c.entityLabel, itemErrors[index])
+		}
+		// The entity can disappear between the list and the read of its details
+		if itemData.Id() == "" {
+			continue
+		}
+		item := map[string]interface{}{
+			"id": itemData.Id(),
+		}
+		for key := range itemSchema {
+			item[key] = itemData.Get(key)
+		}
+		ids = append(ids, itemData.Id())
+		items = append(items, item)
+	}
+
+	err = d.Set("items", items)
+	if err != nil {
+		return diag.Errorf("error storing list of %s: %s", c.entityLabel, err)
+	}
+	d.SetId(strconv.Itoa(hashcodeString(c.entityLabel + ":" + strings.Join(ids, ","))))
+	return nil
+}
+
+// readDatasourceListItem stores a single entity of a list data source into a ResourceData of 'itemDatasource'
+func readDatasourceListItem[O any](vcdClient *VCDClient, c dsListConfig[O], entity O) (*schema.ResourceData, error) {
+	itemData := c.itemDatasource.Data(nil)
+	// Default values are not applied to an empty ResourceData, but some read functions depend on them
+	for key, field := range c.itemDatasource.Schema {
+		if field.Default != nil {
+			err := itemData.Set(key, field.Default)
+			if err != nil {
+				return nil, fmt.Errorf("error setting default value of '%s': %s", key, err)
+			}
+		}
+	}
+	err := c.stateStoreFunc(vcdClient, itemData, entity)
+	if err != nil {
+		return nil, err
+	}
+	return itemData, nil
+}
+
+// readWithItemDatasource returns a stateStoreFunc for dsListConfig that sets the lookup fields of a single
+// entity and runs the read function of 'itemDatasource'. It is used for entities that are not retrieved with all
+// their attributes by the list operation
+func readWithItemDatasource[O any](itemDatasource *schema.Resource, lookupFieldsFunc func(O) map[string]string) func(*VCDClient, *schema.ResourceData, O) error {
+	return func(vcdClient *VCDClient, d *schema.ResourceData, entity O) error {
+		for key, value := range lookupFieldsFunc(entity) {
+			dSet(d, key, value)
+		}
+		diags := itemDatasource.ReadContext(context.Background(), d, vcdClient)
+		for _, diagnostic := range diags {
+			if diagnostic.Severity == diag.Error {
+				return fmt.Errorf("%s", diagnostic.Summary)
+			}
+			util.Logger.Printf("[WARN] %s: %s", diagnostic.Summary, diagnostic.Detail)
+		}
+		return nil
+	}
+}
@@ -0,0 +1,140 @@
+//go:build unit || ALL
+
+package vcd
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// testListEntity is a simple entity used to test the generic list data source
+type testListEntity struct {
+	id   string
+	name string
+	tags []string
+}
+
+func testListItemDatasource() *schema.Resource {
+	return &schema.Resource{
+		Schema: map[string]*schema.Schema{
+			"name": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				ExactlyOneOf: []string{"name", "filter"},
+			},
+			"filter": {
+				Type:     schema.TypeList,
+				Optional: true,
+				MaxItems: 1,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"name_regex": {Type: schema.TypeString, Optional: true},
+					},
+				},
+			},
+			"read_limit": {
+				Type:     schema.TypeInt,
+				Optional: true,
+				Default:  10,
+			},
+			"tags": {
+				Type:     schema.TypeSet,
+				Computed: true,
+				Elem:     &schema.Schema{Type: schema.TypeString},
+			},
+		},
+	}
+}
+
+func Test_readDatasourceList(t *testing.T) {
+	itemDatasource := testListItemDatasource()
+	listDatasource := &schema.Resource{
+		Schema: listDatasourceSchema("test entity", itemDatasource, map[string]*schema.Schema{
+			"org": {Type: schema.TypeString, Optional: true},
+		}),
+	}
+	if err := listDatasource.InternalValidate(nil, false); err != nil {
+		t.Fatalf("invalid list data source schema: %s", err)
+	}
+	if _, found := listDatasource.Schema["items"].Elem.(*schema.Resource).Schema["filter"]; found {
+		t.Fatalf("'filter' should not be part of the items")
+	}
+
+	var receivedFilter string
+	c := dsListConfig[*testListEntity]{
+		entityLabel:    "test entity",
+		itemDatasource: itemDatasource,
+		getEntitiesFunc: func(queryParameters url.Values) ([]*testListEntity, error) {
+			receivedFilter = queryParameters.Get("filter")
+			return []*testListEntity{
+				{id: "urn:vcloud:test:1", name: "first", tags: []string{"a", "b"}},
+				{id: "", name: "deleted"},
+				{id: "urn:vcloud:test:2", name: "second"},
+			}, nil
+		},
+		stateStoreFunc: func(_ *VCDClient, d *schema.ResourceData, entity *testListEntity) error {
+			if d.Get("read_limit").(int) != 10 {
+				return fmt.Errorf("default value of 'read_limit' was not set")
+			}
+			dSet(d, "name", entity.name)
+			err := d.Set("tags", entity.tags)
+			if err != nil {
+				return err
+			}
+			d.SetId(entity.id)
+			return nil
+		},
+	}
+
+	d := listDatasource.Data(nil)
+	dSet(d, "filter", "name==f*")
+	diags := readDatasourceList(context.Background(), d, &VCDClient{}, c)
+	if diags.HasError() {
+		t.Fatalf("error reading list: %v", diags)
+	}
+	if receivedFilter != "name==f*" {
+		t.Errorf("expected filter 'name==f*', got '%s'", receivedFilter)
+	}
+	if d.Id() == "" {
+		t.Errorf("expected the ID of the list data source to be set")
+	}
+	if d.Get("items.#").(int) != 2 {
+		t.Fatalf("expected 2 items, got %d", d.Get("items.#").(int))
+	}
+	expected := map[string]interface{}{
+		"items.0.id":         "urn:vcloud:test:1",
+		"items.0.name":       "first",
+		"items.0.read_limit": 10,
+		"items.0.tags.#":     2,
+		"items.1.id":         "urn:vcloud:test:2",
+		"items.1.name":       "second",
+		"items.1.tags.#":     0,
+	}
+	for key, want := range expected {
+		if got := d.Get(key); got != want {
+			t.Errorf("%s = %v, want %v", key, got, want)
+		}
+	}
+}
+
+func Test_joinFiqlFilters(t *testing.T) {
+	tests := []struct {
+		filters []string
+		want    string
+	}{
+		{filters: nil, want: ""},
+		{filters: []string{"", ""}, want: ""},
+		{filters: []string{"name==a"}, want: "name==a"},
+		{filters: []string{"vdc==x", "", "name==a;status==on"}, want: "vdc==x;name==a;status==on"},
+		{filters: []string{"vdc==x", "name==a,name==b"}, want: "vdc==x;(name==a,name==b)"},
+	}
+	for _, tt := range tests {
+		if got := joinFiqlFilters(tt.filters...); got != tt.want {
+			t.Errorf("joinFiqlFilters(%v) = '%s', want '%s'", tt.filters, got, tt.want)
+		}
+	}
+}
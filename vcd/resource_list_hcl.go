@@ -0,0 +1,274 @@
+package vcd
+
+// This file contains the generation of full HCL resource definitions used by vcd_resource_list in 'hcl' list mode
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// hclResourceData holds a listed entity, together with the state obtained by reading it
+type hclResourceData struct {
+	entry    resourceListEntry
+	resource *schema.Resource
+	data     *schema.ResourceData
+	err      error
+}
+
+// generateResourceListHcl reads every listed entity using the importer and the read function of its resource,
+// and returns the import block and the HCL definition of each of them.
+// Entities that can't be read get no import block, as Terraform would reject an import without its resource.
+// All entities are read before writing anything, so that the IDs found in the attributes can be replaced by
+// references to other generated resources
+func generateResourceListHcl(ctx context.Context, meta interface{}, entries []resourceListEntry) (string, error) {
+	var items []hclResourceData
+	references := make(map[string]string)
+	for _, entry := range entries {
+		resource, ok := globalResourceMap[entry.resourceType]
+		if !ok {
+			return "", fmt.Errorf("resource type '%s' is not handled by this provider", entry.resourceType)
+		}
+		data, err := readResourceForHcl(ctx, meta, resource, entry.importId())
+		items = append(items, hclResourceData{
+			entry:    entry,
+			resource: resource,
+			data:     data,
+			err:      err,
+		})
+		if err != nil {
+			continue
+		}
+		reference := fmt.Sprintf("%s.%s.id", entry.resourceType, entry.address())
+		references[data.Id()] = reference
+		if entry.ref.id != "" {
+			references[entry.ref.id] = reference
+		}
+	}
+
+	var hcl strings.Builder
+	for _, item := range items {
+		if item.err != nil {
+			// A failed read should not prevent the generation of the other resources
+			hcl.WriteString(fmt.Sprintf("# Error reading %s %s: %s\n\n", item.entry.resourceType, item.entry.importId(),
+				strings.ReplaceAll(item.err.Error(), "\n", " ")))
+			continue
+		}
+		values := make(map[string]interface{})
+		for name := range item.resource.Schema {
+			values[name] = item.data.Get(name)
+		}
+		hcl.WriteString(item.entry.importBlock())
+		hcl.WriteString(fmt.Sprintf("resource %q %q {\n", item.entry.resourceType, item.entry.address()))
+		writeHclBody(&hcl, item.resource.Schema, values, "  ", references, item.data.Id())
+		hcl.WriteString("}\n\n")
+	}
+	return hcl.String(), nil
+}
+
+// readResourceForHcl runs the importer of the given resource with the import ID, and then reads the
+// imported entity, in the same way Terraform does during an import
+func readResourceForHcl(ctx context.Context, meta interface{}, resource *schema.Resource, importId string) (*schema.ResourceData, error) {
+	if resource.Importer == nil {
+		return nil, fmt.Errorf("resource does not support import")
+	}
+	data := resource.Data(nil)
+	data.SetId(importId)
+
+	var imported []*schema.ResourceData
+	var err error
+	switch {
+	case resource.Importer.StateContext != nil:
+		imported, err = resource.Importer.StateContext(ctx, data, meta)
+	case resource.Importer.State != nil:
+		imported, err = resource.Importer.State(data, meta)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error importing: %s", err)
+	}
+	if len(imported) > 0 {
+		data = imported[0]
+	}
+
+	var diags diag.Diagnostics
+	switch {
+	case resource.ReadContext != nil:
+		diags = resource.ReadContext(ctx, data, meta)
+	case resource.Read != nil:
+		err = resource.Read(data, meta)
+		if err != nil {
+			diags = diag.FromErr(err)
+		}
+	}
+	if diags != nil && diags.HasError() {
+		return nil, fmt.Errorf("error reading: %s", diags[0].Summary)
+	}
+	if data.Id() == "" {
+		return nil, fmt.Errorf("entity not found")
+	}
+	return data, nil
+}
+
+// writeHclBody writes the configurable attributes and blocks from values, following the given schema
+func writeHclBody(hcl *strings.Builder, schemaMap map[string]*schema.Schema, values map[string]interface{}, indent string,
+	references map[string]string, selfId string) {
+
+	var names []string
+	for name := range schemaMap {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		s := schemaMap[name]
+		// Computed only and deprecated attributes can't (or shouldn't) be set in a configuration
+		if (!s.Optional && !s.Required) || s.Deprecated != "" {
+			continue
+		}
+		value := normalizeHclValue(values[name])
+		if !s.Required && isDefaultHclValue(s, value) {
+			continue
+		}
+		if s.Sensitive {
+			hcl.WriteString(fmt.Sprintf("%s# %s is sensitive and must be filled manually\n", indent, name))
+			continue
+		}
+
+		if elemResource, ok := s.Elem.(*schema.Resource); ok && (s.Type == schema.TypeList || s.Type == schema.TypeSet) {
+			blocks, _ := value.([]interface{})
+			for _, block := range blocks {
+				blockValues, ok := block.(map[string]interface{})
+				if !ok {
+					continue
+				}
+				hcl.WriteString(fmt.Sprintf("%s%s {\n", indent, name))
+				writeHclBody(hcl, elemResource.Schema, blockValues, indent+"  ", references, selfId)
+				hcl.WriteString(fmt.Sprintf("%s}\n", indent))
+			}
+			continue
+		}
+		// Only the attributes that refer to other resources get references, so that a free text equal to an ID is kept
+		valueReferences := references
+		if !isHclReferenceAttribute(name) {
+			valueReferences = nil
+		}
+		hcl.WriteString(fmt.Sprintf("%s%s = %s\n", indent, name, hclValue(value, indent, valueReferences, selfId)))
+	}
+}
+
+// isHclReferenceAttribute returns true if the attribute with the given name contains the IDs of other resources
+func isHclReferenceAttribute(name string) bool {
+	return name == "id" || name == "ids" || strings.HasSuffix(name, "_id") || strings.HasSuffix(name, "_ids")
+}
+
+// normalizeHclValue converts sets into lists, so that all collections can be handled in the same way
+func normalizeHclValue(value interface{}) interface{} {
+	if set, ok := value.(*schema.Set); ok {
+		return set.List()
+	}
+	return value
+}
+
+// isDefaultHclValue returns true if the value does not need to be written in the configuration, as it is
+// equal to the schema default or, when there is no default, it is empty
+func isDefaultHclValue(s *schema.Schema, value interface{}) bool {
+	if s.Default != nil {
+		return reflect.DeepEqual(s.Default, value)
+	}
+	if value == nil {
+		return true
+	}
+	v := reflect.ValueOf(value)
+	switch v.Kind() {
+	case reflect.Slice, reflect.Map:
+		return v.Len() == 0
+	default:
+		return v.IsZero()
+	}
+}
+
+// hclValue returns the HCL representation of a value. The strings found in references are replaced by the reference
+func hclValue(value interface{}, indent string, references map[string]string, selfId string) string {
+	switch v := value.(type) {
+	case string:
+		if reference, ok := references[v]; ok && v != selfId {
+			return reference
+		}
+		if isJsonText(v) {
+			return hclJsonHeredoc(v, indent)
+		}
+		return hclQuote(v)
+	case bool:
+		return strconv.FormatBool(v)
+	case int:
+		return strconv.Itoa(v)
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case []interface{}:
+		var elements []string
+		for _, element := range v {
+			elements = append(elements, hclValue(element, indent, references, selfId))
+		}
+		return "[" + strings.Join(elements, ", ") + "]"
+	case map[string]interface{}:
+		var keys []string
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		var mapText strings.Builder
+		mapText.WriteString("{\n")
+		for _, key := range keys {
+			mapText.WriteString(fmt.Sprintf("%s  %s = %s\n", indent, hclQuote(key), hclValue(v[key], indent+"  ", references, selfId)))
+		}
+		mapText.WriteString(indent + "}")
+		return mapText.String()
+	default:
+		return hclQuote(fmt.Sprintf("%v", v))
+	}
+}
+
+// isJsonText returns true if the text contains a JSON object or array
+func isJsonText(text string) bool {
+	trimmed := strings.TrimSpace(text)
+	if !strings.HasPrefix(trimmed, "{") && !strings.HasPrefix(trimmed, "[") {
+		return false
+	}
+	return json.Valid([]byte(trimmed))
+}
+
+// hclJsonHeredoc returns a JSON text as an indented heredoc string
+func hclJsonHeredoc(text string, indent string) string {
+	var indented bytes.Buffer
+	err := json.Indent(&indented, []byte(strings.TrimSpace(text)), indent+"  ", "  ")
+	if err != nil {
+		return hclQuote(text)
+	}
+	return fmt.Sprintf("<<-EOT\n%s  %s\n%sEOT", indent, hclEscapeTemplate(indented.String()), indent)
+}
+
+// hclQuote returns the text as a quoted HCL string
+func hclQuote(text string) string {
+	replacer := strings.NewReplacer(
+		`\`, `\\`,
+		`"`, `\"`,
+		"\n", `\n`,
+		"\r", `\r`,
+		"\t", `\t`,
+	)
+	return `"` + hclEscapeTemplate(replacer.Replace(text)) + `"`
+}
+
+// hclEscapeTemplate escapes the sequences that HCL would interpret as template interpolations or directives
+func hclEscapeTemplate(text string) string {
+	text = strings.ReplaceAll(text, "${", "$${")
+	return strings.ReplaceAll(text, "%{", "%%{")
+}
@@ -0,0 +1,89 @@
+//go:build unit || ALL
+
+package vcd
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// Test_hclValue checks the conversion of state values into HCL expressions
+func Test_hclValue(t *testing.T) {
+	references := map[string]string{
+		"urn:vcloud:gateway:1111": "vcd_nsxt_edgegateway.edge-1111.id",
+	}
+	tests := []struct {
+		name  string
+		value interface{}
+		want  string
+	}{
+		{name: "plain string", value: "my-net", want: `"my-net"`},
+		{name: "string with quotes", value: `say "hi"`, want: `"say \"hi\""`},
+		{name: "string with interpolation", value: "${var}", want: `"$${var}"`},
+		{name: "reference", value: "urn:vcloud:gateway:1111", want: "vcd_nsxt_edgegateway.edge-1111.id"},
+		{name: "self reference", value: "urn:vcloud:network:2222", want: `"urn:vcloud:network:2222"`},
+		{name: "boolean", value: false, want: "false"},
+		{name: "integer", value: 42, want: "42"},
+		{name: "float", value: 1.5, want: "1.5"},
+		{name: "list", value: []interface{}{"a", "b"}, want: `["a", "b"]`},
+		{name: "JSON", value: `{"a":1}`, want: "<<-EOT\n  {\n    \"a\": 1\n  }\nEOT"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := hclValue(tt.value, "", references, "urn:vcloud:network:2222")
+			if got != tt.want {
+				t.Errorf("hclValue() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+// Test_writeHclBody checks that computed and default attributes are skipped, blocks are written, and IDs are replaced
+// by references only in the attributes that refer to other resources
+func Test_writeHclBody(t *testing.T) {
+	schemaMap := map[string]*schema.Schema{
+		"name":        {Type: schema.TypeString, Required: true},
+		"description": {Type: schema.TypeString, Optional: true},
+		"enabled":     {Type: schema.TypeBool, Optional: true, Default: true},
+		"href":        {Type: schema.TypeString, Computed: true},
+		"password":    {Type: schema.TypeString, Optional: true, Sensitive: true},
+		"gateway_id":  {Type: schema.TypeString, Optional: true},
+		"notes":       {Type: schema.TypeString, Optional: true},
+		"pool": {
+			Type:     schema.TypeList,
+			Optional: true,
+			Elem: &schema.Resource{
+				Schema: map[string]*schema.Schema{
+					"start": {Type: schema.TypeString, Required: true},
+				},
+			},
+		},
+	}
+	values := map[string]interface{}{
+		"name":        "net1",
+		"description": "",
+		"enabled":     true,
+		"href":        "https://example.com/net1",
+		"password":    "secret",
+		"gateway_id":  "urn:vcloud:gateway:1111",
+		"notes":       "urn:vcloud:gateway:1111",
+		"pool":        []interface{}{map[string]interface{}{"start": "10.0.0.1"}},
+	}
+	references := map[string]string{
+		"urn:vcloud:gateway:1111": "vcd_nsxt_edgegateway.edge-1111.id",
+	}
+	var hcl strings.Builder
+	writeHclBody(&hcl, schemaMap, values, "  ", references, "")
+	want := "  gateway_id = vcd_nsxt_edgegateway.edge-1111.id\n" +
+		"  name = \"net1\"\n" +
+		"  notes = \"urn:vcloud:gateway:1111\"\n" +
+		"  # password is sensitive and must be filled manually\n" +
+		"  pool {\n" +
+		"    start = \"10.0.0.1\"\n" +
+		"  }\n"
+	if hcl.String() != want {
+		t.Errorf("writeHclBody() = \n%s\nwant\n%s", hcl.String(), want)
+	}
+}
@@ -0,0 +1,366 @@
+package vcd
+
+// This file contains the recursive discovery used by vcd_resource_list when 'recursive' is set
+
+import (
+	"fmt"
+	"regexp"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/vmware/go-vcloud-director/v3/types/v56"
+)
+
+// resourceListScope contains the values that the listing functions read from the data source fields
+type resourceListScope struct {
+	org    string
+	vdc    string
+	parent string
+	// backing is the network backing ("nsxt" or "nsxv") of the VDC being walked, if known
+	backing string
+}
+
+// resourceListNode describes a resource type in the containment hierarchy walked in recursive mode
+type resourceListNode struct {
+	resourceType string
+	// when backing is set, the node is only walked inside VDCs with the same network backing
+	backing string
+	list    func(d *schema.ResourceData, meta interface{}) ([]resourceListEntry, error)
+	// childScope returns the scope used to list the children of the given entry
+	childScope func(client *VCDClient, scope resourceListScope, entry resourceListEntry) (resourceListScope, error)
+	children   []*resourceListNode
+}
+
+const (
+	backingNsxt = "nsxt"
+	backingNsxv = "nsxv"
+)
+
+// scopeWithParent returns a child scope where the entry becomes the parent
+func scopeWithParent(_ *VCDClient, scope resourceListScope, entry resourceListEntry) (resourceListScope, error) {
+	scope.parent = entry.ref.name
+	return scope, nil
+}
+
+// scopeWithVdc returns a child scope for the given VDC entry, also detecting its network backing
+func scopeWithVdc(client *VCDClient, scope resourceListScope, entry resourceListEntry) (resourceListScope, error) {
+	adminOrg, err := client.GetAdminOrg(scope.org)
+	if err != nil {
+		return scope, err
+	}
+	vdc, err := adminOrg.GetVDCByName(entry.ref.name, false)
+	if err != nil {
+		return scope, fmt.Errorf("error retrieving VDC '%s': %s", entry.ref.name, err)
+	}
+	backing := backingNsxv
+	if vdc.IsNsxt() {
+		backing = backingNsxt
+	}
+	return resourceListScope{
+		org:     scope.org,
+		vdc:     entry.ref.name,
+		parent:  "",
+		backing: backing,
+	}, nil
+}
+
+// scopeWithVdcGroup returns a child scope for the given VDC group entry
+func scopeWithVdcGroup(_ *VCDClient, scope resourceListScope, entry resourceListEntry) (resourceListScope, error) {
+	// The VDC group is also set as 'vdc', so that the children of its edge gateways can find their owner
+	return resourceListScope{
+		org:     scope.org,
+		vdc:     entry.ref.name,
+		parent:  entry.ref.name,
+		backing: backingNsxt,
+	}, nil
+}
+
+// vdcGroupResourceListNodes returns the resource types contained in a VDC group
+func vdcGroupResourceListNodes() []*resourceListNode {
+	return []*resourceListNode{
+		{
+			resourceType: "vcd_nsxt_edgegateway",
+			list:         getNsxtEdgeGatewayList,
+			childScope:   scopeWithParent,
+			children:     nsxtEdgeGatewayResourceListNodes(),
+		},
+		{resourceType: "vcd_network_routed_v2", list: vdcGroupNetworkListV2},
+		{resourceType: "vcd_network_isolated_v2", list: vdcGroupNetworkListV2},
+		{resourceType: "vcd_nsxt_network_imported", list: vdcGroupNetworkListV2},
+		{resourceType: "vcd_nsxt_app_port_profile", list: nsxtAppPortProfileList},
+	}
+}
+
+// nsxtEdgeGatewayResourceListNodes returns the resource types contained in an NSX-T edge gateway
+func nsxtEdgeGatewayResourceListNodes() []*resourceListNode {
+	return []*resourceListNode{
+		{resourceType: "vcd_nsxt_ip_set", list: func(d *schema.ResourceData, meta interface{}) ([]resourceListEntry, error) {
+			return nsxtFirewallGroupList(d, meta, "vcd_nsxt_ip_set", types.FirewallGroupTypeIpSet)
+		}},
+		{resourceType: "vcd_nsxt_security_group", list: func(d *schema.ResourceData, meta interface{}) ([]resourceListEntry, error) {
+			return nsxtFirewallGroupList(d, meta, "vcd_nsxt_security_group", types.FirewallGroupTypeSecurityGroup)
+		}},
+		{resourceType: "vcd_nsxt_firewall", list: nsxtFirewallList},
+		{resourceType: "vcd_nsxt_nat_rule", list: nsxtNatRuleList},
+		{resourceType: "vcd_nsxt_ipsec_vpn_tunnel", list: nsxtIpSecVpnTunnelList},
+		{resourceType: "vcd_nsxt_alb_edgegateway_service_engine_group", list: nsxtAlbServiceEngineGroupAssignment},
+		{resourceType: "vcd_nsxt_alb_pool", list: nsxtAlbPoolList},
+		{resourceType: "vcd_nsxt_alb_virtual_service", list: nsxtAlbVirtualServiceList},
+	}
+}
+
+// nsxvEdgeGatewayResourceListNodes returns the resource types contained in an NSX-V edge gateway
+func nsxvEdgeGatewayResourceListNodes() []*resourceListNode {
+	return []*resourceListNode{
+		{resourceType: "vcd_nsxv_firewall_rule", list: nsxvFirewallList},
+		{resourceType: "vcd_nsxv_dnat", list: func(d *schema.ResourceData, meta interface{}) ([]resourceListEntry, error) {
+			return nsxvNatRuleList("dnat", d, meta)
+		}},
+		{resourceType: "vcd_nsxv_snat", list: func(d *schema.ResourceData, meta interface{}) ([]resourceListEntry, error) {
+			return nsxvNatRuleList("snat", d, meta)
+		}},
+		{resourceType: "vcd_lb_service_monitor", list: lbServiceMonitorList},
+		{resourceType: "vcd_lb_server_pool", list: lbServerPoolList},
+		{resourceType: "vcd_lb_app_profile", list: lbAppProfileList},
+		{resourceType: "vcd_lb_app_rule", list: lbAppRuleList},
+		{resourceType: "vcd_lb_virtual_server", list: lbVirtualServerList},
+	}
+}
+
+// vdcResourceListNodes returns the resource types contained in a VDC
+func vdcResourceListNodes() []*resourceListNode {
+	return []*resourceListNode{
+		{
+			resourceType: "vcd_nsxt_edgegateway",
+			backing:      backingNsxt,
+			list:         getNsxtEdgeGatewayList,
+			childScope:   scopeWithParent,
+			children:     nsxtEdgeGatewayResourceListNodes(),
+		},
+		{
+			resourceType: "vcd_edgegateway",
+			backing:      backingNsxv,
+			list: func(d *schema.ResourceData, meta interface{}) ([]resourceListEntry, error) {
+				return getEdgeGatewayList(d, meta, "vcd_edgegateway")
+			},
+			childScope: scopeWithParent,
+			children:   nsxvEdgeGatewayResourceListNodes(),
+		},
+		// The networks of NSX-V VDCs are also listed by the OpenAPI, but they are walked once, as the resources that
+		// are used with NSX-V
+		{resourceType: "vcd_network_routed", backing: backingNsxv, list: networkList},
+		{resourceType: "vcd_network_isolated", backing: backingNsxv, list: networkList},
+		{resourceType: "vcd_network_routed_v2", backing: backingNsxt, list: orgNetworkListV2},
+		{resourceType: "vcd_network_isolated_v2", backing: backingNsxt, list: orgNetworkListV2},
+		{resourceType: "vcd_nsxt_network_imported", backing: backingNsxt, list: orgNetworkListV2},
+		{resourceType: "vcd_nsxt_app_port_profile", backing: backingNsxt, list: nsxtAppPortProfileList},
+		{resourceType: "vcd_network_direct", list: networkList},
+		{resourceType: "vcd_ipset", backing: backingNsxv, list: ipsetList},
+		{resourceType: "vcd_independent_disk", list: diskList},
+		{
+			resourceType: "vcd_vapp",
+			list: func(d *schema.ResourceData, meta interface{}) ([]resourceListEntry, error) {
+				return vappList(d, meta, "vcd_vapp")
+			},
+			childScope: scopeWithParent,
+			children: []*resourceListNode{
+				{resourceType: "vcd_vapp_network", list: func(d *schema.ResourceData, meta interface{}) ([]resourceListEntry, error) {
+					return vappNetworkList(d, vntVappNetwork, meta)
+				}},
+				{resourceType: "vcd_vapp_org_network", list: func(d *schema.ResourceData, meta interface{}) ([]resourceListEntry, error) {
+					return vappNetworkList(d, vntVappOrgNetwork, meta)
+				}},
+				{resourceType: "vcd_vapp_vm", list: func(d *schema.ResourceData, meta interface{}) ([]resourceListEntry, error) {
+					return vmList(d, meta, vappVmType)
+				}},
+			},
+		},
+		{resourceType: "vcd_vm", list: func(d *schema.ResourceData, meta interface{}) ([]resourceListEntry, error) {
+			return vmList(d, meta, standaloneVmType)
+		}},
+	}
+}
+
+// orgResourceListNodes returns the resource types contained in an organization
+func orgResourceListNodes() []*resourceListNode {
+	return []*resourceListNode{
+		{resourceType: "vcd_org_user", list: orgUserList},
+		{resourceType: "vcd_role", list: rolesList},
+		{
+			resourceType: "vcd_catalog",
+			list: func(d *schema.ResourceData, meta interface{}) ([]resourceListEntry, error) {
+				return catalogList(d, meta, "vcd_catalog")
+			},
+			childScope: scopeWithParent,
+			children: []*resourceListNode{
+				{resourceType: "vcd_catalog_vapp_template", list: vappTemplateList},
+				{resourceType: "vcd_catalog_media", list: func(d *schema.ResourceData, meta interface{}) ([]resourceListEntry, error) {
+					return catalogItemList(d, meta, "vcd_catalog_media")
+				}},
+			},
+		},
+		{
+			resourceType: "vcd_org_vdc",
+			list: func(d *schema.ResourceData, meta interface{}) ([]resourceListEntry, error) {
+				return vdcList(d, meta, "vcd_org_vdc")
+			},
+			childScope: scopeWithVdc,
+			children:   vdcResourceListNodes(),
+		},
+		{
+			resourceType: "vcd_vdc_group",
+			list:         getVdcGroups,
+			childScope:   scopeWithVdcGroup,
+			children:     vdcGroupResourceListNodes(),
+		},
+	}
+}
+
+// recursiveResourceList walks the containment hierarchy of an organization or a VDC group, and returns
+// the entries of every type found, where each entity comes before the ones it contains
+func recursiveResourceList(d *schema.ResourceData, meta interface{}) ([]resourceListEntry, error) {
+	client := meta.(*VCDClient)
+	requested := d.Get("resource_type").(string)
+	nameRegex := d.Get("name_regex").(string)
+
+	adminOrg, err := client.GetAdminOrgFromResource(d)
+	if err != nil {
+		return nil, err
+	}
+	orgName := adminOrg.AdminOrg.Name
+	var root resourceListEntry
+	var rootScope resourceListScope
+	var nodes []*resourceListNode
+
+	switch requested {
+	case "vcd_org", "org", "orgs":
+		root = resourceListEntry{
+			ref: resourceRef{
+				name: orgName,
+				id:   adminOrg.AdminOrg.ID,
+				href: adminOrg.AdminOrg.HREF,
+			},
+			resourceType: "vcd_org",
+		}
+		rootScope = resourceListScope{org: orgName, parent: orgName}
+		nodes = orgResourceListNodes()
+	case "vcd_vdc_group":
+		vdcGroupName := d.Get("parent").(string)
+		if vdcGroupName == "" {
+			return nil, fmt.Errorf(`no VDC group name (as "parent") given`)
+		}
+		vdcGroup, err := adminOrg.GetVdcGroupByName(vdcGroupName)
+		if err != nil {
+			return nil, fmt.Errorf("error retrieving VDC group '%s': %s", vdcGroupName, err)
+		}
+		root = resourceListEntry{
+			ref: resourceRef{
+				name:   vdcGroup.VdcGroup.Name,
+				id:     vdcGroup.VdcGroup.Id,
+				parent: orgName,
+			},
+			resourceType: "vcd_vdc_group",
+			ancestors:    []string{orgName},
+		}
+		rootScope, _ = scopeWithVdcGroup(client, resourceListScope{org: orgName}, root)
+		nodes = vdcGroupResourceListNodes()
+	default:
+		return nil, fmt.Errorf("recursive listing is only supported for 'vcd_org' and 'vcd_vdc_group' - given: '%s'", requested)
+	}
+
+	entries := []resourceListEntry{root}
+	for _, node := range nodes {
+		found, err := walkResourceListNode(client, d.Get("name").(string), rootScope, node)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, found...)
+	}
+
+	return filterRecursiveResourceList(entries, nameRegex,
+		convertSchemaSetToSliceOfStrings(d.Get("include_types").(*schema.Set)),
+		convertSchemaSetToSliceOfStrings(d.Get("exclude_types").(*schema.Set)))
+}
+
+// walkResourceListNode lists the entities of the given node within the scope, followed, for each of them,
+// by the entities of the children nodes
+func walkResourceListNode(client *VCDClient, name string, scope resourceListScope, node *resourceListNode) ([]resourceListEntry, error) {
+	if node.backing != "" && scope.backing != "" && node.backing != scope.backing {
+		return nil, nil
+	}
+	d := datasourceVcdResourceList().Data(nil)
+	dataValues := map[string]string{
+		"name":          name,
+		"resource_type": node.resourceType,
+		"org":           scope.org,
+		"vdc":           scope.vdc,
+		"parent":        scope.parent,
+	}
+	for key, value := range dataValues {
+		err := d.Set(key, value)
+		if err != nil {
+			return nil, err
+		}
+	}
+	listed, err := node.list(d, client)
+	if err != nil {
+		return nil, fmt.Errorf("error listing %s in %s: %s", node.resourceType, scope.description(), err)
+	}
+	var entries []resourceListEntry
+	for _, entry := range listed {
+		entries = append(entries, entry)
+		if node.childScope == nil {
+			continue
+		}
+		childScope, err := node.childScope(client, scope, entry)
+		if err != nil {
+			return nil, err
+		}
+		for _, child := range node.children {
+			found, err := walkResourceListNode(client, name, childScope, child)
+			if err != nil {
+				return nil, err
+			}
+			entries = append(entries, found...)
+		}
+	}
+	return entries, nil
+}
+
+// description returns a readable description of the scope, for error messages
+func (scope resourceListScope) description() string {
+	text := fmt.Sprintf("org '%s'", scope.org)
+	if scope.vdc != "" {
+		text += fmt.Sprintf(", VDC '%s'", scope.vdc)
+	}
+	if scope.parent != "" && scope.parent != scope.org {
+		text += fmt.Sprintf(", parent '%s'", scope.parent)
+	}
+	return text
+}
+
+// filterRecursiveResourceList removes from the entries the ones excluded by name or by type.
+// The filters only apply to the result: the walk always goes through every container
+func filterRecursiveResourceList(entries []resourceListEntry, nameRegex string, includeTypes, excludeTypes []string) ([]resourceListEntry, error) {
+	var reName *regexp.Regexp
+	var err error
+	if nameRegex != "" {
+		reName, err = regexp.Compile(nameRegex)
+		if err != nil {
+			return nil, fmt.Errorf("error compiling regular expression given with 'name_regex' '%s': %s", nameRegex, err)
+		}
+	}
+	var result []resourceListEntry
+	for _, entry := range entries {
+		if len(includeTypes) > 0 && !contains(includeTypes, entry.resourceType) {
+			continue
+		}
+		if contains(excludeTypes, entry.resourceType) {
+			continue
+		}
+		if reName != nil && reName.FindString(entry.ref.name) == "" {
+			continue
+		}
+		result = append(result, entry)
+	}
+	return result, nil
+}
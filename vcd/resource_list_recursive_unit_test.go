@@ -0,0 +1,154 @@
+//go:build unit || ALL
+
+package vcd
+
+import (
+	"reflect"
+	"testing"
+)
+
+// Test_filterRecursiveResourceList checks that the filters by type and name keep the order of the entries
+func Test_filterRecursiveResourceList(t *testing.T) {
+	entries := []resourceListEntry{
+		{ref: resourceRef{name: "org1"}, resourceType: "vcd_org"},
+		{ref: resourceRef{name: "vdc1"}, resourceType: "vcd_org_vdc"},
+		{ref: resourceRef{name: "vapp1"}, resourceType: "vcd_vapp"},
+		{ref: resourceRef{name: "vm1"}, resourceType: "vcd_vapp_vm"},
+		{ref: resourceRef{name: "vm2"}, resourceType: "vcd_vapp_vm"},
+	}
+	names := func(entries []resourceListEntry) []string {
+		var result []string
+		for _, entry := range entries {
+			result = append(result, entry.ref.name)
+		}
+		return result
+	}
+	tests := []struct {
+		name         string
+		nameRegex    string
+		includeTypes []string
+		excludeTypes []string
+		want         []string
+		wantErr      bool
+	}{
+		{name: "no filters", want: []string{"org1", "vdc1", "vapp1", "vm1", "vm2"}},
+		{name: "include", includeTypes: []string{"vcd_vapp", "vcd_vapp_vm"}, want: []string{"vapp1", "vm1", "vm2"}},
+		{name: "exclude", excludeTypes: []string{"vcd_org", "vcd_vapp"}, want: []string{"vdc1", "vm1", "vm2"}},
+		{name: "include and exclude", includeTypes: []string{"vcd_vapp", "vcd_vapp_vm"}, excludeTypes: []string{"vcd_vapp"}, want: []string{"vm1", "vm2"}},
+		{name: "name regex", nameRegex: "1$", want: []string{"org1", "vdc1", "vapp1", "vm1"}},
+		{name: "wrong regex", nameRegex: "(", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := filterRecursiveResourceList(entries, tt.nameRegex, tt.includeTypes, tt.excludeTypes)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("filterRecursiveResourceList() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(names(got), tt.want) {
+				t.Errorf("filterRecursiveResourceList() = %v, want %v", names(got), tt.want)
+			}
+		})
+	}
+}
+
+// Test_resourceListEntryImportId checks that the import ID is built from the ancestors and the right identifier
+func Test_resourceListEntryImportId(t *testing.T) {
+	tests := []struct {
+		name  string
+		entry resourceListEntry
+		want  string
+	}{
+		{
+			name:  "by name",
+			entry: resourceListEntry{ref: resourceRef{name: "edge1", id: "urn:vcloud:gateway:1111"}, ancestors: []string{"org1", "vdc1"}},
+			want:  "org1.vdc1.edge1",
+		},
+		{
+			name:  "by ID",
+			entry: resourceListEntry{ref: resourceRef{name: "rule1", id: "urn:vcloud:nat:2222", importId: true}, ancestors: []string{"org1", "vdc1", "edge1"}},
+			want:  "org1.vdc1.edge1.urn:vcloud:nat:2222",
+		},
+		{
+			name:  "by import identifier",
+			entry: resourceListEntry{ref: resourceRef{name: "My type", id: "urn:vcloud:type:vmware:mytype:1.0.0", importIdentifier: "mytype.1.0.0"}, ancestors: []string{"vmware"}},
+			want:  "vmware.mytype.1.0.0",
+		},
+		{
+			name:  "no ancestors",
+			entry: resourceListEntry{ref: resourceRef{name: "region1", id: "urn:vcloud:region:3333"}},
+			want:  "region1",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.entry.importId()
+			if got != tt.want {
+				t.Errorf("importId() = %s, want %s", got, tt.want)
+			}
+			// The import commands always have a separator before the identifier
+			wantCommandId := tt.want
+			if len(tt.entry.ancestors) == 0 {
+				wantCommandId = ImportSeparator + tt.want
+			}
+			if got := tt.entry.importCommandId(); got != wantCommandId {
+				t.Errorf("importCommandId() = %s, want %s", got, wantCommandId)
+			}
+		})
+	}
+}
+
+// Test_resourceListNodes checks that the networks are walked in the VDCs of both backings and in the VDC groups
+func Test_resourceListNodes(t *testing.T) {
+	nodeTypes := func(nodes []*resourceListNode, backing string) []string {
+		var result []string
+		for _, node := range nodes {
+			if node.backing == "" || node.backing == backing {
+				result = append(result, node.resourceType)
+			}
+		}
+		return result
+	}
+	tests := []struct {
+		name    string
+		nodes   []*resourceListNode
+		backing string
+		want    []string
+		notWant []string
+	}{
+		{
+			name:    "NSX-V VDC",
+			nodes:   vdcResourceListNodes(),
+			backing: backingNsxv,
+			want:    []string{"vcd_network_routed", "vcd_network_isolated", "vcd_network_direct"},
+			notWant: []string{"vcd_network_routed_v2", "vcd_network_isolated_v2", "vcd_nsxt_network_imported"},
+		},
+		{
+			name:    "NSX-T VDC",
+			nodes:   vdcResourceListNodes(),
+			backing: backingNsxt,
+			want:    []string{"vcd_network_routed_v2", "vcd_network_isolated_v2", "vcd_nsxt_network_imported", "vcd_network_direct"},
+			notWant: []string{"vcd_network_routed", "vcd_network_isolated"},
+		},
+		{
+			name:    "VDC group",
+			nodes:   vdcGroupResourceListNodes(),
+			backing: backingNsxt,
+			want:    []string{"vcd_nsxt_edgegateway", "vcd_network_routed_v2", "vcd_network_isolated_v2", "vcd_nsxt_network_imported"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := nodeTypes(tt.nodes, tt.backing)
+			for _, resourceType := range tt.want {
+				if !contains(got, resourceType) {
+					t.Errorf("expected %s to be walked, got %v", resourceType, got)
+				}
+			}
+			for _, resourceType := range tt.notWant {
+				if contains(got, resourceType) {
+					t.Errorf("expected %s not to be walked, got %v", resourceType, got)
+				}
+			}
+		})
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"context"
 	"fmt"
 	"log"
+	"net/http"
 	"strings"
 	"text/tabwriter"
 
@@ -25,6 +26,7 @@ func resourceVcdIndependentDisk() *schema.Resource {
 		ReadContext:   resourceVcdIndependentDiskRead,
 		UpdateContext: resourceVcdIndependentDiskUpdate,
 		DeleteContext: resourceVcdIndependentDiskDelete,
+		CustomizeDiff: resourceVcdIndependentDiskCustomizeDiff,
 		Importer: &schema.ResourceImporter{
 			StateContext: resourceVcdIndependentDiskImport,
 		},
@@ -60,7 +62,7 @@ func resourceVcdIndependentDisk() *schema.Resource {
 			"size_in_mb": {
 				Type:        schema.TypeInt,
 				Required:    true,
-				Description: "size in MB",
+				Description: "size in MB. It can only grow after the creation",
 			},
 			"bus_type": {
 				Type:         schema.TypeString,
@@ -96,8 +98,9 @@ func resourceVcdIndependentDisk() *schema.Resource {
 			},
 			"iops": {
 				Type:        schema.TypeInt,
+				Optional:    true,
 				Computed:    true,
-				Description: "IOPS request for the created disk",
+				Description: "IOPS request for the disk",
 			},
 			"owner_name": {
 				Type:        schema.TypeString,
@@ -216,6 +219,10 @@ func resourceVcdIndependentDiskCreate(ctx context.Context, d *schema.ResourceDat
 		diskCreateParams.Disk.BusSubType = busSubTypes[strings.ToLower(busSubTypeValue)]
 	}
 
+	if iops, ok := d.GetOk("iops"); ok {
+		diskCreateParams.Disk.Iops = addrOf(iops.(int))
+	}
+
 	diskCreateParams.Disk.Description = d.Get("description").(string)
 	diskCreateParams.Disk.SharingType = d.Get("sharing_type").(string)
 
@@ -265,7 +272,7 @@ func resourceVcdIndependentDiskUpdate(ctx context.Context, d *schema.ResourceDat
 			return diag.Errorf("[independent disk update] sharing_type is immutable. It can only be set during disk creation")
 		}
 	}
-	if d.HasChanges("size_in_mb", "storage_profile", "description") {
+	if d.HasChanges("size_in_mb", "storage_profile", "description", "iops") {
 		storageProfileValue := d.Get("storage_profile").(string)
 		var storageProfileRef *types.Reference
 
@@ -298,14 +305,22 @@ func resourceVcdIndependentDiskUpdate(ctx context.Context, d *schema.ResourceDat
 		lockVmsForIndependentDisks(diskAttachedVmsHrefs)
 		defer unlockVmsForIndependentDisks(diskAttachedVmsHrefs)
 
-		diskDetailsForReAttach, diagErr := detachVms(vcdClient, disk, diskAttachedVmsHrefs)
-		if diagErr != nil {
-			return diagErr
-		}
+		// VCD grows and updates attached disks online, without detaching them from their VMs. Only IDE disks, which
+		// can't be extended while attached, and disks moving to another storage profile need to be detached
+		detachNeeded := len(diskAttachedVmsHrefs) > 0 &&
+			(busTypesFromValues[disk.Disk.BusType] == "IDE" || d.HasChange("storage_profile"))
+		var diskDetailsForReAttach map[string]types.DiskSettings
+		if detachNeeded {
+			var diagErr diag.Diagnostics
+			diskDetailsForReAttach, diagErr = detachVms(vcdClient, disk, diskAttachedVmsHrefs)
+			if diagErr != nil {
+				return diagErr
+			}
 
-		err = disk.Refresh()
-		if err != nil {
-			return diag.Errorf("error resourceVcdIndependentDiskUpdate error refreshing independent disk: %s", err)
+			err = disk.Refresh()
+			if err != nil {
+				return diag.Errorf("error resourceVcdIndependentDiskUpdate error refreshing independent disk: %s", err)
+			}
 		}
 
 		disk.Disk.SizeMb = int64(d.Get("size_in_mb").(int))
@@ -313,22 +328,21 @@ func resourceVcdIndependentDiskUpdate(ctx context.Context, d *schema.ResourceDat
 		if storageProfileRef != nil {
 			disk.Disk.StorageProfile = storageProfileRef
 		}
-
-		task, err := disk.Update(disk.Disk)
-		if err != nil {
-			return diag.Errorf("error updating independent disk: %s", err)
+		if d.HasChange("iops") {
+			disk.Disk.Iops = addrOf(d.Get("iops").(int))
 		}
 
-		err = task.WaitTaskCompletion()
+		err = updateIndependentDisk(&vcdClient.Client, disk)
 		if err != nil {
-			return diag.Errorf("error waiting to finish updating of independent disk: %s", err)
+			return diag.Errorf("error updating independent disk: %s", err)
 		}
 
-		diagErr = attachBackVms(vcdClient, disk, diskDetailsForReAttach, diskAttachedVmsHrefs)
-		if diagErr != nil {
-			return diagErr
+		if detachNeeded {
+			diagErr := attachBackVms(vcdClient, disk, diskDetailsForReAttach, diskAttachedVmsHrefs)
+			if diagErr != nil {
+				return diagErr
+			}
 		}
-
 	}
 
 	err = createOrUpdateMetadata(d, disk, "metadata")
@@ -339,6 +353,56 @@ func resourceVcdIndependentDiskUpdate(ctx context.Context, d *schema.ResourceDat
 	return resourceVcdIndependentDiskRead(ctx, d, meta)
 }
 
+// updateIndependentDisk updates the size, description, storage profile and IOPS of an independent disk. Unlike
+// disk.Update, it doesn't require the disk to be detached, as VCD can grow a disk attached to a VM when its bus allows
+// it, and it sends the IOPS
+func updateIndependentDisk(client *govcd.Client, disk *govcd.Disk) error {
+	var updateDiskLink *types.Link
+	for _, link := range disk.Disk.Link {
+		if link.Rel == types.RelEdit && link.Type == types.MimeDisk {
+			updateDiskLink = link
+			break
+		}
+	}
+	if updateDiskLink == nil {
+		return fmt.Errorf("could not find request URL for update of disk %s", disk.Disk.Name)
+	}
+
+	payload := &types.Disk{
+		Xmlns:          types.XMLNamespaceVCloud,
+		Name:           disk.Disk.Name,
+		Description:    disk.Disk.Description,
+		SizeMb:         disk.Disk.SizeMb,
+		Iops:           disk.Disk.Iops,
+		StorageProfile: disk.Disk.StorageProfile,
+		Owner:          disk.Disk.Owner,
+	}
+	task, err := client.ExecuteTaskRequest(updateDiskLink.HREF, http.MethodPut, updateDiskLink.Type,
+		"error updating disk: %s", payload)
+	if err != nil {
+		return err
+	}
+	err = task.WaitTaskCompletion()
+	if err != nil {
+		return fmt.Errorf("error waiting to finish updating of independent disk: %s", err)
+	}
+	return nil
+}
+
+// resourceVcdIndependentDiskCustomizeDiff rejects at plan time a shrink of the disk, which VCD doesn't support, instead
+// of replacing the disk and losing its data
+func resourceVcdIndependentDiskCustomizeDiff(_ context.Context, d *schema.ResourceDiff, _ interface{}) error {
+	if d.Id() == "" || !d.HasChange("size_in_mb") || !d.NewValueKnown("size_in_mb") {
+		return nil
+	}
+	oldSize, newSize := d.GetChange("size_in_mb")
+	if newSize.(int) < oldSize.(int) {
+		return fmt.Errorf("size_in_mb of independent disk %s can't be reduced from %d to %d, as VCD can only grow disks",
+			d.Get("name"), oldSize, newSize)
+	}
+	return nil
+}
+
 // lockIndependentDiskOpsGlobally acquire lock for independent disk resource using key `globalIndependentDiskLockKey`
 func lockIndependentDiskOpsGlobally() {
 	vcdMutexKV.kvLock(globalIndependentDiskLockKey)
@@ -667,6 +667,160 @@ resource "vcd_vapp_vm" "{{.VmName}}" {
 }
 `
 
+// TestAccVcdIndependentDiskOnlineResize grows a disk attached to a powered on VM without replacing it, and checks that
+// a shrink fails at plan time
+func TestAccVcdIndependentDiskOnlineResize(t *testing.T) {
+	preTestChecks(t)
+
+	var params = StringMap{
+		"Org":          testConfig.VCD.Org,
+		"Vdc":          testConfig.Nsxt.Vdc,
+		"ResourceName": t.Name(),
+		"VmName":       t.Name(),
+		"Catalog":      testSuiteCatalogName,
+		"CatalogItem":  testSuiteCatalogOVAItem,
+		"size":         "1024",
+		"Tags":         "disk",
+		"FuncName":     t.Name(),
+	}
+	testParamsNotEmpty(t, params)
+
+	configText := templateFill(testAccCheckVcdIndependentDiskOnlineResize, params)
+	params["FuncName"] = t.Name() + "-grow"
+	params["size"] = "2048"
+	configTextGrow := templateFill(testAccCheckVcdIndependentDiskOnlineResize, params)
+	params["FuncName"] = t.Name() + "-shrink"
+	params["size"] = "1024"
+	configTextShrink := templateFill(testAccCheckVcdIndependentDiskOnlineResize, params)
+
+	if vcdShortTest {
+		t.Skip(acceptanceTestsSkipped)
+		return
+	}
+
+	debugPrintf("#[DEBUG] CONFIGURATION: %s", configText)
+
+	diskResource := "vcd_independent_disk." + t.Name()
+	var diskId string
+	resource.Test(t, resource.TestCase{
+		ProviderFactories: testAccProviders,
+		CheckDestroy:      testDiskResourcesDestroyed,
+		Steps: []resource.TestStep{
+			{
+				Config: configText,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(diskResource, "size_in_mb", "1024"),
+					resource.TestCheckResourceAttr(diskResource, "is_attached", "true"),
+					testCheckResourceAttrStore(diskResource, "id", &diskId),
+				),
+			},
+			{
+				Config: configTextGrow,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttrPtr(diskResource, "id", &diskId),
+					resource.TestCheckResourceAttr(diskResource, "size_in_mb", "2048"),
+					resource.TestCheckResourceAttr(diskResource, "is_attached", "true"),
+					// The state of the VM was read before the disk was grown, so the size is checked in VCD
+					testAccCheckVmIndependentDiskSize("vcd_vapp_vm."+t.Name(), diskResource, 2048),
+				),
+			},
+			{
+				Config:      configTextShrink,
+				ExpectError: regexp.MustCompile(`can't be reduced from 2048 to 1024`),
+			},
+		},
+	})
+	postTestChecks(t)
+}
+
+// testAccCheckVmIndependentDiskSize checks in VCD the size of an independent disk attached to a VM
+func testAccCheckVmIndependentDiskSize(vmResource, diskResource string, expectedSize int64) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		vmRs, ok := s.RootModule().Resources[vmResource]
+		if !ok {
+			return fmt.Errorf("resource %s not found", vmResource)
+		}
+		diskRs, ok := s.RootModule().Resources[diskResource]
+		if !ok {
+			return fmt.Errorf("resource %s not found", diskResource)
+		}
+
+		conn := testAccProvider.Meta().(*VCDClient)
+		_, vdc, err := conn.GetOrgAndVdc(testConfig.VCD.Org, testConfig.Nsxt.Vdc)
+		if err != nil {
+			return fmt.Errorf(errorRetrievingVdcFromOrg, testConfig.Nsxt.Vdc, testConfig.VCD.Org, err)
+		}
+		vm, err := vdc.QueryVmById(vmRs.Primary.ID)
+		if err != nil {
+			return fmt.Errorf("error retrieving VM %s: %s", vmRs.Primary.ID, err)
+		}
+		if vm.VM.VmSpecSection == nil || vm.VM.VmSpecSection.DiskSection == nil {
+			return fmt.Errorf("VM %s has no disks", vm.VM.Name)
+		}
+		for _, disk := range vm.VM.VmSpecSection.DiskSection.DiskSettings {
+			if disk.Disk == nil || extractUuid(disk.Disk.HREF) != extractUuid(diskRs.Primary.ID) {
+				continue
+			}
+			if disk.SizeMb != expectedSize {
+				return fmt.Errorf("disk %s of VM %s has size %d MB, expected %d MB", diskRs.Primary.ID, vm.VM.Name,
+					disk.SizeMb, expectedSize)
+			}
+			return nil
+		}
+		return fmt.Errorf("disk %s not attached to VM %s", diskRs.Primary.ID, vm.VM.Name)
+	}
+}
+
+// testCheckResourceAttrStore saves the value of a resource attribute, to compare it in later steps
+func testCheckResourceAttrStore(resourceName, attribute string, value *string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		rs, ok := s.RootModule().Resources[resourceName]
+		if !ok {
+			return fmt.Errorf("resource %s not found", resourceName)
+		}
+		*value = rs.Primary.Attributes[attribute]
+		if *value == "" {
+			return fmt.Errorf("resource %s has no attribute %s", resourceName, attribute)
+		}
+		return nil
+	}
+}
+
+const testAccCheckVcdIndependentDiskOnlineResize = `
+resource "vcd_independent_disk" "{{.ResourceName}}" {
+  org          = "{{.Org}}"
+  vdc          = "{{.Vdc}}"
+  name         = "{{.ResourceName}}"
+  size_in_mb   = {{.size}}
+  bus_type     = "SCSI"
+  bus_sub_type = "VirtualSCSI"
+}
+
+resource "vcd_vapp" "{{.ResourceName}}" {
+  org  = "{{.Org}}"
+  vdc  = "{{.Vdc}}"
+  name = "{{.ResourceName}}"
+}
+
+resource "vcd_vapp_vm" "{{.VmName}}" {
+  org           = "{{.Org}}"
+  vdc           = "{{.Vdc}}"
+  vapp_name     = vcd_vapp.{{.ResourceName}}.name
+  name          = "{{.VmName}}"
+  catalog_name  = "{{.Catalog}}"
+  template_name = "{{.CatalogItem}}"
+  memory        = 1024
+  cpus          = 1
+  power_on      = true
+
+  disk {
+    name        = vcd_independent_disk.{{.ResourceName}}.name
+    bus_number  = 1
+    unit_number = 0
+  }
+}
+`
+
 // TestAccVcdIndependentDiskMetadata tests metadata CRUD on independent disks
 func TestAccVcdIndependentDiskMetadata(t *testing.T) {
 	testMetadataEntryCRUD(t,
@@ -0,0 +1,97 @@
+//go:build unit || ALL
+
+package vcd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+	"github.com/vmware/go-vcloud-director/v3/govcd"
+	"github.com/vmware/go-vcloud-director/v3/types/v56"
+	"github.com/vmware/terraform-provider-vcd/v4/vcd/mockvcd"
+)
+
+// Test_updateIndependentDisk checks that the disk is updated with a single request, sending the new size and IOPS
+func Test_updateIndependentDisk(t *testing.T) {
+	server := mockvcd.NewServer()
+	defer server.Close()
+	vcdClient := newMockVcdClient(t, server)
+
+	diskPath := "/api/disk/11111111-2222-3333-4444-555555555555"
+	var bodies []string
+	server.HandleFunc(http.MethodPut, diskPath, func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		bodies = append(bodies, string(body))
+		taskHref := server.NewTask("urn:vcloud:disk:11111111-2222-3333-4444-555555555555", server.URL+diskPath)
+		w.Header().Set("Content-Type", types.MimeTask)
+		w.WriteHeader(http.StatusAccepted)
+		_, _ = fmt.Fprintf(w, `<Task xmlns="%s" href="%s" status="running"></Task>`, types.XMLNamespaceVCloud, taskHref)
+	})
+
+	disk := govcd.NewDisk(&vcdClient.Client)
+	disk.Disk = &types.Disk{
+		HREF:   server.URL + diskPath,
+		Name:   "data",
+		SizeMb: 2048,
+		Iops:   addrOf(500),
+		Link:   types.LinkList{{Rel: types.RelEdit, Type: types.MimeDisk, HREF: server.URL + diskPath}},
+	}
+
+	err := updateIndependentDisk(&vcdClient.Client, disk)
+	if err != nil {
+		t.Fatalf("error updating independent disk: %s", err)
+	}
+	if len(bodies) != 1 {
+		t.Fatalf("expected a single update request, got %d", len(bodies))
+	}
+	for _, expected := range []string{`name="data"`, `sizeMb="2048"`, `iops="500"`} {
+		if !strings.Contains(bodies[0], expected) {
+			t.Errorf("expected '%s' in the update payload, got: %s", expected, bodies[0])
+		}
+	}
+
+	disk.Disk.Link = nil
+	err = updateIndependentDisk(&vcdClient.Client, disk)
+	if err == nil {
+		t.Errorf("expected an error without edit link")
+	}
+}
+
+// Test_resourceVcdIndependentDiskCustomizeDiff checks that a shrink fails at plan time, while a grow is an in-place
+// update
+func Test_resourceVcdIndependentDiskCustomizeDiff(t *testing.T) {
+	state := &terraform.InstanceState{
+		ID: "urn:vcloud:disk:11111111-2222-3333-4444-555555555555",
+		Attributes: map[string]string{
+			"id":         "urn:vcloud:disk:11111111-2222-3333-4444-555555555555",
+			"name":       "data",
+			"size_in_mb": "2048",
+		},
+	}
+	tests := []struct {
+		name    string
+		size    int
+		wantErr bool
+	}{
+		{name: "Grow", size: 4096},
+		{name: "Unchanged", size: 2048},
+		{name: "Shrink", size: 1024, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := terraform.NewResourceConfigRaw(map[string]interface{}{"name": "data", "size_in_mb": tt.size})
+			diff, err := resourceVcdIndependentDisk().Diff(context.Background(), state, config, nil)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("expected error: %t, got: %v", tt.wantErr, err)
+			}
+			if diff != nil && diff.RequiresNew() {
+				t.Errorf("expected an in-place update, got a replacement")
+			}
+		})
+	}
+}
@@ -68,13 +68,18 @@ func resourceVcdInsertedMedia() *schema.Resource {
 	}
 }
 
-func resourceVcdMediaInsert(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+func resourceVcdMediaInsert(ctx context.Context, d *schema.ResourceData, meta interface{}) (diags diag.Diagnostics) {
 	log.Printf("[TRACE] VM media insert initiated")
 
 	vcdClient := meta.(*VCDClient)
 
 	vcdClient.lockParentVapp(d)
 	defer vcdClient.unLockParentVapp(d)
+	unlockDistributed, err := vcdClient.lockDistributedVapp(d, d.Get("vapp_name").(string))
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	defer releaseDistributedLock(unlockDistributed, &diags)
 
 	vm, org, err := getVM(d, meta)
 	if err != nil || org == nil {
@@ -121,12 +126,17 @@ func resourceVcdVmInsertedMediaRead(_ context.Context, d *schema.ResourceData, m
 	return nil
 }
 
-func resourceVcdMediaEject(_ context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+func resourceVcdMediaEject(_ context.Context, d *schema.ResourceData, meta interface{}) (diags diag.Diagnostics) {
 
 	vcdClient := meta.(*VCDClient)
 
 	vcdClient.lockParentVapp(d)
 	defer vcdClient.unLockParentVapp(d)
+	unlockDistributed, err := vcdClient.lockDistributedVapp(d, d.Get("vapp_name").(string))
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	defer releaseDistributedLock(unlockDistributed, &diags)
 
 	vm, org, err := getVM(d, meta)
 	if err != nil {
@@ -0,0 +1,325 @@
+package vcd
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+	"github.com/vmware/go-vcloud-director/v3/govcd"
+	"github.com/vmware/go-vcloud-director/v3/types/v56"
+)
+
+const labelMetadata = "metadata"
+
+const (
+	metadataModeAdditive      = "additive"
+	metadataModeAuthoritative = "authoritative"
+)
+
+// metadataUrnTypePaths links the entity types found in URNs (lowercase) with the path of the
+// entity in the legacy API, relative to the API root. The path receives the UUID of the entity.
+var metadataUrnTypePaths = map[string]string{
+	"catalog":           "/admin/catalog/%s",
+	"catalogitem":       "/catalogItem/%s",
+	"disk":              "/disk/%s",
+	"media":             "/media/%s",
+	"network":           "/network/%s",
+	"org":               "/admin/org/%s",
+	"providervdc":       "/admin/providervdc/%s",
+	"vapp":              "/vApp/vapp-%s",
+	"vapptemplate":      "/vAppTemplate/vappTemplate-%s",
+	"vdc":               "/admin/vdc/%s",
+	"vdcstorageprofile": "/admin/vdcStorageProfile/%s",
+	"vm":                "/vApp/vm-%s",
+}
+
+// metadataModeSchema returns the schema of the 'mode' attribute shared by vcd_metadata and vcd_openapi_metadata
+func metadataModeSchema() *schema.Schema {
+	return &schema.Schema{
+		Type:     schema.TypeString,
+		Optional: true,
+		Default:  metadataModeAdditive,
+		Description: fmt.Sprintf("How the metadata of the entity is managed. '%s' only manages the given entries, "+
+			"'%s' also removes any other entry of the entity. Defaults to '%s'", metadataModeAdditive, metadataModeAuthoritative, metadataModeAdditive),
+		ValidateFunc: validation.StringInSlice([]string{metadataModeAdditive, metadataModeAuthoritative}, false),
+	}
+}
+
+func resourceVcdMetadata() *schema.Resource {
+	return &schema.Resource{
+		CreateContext: resourceVcdMetadataCreate,
+		ReadContext:   resourceVcdMetadataRead,
+		UpdateContext: resourceVcdMetadataUpdate,
+		DeleteContext: resourceVcdMetadataDelete,
+		Importer: &schema.ResourceImporter{
+			StateContext: resourceVcdMetadataImport,
+		},
+		Schema: map[string]*schema.Schema{
+			"entity_id": {
+				Type:        schema.TypeString,
+				Required:    true,
+				ForceNew:    true,
+				Description: "URN of the entity that owns the metadata (e.g. 'urn:vcloud:vm:...')",
+			},
+			"entity_type": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "Type of the entity, as found in its URN",
+			},
+			"mode":           metadataModeSchema(),
+			"metadata_entry": metadataEntryResourceSchema("entity"),
+		},
+	}
+}
+
+func resourceVcdMetadataCreate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	vcdClient := meta.(*VCDClient)
+	entityId := d.Get("entity_id").(string)
+
+	entity, err := getMetadataEntityByUrn(vcdClient, entityId)
+	if err != nil {
+		return diag.Errorf("error creating %s of entity '%s': %s", labelMetadata, entityId, err)
+	}
+	err = applyMetadataEntries(d, entity)
+	if err != nil {
+		return diag.Errorf("error creating %s of entity '%s': %s", labelMetadata, entityId, err)
+	}
+	d.SetId(entityId)
+
+	return resourceVcdMetadataRead(ctx, d, meta)
+}
+
+func resourceVcdMetadataRead(_ context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	return readMetadataEntries(d, meta.(*VCDClient), d.Get("mode").(string) == metadataModeAuthoritative)
+}
+
+func resourceVcdMetadataUpdate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	vcdClient := meta.(*VCDClient)
+
+	entity, err := getMetadataEntityByUrn(vcdClient, d.Id())
+	if err != nil {
+		return diag.Errorf("error updating %s of entity '%s': %s", labelMetadata, d.Id(), err)
+	}
+	err = applyMetadataEntries(d, entity)
+	if err != nil {
+		return diag.Errorf("error updating %s of entity '%s': %s", labelMetadata, d.Id(), err)
+	}
+
+	return resourceVcdMetadataRead(ctx, d, meta)
+}
+
+func resourceVcdMetadataDelete(_ context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	vcdClient := meta.(*VCDClient)
+
+	entity, err := getMetadataEntityByUrn(vcdClient, d.Id())
+	if err != nil {
+		return diag.Errorf("error deleting %s of entity '%s': %s", labelMetadata, d.Id(), err)
+	}
+	for key, isSystem := range getMetadataKeyWithDomainMap(d.Get("metadata_entry").(*schema.Set).List()) {
+		err = entity.DeleteMetadataEntryWithDomain(key, isSystem)
+		if err != nil && !govcd.ContainsNotFound(err) {
+			return diag.Errorf("error deleting %s entry '%s' of entity '%s': %s", labelMetadata, key, d.Id(), err)
+		}
+	}
+	return nil
+}
+
+// resourceVcdMetadataImport imports the metadata of an entity using its URN.
+// Example import path (_the_id_string_): urn:vcloud:vm:9fa8cd3b-d6b9-4e1d-b7d7-8e7e1a4b3f8f
+// All the metadata entries of the entity are imported, in additive mode
+func resourceVcdMetadataImport(_ context.Context, d *schema.ResourceData, meta interface{}) ([]*schema.ResourceData, error) {
+	dSet(d, "entity_id", d.Id())
+	dSet(d, "mode", metadataModeAdditive)
+	diags := readMetadataEntries(d, meta.(*VCDClient), true)
+	if diags.HasError() {
+		return nil, fmt.Errorf("error importing %s of entity '%s': %v", labelMetadata, d.Id(), diags)
+	}
+	if d.Id() == "" {
+		return nil, fmt.Errorf("error importing %s: entity not found", labelMetadata)
+	}
+	return []*schema.ResourceData{d}, nil
+}
+
+// applyMetadataEntries creates, updates and deletes the metadata entries of the given entity, depending on the changes
+// of metadata_entry. In authoritative mode, the entries of the entity that are not in metadata_entry are deleted too
+func applyMetadataEntries(d *schema.ResourceData, entity metadataCompatible) error {
+	err := createOrUpdateMetadataEntryInVcd(d, entity)
+	if err != nil {
+		return err
+	}
+	if d.Get("mode").(string) != metadataModeAuthoritative {
+		return nil
+	}
+
+	metadata, err := entity.GetMetadata()
+	if err != nil {
+		return fmt.Errorf("error retrieving metadata: %s", err)
+	}
+	entityType, _ := getUrnEntityType(d.Get("entity_id").(string))
+	managedKeys := getMetadataKeyWithDomainMap(d.Get("metadata_entry").(*schema.Set).List())
+	for _, entry := range getManageableMetadataEntries(entityType, metadata) {
+		isSystem := entry.Domain != nil && entry.Domain.Domain == "SYSTEM"
+		if managedIsSystem, found := managedKeys[entry.Key]; found && managedIsSystem == isSystem {
+			continue
+		}
+		log.Printf("[DEBUG] deleting %s entry '%s' of entity '%s' not present in the configuration", labelMetadata, entry.Key, d.Get("entity_id").(string))
+		err = entity.DeleteMetadataEntryWithDomain(entry.Key, isSystem)
+		if err != nil {
+			return fmt.Errorf("error deleting metadata entry corresponding to key %s: %s", entry.Key, err)
+		}
+	}
+	return nil
+}
+
+// readMetadataEntries sets the metadata entries of the entity in metadata_entry. When readAll is false, only the
+// entries whose keys are already in metadata_entry are kept
+func readMetadataEntries(d *schema.ResourceData, vcdClient *VCDClient, readAll bool) diag.Diagnostics {
+	entityId := d.Get("entity_id").(string)
+	entity, err := getMetadataEntityByUrn(vcdClient, entityId)
+	if err != nil {
+		return diag.Errorf("error reading %s of entity '%s': %s", labelMetadata, entityId, err)
+	}
+	metadata, err := entity.GetMetadata()
+	if govcd.ContainsNotFound(err) {
+		log.Printf("[DEBUG] entity '%s' not found. Removing %s from state", entityId, labelMetadata)
+		d.SetId("")
+		return nil
+	}
+	if err != nil {
+		return diag.Errorf("error reading %s of entity '%s': %s", labelMetadata, entityId, err)
+	}
+
+	entityType, _ := getUrnEntityType(entityId)
+	dSet(d, "entity_type", entityType)
+
+	entries := getManageableMetadataEntries(entityType, metadata)
+	if !readAll {
+		managedKeys := getMetadataKeyWithDomainMap(d.Get("metadata_entry").(*schema.Set).List())
+		var managedEntries []*types.MetadataEntry
+		for _, entry := range entries {
+			if _, found := managedKeys[entry.Key]; found {
+				managedEntries = append(managedEntries, entry)
+			}
+		}
+		entries = managedEntries
+	}
+	if len(entries) == 0 {
+		err = d.Set("metadata_entry", nil)
+	} else {
+		err = setMetadataEntryInState(d, entries)
+	}
+	if err != nil {
+		return diag.Errorf("error setting metadata entry in state: %s", err)
+	}
+	return nil
+}
+
+// getManageableMetadataEntries returns the metadata entries that can be managed by vcd_metadata, which excludes
+// the ones inherited automatically by VMs, vApps and vApp Templates, and the distributed lock leases
+func getManageableMetadataEntries(entityType string, metadata *types.Metadata) []*types.MetadataEntry {
+	filterDistributedLockMetadata(metadata)
+	switch entityType {
+	case "vm", "vapp", "vapptemplate":
+		_ = filterAndGetVcdInheritedMetadata(metadata)
+	}
+	return metadata.MetadataEntry
+}
+
+// getUrnEntityType returns the entity type found in the given URN, in lowercase.
+// For example, 'urn:vcloud:vm:9fa8cd3b-d6b9-4e1d-b7d7-8e7e1a4b3f8f' returns 'vm'
+func getUrnEntityType(urn string) (string, error) {
+	urnElements := strings.Split(urn, ":")
+	if len(urnElements) < 4 || urnElements[0] != "urn" || urnElements[1] != "vcloud" || urnElements[2] == "" || urnElements[3] == "" {
+		return "", fmt.Errorf("'%s' is not a valid URN, expected 'urn:vcloud:<type>:<id>'", urn)
+	}
+	return strings.ToLower(urnElements[2]), nil
+}
+
+// getMetadataHrefByUrn returns the legacy API HREF of the entity identified by the given URN
+func getMetadataHrefByUrn(apiHref, urn string) (string, error) {
+	entityType, err := getUrnEntityType(urn)
+	if err != nil {
+		return "", err
+	}
+	path, found := metadataUrnTypePaths[entityType]
+	if !found {
+		var supportedTypes []string
+		for supportedType := range metadataUrnTypePaths {
+			supportedTypes = append(supportedTypes, supportedType)
+		}
+		sort.Strings(supportedTypes)
+		return "", fmt.Errorf("entity type '%s' does not support metadata. Supported types: %s", entityType, strings.Join(supportedTypes, ", "))
+	}
+	uuid := extractUuid(urn)
+	if uuid == "" {
+		return "", fmt.Errorf("could not find the UUID of '%s'", urn)
+	}
+	return strings.TrimSuffix(apiHref, "/") + fmt.Sprintf(path, uuid), nil
+}
+
+// getMetadataEntityByUrn returns the entity identified by the given URN as a metadataCompatible object
+func getMetadataEntityByUrn(vcdClient *VCDClient, urn string) (metadataCompatible, error) {
+	href, err := getMetadataHrefByUrn(vcdClient.Client.VCDHREF.String(), urn)
+	if err != nil {
+		return nil, err
+	}
+	return &metadataEntityByHref{vcdClient: vcdClient, href: href}, nil
+}
+
+// metadataEntityByHref handles the metadata of any entity through its HREF, so it can be used with the
+// functions that expect a metadataCompatible object
+type metadataEntityByHref struct {
+	vcdClient *VCDClient
+	href      string
+}
+
+func (entity *metadataEntityByHref) GetMetadataByKey(key string, isSystem bool) (*types.MetadataValue, error) {
+	metadata, err := entity.GetMetadata()
+	if err != nil {
+		return nil, err
+	}
+	for _, entry := range metadata.MetadataEntry {
+		entryIsSystem := entry.Domain != nil && entry.Domain.Domain == "SYSTEM"
+		if entry.Key == key && entryIsSystem == isSystem {
+			return &types.MetadataValue{Domain: entry.Domain, TypedValue: entry.TypedValue}, nil
+		}
+	}
+	return nil, fmt.Errorf("%s: metadata entry with key '%s'", govcd.ErrorEntityNotFound, key)
+}
+
+func (entity *metadataEntityByHref) GetMetadata() (*types.Metadata, error) {
+	return entity.vcdClient.GetMetadataByHref(entity.href)
+}
+
+func (entity *metadataEntityByHref) AddMetadataEntry(typedValue, key, value string) error {
+	return entity.vcdClient.AddMetadataEntryByHref(entity.href, typedValue, key, value)
+}
+
+func (entity *metadataEntityByHref) AddMetadataEntryWithVisibility(key, value, typedValue, visibility string, isSystem bool) error {
+	return entity.vcdClient.AddMetadataEntryWithVisibilityByHref(entity.href, key, value, typedValue, visibility, isSystem)
+}
+
+func (entity *metadataEntityByHref) MergeMetadataWithMetadataValues(metadata map[string]types.MetadataValue) error {
+	task, err := entity.vcdClient.MergeMetadataWithVisibilityByHrefAsync(entity.href, metadata)
+	if err != nil {
+		return err
+	}
+	return task.WaitTaskCompletion()
+}
+
+func (entity *metadataEntityByHref) MergeMetadata(typedValue string, metadata map[string]interface{}) error {
+	return entity.vcdClient.MergeMetadataByHref(entity.href, typedValue, metadata)
+}
+
+func (entity *metadataEntityByHref) DeleteMetadataEntry(key string) error {
+	return entity.vcdClient.DeleteMetadataEntryByHref(entity.href, key)
+}
+
+func (entity *metadataEntityByHref) DeleteMetadataEntryWithDomain(key string, isSystem bool) error {
+	return entity.vcdClient.DeleteMetadataEntryWithDomainByHref(entity.href, key, isSystem)
+}
@@ -0,0 +1,102 @@
+//go:build vdc || functional || ALL
+
+package vcd
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+)
+
+// TestAccVcdMetadata tags the VDC used in the tests with vcd_metadata, which does not own the VDC,
+// and checks the entries with the vcd_org_vdc data source
+func TestAccVcdMetadata(t *testing.T) {
+	preTestChecks(t)
+
+	var params = StringMap{
+		"Org":      testConfig.VCD.Org,
+		"Vdc":      testConfig.Nsxt.Vdc,
+		"Key":      t.Name(),
+		"Value":    "first",
+		"FuncName": t.Name() + "Step1",
+	}
+	testParamsNotEmpty(t, params)
+
+	configText1 := templateFill(testAccCheckVcdMetadata, params)
+	debugPrintf("#[DEBUG] CONFIGURATION 1: %s", configText1)
+
+	params["FuncName"] = t.Name() + "Step2"
+	params["Value"] = "second"
+	configText2 := templateFill(testAccCheckVcdMetadata, params)
+	debugPrintf("#[DEBUG] CONFIGURATION 2: %s", configText2)
+
+	if vcdShortTest {
+		t.Skip(acceptanceTestsSkipped)
+		return
+	}
+
+	resourceName := "vcd_metadata.tags"
+	resource.Test(t, resource.TestCase{
+		ProviderFactories: testAccProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: configText1,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestMatchResourceAttr(resourceName, "id", regexp.MustCompile(`^urn:vcloud:vdc:.+`)),
+					resource.TestCheckResourceAttr(resourceName, "entity_type", "vdc"),
+					resource.TestCheckResourceAttr(resourceName, "metadata_entry.#", "2"),
+					resource.TestCheckTypeSetElemNestedAttrs("data.vcd_org_vdc.tagged", "metadata_entry.*", map[string]string{
+						"key":   params["Key"].(string),
+						"value": "first",
+					}),
+				),
+			},
+			{
+				Config: configText2,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr(resourceName, "metadata_entry.#", "2"),
+					resource.TestCheckTypeSetElemNestedAttrs("data.vcd_org_vdc.tagged", "metadata_entry.*", map[string]string{
+						"key":   params["Key"].(string),
+						"value": "second",
+					}),
+				),
+			},
+			{
+				ResourceName:      resourceName,
+				ImportState:       true,
+				ImportStateVerify: false, // The import reads all the entries of the VDC
+			},
+		},
+	})
+	postTestChecks(t)
+}
+
+const testAccCheckVcdMetadata = `
+data "vcd_org_vdc" "vdc" {
+  org  = "{{.Org}}"
+  name = "{{.Vdc}}"
+}
+
+resource "vcd_metadata" "tags" {
+  entity_id = data.vcd_org_vdc.vdc.id
+
+  metadata_entry {
+    key   = "{{.Key}}"
+    value = "{{.Value}}"
+  }
+
+  metadata_entry {
+    key   = "{{.Key}}-number"
+    value = "42"
+    type  = "MetadataNumberValue"
+  }
+}
+
+data "vcd_org_vdc" "tagged" {
+  org  = "{{.Org}}"
+  name = "{{.Vdc}}"
+
+  depends_on = [vcd_metadata.tags]
+}
+`
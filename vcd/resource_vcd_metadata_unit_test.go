@@ -0,0 +1,64 @@
+//go:build unit || ALL
+
+package vcd
+
+import (
+	"testing"
+)
+
+func Test_getMetadataHrefByUrn(t *testing.T) {
+	tests := []struct {
+		urn      string
+		wantHref string
+		wantErr  bool
+	}{
+		{
+			urn:      "urn:vcloud:vm:9fa8cd3b-d6b9-4e1d-b7d7-8e7e1a4b3f8f",
+			wantHref: "https://vcd.example.com/api/vApp/vm-9fa8cd3b-d6b9-4e1d-b7d7-8e7e1a4b3f8f",
+		},
+		{
+			urn:      "urn:vcloud:vdcstorageProfile:9fa8cd3b-d6b9-4e1d-b7d7-8e7e1a4b3f8f",
+			wantHref: "https://vcd.example.com/api/admin/vdcStorageProfile/9fa8cd3b-d6b9-4e1d-b7d7-8e7e1a4b3f8f",
+		},
+		{
+			urn:      "urn:vcloud:vapptemplate:9fa8cd3b-d6b9-4e1d-b7d7-8e7e1a4b3f8f",
+			wantHref: "https://vcd.example.com/api/vAppTemplate/vappTemplate-9fa8cd3b-d6b9-4e1d-b7d7-8e7e1a4b3f8f",
+		},
+		{urn: "urn:vcloud:gateway:9fa8cd3b-d6b9-4e1d-b7d7-8e7e1a4b3f8f", wantErr: true},
+		{urn: "urn:vcloud:vm:not-a-uuid", wantErr: true},
+		{urn: "9fa8cd3b-d6b9-4e1d-b7d7-8e7e1a4b3f8f", wantErr: true},
+	}
+	for _, tt := range tests {
+		href, err := getMetadataHrefByUrn("https://vcd.example.com/api/", tt.urn)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("getMetadataHrefByUrn(%s) error = %v, wantErr %v", tt.urn, err, tt.wantErr)
+			continue
+		}
+		if href != tt.wantHref {
+			t.Errorf("getMetadataHrefByUrn(%s) = '%s', want '%s'", tt.urn, href, tt.wantHref)
+		}
+	}
+}
+
+func Test_getUrnEntityType(t *testing.T) {
+	tests := []struct {
+		urn     string
+		want    string
+		wantErr bool
+	}{
+		{urn: "urn:vcloud:firewallRule:9fa8cd3b-d6b9-4e1d-b7d7-8e7e1a4b3f8f", want: "firewallrule"},
+		{urn: "urn:vcloud:entity:vmware:k8s:9fa8cd3b-d6b9-4e1d-b7d7-8e7e1a4b3f8f", want: "entity"},
+		{urn: "urn:vcloud:vm", wantErr: true},
+		{urn: "urn:other:vm:9fa8cd3b-d6b9-4e1d-b7d7-8e7e1a4b3f8f", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := getUrnEntityType(tt.urn)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("getUrnEntityType(%s) error = %v, wantErr %v", tt.urn, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("getUrnEntityType(%s) = '%s', want '%s'", tt.urn, got, tt.want)
+		}
+	}
+}
@@ -150,10 +150,15 @@ func resourceVcdNsxtDistributedFirewall() *schema.Resource {
 // resourceVcdNsxtDistributedFirewallCreateUpdate is used in both Create and Update cases because
 // firewall rules don't have separate create or update methods. Firewall endpoint only uses HTTP PUT
 // for update.
-func resourceVcdNsxtDistributedFirewallCreateUpdate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+func resourceVcdNsxtDistributedFirewallCreateUpdate(ctx context.Context, d *schema.ResourceData, meta interface{}) (diags diag.Diagnostics) {
 	vcdClient := meta.(*VCDClient)
 	vcdClient.lockParentVdcGroup(d)
 	defer vcdClient.unlockParentVdcGroup(d)
+	unlockDistributed, err := vcdClient.lockDistributed(d.Get("vdc_group_id").(string))
+	if err != nil {
+		return diag.Errorf("[Distributed Firewall Create/Update] %s", err)
+	}
+	defer releaseDistributedLock(unlockDistributed, &diags)
 
 	org, err := vcdClient.GetOrgFromResource(d)
 	if err != nil {
@@ -210,10 +215,15 @@ func resourceVcdNsxtDistributedFirewallRead(_ context.Context, d *schema.Resourc
 	return nil
 }
 
-func resourceVcdNsxtDistributedFirewallDelete(_ context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+func resourceVcdNsxtDistributedFirewallDelete(_ context.Context, d *schema.ResourceData, meta interface{}) (diags diag.Diagnostics) {
 	vcdClient := meta.(*VCDClient)
 	vcdClient.lockParentVdcGroup(d)
 	defer vcdClient.unlockParentVdcGroup(d)
+	unlockDistributed, err := vcdClient.lockDistributed(d.Get("vdc_group_id").(string))
+	if err != nil {
+		return diag.Errorf("[Distributed Firewall Delete] %s", err)
+	}
+	defer releaseDistributedLock(unlockDistributed, &diags)
 
 	org, err := vcdClient.GetOrgFromResource(d)
 	if err != nil {
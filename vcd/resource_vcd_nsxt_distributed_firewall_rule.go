@@ -138,10 +138,15 @@ func resourceVcdNsxtDistributedFirewallRule() *schema.Resource {
 	}
 }
 
-func resourceVcdNsxtDistributedFirewallRuleCreate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+func resourceVcdNsxtDistributedFirewallRuleCreate(ctx context.Context, d *schema.ResourceData, meta interface{}) (diags diag.Diagnostics) {
 	vcdClient := meta.(*VCDClient)
 	vcdClient.lockParentVdcGroup(d)
 	defer vcdClient.unlockParentVdcGroup(d)
+	unlockDistributed, err := vcdClient.lockDistributed(d.Get("vdc_group_id").(string))
+	if err != nil {
+		return diag.Errorf("[Distributed Firewall Rule create] %s", err)
+	}
+	defer releaseDistributedLock(unlockDistributed, &diags)
 
 	org, err := vcdClient.GetOrgFromResource(d)
 	if err != nil {
@@ -167,10 +172,15 @@ func resourceVcdNsxtDistributedFirewallRuleCreate(ctx context.Context, d *schema
 	return resourceVcdNsxtDistributedFirewallRuleRead(ctx, d, meta)
 }
 
-func resourceVcdNsxtDistributedFirewallRuleUpdate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+func resourceVcdNsxtDistributedFirewallRuleUpdate(ctx context.Context, d *schema.ResourceData, meta interface{}) (diags diag.Diagnostics) {
 	vcdClient := meta.(*VCDClient)
 	vcdClient.lockParentVdcGroup(d)
 	defer vcdClient.unlockParentVdcGroup(d)
+	unlockDistributed, err := vcdClient.lockDistributed(d.Get("vdc_group_id").(string))
+	if err != nil {
+		return diag.Errorf("[Distributed Firewall Rule update] %s", err)
+	}
+	defer releaseDistributedLock(unlockDistributed, &diags)
 
 	org, err := vcdClient.GetOrgFromResource(d)
 	if err != nil {
@@ -230,10 +240,15 @@ func resourceVcdNsxtDistributedFirewallRuleRead(_ context.Context, d *schema.Res
 	return nil
 }
 
-func resourceVcdNsxtDistributedFirewallRuleDelete(_ context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+func resourceVcdNsxtDistributedFirewallRuleDelete(_ context.Context, d *schema.ResourceData, meta interface{}) (diags diag.Diagnostics) {
 	vcdClient := meta.(*VCDClient)
 	vcdClient.lockParentVdcGroup(d)
 	defer vcdClient.unlockParentVdcGroup(d)
+	unlockDistributed, err := vcdClient.lockDistributed(d.Get("vdc_group_id").(string))
+	if err != nil {
+		return diag.Errorf("[Distributed Firewall Rule delete] %s", err)
+	}
+	defer releaseDistributedLock(unlockDistributed, &diags)
 
 	org, err := vcdClient.GetOrgFromResource(d)
 	if err != nil {
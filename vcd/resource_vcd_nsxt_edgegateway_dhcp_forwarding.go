@@ -63,7 +63,7 @@ func resourceVcdNsxtEdgegatewayDhcpForwardingUpdate(ctx context.Context, d *sche
 	return resourceVcdNsxtEdgegatewayDhcpForwardingCreateUpdate(ctx, d, meta, "update")
 }
 
-func resourceVcdNsxtEdgegatewayDhcpForwardingCreateUpdate(ctx context.Context, d *schema.ResourceData, meta interface{}, method string) diag.Diagnostics {
+func resourceVcdNsxtEdgegatewayDhcpForwardingCreateUpdate(ctx context.Context, d *schema.ResourceData, meta interface{}, method string) (diags diag.Diagnostics) {
 	vcdClient := meta.(*VCDClient)
 
 	unlock, err := vcdClient.lockParentVdcGroupOrEdgeGateway(d)
@@ -71,7 +71,7 @@ func resourceVcdNsxtEdgegatewayDhcpForwardingCreateUpdate(ctx context.Context, d
 		return diag.Errorf("[DHCP forwarding %s] %s", method, err)
 	}
 
-	defer unlock()
+	defer releaseDistributedLock(unlock, &diags)
 
 	orgName := d.Get("org").(string)
 	edgeGatewayId := d.Get("edge_gateway_id").(string)
@@ -93,7 +93,6 @@ func resourceVcdNsxtEdgegatewayDhcpForwardingCreateUpdate(ctx context.Context, d
 
 	d.SetId(edgeGatewayId)
 
-	var diags diag.Diagnostics
 	if !dhcpForwardConfig.Enabled && d.HasChange("dhcp_servers") {
 		diags = append(diags, diag.Diagnostic{
 			Severity: diag.Warning,
@@ -150,7 +149,7 @@ func genericVcdNsxtEdgegatewayDhcpForwardingRead(_ context.Context, d *schema.Re
 	return nil
 }
 
-func resourceVcdNsxtEdgegatewayDhcpForwardingDelete(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+func resourceVcdNsxtEdgegatewayDhcpForwardingDelete(ctx context.Context, d *schema.ResourceData, meta interface{}) (diags diag.Diagnostics) {
 	vcdClient := meta.(*VCDClient)
 
 	unlock, err := vcdClient.lockParentVdcGroupOrEdgeGateway(d)
@@ -158,7 +157,7 @@ func resourceVcdNsxtEdgegatewayDhcpForwardingDelete(ctx context.Context, d *sche
 		return diag.Errorf("[DHCP forwarding delete] %s", err)
 	}
 
-	defer unlock()
+	defer releaseDistributedLock(unlock, &diags)
 
 	orgName := d.Get("org").(string)
 	edgeGatewayId := d.Get("edge_gateway_id").(string)
@@ -71,14 +71,14 @@ func resourceVcdNsxtEdgegatewayDhcpV6Update(ctx context.Context, d *schema.Resou
 	return resourceVcdNsxtEdgegatewayDhcpV6CreateUpdate(ctx, d, meta, "update")
 }
 
-func resourceVcdNsxtEdgegatewayDhcpV6CreateUpdate(ctx context.Context, d *schema.ResourceData, meta interface{}, origin string) diag.Diagnostics {
+func resourceVcdNsxtEdgegatewayDhcpV6CreateUpdate(ctx context.Context, d *schema.ResourceData, meta interface{}, origin string) (diags diag.Diagnostics) {
 	vcdClient := meta.(*VCDClient)
 
 	unlock, err := vcdClient.lockParentVdcGroupOrEdgeGateway(d)
 	if err != nil {
 		return diag.Errorf("[dhcpv6 (SLAAC Profile) %s] %s", origin, err)
 	}
-	defer unlock()
+	defer releaseDistributedLock(unlock, &diags)
 
 	orgName := d.Get("org").(string)
 	edgeGatewayId := d.Get("edge_gateway_id").(string)
@@ -133,14 +133,14 @@ func resourceVcdNsxtEdgegatewayDhcpV6Read(ctx context.Context, d *schema.Resourc
 	return nil
 }
 
-func resourceVcdNsxtEdgegatewayDhcpV6Delete(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+func resourceVcdNsxtEdgegatewayDhcpV6Delete(ctx context.Context, d *schema.ResourceData, meta interface{}) (diags diag.Diagnostics) {
 	vcdClient := meta.(*VCDClient)
 
 	unlock, err := vcdClient.lockParentVdcGroupOrEdgeGateway(d)
 	if err != nil {
 		return diag.Errorf("[dhcpv6 (SLAAC Profile) delete] %s", err)
 	}
-	defer unlock()
+	defer releaseDistributedLock(unlock, &diags)
 
 	orgName := d.Get("org").(string)
 	edgeGatewayId := d.Get("edge_gateway_id").(string)
@@ -147,14 +147,14 @@ func resourceVcdNsxtEdgegatewayDnsUpdate(ctx context.Context, d *schema.Resource
 	return resourceVcdNsxtEdgegatewayDnsCreateUpdate(ctx, d, meta, "update")
 }
 
-func resourceVcdNsxtEdgegatewayDnsCreateUpdate(ctx context.Context, d *schema.ResourceData, meta interface{}, origin string) diag.Diagnostics {
+func resourceVcdNsxtEdgegatewayDnsCreateUpdate(ctx context.Context, d *schema.ResourceData, meta interface{}, origin string) (diags diag.Diagnostics) {
 	vcdClient := meta.(*VCDClient)
 
-	unlock, err := vcdClient.lockParentVdcGroupOrEdgeGateway(d)
+	unlock, err := vcdClient.lockParentVdcGroupOrEdgeGatewayForRuleType(d, "dns")
 	if err != nil {
 		return diag.Errorf("[edge gateway dns %s] %s", origin, err)
 	}
-	defer unlock()
+	defer releaseDistributedLock(unlock, &diags)
 
 	orgName := d.Get("org").(string)
 	edgeGatewayId := d.Get("edge_gateway_id").(string)
@@ -238,14 +238,14 @@ func genericVcdNsxtEdgegatewayDnsRead(_ context.Context, d *schema.ResourceData,
 	return nil
 }
 
-func resourceVcdNsxtEdgegatewayDnsDelete(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+func resourceVcdNsxtEdgegatewayDnsDelete(ctx context.Context, d *schema.ResourceData, meta interface{}) (diags diag.Diagnostics) {
 	vcdClient := meta.(*VCDClient)
 
-	unlock, err := vcdClient.lockParentVdcGroupOrEdgeGateway(d)
+	unlock, err := vcdClient.lockParentVdcGroupOrEdgeGatewayForRuleType(d, "dns")
 	if err != nil {
 		return diag.Errorf("[edge gateway dns delete] %s", err)
 	}
-	defer unlock()
+	defer releaseDistributedLock(unlock, &diags)
 
 	orgName := d.Get("org").(string)
 	edgeGatewayId := d.Get("edge_gateway_id").(string)
@@ -128,14 +128,14 @@ var stretchedNetwork = &schema.Resource{
 	},
 }
 
-func resourceVcdNsxtEdgegatewayL2VpnTunnelCreate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+func resourceVcdNsxtEdgegatewayL2VpnTunnelCreate(ctx context.Context, d *schema.ResourceData, meta interface{}) (diags diag.Diagnostics) {
 	vcdClient := meta.(*VCDClient)
 
-	unlock, err := vcdClient.lockParentVdcGroupOrEdgeGateway(d)
+	unlock, err := vcdClient.lockParentVdcGroupOrEdgeGatewayForRuleType(d, "l2_vpn")
 	if err != nil {
 		return diag.Errorf("[L2 VPN Tunnel create] %s", err)
 	}
-	defer unlock()
+	defer releaseDistributedLock(unlock, &diags)
 
 	orgName := d.Get("org").(string)
 	edgeGatewayId := d.Get("edge_gateway_id").(string)
@@ -202,14 +202,14 @@ func genericNsxtEdgegatewayL2VpnTunnelRead(_ context.Context, d *schema.Resource
 	return nil
 }
 
-func resourceVcdNsxtEdgegatewayL2VpnTunnelUpdate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+func resourceVcdNsxtEdgegatewayL2VpnTunnelUpdate(ctx context.Context, d *schema.ResourceData, meta interface{}) (diags diag.Diagnostics) {
 	vcdClient := meta.(*VCDClient)
 
-	unlock, err := vcdClient.lockParentVdcGroupOrEdgeGateway(d)
+	unlock, err := vcdClient.lockParentVdcGroupOrEdgeGatewayForRuleType(d, "l2_vpn")
 	if err != nil {
 		return diag.Errorf("[L2 VPN Tunnel update] %s", err)
 	}
-	defer unlock()
+	defer releaseDistributedLock(unlock, &diags)
 
 	orgName := d.Get("org").(string)
 	edgeGatewayId := d.Get("edge_gateway_id").(string)
@@ -236,14 +236,14 @@ func resourceVcdNsxtEdgegatewayL2VpnTunnelUpdate(ctx context.Context, d *schema.
 	return resourceVcdNsxtEdgegatewayL2VpnTunnelRead(ctx, d, meta)
 }
 
-func resourceVcdNsxtEdgegatewayL2VpnTunnelDestroy(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+func resourceVcdNsxtEdgegatewayL2VpnTunnelDestroy(ctx context.Context, d *schema.ResourceData, meta interface{}) (diags diag.Diagnostics) {
 	vcdClient := meta.(*VCDClient)
 
-	unlock, err := vcdClient.lockParentVdcGroupOrEdgeGateway(d)
+	unlock, err := vcdClient.lockParentVdcGroupOrEdgeGatewayForRuleType(d, "l2_vpn")
 	if err != nil {
 		return diag.Errorf("[L2 VPN Tunnel destroy] %s", err)
 	}
-	defer unlock()
+	defer releaseDistributedLock(unlock, &diags)
 
 	orgName := d.Get("org").(string)
 	edgeGatewayId := d.Get("edge_gateway_id").(string)
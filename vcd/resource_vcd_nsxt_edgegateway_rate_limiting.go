@@ -50,7 +50,7 @@ func resourceVcdNsxtEdgegatewayRateLimiting() *schema.Resource {
 	}
 }
 
-func resourceVcdNsxtEdgegatewayRateLimitingCreateUpdate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+func resourceVcdNsxtEdgegatewayRateLimitingCreateUpdate(ctx context.Context, d *schema.ResourceData, meta interface{}) (diags diag.Diagnostics) {
 	vcdClient := meta.(*VCDClient)
 
 	unlock, err := vcdClient.lockParentVdcGroupOrEdgeGateway(d)
@@ -58,7 +58,7 @@ func resourceVcdNsxtEdgegatewayRateLimitingCreateUpdate(ctx context.Context, d *
 		return diag.Errorf("[rate limiting (QoS) create/update] %s", err)
 	}
 
-	defer unlock()
+	defer releaseDistributedLock(unlock, &diags)
 
 	orgName := d.Get("org").(string)
 	edgeGatewayId := d.Get("edge_gateway_id").(string)
@@ -110,7 +110,7 @@ func resourceVcdNsxtEdgegatewayRateLimitingRead(ctx context.Context, d *schema.R
 	return nil
 }
 
-func resourceVcdNsxtEdgegatewayRateLimitingDelete(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+func resourceVcdNsxtEdgegatewayRateLimitingDelete(ctx context.Context, d *schema.ResourceData, meta interface{}) (diags diag.Diagnostics) {
 	vcdClient := meta.(*VCDClient)
 
 	unlock, err := vcdClient.lockParentVdcGroupOrEdgeGateway(d)
@@ -118,7 +118,7 @@ func resourceVcdNsxtEdgegatewayRateLimitingDelete(ctx context.Context, d *schema
 		return diag.Errorf("[rate limiting (QoS) delete] %s", err)
 	}
 
-	defer unlock()
+	defer releaseDistributedLock(unlock, &diags)
 
 	orgName := d.Get("org").(string)
 	edgeGatewayId := d.Get("edge_gateway_id").(string)
@@ -101,14 +101,14 @@ func resourceVcdNsxtEdgeGatewayStaticRoute() *schema.Resource {
 	}
 }
 
-func resourceVcdNsxtEdgeGatewayStaticRouteCreate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+func resourceVcdNsxtEdgeGatewayStaticRouteCreate(ctx context.Context, d *schema.ResourceData, meta interface{}) (diags diag.Diagnostics) {
 	vcdClient := meta.(*VCDClient)
 
-	unlock, err := vcdClient.lockParentVdcGroupOrEdgeGateway(d)
+	unlock, err := vcdClient.lockParentVdcGroupOrEdgeGatewayForRuleType(d, "static_route")
 	if err != nil {
 		return diag.Errorf("[NSX-T Edge Gateway Static Route create] %s", err)
 	}
-	defer unlock()
+	defer releaseDistributedLock(unlock, &diags)
 
 	orgName := d.Get("org").(string)
 	edgeGatewayId := d.Get("edge_gateway_id").(string)
@@ -135,14 +135,14 @@ func resourceVcdNsxtEdgeGatewayStaticRouteCreate(ctx context.Context, d *schema.
 	return resourceVcdNsxtEdgeGatewayStaticRouteRead(ctx, d, meta)
 }
 
-func resourceVcdNsxtEdgeGatewayStaticRouteUpdate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+func resourceVcdNsxtEdgeGatewayStaticRouteUpdate(ctx context.Context, d *schema.ResourceData, meta interface{}) (diags diag.Diagnostics) {
 	vcdClient := meta.(*VCDClient)
 
-	unlock, err := vcdClient.lockParentVdcGroupOrEdgeGateway(d)
+	unlock, err := vcdClient.lockParentVdcGroupOrEdgeGatewayForRuleType(d, "static_route")
 	if err != nil {
 		return diag.Errorf("[NSX-T Edge Gateway Static Route update] %s", err)
 	}
-	defer unlock()
+	defer releaseDistributedLock(unlock, &diags)
 
 	orgName := d.Get("org").(string)
 	edgeGatewayId := d.Get("edge_gateway_id").(string)
@@ -196,14 +196,14 @@ func resourceVcdNsxtEdgeGatewayStaticRouteRead(ctx context.Context, d *schema.Re
 	return nil
 }
 
-func resourceVcdNsxtEdgeGatewayStaticRouteDelete(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+func resourceVcdNsxtEdgeGatewayStaticRouteDelete(ctx context.Context, d *schema.ResourceData, meta interface{}) (diags diag.Diagnostics) {
 	vcdClient := meta.(*VCDClient)
 
-	unlock, err := vcdClient.lockParentVdcGroupOrEdgeGateway(d)
+	unlock, err := vcdClient.lockParentVdcGroupOrEdgeGatewayForRuleType(d, "static_route")
 	if err != nil {
 		return diag.Errorf("[NSX-T Edge Gateway Static Route delete] %s", err)
 	}
-	defer unlock()
+	defer releaseDistributedLock(unlock, &diags)
 
 	orgName := d.Get("org").(string)
 	edgeGatewayId := d.Get("edge_gateway_id").(string)
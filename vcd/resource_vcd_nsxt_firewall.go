@@ -126,15 +126,15 @@ func resourceVcdNsxtFirewall() *schema.Resource {
 }
 
 // resourceVcdNsxtFirewallCreateUpdate is the same function used for both - Create and Update
-func resourceVcdNsxtFirewallCreateUpdate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+func resourceVcdNsxtFirewallCreateUpdate(ctx context.Context, d *schema.ResourceData, meta interface{}) (diags diag.Diagnostics) {
 	vcdClient := meta.(*VCDClient)
 
-	unlock, err := vcdClient.lockParentVdcGroupOrEdgeGateway(d)
+	unlock, err := vcdClient.lockParentVdcGroupOrEdgeGatewayForRuleType(d, "firewall")
 	if err != nil {
 		return diag.Errorf("[nsx-t firewall create/update] %s", err)
 	}
 
-	defer unlock()
+	defer releaseDistributedLock(unlock, &diags)
 
 	orgName := d.Get("org").(string)
 	edgeGatewayId := d.Get("edge_gateway_id").(string)
@@ -195,15 +195,15 @@ func resourceVcdNsxtFirewallRead(_ context.Context, d *schema.ResourceData, meta
 	return nil
 }
 
-func resourceVcdNsxtFirewallDelete(_ context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+func resourceVcdNsxtFirewallDelete(_ context.Context, d *schema.ResourceData, meta interface{}) (diags diag.Diagnostics) {
 	vcdClient := meta.(*VCDClient)
 
-	unlock, err := vcdClient.lockParentVdcGroupOrEdgeGateway(d)
+	unlock, err := vcdClient.lockParentVdcGroupOrEdgeGatewayForRuleType(d, "firewall")
 	if err != nil {
 		return diag.Errorf("[nsx-t firewall delete] %s", err)
 	}
 
-	defer unlock()
+	defer releaseDistributedLock(unlock, &diags)
 
 	orgName := d.Get("org").(string)
 	edgeGatewayId := d.Get("edge_gateway_id").(string)
@@ -257,15 +257,15 @@ func resourceVcdNsxtIpSecVpnTunnel() *schema.Resource {
 	}
 }
 
-func resourceVcdNsxtIpSecVpnTunnelCreate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+func resourceVcdNsxtIpSecVpnTunnelCreate(ctx context.Context, d *schema.ResourceData, meta interface{}) (diags diag.Diagnostics) {
 	vcdClient := meta.(*VCDClient)
 
-	unlock, err := vcdClient.lockParentVdcGroupOrEdgeGateway(d)
+	unlock, err := vcdClient.lockParentVdcGroupOrEdgeGatewayForRuleType(d, "ipsec_vpn")
 	if err != nil {
 		return diag.Errorf("[nsx-t ipsec vpn tunnel create] %s", err)
 	}
 
-	defer unlock()
+	defer releaseDistributedLock(unlock, &diags)
 
 	orgName := d.Get("org").(string)
 	edgeGatewayId := d.Get("edge_gateway_id").(string)
@@ -307,15 +307,15 @@ func resourceVcdNsxtIpSecVpnTunnelCreate(ctx context.Context, d *schema.Resource
 	return resourceVcdNsxtIpSecVpnTunnelRead(ctx, d, meta)
 }
 
-func resourceVcdNsxtIpSecVpnTunnelUpdate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+func resourceVcdNsxtIpSecVpnTunnelUpdate(ctx context.Context, d *schema.ResourceData, meta interface{}) (diags diag.Diagnostics) {
 	vcdClient := meta.(*VCDClient)
 
-	unlock, err := vcdClient.lockParentVdcGroupOrEdgeGateway(d)
+	unlock, err := vcdClient.lockParentVdcGroupOrEdgeGatewayForRuleType(d, "ipsec_vpn")
 	if err != nil {
 		return diag.Errorf("[nsx-t ipsec vpn tunnel update] %s", err)
 	}
 
-	defer unlock()
+	defer releaseDistributedLock(unlock, &diags)
 
 	orgName := d.Get("org").(string)
 	edgeGatewayId := d.Get("edge_gateway_id").(string)
@@ -411,15 +411,15 @@ func resourceVcdNsxtIpSecVpnTunnelRead(_ context.Context, d *schema.ResourceData
 	return nil
 }
 
-func resourceVcdNsxtIpSecVpnTunnelDelete(_ context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+func resourceVcdNsxtIpSecVpnTunnelDelete(_ context.Context, d *schema.ResourceData, meta interface{}) (diags diag.Diagnostics) {
 	vcdClient := meta.(*VCDClient)
 
-	unlock, err := vcdClient.lockParentVdcGroupOrEdgeGateway(d)
+	unlock, err := vcdClient.lockParentVdcGroupOrEdgeGatewayForRuleType(d, "ipsec_vpn")
 	if err != nil {
 		return diag.Errorf("[nsx-t ipsec vpn tunnel delete] %s", err)
 	}
 
-	defer unlock()
+	defer releaseDistributedLock(unlock, &diags)
 
 	orgName := d.Get("org").(string)
 	edgeGatewayId := d.Get("edge_gateway_id").(string)
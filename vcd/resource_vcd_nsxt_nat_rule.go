@@ -118,15 +118,15 @@ func resourceVcdNsxtNatRule() *schema.Resource {
 	}
 }
 
-func resourceVcdNsxtNatRuleCreate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+func resourceVcdNsxtNatRuleCreate(ctx context.Context, d *schema.ResourceData, meta interface{}) (diags diag.Diagnostics) {
 	vcdClient := meta.(*VCDClient)
 
-	unlock, err := vcdClient.lockParentVdcGroupOrEdgeGateway(d)
+	unlock, err := vcdClient.lockParentVdcGroupOrEdgeGatewayForRuleType(d, "nat")
 	if err != nil {
 		return diag.Errorf("[nsx-t nat rule create] %s", err)
 	}
 
-	defer unlock()
+	defer releaseDistributedLock(unlock, &diags)
 
 	orgName := d.Get("org").(string)
 	edgeGatewayId := d.Get("edge_gateway_id").(string)
@@ -155,15 +155,15 @@ func resourceVcdNsxtNatRuleCreate(ctx context.Context, d *schema.ResourceData, m
 	return resourceVcdNsxtNatRuleRead(ctx, d, meta)
 }
 
-func resourceVcdNsxtNatRuleUpdate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+func resourceVcdNsxtNatRuleUpdate(ctx context.Context, d *schema.ResourceData, meta interface{}) (diags diag.Diagnostics) {
 	vcdClient := meta.(*VCDClient)
 
-	unlock, err := vcdClient.lockParentVdcGroupOrEdgeGateway(d)
+	unlock, err := vcdClient.lockParentVdcGroupOrEdgeGatewayForRuleType(d, "nat")
 	if err != nil {
 		return diag.Errorf("[nsx-t nat rule update] %s", err)
 	}
 
-	defer unlock()
+	defer releaseDistributedLock(unlock, &diags)
 
 	orgName := d.Get("org").(string)
 	edgeGatewayId := d.Get("edge_gateway_id").(string)
@@ -220,15 +220,15 @@ func resourceVcdNsxtNatRuleRead(_ context.Context, d *schema.ResourceData, meta
 	return nil
 }
 
-func resourceVcdNsxtNatRuleDelete(_ context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+func resourceVcdNsxtNatRuleDelete(_ context.Context, d *schema.ResourceData, meta interface{}) (diags diag.Diagnostics) {
 	vcdClient := meta.(*VCDClient)
 
-	unlock, err := vcdClient.lockParentVdcGroupOrEdgeGateway(d)
+	unlock, err := vcdClient.lockParentVdcGroupOrEdgeGatewayForRuleType(d, "nat")
 	if err != nil {
 		return diag.Errorf("[nsx-t nat rule delete] %s", err)
 	}
 
-	defer unlock()
+	defer releaseDistributedLock(unlock, &diags)
 
 	orgName := d.Get("org").(string)
 	edgeGatewayId := d.Get("edge_gateway_id").(string)
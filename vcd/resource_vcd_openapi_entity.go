@@ -0,0 +1,381 @@
+package vcd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+	"github.com/vmware/go-vcloud-director/v3/govcd"
+	"github.com/vmware/go-vcloud-director/v3/types/v56"
+)
+
+const labelOpenApiEntity = "OpenAPI entity"
+
+// openApiEntityIdPlaceholder is replaced by the entity ID in the paths given to vcd_openapi_entity
+const openApiEntityIdPlaceholder = "{id}"
+
+func resourceVcdOpenApiEntity() *schema.Resource {
+	return &schema.Resource{
+		CreateContext: resourceVcdOpenApiEntityCreate,
+		ReadContext:   resourceVcdOpenApiEntityRead,
+		UpdateContext: resourceVcdOpenApiEntityUpdate,
+		DeleteContext: resourceVcdOpenApiEntityDelete,
+		Importer: &schema.ResourceImporter{
+			StateContext: resourceVcdOpenApiEntityImport,
+		},
+		Schema: map[string]*schema.Schema{
+			"org": {
+				Type:     schema.TypeString,
+				Optional: true,
+				Description: "The name of the organization used as tenant context for the requests. " +
+					"If empty, the requests are sent in the context of the logged in user",
+			},
+			"endpoint": {
+				Type:        schema.TypeString,
+				Required:    true,
+				ForceNew:    true,
+				Description: "OpenAPI endpoint where the entity is created, relative to '/cloudapi/' (e.g. '1.0.0/edgeGateways/')",
+			},
+			"api_version": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "API version used for the requests. If empty, the API version of the provider is used",
+			},
+			"body": {
+				Type:                  schema.TypeString,
+				Required:              true,
+				Description:           "JSON body of the entity. Only the fields given here are compared with the entity in VCD",
+				ValidateFunc:          validation.StringIsJSON,
+				DiffSuppressFunc:      hasJsonValueChanged,
+				DiffSuppressOnRefresh: true,
+			},
+			"id_path": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				ForceNew:    true,
+				Default:     "id",
+				Description: "Path of the entity ID in the creation response, with dot separated keys and list indexes (e.g. 'id' or 'entity.id')",
+			},
+			"read_path": {
+				Type:     schema.TypeString,
+				Optional: true,
+				Description: "Path used to read the entity, relative to '/cloudapi/', where '{id}' is replaced by the entity ID. " +
+					"If empty, the entity ID is appended to 'endpoint'",
+			},
+			"update_path": {
+				Type:     schema.TypeString,
+				Optional: true,
+				Description: "Path used to update the entity, relative to '/cloudapi/', where '{id}' is replaced by the entity ID. " +
+					"If empty, the entity ID is appended to 'endpoint'",
+			},
+			"delete_path": {
+				Type:     schema.TypeString,
+				Optional: true,
+				Description: "Path used to delete the entity, relative to '/cloudapi/', where '{id}' is replaced by the entity ID. " +
+					"If empty, the entity ID is appended to 'endpoint'",
+			},
+			"output": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "JSON representation of the whole entity, as returned by VCD",
+			},
+		},
+	}
+}
+
+func resourceVcdOpenApiEntityCreate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	vcdClient := meta.(*VCDClient)
+
+	apiVersion, headers, err := getOpenApiEntityRequestSettings(vcdClient, d)
+	if err != nil {
+		return diag.Errorf("error creating %s: %s", labelOpenApiEntity, err)
+	}
+	endpoint := d.Get("endpoint").(string)
+	// A trailing slash is needed to retrieve the created entity when the creation runs as a task
+	if !strings.HasSuffix(endpoint, "/") {
+		endpoint += "/"
+	}
+	urlRef, err := vcdClient.Client.OpenApiBuildEndpoint(endpoint)
+	if err != nil {
+		return diag.Errorf("error building endpoint '%s' for %s: %s", endpoint, labelOpenApiEntity, err)
+	}
+
+	var created interface{}
+	err = vcdClient.Client.OpenApiPostItem(apiVersion, urlRef, nil, json.RawMessage(d.Get("body").(string)), &created, headers)
+	if err != nil {
+		return diag.Errorf("error creating %s in endpoint '%s': %s", labelOpenApiEntity, endpoint, err)
+	}
+
+	idPath := d.Get("id_path").(string)
+	id, err := getJsonValueByPath(created, idPath)
+	if err != nil {
+		return diag.Errorf("%s was created, but its ID could not be found with path '%s': %s", labelOpenApiEntity, idPath, err)
+	}
+	d.SetId(id)
+
+	return resourceVcdOpenApiEntityRead(ctx, d, meta)
+}
+
+func resourceVcdOpenApiEntityRead(_ context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	vcdClient := meta.(*VCDClient)
+
+	entity, err := getOpenApiEntity(vcdClient, d)
+	if govcd.ContainsNotFound(err) {
+		log.Printf("[DEBUG] %s '%s' not found. Removing from state", labelOpenApiEntity, d.Id())
+		d.SetId("")
+		return nil
+	}
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	output, err := json.Marshal(entity)
+	if err != nil {
+		return diag.Errorf("error marshaling %s '%s': %s", labelOpenApiEntity, d.Id(), err)
+	}
+	dSet(d, "output", string(output))
+
+	// Only the fields given in 'body' are compared, as VCD adds many computed fields to the entities.
+	// When they differ, 'body' is replaced with the values found in VCD, so that Terraform shows the drift
+	var body interface{}
+	err = json.Unmarshal([]byte(d.Get("body").(string)), &body)
+	if err != nil || body == nil {
+		// This happens during import, when there is no body yet
+		dSet(d, "body", string(output))
+		return nil
+	}
+	projected, err := json.Marshal(projectJsonValue(entity, body))
+	if err != nil {
+		return diag.Errorf("error marshaling %s '%s': %s", labelOpenApiEntity, d.Id(), err)
+	}
+	if !hasJsonValueChanged("body", d.Get("body").(string), string(projected), d) {
+		dSet(d, "body", string(projected))
+	}
+	return nil
+}
+
+func resourceVcdOpenApiEntityUpdate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	vcdClient := meta.(*VCDClient)
+
+	if !d.HasChange("body") {
+		return resourceVcdOpenApiEntityRead(ctx, d, meta)
+	}
+
+	apiVersion, headers, err := getOpenApiEntityRequestSettings(vcdClient, d)
+	if err != nil {
+		return diag.Errorf("error updating %s '%s': %s", labelOpenApiEntity, d.Id(), err)
+	}
+
+	// OpenAPI endpoints usually require the whole entity in updates, so the body is merged into the current entity
+	entity, err := getOpenApiEntity(vcdClient, d)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	var body interface{}
+	err = json.Unmarshal([]byte(d.Get("body").(string)), &body)
+	if err != nil {
+		return diag.Errorf("error unmarshaling body of %s '%s': %s", labelOpenApiEntity, d.Id(), err)
+	}
+	payload := mergeJsonValue(entity, body)
+
+	urlRef, err := vcdClient.Client.OpenApiBuildEndpoint(getOpenApiEntityPath(d, "update_path"))
+	if err != nil {
+		return diag.Errorf("error building update path for %s '%s': %s", labelOpenApiEntity, d.Id(), err)
+	}
+	var updated interface{}
+	err = vcdClient.Client.OpenApiPutItem(apiVersion, urlRef, nil, payload, &updated, headers)
+	if err != nil {
+		return diag.Errorf("error updating %s '%s': %s", labelOpenApiEntity, d.Id(), err)
+	}
+
+	return resourceVcdOpenApiEntityRead(ctx, d, meta)
+}
+
+func resourceVcdOpenApiEntityDelete(_ context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	vcdClient := meta.(*VCDClient)
+
+	apiVersion, headers, err := getOpenApiEntityRequestSettings(vcdClient, d)
+	if err != nil {
+		return diag.Errorf("error deleting %s '%s': %s", labelOpenApiEntity, d.Id(), err)
+	}
+	urlRef, err := vcdClient.Client.OpenApiBuildEndpoint(getOpenApiEntityPath(d, "delete_path"))
+	if err != nil {
+		return diag.Errorf("error building delete path for %s '%s': %s", labelOpenApiEntity, d.Id(), err)
+	}
+	err = vcdClient.Client.OpenApiDeleteItem(apiVersion, urlRef, nil, headers)
+	if err != nil && !govcd.ContainsNotFound(err) {
+		return diag.Errorf("error deleting %s '%s': %s", labelOpenApiEntity, d.Id(), err)
+	}
+	return nil
+}
+
+// resourceVcdOpenApiEntityImport imports an entity using its full path, relative to '/cloudapi/', in which the entity
+// ID is the last element.
+// Example import path (_the_id_string_): 1.0.0/edgeGateways/urn:vcloud:gateway:9fa8cd3b-d6b9-4e1d-b7d7-8e7e1a4b3f8f
+// The 'body' of the imported entity contains all its fields, and can be reduced in the configuration
+func resourceVcdOpenApiEntityImport(_ context.Context, d *schema.ResourceData, meta interface{}) ([]*schema.ResourceData, error) {
+	vcdClient := meta.(*VCDClient)
+
+	lastSlash := strings.LastIndex(d.Id(), "/")
+	if lastSlash < 1 || lastSlash == len(d.Id())-1 {
+		return nil, fmt.Errorf("resource name must be specified as endpoint/entity-id (e.g. '1.0.0/edgeGateways/urn:vcloud:gateway:...')")
+	}
+	endpoint, id := d.Id()[:lastSlash+1], d.Id()[lastSlash+1:]
+	dSet(d, "endpoint", endpoint)
+	dSet(d, "id_path", "id")
+	d.SetId(id)
+
+	entity, err := getOpenApiEntity(vcdClient, d)
+	if err != nil {
+		return nil, err
+	}
+	body, err := json.Marshal(entity)
+	if err != nil {
+		return nil, fmt.Errorf("error marshaling %s '%s': %s", labelOpenApiEntity, id, err)
+	}
+	dSet(d, "body", string(body))
+	return []*schema.ResourceData{d}, nil
+}
+
+// getOpenApiEntity retrieves the entity identified by the resource ID, using 'read_path' or the default path
+func getOpenApiEntity(vcdClient *VCDClient, d *schema.ResourceData) (interface{}, error) {
+	apiVersion, headers, err := getOpenApiEntityRequestSettings(vcdClient, d)
+	if err != nil {
+		return nil, fmt.Errorf("error reading %s '%s': %s", labelOpenApiEntity, d.Id(), err)
+	}
+	urlRef, err := vcdClient.Client.OpenApiBuildEndpoint(getOpenApiEntityPath(d, "read_path"))
+	if err != nil {
+		return nil, fmt.Errorf("error building read path for %s '%s': %s", labelOpenApiEntity, d.Id(), err)
+	}
+	var entity interface{}
+	err = vcdClient.Client.OpenApiGetItem(apiVersion, urlRef, nil, &entity, headers)
+	if err != nil {
+		return nil, fmt.Errorf("error reading %s '%s': %s", labelOpenApiEntity, d.Id(), err)
+	}
+	return entity, nil
+}
+
+// getOpenApiEntityPath returns the path stored in the given field, with the ID placeholder replaced by the entity ID.
+// If the field is empty, it returns the endpoint followed by the entity ID
+func getOpenApiEntityPath(d *schema.ResourceData, field string) string {
+	path := d.Get(field).(string)
+	if path == "" {
+		return strings.TrimSuffix(d.Get("endpoint").(string), "/") + "/" + d.Id()
+	}
+	return strings.ReplaceAll(path, openApiEntityIdPlaceholder, d.Id())
+}
+
+// getOpenApiEntityRequestSettings returns the API version and the tenant context headers to use in the requests
+func getOpenApiEntityRequestSettings(vcdClient *VCDClient, d *schema.ResourceData) (string, map[string]string, error) {
+	apiVersion := d.Get("api_version").(string)
+	if apiVersion == "" {
+		apiVersion = vcdClient.Client.APIVersion
+	}
+	orgName := d.Get("org").(string)
+	if orgName == "" {
+		return apiVersion, nil, nil
+	}
+	org, err := vcdClient.GetOrg(orgName)
+	if err != nil {
+		return "", nil, fmt.Errorf("error retrieving Org '%s': %s", orgName, err)
+	}
+	return apiVersion, map[string]string{
+		types.HeaderTenantContext: org.Org.ID,
+		types.HeaderAuthContext:   org.Org.Name,
+	}, nil
+}
+
+// getJsonValueByPath returns the value found in a decoded JSON following a path of dot separated keys.
+// Numeric elements of the path are used as list indexes. The value must be a string or a number
+func getJsonValueByPath(value interface{}, path string) (string, error) {
+	current := value
+	for _, element := range strings.Split(path, ".") {
+		switch typedValue := current.(type) {
+		case map[string]interface{}:
+			next, ok := typedValue[element]
+			if !ok {
+				return "", fmt.Errorf("key '%s' not found", element)
+			}
+			current = next
+		case []interface{}:
+			index, err := strconv.Atoi(element)
+			if err != nil || index < 0 || index >= len(typedValue) {
+				return "", fmt.Errorf("invalid list index '%s'", element)
+			}
+			current = typedValue[index]
+		default:
+			return "", fmt.Errorf("element '%s' can't be found in a scalar value", element)
+		}
+	}
+	switch typedValue := current.(type) {
+	case string:
+		if typedValue == "" {
+			return "", fmt.Errorf("value in path '%s' is empty", path)
+		}
+		return typedValue, nil
+	case float64:
+		return strconv.FormatFloat(typedValue, 'f', -1, 64), nil
+	default:
+		return "", fmt.Errorf("value in path '%s' is not a string or a number", path)
+	}
+}
+
+// projectJsonValue returns the parts of a decoded JSON value that have the same structure of the given template:
+// objects keep only the keys that are in the template, and lists of the same length are projected element by element
+func projectJsonValue(value, template interface{}) interface{} {
+	switch typedTemplate := template.(type) {
+	case map[string]interface{}:
+		typedValue, ok := value.(map[string]interface{})
+		if !ok {
+			return value
+		}
+		result := make(map[string]interface{})
+		for key, templateItem := range typedTemplate {
+			item, found := typedValue[key]
+			if !found {
+				continue
+			}
+			result[key] = projectJsonValue(item, templateItem)
+		}
+		return result
+	case []interface{}:
+		typedValue, ok := value.([]interface{})
+		if !ok || len(typedValue) != len(typedTemplate) {
+			return value
+		}
+		result := make([]interface{}, len(typedValue))
+		for i := range typedValue {
+			result[i] = projectJsonValue(typedValue[i], typedTemplate[i])
+		}
+		return result
+	default:
+		return value
+	}
+}
+
+// mergeJsonValue returns the decoded JSON value with the fields of the override applied on top of it.
+// Objects are merged key by key, while any other value is replaced
+func mergeJsonValue(value, override interface{}) interface{} {
+	typedOverride, ok := override.(map[string]interface{})
+	if !ok {
+		return override
+	}
+	typedValue, ok := value.(map[string]interface{})
+	if !ok {
+		return override
+	}
+	result := make(map[string]interface{})
+	for key, item := range typedValue {
+		result[key] = item
+	}
+	for key, item := range typedOverride {
+		result[key] = mergeJsonValue(typedValue[key], item)
+	}
+	return result
+}
@@ -0,0 +1,91 @@
+//go:build functional || role || ALL
+
+package vcd
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+)
+
+// TestAccVcdOpenApiEntity manages an Org role with the generic vcd_openapi_entity resource, checking it
+// with the vcd_role data source
+func TestAccVcdOpenApiEntity(t *testing.T) {
+	preTestChecks(t)
+
+	var params = StringMap{
+		"Org":         testConfig.VCD.Org,
+		"Name":        t.Name(),
+		"Description": "Created with vcd_openapi_entity",
+		"FuncName":    t.Name() + "Step1",
+	}
+	testParamsNotEmpty(t, params)
+
+	configText1 := templateFill(testAccCheckVcdOpenApiEntity, params)
+	debugPrintf("#[DEBUG] CONFIGURATION 1: %s", configText1)
+
+	params["FuncName"] = t.Name() + "Step2"
+	params["Description"] = "Updated with vcd_openapi_entity"
+	configText2 := templateFill(testAccCheckVcdOpenApiEntity, params)
+	debugPrintf("#[DEBUG] CONFIGURATION 2: %s", configText2)
+
+	if vcdShortTest {
+		t.Skip(acceptanceTestsSkipped)
+		return
+	}
+
+	resourceName := "vcd_openapi_entity.role"
+	cachedId := testCachedFieldValue{}
+	resource.Test(t, resource.TestCase{
+		ProviderFactories: testAccProviders,
+		CheckDestroy:      testAccCheckRoleDestroy(resourceName),
+		Steps: []resource.TestStep{
+			{
+				Config: configText1,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					cachedId.cacheTestResourceFieldValue(resourceName, "id"),
+					resource.TestMatchResourceAttr(resourceName, "id", regexp.MustCompile(`^urn:vcloud:role:.+`)),
+					resource.TestMatchResourceAttr(resourceName, "output", regexp.MustCompile(`"name":"`+t.Name()+`"`)),
+					resource.TestCheckResourceAttr("data.vcd_role.role", "description", "Created with vcd_openapi_entity"),
+				),
+			},
+			{
+				Config: configText2,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					cachedId.testCheckCachedResourceFieldValue(resourceName, "id"),
+					resource.TestCheckResourceAttr("data.vcd_role.role", "description", "Updated with vcd_openapi_entity"),
+				),
+			},
+			{
+				ResourceName:            resourceName,
+				ImportState:             true,
+				ImportStateVerify:       true,
+				ImportStateVerifyIgnore: []string{"org", "body"},
+				ImportStateIdFunc: func(state *terraform.State) (string, error) {
+					return "1.0.0/roles/" + cachedId.fieldValue, nil
+				},
+			},
+		},
+	})
+	postTestChecks(t)
+}
+
+const testAccCheckVcdOpenApiEntity = `
+resource "vcd_openapi_entity" "role" {
+  org      = "{{.Org}}"
+  endpoint = "1.0.0/roles/"
+  body = jsonencode({
+    name        = "{{.Name}}"
+    description = "{{.Description}}"
+    bundleKey   = "com.vmware.vcloud.undefined.key"
+    readOnly    = false
+  })
+}
+
+data "vcd_role" "role" {
+  org  = "{{.Org}}"
+  name = jsondecode(vcd_openapi_entity.role.output).name
+}
+`
@@ -0,0 +1,199 @@
+//go:build unit || ALL
+
+package vcd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/vmware/terraform-provider-vcd/v4/vcd/mockvcd"
+)
+
+func unmarshalTestJson(t *testing.T, text string) interface{} {
+	var value interface{}
+	if err := json.Unmarshal([]byte(text), &value); err != nil {
+		t.Fatalf("error unmarshaling '%s': %s", text, err)
+	}
+	return value
+}
+
+func Test_getJsonValueByPath(t *testing.T) {
+	value := unmarshalTestJson(t, `{"id":"urn:vcloud:role:1","entity":{"count":3,"values":[{"id":"a"},{"id":""}]}}`)
+	tests := []struct {
+		path    string
+		want    string
+		wantErr bool
+	}{
+		{path: "id", want: "urn:vcloud:role:1"},
+		{path: "entity.count", want: "3"},
+		{path: "entity.values.0.id", want: "a"},
+		{path: "entity.values.1.id", wantErr: true},
+		{path: "entity.values.2.id", wantErr: true},
+		{path: "entity.values", wantErr: true},
+		{path: "missing", wantErr: true},
+		{path: "id.name", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			got, err := getJsonValueByPath(value, tt.path)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("getJsonValueByPath() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("getJsonValueByPath() = '%s', want '%s'", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_projectJsonValue(t *testing.T) {
+	tests := []struct {
+		name     string
+		value    string
+		template string
+		want     string
+	}{
+		{
+			name:     "ComputedFieldsRemoved",
+			value:    `{"id":"1","name":"role","description":"new","readOnly":false}`,
+			template: `{"name":"role","description":"old"}`,
+			want:     `{"description":"new","name":"role"}`,
+		},
+		{
+			name:     "NestedObjectsAndLists",
+			value:    `{"entity":{"a":1,"b":2},"list":[{"x":1,"y":2}]}`,
+			template: `{"entity":{"a":0},"list":[{"x":0}]}`,
+			want:     `{"entity":{"a":1},"list":[{"x":1}]}`,
+		},
+		{
+			name:     "ListOfDifferentLength",
+			value:    `{"list":[{"x":1,"y":2},{"x":3}]}`,
+			template: `{"list":[{"x":0}]}`,
+			want:     `{"list":[{"x":1,"y":2},{"x":3}]}`,
+		},
+		{
+			name:     "MissingFieldsOmitted",
+			value:    `{"name":"role"}`,
+			template: `{"name":"role","description":"old"}`,
+			want:     `{"name":"role"}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := json.Marshal(projectJsonValue(unmarshalTestJson(t, tt.value), unmarshalTestJson(t, tt.template)))
+			if err != nil {
+				t.Fatalf("error marshaling projection: %s", err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("projectJsonValue() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_mergeJsonValue(t *testing.T) {
+	tests := []struct {
+		name     string
+		value    string
+		override string
+		want     string
+	}{
+		{
+			name:     "FieldsReplaced",
+			value:    `{"id":"1","name":"role","description":"old"}`,
+			override: `{"description":"new"}`,
+			want:     `{"description":"new","id":"1","name":"role"}`,
+		},
+		{
+			name:     "NestedObjectsMerged",
+			value:    `{"entity":{"a":1,"b":2}}`,
+			override: `{"entity":{"b":3,"c":4}}`,
+			want:     `{"entity":{"a":1,"b":3,"c":4}}`,
+		},
+		{
+			name:     "ListsReplaced",
+			value:    `{"list":[1,2,3]}`,
+			override: `{"list":[4]}`,
+			want:     `{"list":[4]}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := json.Marshal(mergeJsonValue(unmarshalTestJson(t, tt.value), unmarshalTestJson(t, tt.override)))
+			if err != nil {
+				t.Fatalf("error marshaling merge: %s", err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("mergeJsonValue() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_resourceVcdOpenApiEntityCrud(t *testing.T) {
+	for _, async := range []bool{false, true} {
+		t.Run(fmt.Sprintf("async=%v", async), func(t *testing.T) {
+			var options []mockvcd.Option
+			if async {
+				options = append(options, mockvcd.WithAsyncCollections("1.0.0/roles"))
+			}
+			server := mockvcd.NewServer(options...)
+			defer server.Close()
+			vcdClient := newMockVcdClient(t, server)
+			ctx := context.Background()
+			res := resourceVcdOpenApiEntity()
+
+			d := schema.TestResourceDataRaw(t, res.Schema, map[string]interface{}{
+				"endpoint": "1.0.0/roles",
+				"body":     `{"name":"role1","description":"first"}`,
+			})
+			if diags := res.CreateContext(ctx, d, vcdClient); diags.HasError() {
+				t.Fatalf("error creating entity: %v", diags)
+			}
+			id := d.Id()
+			if stored, found := server.GetEntity("1.0.0/roles", id); !found || stored["description"] != "first" {
+				t.Fatalf("entity %s not stored correctly: %v", id, stored)
+			}
+			if !strings.Contains(d.Get("output").(string), id) {
+				t.Errorf("expected 'output' to contain the entity ID, got %s", d.Get("output"))
+			}
+
+			dSet(d, "body", `{"name":"role1","description":"updated"}`)
+			if diags := res.UpdateContext(ctx, d, vcdClient); diags.HasError() {
+				t.Fatalf("error updating entity: %v", diags)
+			}
+			if stored, _ := server.GetEntity("1.0.0/roles", id); stored["description"] != "updated" {
+				t.Errorf("expected updated description, got %v", stored["description"])
+			}
+
+			imported := res.Data(nil)
+			imported.SetId("1.0.0/roles/" + id)
+			if _, err := res.Importer.StateContext(ctx, imported, vcdClient); err != nil {
+				t.Fatalf("error importing entity: %s", err)
+			}
+			if imported.Id() != id || imported.Get("endpoint") != "1.0.0/roles/" {
+				t.Errorf("unexpected import result: ID '%s', endpoint '%s'", imported.Id(), imported.Get("endpoint"))
+			}
+			if !strings.Contains(imported.Get("body").(string), `"updated"`) {
+				t.Errorf("expected imported body to contain the description, got %s", imported.Get("body"))
+			}
+
+			if diags := res.DeleteContext(ctx, d, vcdClient); diags.HasError() {
+				t.Fatalf("error deleting entity: %v", diags)
+			}
+			if _, found := server.GetEntity("1.0.0/roles", id); found {
+				t.Errorf("entity %s was not deleted", id)
+			}
+			if diags := res.ReadContext(ctx, d, vcdClient); diags.HasError() {
+				t.Fatalf("error reading deleted entity: %v", diags)
+			}
+			if d.Id() != "" {
+				t.Errorf("expected ID to be removed after reading a deleted entity, got '%s'", d.Id())
+			}
+		})
+	}
+}
@@ -0,0 +1,327 @@
+package vcd
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/vmware/go-vcloud-director/v3/govcd"
+	"github.com/vmware/go-vcloud-director/v3/types/v56"
+)
+
+const labelOpenApiMetadata = "OpenAPI metadata"
+
+func resourceVcdOpenApiMetadata() *schema.Resource {
+	return &schema.Resource{
+		CreateContext: resourceVcdOpenApiMetadataCreate,
+		ReadContext:   resourceVcdOpenApiMetadataRead,
+		UpdateContext: resourceVcdOpenApiMetadataUpdate,
+		DeleteContext: resourceVcdOpenApiMetadataDelete,
+		Importer: &schema.ResourceImporter{
+			StateContext: resourceVcdOpenApiMetadataImport,
+		},
+		Schema: map[string]*schema.Schema{
+			"entity_id": {
+				Type:        schema.TypeString,
+				Required:    true,
+				ForceNew:    true,
+				Description: "URN of the entity that owns the metadata (e.g. 'urn:vcloud:entity:...')",
+			},
+			"entity_type": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "Type of the entity, as found in its URN",
+			},
+			"mode":           metadataModeSchema(),
+			"metadata_entry": openApiMetadataEntryResourceSchema("entity"),
+		},
+	}
+}
+
+func resourceVcdOpenApiMetadataCreate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	vcdClient := meta.(*VCDClient)
+	entityId := d.Get("entity_id").(string)
+
+	entity, err := getOpenApiMetadataEntityByUrn(vcdClient, entityId)
+	if err != nil {
+		return diag.Errorf("error creating %s of entity '%s': %s", labelOpenApiMetadata, entityId, err)
+	}
+	err = applyOpenApiMetadataEntries(d, entity)
+	if err != nil {
+		return diag.Errorf("error creating %s of entity '%s': %s", labelOpenApiMetadata, entityId, err)
+	}
+	d.SetId(entityId)
+
+	return resourceVcdOpenApiMetadataRead(ctx, d, meta)
+}
+
+func resourceVcdOpenApiMetadataRead(_ context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	return readOpenApiMetadataEntries(d, meta.(*VCDClient), d.Get("mode").(string) == metadataModeAuthoritative)
+}
+
+func resourceVcdOpenApiMetadataUpdate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	vcdClient := meta.(*VCDClient)
+
+	entity, err := getOpenApiMetadataEntityByUrn(vcdClient, d.Id())
+	if err != nil {
+		return diag.Errorf("error updating %s of entity '%s': %s", labelOpenApiMetadata, d.Id(), err)
+	}
+	err = applyOpenApiMetadataEntries(d, entity)
+	if err != nil {
+		return diag.Errorf("error updating %s of entity '%s': %s", labelOpenApiMetadata, d.Id(), err)
+	}
+
+	return resourceVcdOpenApiMetadataRead(ctx, d, meta)
+}
+
+func resourceVcdOpenApiMetadataDelete(_ context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	vcdClient := meta.(*VCDClient)
+
+	entity, err := getOpenApiMetadataEntityByUrn(vcdClient, d.Id())
+	if err != nil {
+		return diag.Errorf("error deleting %s of entity '%s': %s", labelOpenApiMetadata, d.Id(), err)
+	}
+	currentEntries, err := entity.getMetadata()
+	if govcd.ContainsNotFound(err) {
+		return nil
+	}
+	if err != nil {
+		return diag.Errorf("error deleting %s of entity '%s': %s", labelOpenApiMetadata, d.Id(), err)
+	}
+	managedKeys := getOpenApiMetadataNamespacedKeys(d.Get("metadata_entry").(*schema.Set).List())
+	for _, entry := range currentEntries {
+		if !managedKeys[getOpenApiMetadataNamespacedKey(entry)] {
+			continue
+		}
+		err = entity.deleteMetadata(entry.ID)
+		if err != nil && !govcd.ContainsNotFound(err) {
+			return diag.Errorf("error deleting %s entry '%s' of entity '%s': %s", labelOpenApiMetadata, entry.KeyValue.Key, d.Id(), err)
+		}
+	}
+	return nil
+}
+
+// resourceVcdOpenApiMetadataImport imports the OpenAPI metadata of an entity using its URN.
+// Example import path (_the_id_string_): urn:vcloud:entity:vmware:k8s:9fa8cd3b-d6b9-4e1d-b7d7-8e7e1a4b3f8f
+// All the metadata entries of the entity are imported, in additive mode
+func resourceVcdOpenApiMetadataImport(_ context.Context, d *schema.ResourceData, meta interface{}) ([]*schema.ResourceData, error) {
+	dSet(d, "entity_id", d.Id())
+	dSet(d, "mode", metadataModeAdditive)
+	diags := readOpenApiMetadataEntries(d, meta.(*VCDClient), true)
+	if diags.HasError() {
+		return nil, fmt.Errorf("error importing %s of entity '%s': %v", labelOpenApiMetadata, d.Id(), diags)
+	}
+	if d.Id() == "" {
+		return nil, fmt.Errorf("error importing %s: entity not found", labelOpenApiMetadata)
+	}
+	return []*schema.ResourceData{d}, nil
+}
+
+// applyOpenApiMetadataEntries creates, updates and deletes the OpenAPI metadata entries of the given entity, depending
+// on the changes of metadata_entry. In authoritative mode, the entries of the entity that are not in metadata_entry
+// are deleted too
+func applyOpenApiMetadataEntries(d *schema.ResourceData, entity openApiMetadataEntity) error {
+	currentEntries, err := entity.getMetadata()
+	if err != nil {
+		return fmt.Errorf("error retrieving metadata: %s", err)
+	}
+	currentEntriesByKey := map[string]*types.OpenApiMetadataEntry{}
+	for _, entry := range currentEntries {
+		currentEntriesByKey[getOpenApiMetadataNamespacedKey(entry)] = entry
+	}
+
+	oldRaw, newRaw := d.GetChange("metadata_entry")
+	metadataToAdd, metadataToUpdate, metadataToDelete, err := getOpenApiMetadataOperations(oldRaw.(*schema.Set).List(), newRaw.(*schema.Set).List())
+	if err != nil {
+		return fmt.Errorf("could not calculate the needed metadata operations: %s", err)
+	}
+
+	// In authoritative mode, the entries of the entity that are not managed by the configuration are deleted as well
+	if d.Get("mode").(string) == metadataModeAuthoritative {
+		managedKeys := getOpenApiMetadataNamespacedKeys(newRaw.(*schema.Set).List())
+		for namespacedKey, entry := range currentEntriesByKey {
+			if !managedKeys[namespacedKey] {
+				metadataToDelete = append(metadataToDelete, *entry)
+			}
+		}
+	}
+
+	deleted := map[string]bool{}
+	for _, entry := range metadataToDelete {
+		current, found := currentEntriesByKey[getOpenApiMetadataNamespacedKey(&entry)]
+		if !found || deleted[current.ID] {
+			continue
+		}
+		err = entity.deleteMetadata(current.ID)
+		if err != nil {
+			return fmt.Errorf("error deleting metadata with namespace '%s' and key '%s': %s", entry.KeyValue.Namespace, entry.KeyValue.Key, err)
+		}
+		deleted[current.ID] = true
+	}
+
+	for _, entry := range metadataToUpdate {
+		current, found := currentEntriesByKey[getOpenApiMetadataNamespacedKey(&entry)]
+		if !found {
+			// The entry was removed outside Terraform, so it is created again
+			metadataToAdd = append(metadataToAdd, entry)
+			continue
+		}
+		err = entity.updateMetadata(current.ID, entry.KeyValue.Value.Value, entry.IsPersistent)
+		if err != nil {
+			return fmt.Errorf("error updating metadata with namespace '%s' and key '%s': %s", entry.KeyValue.Namespace, entry.KeyValue.Key, err)
+		}
+	}
+
+	for _, entry := range metadataToAdd {
+		current, found := currentEntriesByKey[getOpenApiMetadataNamespacedKey(&entry)]
+		if found && !deleted[current.ID] {
+			// The entry already exists in VCD, as it was created outside Terraform. Only the value and
+			// persistence can be updated, otherwise it needs to be re-created
+			if current.IsReadOnly == entry.IsReadOnly && current.KeyValue.Domain == entry.KeyValue.Domain && current.KeyValue.Value.Type == entry.KeyValue.Value.Type {
+				err = entity.updateMetadata(current.ID, entry.KeyValue.Value.Value, entry.IsPersistent)
+				if err != nil {
+					return fmt.Errorf("error updating metadata with namespace '%s' and key '%s': %s", entry.KeyValue.Namespace, entry.KeyValue.Key, err)
+				}
+				continue
+			}
+			err = entity.deleteMetadata(current.ID)
+			if err != nil {
+				return fmt.Errorf("error deleting metadata with namespace '%s' and key '%s': %s", entry.KeyValue.Namespace, entry.KeyValue.Key, err)
+			}
+		}
+		err = entity.addMetadata(entry)
+		if err != nil {
+			return fmt.Errorf("error adding metadata with namespace '%s' and key '%s': %s", entry.KeyValue.Namespace, entry.KeyValue.Key, err)
+		}
+	}
+	return nil
+}
+
+// readOpenApiMetadataEntries sets the OpenAPI metadata entries of the entity in metadata_entry. When readAll is false,
+// only the entries whose namespace and key are already in metadata_entry are kept
+func readOpenApiMetadataEntries(d *schema.ResourceData, vcdClient *VCDClient, readAll bool) diag.Diagnostics {
+	entityId := d.Get("entity_id").(string)
+	entity, err := getOpenApiMetadataEntityByUrn(vcdClient, entityId)
+	if govcd.ContainsNotFound(err) {
+		log.Printf("[DEBUG] entity '%s' not found. Removing %s from state", entityId, labelOpenApiMetadata)
+		d.SetId("")
+		return nil
+	}
+	if err != nil {
+		return diag.Errorf("error reading %s of entity '%s': %s", labelOpenApiMetadata, entityId, err)
+	}
+	allMetadata, err := entity.getMetadata()
+	if govcd.ContainsNotFound(err) {
+		log.Printf("[DEBUG] entity '%s' not found. Removing %s from state", entityId, labelOpenApiMetadata)
+		d.SetId("")
+		return nil
+	}
+	if err != nil {
+		return diag.Errorf("error reading %s of entity '%s': %s", labelOpenApiMetadata, entityId, err)
+	}
+
+	entityType, _ := getUrnEntityType(entityId)
+	dSet(d, "entity_type", entityType)
+
+	managedKeys := getOpenApiMetadataNamespacedKeys(d.Get("metadata_entry").(*schema.Set).List())
+	var entries []*types.OpenApiMetadataEntry
+	for _, entry := range allMetadata {
+		if readAll || managedKeys[getOpenApiMetadataNamespacedKey(entry)] {
+			entries = append(entries, entry)
+		}
+	}
+	metadata, err := getOpenApiMetadataEntriesForState(entries)
+	if err != nil {
+		return diag.Errorf("error reading %s of entity '%s': %s", labelOpenApiMetadata, entityId, err)
+	}
+	err = d.Set("metadata_entry", metadata)
+	if err != nil {
+		return diag.Errorf("error setting metadata entry in state: %s", err)
+	}
+	return nil
+}
+
+// getOpenApiMetadataNamespacedKey returns the namespace and key that identify the given entry, in the same way as
+// getOpenApiMetadataEntryMap does
+func getOpenApiMetadataNamespacedKey(entry *types.OpenApiMetadataEntry) string {
+	return fmt.Sprintf("%s%s", entry.KeyValue.Namespace, entry.KeyValue.Key)
+}
+
+// getOpenApiMetadataNamespacedKeys returns the namespaced keys of the given metadata_entry attribute
+func getOpenApiMetadataNamespacedKeys(metadataAttribute []interface{}) map[string]bool {
+	keys := map[string]bool{}
+	for _, rawItem := range metadataAttribute {
+		metadataEntry := rawItem.(map[string]interface{})
+		namespace, _ := metadataEntry["namespace"].(string)
+		keys[fmt.Sprintf("%s%s", namespace, metadataEntry["key"].(string))] = true
+	}
+	return keys
+}
+
+// openApiMetadataEntity is the minimum set of operations needed by vcd_openapi_metadata to manage the metadata of
+// an entity, working with the entry IDs
+type openApiMetadataEntity interface {
+	getMetadata() ([]*types.OpenApiMetadataEntry, error)
+	addMetadata(entry types.OpenApiMetadataEntry) error
+	updateMetadata(id string, value interface{}, persistent bool) error
+	deleteMetadata(id string) error
+}
+
+// getOpenApiMetadataEntityByUrn returns the entity identified by the given URN.
+// Only Runtime Defined Entities are supported, as they are the only entities whose OpenAPI metadata is handled by the
+// SDK. The metadata of other entities must be managed with vcd_metadata
+func getOpenApiMetadataEntityByUrn(vcdClient *VCDClient, urn string) (openApiMetadataEntity, error) {
+	entityType, err := getUrnEntityType(urn)
+	if err != nil {
+		return nil, err
+	}
+	if entityType != "entity" {
+		return nil, fmt.Errorf("entities of type '%s' are not supported, only Runtime Defined Entities ('urn:vcloud:entity:...') are. "+
+			"Use vcd_metadata to manage the metadata of other entities", entityType)
+	}
+	rde, err := vcdClient.GetRdeById(urn)
+	if err != nil {
+		return nil, err
+	}
+	return openApiMetadataCompatibleEntity{rde}, nil
+}
+
+// openApiMetadataCompatibleEntity handles the metadata of any openApiMetadataCompatible object
+type openApiMetadataCompatibleEntity struct {
+	openApiMetadataCompatible
+}
+
+func (entity openApiMetadataCompatibleEntity) getMetadata() ([]*types.OpenApiMetadataEntry, error) {
+	allMetadata, err := entity.GetMetadata()
+	if err != nil {
+		return nil, err
+	}
+	entries := make([]*types.OpenApiMetadataEntry, len(allMetadata))
+	for i, entry := range allMetadata {
+		entries[i] = entry.MetadataEntry
+	}
+	return entries, nil
+}
+
+func (entity openApiMetadataCompatibleEntity) addMetadata(entry types.OpenApiMetadataEntry) error {
+	_, err := entity.AddMetadata(entry)
+	return err
+}
+
+func (entity openApiMetadataCompatibleEntity) updateMetadata(id string, value interface{}, persistent bool) error {
+	entry, err := entity.GetMetadataById(id) // Refreshes ETags
+	if err != nil {
+		return err
+	}
+	return entry.Update(value, persistent)
+}
+
+func (entity openApiMetadataCompatibleEntity) deleteMetadata(id string) error {
+	entry, err := entity.GetMetadataById(id)
+	if err != nil {
+		return err
+	}
+	return entry.Delete()
+}
@@ -0,0 +1,171 @@
+//go:build unit || ALL
+
+package vcd
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/vmware/go-vcloud-director/v3/types/v56"
+	"github.com/vmware/terraform-provider-vcd/v4/vcd/mockvcd"
+)
+
+// testOpenApiMetadataEntity keeps OpenAPI metadata entries in memory and records the operations done on them
+type testOpenApiMetadataEntity struct {
+	entries    map[string]*types.OpenApiMetadataEntry
+	operations []string
+}
+
+func (entity *testOpenApiMetadataEntity) getMetadata() ([]*types.OpenApiMetadataEntry, error) {
+	var entries []*types.OpenApiMetadataEntry
+	for _, entry := range entity.entries {
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
+
+func (entity *testOpenApiMetadataEntity) addMetadata(entry types.OpenApiMetadataEntry) error {
+	entry.ID = "id-" + entry.KeyValue.Key
+	entity.entries[entry.ID] = &entry
+	entity.operations = append(entity.operations, "add "+entry.KeyValue.Key)
+	return nil
+}
+
+func (entity *testOpenApiMetadataEntity) updateMetadata(id string, value interface{}, persistent bool) error {
+	entry, found := entity.entries[id]
+	if !found {
+		return fmt.Errorf("entry %s not found", id)
+	}
+	entry.KeyValue.Value.Value = value
+	entry.IsPersistent = persistent
+	entity.operations = append(entity.operations, "update "+entry.KeyValue.Key)
+	return nil
+}
+
+func (entity *testOpenApiMetadataEntity) deleteMetadata(id string) error {
+	entry, found := entity.entries[id]
+	if !found {
+		return fmt.Errorf("entry %s not found", id)
+	}
+	delete(entity.entries, id)
+	entity.operations = append(entity.operations, "delete "+entry.KeyValue.Key)
+	return nil
+}
+
+func Test_applyOpenApiMetadataEntries(t *testing.T) {
+	tests := []struct {
+		mode           string
+		wantOperations []string
+	}{
+		{mode: metadataModeAdditive, wantOperations: []string{"add new", "delete type", "add type", "update env"}},
+		{mode: metadataModeAuthoritative, wantOperations: []string{"add new", "delete type", "add type", "delete unmanaged", "update env"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.mode, func(t *testing.T) {
+			entity := &testOpenApiMetadataEntity{entries: map[string]*types.OpenApiMetadataEntry{}}
+			for _, entry := range []types.OpenApiMetadataEntry{
+				{ID: "id-env", KeyValue: types.OpenApiMetadataKeyValue{Key: "env", Domain: "TENANT",
+					Value: types.OpenApiMetadataTypedValue{Type: types.OpenApiMetadataStringEntry, Value: "dev"}}},
+				{ID: "id-type", KeyValue: types.OpenApiMetadataKeyValue{Key: "type", Domain: "TENANT",
+					Value: types.OpenApiMetadataTypedValue{Type: types.OpenApiMetadataStringEntry, Value: "1"}}},
+				{ID: "id-unmanaged", KeyValue: types.OpenApiMetadataKeyValue{Key: "unmanaged", Domain: "TENANT",
+					Value: types.OpenApiMetadataTypedValue{Type: types.OpenApiMetadataStringEntry, Value: "other"}}},
+			} {
+				entry := entry
+				entity.entries[entry.ID] = &entry
+			}
+
+			d := schema.TestResourceDataRaw(t, resourceVcdOpenApiMetadata().Schema, map[string]interface{}{
+				"entity_id": "urn:vcloud:firewallRule:9fa8cd3b-d6b9-4e1d-b7d7-8e7e1a4b3f8f",
+				"mode":      tt.mode,
+				"metadata_entry": []interface{}{
+					map[string]interface{}{"key": "env", "value": "prod"},
+					map[string]interface{}{"key": "type", "value": "1", "type": types.OpenApiMetadataNumberEntry},
+					map[string]interface{}{"key": "new", "value": "true", "type": types.OpenApiMetadataBooleanEntry},
+				},
+			})
+			err := applyOpenApiMetadataEntries(d, entity)
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			// The entries to add come from a map, so the order is not deterministic
+			sort.Strings(entity.operations)
+			sort.Strings(tt.wantOperations)
+			if fmt.Sprintf("%v", entity.operations) != fmt.Sprintf("%v", tt.wantOperations) {
+				t.Errorf("operations = %v, want %v", entity.operations, tt.wantOperations)
+			}
+			if entity.entries["id-env"].KeyValue.Value.Value != "prod" {
+				t.Errorf("expected 'env' to be updated to 'prod', got %v", entity.entries["id-env"].KeyValue.Value.Value)
+			}
+			if entity.entries["id-type"].KeyValue.Value.Type != types.OpenApiMetadataNumberEntry {
+				t.Errorf("expected 'type' to be re-created as a number, got %s", entity.entries["id-type"].KeyValue.Value.Type)
+			}
+		})
+	}
+}
+
+func Test_resourceVcdOpenApiMetadataCrud(t *testing.T) {
+	server := mockvcd.NewServer()
+	defer server.Close()
+	vcdClient := newMockVcdClient(t, server)
+	ctx := context.Background()
+	res := resourceVcdOpenApiMetadata()
+
+	entityId := "urn:vcloud:entity:vmware:k8s:1.0.0:9fa8cd3b-d6b9-4e1d-b7d7-8e7e1a4b3f8f"
+	server.AddEntity("1.0.0/entities", map[string]interface{}{"id": entityId, "name": "cluster", "entityType": "urn:vcloud:type:vmware:k8s:1.0.0"})
+	collection := "1.0.0/entities/" + entityId + "/metadata"
+	server.AddEntity(collection, map[string]interface{}{
+		"keyValue": map[string]interface{}{"key": "unmanaged", "domain": "TENANT",
+			"value": map[string]interface{}{"type": types.OpenApiMetadataStringEntry, "value": "other"}},
+	})
+
+	d := schema.TestResourceDataRaw(t, res.Schema, map[string]interface{}{
+		"entity_id": entityId,
+		"metadata_entry": []interface{}{
+			map[string]interface{}{"key": "env", "value": "prod"},
+			map[string]interface{}{"key": "replicas", "value": "3", "type": types.OpenApiMetadataNumberEntry},
+		},
+	})
+	if diags := res.CreateContext(ctx, d, vcdClient); diags.HasError() {
+		t.Fatalf("error creating metadata: %v", diags)
+	}
+	if d.Id() != entityId {
+		t.Errorf("expected ID '%s', got '%s'", entityId, d.Id())
+	}
+	if entries := server.Entities(collection); len(entries) != 3 {
+		t.Fatalf("expected 3 metadata entries in the entity, got %d", len(entries))
+	}
+	if entries := d.Get("metadata_entry").(*schema.Set).List(); len(entries) != 2 {
+		t.Errorf("expected 2 managed entries in state, got %d", len(entries))
+	}
+
+	imported := res.Data(nil)
+	imported.SetId(entityId)
+	if _, err := res.Importer.StateContext(ctx, imported, vcdClient); err != nil {
+		t.Fatalf("error importing metadata: %s", err)
+	}
+	if entries := imported.Get("metadata_entry").(*schema.Set).List(); len(entries) != 3 {
+		t.Errorf("expected 3 imported entries, got %d", len(entries))
+	}
+
+	if diags := res.DeleteContext(ctx, d, vcdClient); diags.HasError() {
+		t.Fatalf("error deleting metadata: %v", diags)
+	}
+	entries := server.Entities(collection)
+	if len(entries) != 1 || entries[0]["keyValue"].(map[string]interface{})["key"] != "unmanaged" {
+		t.Errorf("expected only the unmanaged entry to remain, got %v", entries)
+	}
+
+	// Only Runtime Defined Entities are supported
+	vdc := schema.TestResourceDataRaw(t, res.Schema, map[string]interface{}{
+		"entity_id":      "urn:vcloud:vdc:9fa8cd3b-d6b9-4e1d-b7d7-8e7e1a4b3f8f",
+		"metadata_entry": []interface{}{map[string]interface{}{"key": "env", "value": "prod"}},
+	})
+	if diags := res.CreateContext(ctx, vdc, vcdClient); !diags.HasError() {
+		t.Errorf("expected an error creating metadata of a VDC")
+	}
+}
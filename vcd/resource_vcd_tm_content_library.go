@@ -3,6 +3,8 @@ package vcd
 import (
 	"context"
 	"fmt"
+	"net/url"
+
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	"github.com/vmware/go-vcloud-director/v3/govcd"
@@ -161,9 +163,21 @@ func genericVcdTmContentLibraryRead(_ context.Context, d *schema.ResourceData, m
 	var cl *govcd.ContentLibrary
 	var err error
 	// TODO: TM: Tenant Context should not be nil and depend on the configured owner_org_id
-	if d.Id() != "" {
+	switch {
+	case d.Id() != "":
 		cl, err = vcdClient.GetContentLibraryById(d.Id(), nil)
-	} else {
+	case origin == "datasource" && d.Get("name").(string) == "":
+		// The data source has a 'filter' block instead of 'name'
+		cl, err = getOpenApiEntityByFilter(&vcdClient.Client, d.Get("filter"), "Content Library", openApiFilterConfig[*govcd.ContentLibrary]{
+			getAllEntitiesFunc: func(queryParameters url.Values) ([]*govcd.ContentLibrary, error) {
+				return vcdClient.GetAllContentLibraries(queryParameters, nil)
+			},
+			getIdAndNameFunc: func(e *govcd.ContentLibrary) (string, string) {
+				return e.ContentLibrary.ID, e.ContentLibrary.Name
+			},
+			dateField: "creationDate",
+		})
+	default:
 		cl, err = vcdClient.GetContentLibraryByName(d.Get("name").(string), nil)
 	}
 	if err != nil {
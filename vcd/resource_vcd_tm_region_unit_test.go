@@ -0,0 +1,68 @@
+//go:build unit || ALL
+
+package vcd
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/vmware/terraform-provider-vcd/v4/vcd/mockvcd"
+)
+
+func Test_resourceVcdTmRegionCrud(t *testing.T) {
+	server := mockvcd.NewServer(mockvcd.WithApiVersions("37.0", "38.0", "40.0"))
+	defer server.Close()
+	supervisorId := "urn:vcloud:supervisor:c9e7f5c4-5a0e-4a1d-8d4b-3b2f4f1c2e7a"
+	server.AddEntity("vcf/supervisors", map[string]interface{}{"id": supervisorId, "supervisorId": supervisorId, "name": "supervisor1"})
+	vcdClient := newMockVcdClient(t, server)
+	ctx := context.Background()
+	res := resourceVcdTmRegion()
+
+	d := schema.TestResourceDataRaw(t, res.Schema, map[string]interface{}{
+		"name":                 "region1",
+		"nsx_manager_id":       "urn:vcloud:nsxtmanager:9fa8cd3b-d6b9-4e1d-b7d7-8e7e1a4b3f8f",
+		"supervisor_ids":       []interface{}{supervisorId},
+		"storage_policy_names": []interface{}{"policy1"},
+	})
+	if diags := res.CreateContext(ctx, d, vcdClient); diags.HasError() {
+		t.Fatalf("error creating region: %v", diags)
+	}
+	id := d.Id()
+	if _, found := server.GetEntity("vcf/regions", id); !found {
+		t.Fatalf("region %s not stored", id)
+	}
+	if supervisorIds := d.Get("supervisor_ids").(*schema.Set).List(); len(supervisorIds) != 1 || supervisorIds[0] != supervisorId {
+		t.Errorf("expected supervisor_ids [%s], got %v", supervisorId, supervisorIds)
+	}
+
+	ds := datasourceVcdTmRegion()
+	dataSource := schema.TestResourceDataRaw(t, ds.Schema, map[string]interface{}{
+		"filter": []interface{}{map[string]interface{}{"name": "region*"}},
+	})
+	if diags := ds.ReadContext(ctx, dataSource, vcdClient); diags.HasError() {
+		t.Fatalf("error reading region data source: %v", diags)
+	}
+	if dataSource.Id() != id || dataSource.Get("nsx_manager_id") != d.Get("nsx_manager_id") {
+		t.Errorf("data source found region '%s', expected '%s'", dataSource.Id(), id)
+	}
+
+	imported := res.Data(nil)
+	imported.SetId("region1")
+	if _, err := res.Importer.StateContext(ctx, imported, vcdClient); err != nil {
+		t.Fatalf("error importing region: %s", err)
+	}
+	if imported.Id() != id {
+		t.Errorf("imported region '%s', expected '%s'", imported.Id(), id)
+	}
+
+	if diags := res.DeleteContext(ctx, d, vcdClient); diags.HasError() {
+		t.Fatalf("error deleting region: %v", diags)
+	}
+	if diags := res.ReadContext(ctx, d, vcdClient); diags.HasError() {
+		t.Fatalf("error reading deleted region: %v", diags)
+	}
+	if d.Id() != "" {
+		t.Errorf("expected ID to be removed after reading a deleted region, got '%s'", d.Id())
+	}
+}
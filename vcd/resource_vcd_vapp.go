@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"log"
+	"net/http"
 	"regexp"
 	"sort"
 	"strings"
@@ -12,6 +13,7 @@ import (
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	"github.com/vmware/go-vcloud-director/v3/govcd"
+	"github.com/vmware/go-vcloud-director/v3/types/v56"
 )
 
 const vAppUnknownStatus = "-unknown-status-"
@@ -261,7 +263,9 @@ func resourceVcdVAppUpdate(ctx context.Context, d *schema.ResourceData, meta int
 		}
 
 		if shouldBePoweredOff {
-			task, err := vapp.Undeploy() // UI Button "Power Off" calls undeploy API endpoint
+			// UI Button "Power Off" calls undeploy API endpoint. The VMs are stopped following the order and stop
+			// action of the vApp startup section, in the same way as the power on follows the start order
+			task, err := undeployVappWithStopActions(&vcdClient.Client, vapp)
 			if err != nil {
 				return diag.Errorf("error Powering Off: %s", err)
 			}
@@ -393,11 +397,16 @@ func genericVcdVAppRead(d *schema.ResourceData, meta interface{}, origin string)
 	return nil
 }
 
-func resourceVcdVAppDelete(_ context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+func resourceVcdVAppDelete(_ context.Context, d *schema.ResourceData, meta interface{}) (diags diag.Diagnostics) {
 	vcdClient := meta.(*VCDClient)
 
 	vcdClient.lockVapp(d)
 	defer vcdClient.unLockVapp(d)
+	unlockDistributed, err := vcdClient.lockDistributedVapp(d, d.Get("name").(string))
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	defer releaseDistributedLock(unlockDistributed, &diags)
 
 	_, vdc, err := vcdClient.GetOrgAndVdcFromResource(d)
 	if err != nil {
@@ -437,6 +446,17 @@ func resourceVcdVAppDelete(_ context.Context, d *schema.ResourceData, meta inter
 	return nil
 }
 
+// undeployVappWithStopActions undeploys a vApp stopping each VM with the stop action defined in the vApp startup
+// section (e.g. guest shutdown), instead of powering all of them off
+func undeployVappWithStopActions(client *govcd.Client, vapp *govcd.VApp) (govcd.Task, error) {
+	undeployParams := &types.UndeployVAppParams{
+		Xmlns:               types.XMLNamespaceVCloud,
+		UndeployPowerAction: "default",
+	}
+	return client.ExecuteTaskRequest(vapp.VApp.HREF+"/action/undeploy", http.MethodPost,
+		types.MimeUndeployVappParams, "error undeploying vApp: %s", undeployParams)
+}
+
 // Try to undeploy a vApp, but do not throw an error if the vApp is powered off.
 // Very often the vApp is powered off at this point and Undeploy() would fail with error:
 // "The requested operation could not be executed since vApp vApp_name is not running"
@@ -0,0 +1,310 @@
+package vcd
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/vmware/go-vcloud-director/v3/govcd"
+)
+
+const labelVappSnapshot = "vApp snapshot"
+
+func resourceVcdVappSnapshot() *schema.Resource {
+	return &schema.Resource{
+		CreateContext: resourceVcdVappSnapshotCreate,
+		ReadContext:   resourceVcdVappSnapshotRead,
+		UpdateContext: resourceVcdVappSnapshotUpdate,
+		DeleteContext: resourceVcdVappSnapshotDelete,
+		Importer: &schema.ResourceImporter{
+			StateContext: resourceVcdVappSnapshotImport,
+		},
+
+		Schema: map[string]*schema.Schema{
+			"org": {
+				Type:     schema.TypeString,
+				Optional: true,
+				ForceNew: true,
+				Description: "The name of organization to use, optional if defined at provider " +
+					"level. Useful when connected as sysadmin working across different organizations",
+			},
+			"vdc": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				ForceNew:    true,
+				Description: "The name of VDC to use, optional if defined at provider level",
+			},
+			"vapp_id": {
+				Type:        schema.TypeString,
+				Required:    true,
+				ForceNew:    true,
+				Description: "ID of the vApp to snapshot. All its VMs are included in the snapshot",
+			},
+			"name": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				ForceNew:    true,
+				Description: "Name of the snapshot",
+			},
+			"description": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				ForceNew:    true,
+				Description: "Description of the snapshot",
+			},
+			"memory": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				ForceNew:    true,
+				Default:     false,
+				Description: "Whether to include the memory of the VMs in the snapshot",
+			},
+			"quiesce": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				ForceNew:    true,
+				Default:     false,
+				Description: "Whether to quiesce the file system of the VMs before taking the snapshot. Requires VMware Tools",
+			},
+			"revert_on": {
+				Type:     schema.TypeString,
+				Optional: true,
+				Description: "Any value. When it changes, the vApp is reverted to this snapshot. " +
+					"Setting it on creation does not revert the vApp",
+			},
+			"size": {
+				Type:        schema.TypeInt,
+				Computed:    true,
+				Description: "Total size of the snapshots of the VMs in bytes",
+			},
+			"created_at": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "Creation date of the most recent snapshot of the VMs",
+			},
+		},
+	}
+}
+
+func resourceVcdVappSnapshotCreate(ctx context.Context, d *schema.ResourceData, meta interface{}) (diags diag.Diagnostics) {
+	vcdClient := meta.(*VCDClient)
+	vappId := d.Get("vapp_id").(string)
+
+	vapp, unlock, err := lockSnapshotVapp(d, vcdClient)
+	if err != nil {
+		return diag.Errorf("error retrieving vApp %s for its snapshot: %s", vappId, err)
+	}
+	defer releaseDistributedLock(unlock, &diags)
+
+	// A new snapshot would silently replace the existing ones of the VMs, which may belong to other resources
+	if _, created := vappSnapshotSummary(vapp); created != "" {
+		return diag.Errorf("error creating %s: VMs of vApp '%s' already have snapshots, the most recent created at %s. "+
+			"Remove them or import them into this resource", labelVappSnapshot, vapp.VApp.Name, created)
+	}
+	target := snapshotTarget{client: &vcdClient.Client, href: vapp.VApp.HREF, label: fmt.Sprintf("vApp '%s'", vapp.VApp.Name)}
+	err = target.create(&createSnapshotParams{
+		Memory:      d.Get("memory").(bool),
+		Quiesce:     d.Get("quiesce").(bool),
+		Name:        d.Get("name").(string),
+		Description: d.Get("description").(string),
+	})
+	if err != nil {
+		return diag.Errorf("error creating %s: %s", labelVappSnapshot, err)
+	}
+
+	d.SetId(vapp.VApp.ID)
+	err = setVappSnapshotData(d, vcdClient, true)
+	if err != nil {
+		return diag.Errorf("error reading %s after creation: %s", labelVappSnapshot, err)
+	}
+	return resourceVcdVappSnapshotRead(ctx, d, meta)
+}
+
+func resourceVcdVappSnapshotRead(_ context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	err := setVappSnapshotData(d, meta.(*VCDClient), false)
+	if err != nil {
+		return diag.Errorf("error reading %s: %s", labelVappSnapshot, err)
+	}
+	return nil
+}
+
+func resourceVcdVappSnapshotUpdate(ctx context.Context, d *schema.ResourceData, meta interface{}) (diags diag.Diagnostics) {
+	vcdClient := meta.(*VCDClient)
+
+	if d.HasChange("revert_on") {
+		vapp, unlock, err := lockSnapshotVapp(d, vcdClient)
+		if err != nil {
+			return diag.Errorf("error retrieving vApp %s for its snapshot: %s", d.Id(), err)
+		}
+		defer releaseDistributedLock(unlock, &diags)
+
+		if _, created := vappSnapshotSummary(vapp); created == "" {
+			return diag.Errorf("error reverting vApp '%s': the snapshot does not exist anymore", vapp.VApp.Name)
+		}
+		target := snapshotTarget{client: &vcdClient.Client, href: vapp.VApp.HREF, label: fmt.Sprintf("vApp '%s'", vapp.VApp.Name)}
+		err = target.revert()
+		if err != nil {
+			return diag.Errorf("error reverting vApp '%s' to its snapshot: %s", vapp.VApp.Name, err)
+		}
+	}
+	return resourceVcdVappSnapshotRead(ctx, d, meta)
+}
+
+func resourceVcdVappSnapshotDelete(_ context.Context, d *schema.ResourceData, meta interface{}) (diags diag.Diagnostics) {
+	vcdClient := meta.(*VCDClient)
+
+	vapp, unlock, err := lockSnapshotVapp(d, vcdClient)
+	if govcd.ContainsNotFound(err) {
+		return nil
+	}
+	if err != nil {
+		return diag.Errorf("error retrieving vApp %s for its snapshot: %s", d.Id(), err)
+	}
+	defer releaseDistributedLock(unlock, &diags)
+
+	_, created := vappSnapshotSummary(vapp)
+	if created == "" {
+		log.Printf("[DEBUG] %s of vApp '%s' already removed", labelVappSnapshot, vapp.VApp.Name)
+		return nil
+	}
+	// The snapshot of this resource was replaced by another one (e.g. of a single VM), which is not removed
+	if created != d.Get("created_at").(string) {
+		log.Printf("[DEBUG] %s of vApp '%s' was replaced by a snapshot created at %s, which is kept",
+			labelVappSnapshot, vapp.VApp.Name, created)
+		return nil
+	}
+	target := snapshotTarget{client: &vcdClient.Client, href: vapp.VApp.HREF, label: fmt.Sprintf("vApp '%s'", vapp.VApp.Name)}
+	err = target.remove()
+	if err != nil {
+		return diag.Errorf("error removing %s: %s", labelVappSnapshot, err)
+	}
+	return nil
+}
+
+// resourceVcdVappSnapshotImport imports the current snapshot of a vApp
+// Example import path (id): org-name.vdc-name.vapp-id
+func resourceVcdVappSnapshotImport(_ context.Context, d *schema.ResourceData, meta interface{}) ([]*schema.ResourceData, error) {
+	resourceURI := strings.Split(d.Id(), ImportSeparator)
+	if len(resourceURI) != 3 {
+		return nil, fmt.Errorf("resource name must be specified as org-name.vdc-name.vapp-id")
+	}
+	orgName, vdcName, vappId := resourceURI[0], resourceURI[1], resourceURI[2]
+
+	vcdClient := meta.(*VCDClient)
+	_, vdc, err := vcdClient.GetOrgAndVdc(orgName, vdcName)
+	if err != nil {
+		return nil, fmt.Errorf(errorRetrievingOrgAndVdc, err)
+	}
+	vapp, err := vdc.GetVAppById(vappId, false)
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving vApp %s: %s", vappId, err)
+	}
+
+	dSet(d, "org", orgName)
+	dSet(d, "vdc", vdcName)
+	dSet(d, "vapp_id", vapp.VApp.ID)
+	dSet(d, "memory", false)
+	dSet(d, "quiesce", false)
+	d.SetId(vapp.VApp.ID)
+
+	err = setVappSnapshotData(d, vcdClient, true)
+	if err != nil {
+		return nil, err
+	}
+	return []*schema.ResourceData{d}, nil
+}
+
+// setVappSnapshotData sets the computed properties of the vApp snapshot from the snapshots of its VMs. Unless 'adopt'
+// is set, the resource is removed from state when the snapshots were removed or replaced out of band
+func setVappSnapshotData(d *schema.ResourceData, vcdClient *VCDClient, adopt bool) error {
+	_, vdc, err := vcdClient.GetOrgAndVdcFromResource(d)
+	if err != nil {
+		return fmt.Errorf(errorRetrievingOrgAndVdc, err)
+	}
+	vapp, err := vdc.GetVAppById(d.Get("vapp_id").(string), true)
+	if govcd.ContainsNotFound(err) && !adopt {
+		log.Printf("[DEBUG] vApp %s not found. Removing %s from state", d.Get("vapp_id"), labelVappSnapshot)
+		d.SetId("")
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	size, created := vappSnapshotSummary(vapp)
+	if created == "" {
+		if adopt {
+			return fmt.Errorf("vApp '%s' has no snapshot", vapp.VApp.Name)
+		}
+		log.Printf("[DEBUG] %s of vApp '%s' was removed. Removing it from state", labelVappSnapshot, vapp.VApp.Name)
+		d.SetId("")
+		return nil
+	}
+	if !adopt && created != d.Get("created_at").(string) {
+		log.Printf("[DEBUG] %s of vApp '%s' was replaced by a snapshot created at %s. Removing it from state",
+			labelVappSnapshot, vapp.VApp.Name, created)
+		d.SetId("")
+		return nil
+	}
+
+	dSet(d, "size", size)
+	dSet(d, "created_at", created)
+	return nil
+}
+
+// vappSnapshotSummary returns the total size and the most recent creation date of the snapshots of the VMs of a
+// vApp. The creation date is empty when no VM has a snapshot
+func vappSnapshotSummary(vapp *govcd.VApp) (int, string) {
+	size := 0
+	created := ""
+	if vapp.VApp.Children == nil {
+		return size, created
+	}
+	for _, vm := range vapp.VApp.Children.VM {
+		if vm.Snapshots == nil {
+			continue
+		}
+		for _, snapshot := range vm.Snapshots.Snapshot {
+			size += snapshot.Size
+			// VCD dates share the same ISO 8601 format, so that they sort as strings
+			if snapshot.Created > created {
+				created = snapshot.Created
+			}
+		}
+	}
+	return size, created
+}
+
+// lockSnapshotVapp retrieves the vApp of a snapshot resource and locks it. It returns the function that releases the
+// locks, which fails if the distributed lock was lost meanwhile
+func lockSnapshotVapp(d *schema.ResourceData, vcdClient *VCDClient) (*govcd.VApp, func() error, error) {
+	_, vdc, err := vcdClient.GetOrgAndVdcFromResource(d)
+	if err != nil {
+		return nil, nil, fmt.Errorf(errorRetrievingOrgAndVdc, err)
+	}
+	vapp, err := vdc.GetVAppById(d.Get("vapp_id").(string), false)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	unlock := vcdClient.lockVappWithName(vcdClient.getOrgName(d), vcdClient.getVdcName(d), vapp.VApp.Name)
+	unlockDistributed, err := vcdClient.lockDistributed(vapp.VApp.ID)
+	if err != nil {
+		unlock()
+		return nil, nil, err
+	}
+	// The vApp is retrieved again, as it may have changed while waiting for the locks
+	err = vapp.Refresh()
+	if err != nil {
+		_ = unlockDistributed()
+		unlock()
+		return nil, nil, fmt.Errorf("error refreshing vApp '%s': %s", vapp.VApp.Name, err)
+	}
+	return vapp, func() error {
+		defer unlock()
+		return unlockDistributed()
+	}, nil
+}
@@ -0,0 +1,753 @@
+package vcd
+
+import (
+	"archive/tar"
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"hash"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+	"github.com/vmware/go-vcloud-director/v3/govcd"
+	"github.com/vmware/go-vcloud-director/v3/types/v56"
+)
+
+const (
+	labelVappTemplateExport = "vApp template export"
+
+	vappTemplateExportOva = "ova"
+	vappTemplateExportOvf = "ovf"
+)
+
+func resourceVcdVappTemplateExport() *schema.Resource {
+	return &schema.Resource{
+		CreateContext: resourceVcdVappTemplateExportCreate,
+		ReadContext:   resourceVcdVappTemplateExportRead,
+		DeleteContext: resourceVcdVappTemplateExportDelete,
+
+		Schema: map[string]*schema.Schema{
+			"org": {
+				Type:     schema.TypeString,
+				Optional: true,
+				ForceNew: true,
+				Description: "The name of organization to use, optional if defined at provider " +
+					"level. Useful when connected as sysadmin working across different organizations",
+			},
+			"vapp_template_id": {
+				Type:        schema.TypeString,
+				Required:    true,
+				ForceNew:    true,
+				Description: "ID of the vApp template to export",
+			},
+			"output_path": {
+				Type:     schema.TypeString,
+				Required: true,
+				ForceNew: true,
+				Description: "Local path of the export. It is the OVA file when 'format' is 'ova', and the directory " +
+					"containing the OVF descriptor, the manifest and the disk files when 'format' is 'ovf'",
+			},
+			"format": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				ForceNew:     true,
+				Default:      vappTemplateExportOva,
+				Description:  "Format of the export. One of 'ova' (single file) or 'ovf' (directory)",
+				ValidateFunc: validation.StringInSlice([]string{vappTemplateExportOva, vappTemplateExportOvf}, false),
+			},
+			"preserve_identity_information": {
+				Type:     schema.TypeBool,
+				Computed: true,
+				Description: "Whether the export includes BIOS UUIDs and MAC addresses, as defined by the " +
+					"'preserve_identity_information' setting of the catalog of the template",
+			},
+			"files": {
+				Type:        schema.TypeList,
+				Computed:    true,
+				Description: "Names of the files of the OVF package, starting with the descriptor",
+				Elem:        &schema.Schema{Type: schema.TypeString},
+			},
+			"size": {
+				Type:        schema.TypeInt,
+				Computed:    true,
+				Description: "Total size of the files of the OVF package in bytes",
+			},
+			"manifest": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "Manifest of the OVF package, with the SHA-256 checksums of its files",
+			},
+		},
+	}
+}
+
+func resourceVcdVappTemplateExportCreate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	vcdClient := meta.(*VCDClient)
+	vappTemplateId := d.Get("vapp_template_id").(string)
+
+	vAppTemplate, err := vcdClient.GetVAppTemplateById(vappTemplateId)
+	if err != nil {
+		return diag.Errorf("error retrieving vApp template %s: %s", vappTemplateId, err)
+	}
+	preserveIdentity, err := vappTemplatePreservesIdentity(d, vcdClient, vAppTemplate)
+	if err != nil {
+		return diag.Errorf("error retrieving download settings of vApp template '%s': %s", vAppTemplate.VAppTemplate.Name, err)
+	}
+
+	export := &vappTemplateExport{
+		client:     &vcdClient.Client,
+		format:     d.Get("format").(string),
+		outputPath: filepath.Clean(d.Get("output_path").(string)),
+	}
+	err = export.run(vAppTemplate.VAppTemplate, preserveIdentity)
+	if err != nil {
+		return diag.Errorf("error exporting vApp template '%s': %s", vAppTemplate.VAppTemplate.Name, err)
+	}
+
+	d.SetId(vAppTemplate.VAppTemplate.ID)
+	dSet(d, "preserve_identity_information", export.preserveIdentity)
+	return resourceVcdVappTemplateExportRead(ctx, d, meta)
+}
+
+// resourceVcdVappTemplateExportRead checks that the local files still match the exported package. When they were
+// changed or removed, the resource is removed from state, so that the next apply exports the template again
+func resourceVcdVappTemplateExportRead(_ context.Context, d *schema.ResourceData, _ interface{}) diag.Diagnostics {
+	export := &vappTemplateExport{
+		format:     d.Get("format").(string),
+		outputPath: filepath.Clean(d.Get("output_path").(string)),
+	}
+	pkg, err := export.readLocal()
+	if err != nil {
+		log.Printf("[DEBUG] %s at %s is not valid anymore (%s). Removing it from state", labelVappTemplateExport,
+			export.outputPath, err)
+		d.SetId("")
+		return nil
+	}
+	if manifest := d.Get("manifest").(string); manifest != "" && manifest != pkg.manifest() {
+		log.Printf("[DEBUG] %s at %s was replaced by a different package. Removing it from state",
+			labelVappTemplateExport, export.outputPath)
+		d.SetId("")
+		return nil
+	}
+
+	err = d.Set("files", pkg.fileNames())
+	if err != nil {
+		return diag.Errorf("error setting files of %s: %s", labelVappTemplateExport, err)
+	}
+	dSet(d, "size", pkg.size())
+	dSet(d, "manifest", pkg.manifest())
+	return nil
+}
+
+// resourceVcdVappTemplateExportDelete removes the resource from state. The exported files are kept, as they are
+// usually copied elsewhere (e.g. for disaster recovery)
+func resourceVcdVappTemplateExportDelete(_ context.Context, d *schema.ResourceData, _ interface{}) diag.Diagnostics {
+	log.Printf("[DEBUG] Removing %s of %s from state. The files at %s are kept", labelVappTemplateExport,
+		d.Get("vapp_template_id"), d.Get("output_path"))
+	return nil
+}
+
+// vappTemplatePreservesIdentity returns whether the catalog of the template preserves the identity information in
+// downloads. The catalog is read with the tenant view, so that users without catalog administration rights can export
+// the templates they can see
+func vappTemplatePreservesIdentity(d *schema.ResourceData, vcdClient *VCDClient, vAppTemplate *govcd.VAppTemplate) (bool, error) {
+	record, err := vAppTemplate.GetVappTemplateRecord()
+	if err != nil {
+		return false, err
+	}
+	org, err := vcdClient.GetOrgFromResource(d)
+	if err != nil {
+		return false, fmt.Errorf(errorRetrievingOrg, err)
+	}
+	catalog, err := org.GetCatalogById("urn:vcloud:catalog:"+extractUuid(record.Catalog), false)
+	if err != nil {
+		return false, fmt.Errorf("error reading the download settings of catalog '%s': %s", record.CatalogName, err)
+	}
+	params := catalog.Catalog.PublishExternalCatalogParams
+	return params != nil && params.PreserveIdentityInfoFlag != nil && *params.PreserveIdentityInfoFlag, nil
+}
+
+// ovfEnvelope contains the references to the files of an OVF descriptor
+type ovfEnvelope struct {
+	XMLName    xml.Name `xml:"Envelope"`
+	References []struct {
+		Href string `xml:"href,attr"`
+		Size int64  `xml:"size,attr"`
+	} `xml:"References>File"`
+}
+
+// ovfPackageFile is a file of an OVF package, with its SHA-256 checksum
+type ovfPackageFile struct {
+	name   string
+	size   int64
+	sha256 string
+}
+
+// ovfPackage is an OVF package: the descriptor, followed by the files it references
+type ovfPackage struct {
+	descriptorName string
+	descriptor     []byte
+	files          []ovfPackageFile
+}
+
+func (pkg *ovfPackage) manifestName() string {
+	return strings.TrimSuffix(pkg.descriptorName, path.Ext(pkg.descriptorName)) + ".mf"
+}
+
+// manifest returns the OVF manifest of the package, using SHA-256 checksums
+func (pkg *ovfPackage) manifest() string {
+	descriptorSum := sha256.Sum256(pkg.descriptor)
+	var manifest strings.Builder
+	manifest.WriteString(fmt.Sprintf("SHA256(%s)= %s\n", pkg.descriptorName, hex.EncodeToString(descriptorSum[:])))
+	for _, file := range pkg.files {
+		manifest.WriteString(fmt.Sprintf("SHA256(%s)= %s\n", file.name, file.sha256))
+	}
+	return manifest.String()
+}
+
+func (pkg *ovfPackage) fileNames() []string {
+	names := []string{pkg.descriptorName}
+	for _, file := range pkg.files {
+		names = append(names, file.name)
+	}
+	return names
+}
+
+func (pkg *ovfPackage) size() int {
+	size := int64(len(pkg.descriptor))
+	for _, file := range pkg.files {
+		size += file.size
+	}
+	return int(size)
+}
+
+// vappTemplateExport downloads a vApp template into a local OVA file or OVF directory
+type vappTemplateExport struct {
+	client           *govcd.Client
+	format           string
+	outputPath       string
+	preserveIdentity bool
+}
+
+// run enables the download of the template, retrieves its OVF descriptor and, unless the local package already
+// matches it, downloads all its files. The download is disabled at the end, to release the transfer storage
+func (export *vappTemplateExport) run(vAppTemplate *types.VAppTemplate, preserveIdentity bool) error {
+	enableHref := vappTemplateLinkHref(vAppTemplate.Link, "enable")
+	if enableHref == "" {
+		return fmt.Errorf("the vApp template can't be downloaded: no 'enable' link found")
+	}
+	log.Printf("[TRACE] Enabling download of vApp template %s", vAppTemplate.Name)
+	task, err := export.client.ExecuteTaskRequest(enableHref, http.MethodPost, "", "error enabling download: %s", nil)
+	if err != nil {
+		return err
+	}
+	err = task.WaitTaskCompletion()
+	if err != nil {
+		return fmt.Errorf("error enabling download: %s", err)
+	}
+	defer export.disableDownload(vAppTemplate.HREF)
+
+	refreshed := &types.VAppTemplate{}
+	_, err = export.client.ExecuteRequest(vAppTemplate.HREF, http.MethodGet, "", "error refreshing vApp template: %s", nil, refreshed)
+	if err != nil {
+		return err
+	}
+	descriptorHref, err := export.selectDescriptorHref(refreshed.Link, preserveIdentity)
+	if err != nil {
+		return err
+	}
+	descriptorUrl, err := url.Parse(descriptorHref)
+	if err != nil {
+		return fmt.Errorf("error parsing download URL '%s': %s", descriptorHref, err)
+	}
+
+	remote, err := export.fetchDescriptor(descriptorUrl)
+	if err != nil {
+		return err
+	}
+	remoteManifest, err := export.fetchManifest(descriptorUrl, remote)
+	if err != nil {
+		return err
+	}
+
+	if local, err := export.readLocal(); err == nil && local.matches(remote, remoteManifest) {
+		log.Printf("[DEBUG] %s at %s already matches vApp template %s. Skipping download", labelVappTemplateExport,
+			export.outputPath, vAppTemplate.Name)
+		return nil
+	}
+	return export.download(descriptorUrl, remote, remoteManifest)
+}
+
+// selectDescriptorHref returns the link to the OVF descriptor. The identity link includes BIOS UUIDs and MAC
+// addresses, and is used when the catalog preserves the identity information
+func (export *vappTemplateExport) selectDescriptorHref(links types.LinkList, preserveIdentity bool) (string, error) {
+	if preserveIdentity {
+		identityHref := vappTemplateLinkHref(links, types.RelDownloadIdentity)
+		if identityHref == "" {
+			return "", fmt.Errorf("the catalog preserves identity information, but no '%s' link was found", types.RelDownloadIdentity)
+		}
+		export.preserveIdentity = true
+		return identityHref, nil
+	}
+	if defaultHref := vappTemplateLinkHref(links, types.RelDownloadDefault); defaultHref != "" {
+		return defaultHref, nil
+	}
+	return "", fmt.Errorf("no '%s' link found after enabling the download", types.RelDownloadDefault)
+}
+
+func (export *vappTemplateExport) disableDownload(vAppTemplateHref string) {
+	refreshed := &types.VAppTemplate{}
+	_, err := export.client.ExecuteRequest(vAppTemplateHref, http.MethodGet, "", "error refreshing vApp template: %s", nil, refreshed)
+	if err == nil {
+		disableHref := vappTemplateLinkHref(refreshed.Link, "disable")
+		if disableHref == "" {
+			return
+		}
+		var task govcd.Task
+		task, err = export.client.ExecuteTaskRequest(disableHref, http.MethodPost, "", "error disabling download: %s", nil)
+		if err == nil {
+			err = task.WaitTaskCompletion()
+		}
+	}
+	if err != nil {
+		log.Printf("[DEBUG] unable to disable download of vApp template %s: %s", vAppTemplateHref, err)
+	}
+}
+
+// fetchDescriptor retrieves the OVF descriptor, and checks that the files it references are in the same directory
+func (export *vappTemplateExport) fetchDescriptor(descriptorUrl *url.URL) (*ovfPackage, error) {
+	var descriptor bytes.Buffer
+	_, err := export.get(descriptorUrl, &descriptor)
+	if err != nil {
+		return nil, fmt.Errorf("error downloading OVF descriptor: %s", err)
+	}
+
+	envelope := ovfEnvelope{}
+	err = xml.Unmarshal(descriptor.Bytes(), &envelope)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing OVF descriptor: %s", err)
+	}
+	pkg := &ovfPackage{descriptorName: path.Base(descriptorUrl.Path), descriptor: descriptor.Bytes()}
+	for _, reference := range envelope.References {
+		if !isOvfPackageFileName(reference.Href) {
+			return nil, fmt.Errorf("OVF descriptor references an unsupported file '%s'", reference.Href)
+		}
+		pkg.files = append(pkg.files, ovfPackageFile{name: reference.Href, size: reference.Size})
+	}
+	return pkg, nil
+}
+
+// fetchManifest retrieves the manifest published next to the OVF descriptor, if any. It returns the checksums by file
+// name
+func (export *vappTemplateExport) fetchManifest(descriptorUrl *url.URL, pkg *ovfPackage) (map[string]ovfChecksum, error) {
+	var manifest bytes.Buffer
+	found, err := export.get(descriptorUrl.ResolveReference(&url.URL{Path: pkg.manifestName()}), &manifest)
+	if err != nil {
+		return nil, fmt.Errorf("error downloading OVF manifest: %s", err)
+	}
+	if !found {
+		log.Printf("[DEBUG] no OVF manifest published for %s", descriptorUrl)
+		return nil, nil
+	}
+	checksums, err := parseOvfManifest(manifest.String())
+	if err != nil {
+		return nil, err
+	}
+	if checksum, ok := checksums[pkg.descriptorName]; ok {
+		err = checksum.verify(pkg.descriptorName, pkg.descriptor)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return checksums, nil
+}
+
+// download retrieves the files of the package into a temporary location, verifies them against the published manifest
+// and then moves them to the output path
+func (export *vappTemplateExport) download(descriptorUrl *url.URL, pkg *ovfPackage, remoteManifest map[string]ovfChecksum) error {
+	outputDir := export.outputPath
+	if export.format == vappTemplateExportOva {
+		outputDir = filepath.Dir(export.outputPath)
+	}
+	err := os.MkdirAll(outputDir, 0750)
+	if err != nil {
+		return fmt.Errorf("error creating directory %s: %s", outputDir, err)
+	}
+	workDir, err := os.MkdirTemp(outputDir, ".vcd-export-")
+	if err != nil {
+		return fmt.Errorf("error creating temporary directory: %s", err)
+	}
+	defer func() {
+		_ = os.RemoveAll(workDir)
+	}()
+
+	for i, file := range pkg.files {
+		fileUrl := descriptorUrl.ResolveReference(&url.URL{Path: file.name})
+		log.Printf("[TRACE] Downloading %s of %s", file.name, labelVappTemplateExport)
+		pkg.files[i], err = export.downloadFile(fileUrl, filepath.Join(workDir, file.name), remoteManifest[file.name])
+		if err != nil {
+			return fmt.Errorf("error downloading %s: %s", file.name, err)
+		}
+	}
+
+	if export.format == vappTemplateExportOva {
+		return export.writeOva(workDir, pkg)
+	}
+	return export.writeOvf(workDir, pkg)
+}
+
+// downloadFile writes the content of a URL to a file, computing its checksum on the way. When the published manifest
+// has a checksum for the file, it is verified
+func (export *vappTemplateExport) downloadFile(fileUrl *url.URL, filePath string, expected ovfChecksum) (ovfPackageFile, error) {
+	file := ovfPackageFile{name: filepath.Base(filePath)}
+	output, err := os.Create(filepath.Clean(filePath))
+	if err != nil {
+		return file, err
+	}
+	sha256Hash := sha256.New()
+	writers := []io.Writer{output, sha256Hash}
+	var expectedHash hash.Hash
+	if expected.algorithm != "" {
+		expectedHash = expected.newHash()
+		writers = append(writers, expectedHash)
+	}
+	found, err := export.get(fileUrl, io.MultiWriter(writers...))
+	if closeErr := output.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return file, err
+	}
+	if !found {
+		return file, fmt.Errorf("file not found at %s", fileUrl)
+	}
+
+	info, err := os.Stat(filePath)
+	if err != nil {
+		return file, err
+	}
+	file.size = info.Size()
+	file.sha256 = hex.EncodeToString(sha256Hash.Sum(nil))
+	if expectedHash != nil {
+		if actual := hex.EncodeToString(expectedHash.Sum(nil)); actual != expected.digest {
+			return file, fmt.Errorf("checksum mismatch: the manifest has %s(%s)= %s, the download has %s",
+				expected.algorithm, file.name, expected.digest, actual)
+		}
+	}
+	return file, nil
+}
+
+// writeOva packs the downloaded files into an OVA archive: the descriptor comes first, followed by the manifest and the
+// files in the order of the descriptor references, as required by the OVF specification
+func (export *vappTemplateExport) writeOva(workDir string, pkg *ovfPackage) error {
+	temporaryOva := filepath.Join(workDir, filepath.Base(export.outputPath))
+	output, err := os.Create(filepath.Clean(temporaryOva))
+	if err != nil {
+		return err
+	}
+	err = writeOvaArchive(output, workDir, pkg)
+	if closeErr := output.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return fmt.Errorf("error writing OVA: %s", err)
+	}
+	return os.Rename(temporaryOva, export.outputPath)
+}
+
+func writeOvaArchive(output io.Writer, workDir string, pkg *ovfPackage) error {
+	archive := tar.NewWriter(output)
+	modTime := time.Now()
+	addEntry := func(name string, size int64, content io.Reader) error {
+		err := archive.WriteHeader(&tar.Header{
+			Name:    name,
+			Size:    size,
+			Mode:    0644,
+			ModTime: modTime,
+			Format:  tar.FormatUSTAR,
+		})
+		if err != nil {
+			return err
+		}
+		_, err = io.Copy(archive, content)
+		return err
+	}
+
+	err := addEntry(pkg.descriptorName, int64(len(pkg.descriptor)), bytes.NewReader(pkg.descriptor))
+	if err != nil {
+		return err
+	}
+	manifest := pkg.manifest()
+	err = addEntry(pkg.manifestName(), int64(len(manifest)), strings.NewReader(manifest))
+	if err != nil {
+		return err
+	}
+	for _, file := range pkg.files {
+		content, err := os.Open(filepath.Join(workDir, file.name))
+		if err != nil {
+			return err
+		}
+		err = addEntry(file.name, file.size, content)
+		_ = content.Close()
+		if err != nil {
+			return err
+		}
+	}
+	return archive.Close()
+}
+
+// writeOvf moves the downloaded files to the output directory, together with the descriptor and the manifest
+func (export *vappTemplateExport) writeOvf(workDir string, pkg *ovfPackage) error {
+	for _, file := range pkg.files {
+		err := os.Rename(filepath.Join(workDir, file.name), filepath.Join(export.outputPath, file.name))
+		if err != nil {
+			return err
+		}
+	}
+	err := os.WriteFile(filepath.Join(export.outputPath, pkg.manifestName()), []byte(pkg.manifest()), 0600)
+	if err != nil {
+		return err
+	}
+	// The descriptor is written last, so that an interrupted export is not taken for a complete one
+	return os.WriteFile(filepath.Join(export.outputPath, pkg.descriptorName), pkg.descriptor, 0600)
+}
+
+// readLocal reads the local package at the output path, and checks that its files match its manifest
+func (export *vappTemplateExport) readLocal() (*ovfPackage, error) {
+	if export.format == vappTemplateExportOva {
+		return readOvaArchive(export.outputPath)
+	}
+	return readOvfDirectory(export.outputPath)
+}
+
+func readOvaArchive(ovaPath string) (*ovfPackage, error) {
+	ova, err := os.Open(filepath.Clean(ovaPath))
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = ova.Close()
+	}()
+
+	pkg := &ovfPackage{}
+	var manifest string
+	archive := tar.NewReader(bufio.NewReader(ova))
+	for {
+		header, err := archive.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("error reading OVA %s: %s", ovaPath, err)
+		}
+		switch {
+		case pkg.descriptorName == "":
+			if path.Ext(header.Name) != ".ovf" {
+				return nil, fmt.Errorf("the first entry of OVA %s is not an OVF descriptor", ovaPath)
+			}
+			pkg.descriptorName = header.Name
+			pkg.descriptor, err = io.ReadAll(archive)
+		case header.Name == pkg.manifestName():
+			var content []byte
+			content, err = io.ReadAll(archive)
+			manifest = string(content)
+		default:
+			sha256Hash := sha256.New()
+			var size int64
+			size, err = io.Copy(sha256Hash, archive)
+			pkg.files = append(pkg.files, ovfPackageFile{name: header.Name, size: size, sha256: hex.EncodeToString(sha256Hash.Sum(nil))})
+		}
+		if err != nil {
+			return nil, fmt.Errorf("error reading %s from OVA %s: %s", header.Name, ovaPath, err)
+		}
+	}
+	if pkg.descriptorName == "" {
+		return nil, fmt.Errorf("OVA %s is empty", ovaPath)
+	}
+	if manifest != pkg.manifest() {
+		return nil, fmt.Errorf("the content of OVA %s doesn't match its manifest", ovaPath)
+	}
+	return pkg, nil
+}
+
+func readOvfDirectory(directory string) (*ovfPackage, error) {
+	descriptors, err := filepath.Glob(filepath.Join(directory, "*.ovf"))
+	if err != nil {
+		return nil, err
+	}
+	if len(descriptors) != 1 {
+		return nil, fmt.Errorf("expected one OVF descriptor in %s, found %d", directory, len(descriptors))
+	}
+	pkg := &ovfPackage{descriptorName: filepath.Base(descriptors[0])}
+	pkg.descriptor, err = os.ReadFile(descriptors[0])
+	if err != nil {
+		return nil, err
+	}
+	manifest, err := os.ReadFile(filepath.Join(directory, pkg.manifestName()))
+	if err != nil {
+		return nil, err
+	}
+
+	// The files are listed in the order of the manifest, which follows the descriptor references
+	checksums, err := parseOvfManifest(string(manifest))
+	if err != nil {
+		return nil, err
+	}
+	for _, name := range orderedOvfManifestNames(string(manifest)) {
+		if name == pkg.descriptorName {
+			continue
+		}
+		content, err := os.Open(filepath.Join(directory, name))
+		if err != nil {
+			return nil, err
+		}
+		sha256Hash := sha256.New()
+		size, err := io.Copy(sha256Hash, content)
+		_ = content.Close()
+		if err != nil {
+			return nil, err
+		}
+		pkg.files = append(pkg.files, ovfPackageFile{name: name, size: size, sha256: hex.EncodeToString(sha256Hash.Sum(nil))})
+		if checksums[name].digest != pkg.files[len(pkg.files)-1].sha256 {
+			return nil, fmt.Errorf("the content of %s doesn't match the manifest", name)
+		}
+	}
+	if string(manifest) != pkg.manifest() {
+		return nil, fmt.Errorf("the content of %s doesn't match its manifest", directory)
+	}
+	return pkg, nil
+}
+
+// matches returns true when the local package has the same descriptor as the remote one and, if VCD publishes a
+// manifest, the same file checksums
+func (pkg *ovfPackage) matches(remote *ovfPackage, remoteManifest map[string]ovfChecksum) bool {
+	if !bytes.Equal(pkg.descriptor, remote.descriptor) || len(pkg.files) != len(remote.files) {
+		return false
+	}
+	for i, file := range pkg.files {
+		if file.name != remote.files[i].name || (remote.files[i].size > 0 && file.size != remote.files[i].size) {
+			return false
+		}
+	}
+	// Without a published manifest, the local files were verified against the local manifest only
+	for name, checksum := range remoteManifest {
+		if name == pkg.descriptorName || checksum.algorithm != "SHA256" {
+			continue
+		}
+		for _, file := range pkg.files {
+			if file.name == name && file.sha256 != checksum.digest {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// get retrieves a URL with the credentials of the client, writing the response body to 'output'. It returns false
+// when the URL was not found
+func (export *vappTemplateExport) get(fileUrl *url.URL, output io.Writer) (bool, error) {
+	request := export.client.NewRequest(map[string]string{}, http.MethodGet, *fileUrl, nil)
+	response, err := export.client.Http.Do(request)
+	if err != nil {
+		return false, err
+	}
+	defer func() {
+		_ = response.Body.Close()
+	}()
+	if response.StatusCode == http.StatusNotFound {
+		return false, nil
+	}
+	if response.StatusCode >= http.StatusBadRequest {
+		return false, fmt.Errorf("error retrieving %s: %s", fileUrl, response.Status)
+	}
+	_, err = io.Copy(output, response.Body)
+	return true, err
+}
+
+// ovfChecksum is an entry of an OVF manifest
+type ovfChecksum struct {
+	algorithm string
+	digest    string
+}
+
+func (checksum ovfChecksum) newHash() hash.Hash {
+	switch checksum.algorithm {
+	case "SHA1":
+		return sha1.New() // #nosec G401 -- SHA-1 manifests are still produced by older tools
+	case "SHA512":
+		return sha512.New()
+	}
+	return sha256.New()
+}
+
+func (checksum ovfChecksum) verify(name string, content []byte) error {
+	checksumHash := checksum.newHash()
+	_, _ = checksumHash.Write(content)
+	if actual := hex.EncodeToString(checksumHash.Sum(nil)); actual != checksum.digest {
+		return fmt.Errorf("checksum mismatch: the manifest has %s(%s)= %s, the download has %s",
+			checksum.algorithm, name, checksum.digest, actual)
+	}
+	return nil
+}
+
+var ovfManifestLine = regexp.MustCompile(`^(SHA1|SHA256|SHA512)\(([^)]+)\)\s*=\s*([0-9a-fA-F]+)$`)
+
+// parseOvfManifest parses an OVF manifest, returning the checksums by file name
+func parseOvfManifest(manifest string) (map[string]ovfChecksum, error) {
+	checksums := make(map[string]ovfChecksum)
+	for _, line := range strings.Split(manifest, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		match := ovfManifestLine.FindStringSubmatch(line)
+		if match == nil {
+			return nil, fmt.Errorf("invalid OVF manifest line '%s'", line)
+		}
+		checksums[match[2]] = ovfChecksum{algorithm: match[1], digest: strings.ToLower(match[3])}
+	}
+	return checksums, nil
+}
+
+// orderedOvfManifestNames returns the file names of an OVF manifest, in the order of the manifest
+func orderedOvfManifestNames(manifest string) []string {
+	var names []string
+	for _, line := range strings.Split(manifest, "\n") {
+		if match := ovfManifestLine.FindStringSubmatch(strings.TrimSpace(line)); match != nil {
+			names = append(names, match[2])
+		}
+	}
+	return names
+}
+
+// isOvfPackageFileName returns true when a file reference is a plain file name, which can't point outside the export
+func isOvfPackageFileName(name string) bool {
+	return name != "" && name != "." && name != ".." && !strings.ContainsAny(name, `/\`)
+}
+
+// vappTemplateLinkHref returns the first link with the given relation
+func vappTemplateLinkHref(links types.LinkList, rel string) string {
+	for _, link := range links {
+		if link.Rel == rel {
+			return link.HREF
+		}
+	}
+	return ""
+}
@@ -0,0 +1,105 @@
+//go:build catalog || ALL || functional
+
+package vcd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+)
+
+// TestAccVcdVappTemplateExport exports the same vApp template to an OVA file and to an OVF directory, and checks that
+// a removed local export is downloaded again
+func TestAccVcdVappTemplateExport(t *testing.T) {
+	preTestChecks(t)
+
+	outputDir := t.TempDir()
+	var params = StringMap{
+		"Org":          testConfig.VCD.Org,
+		"Catalog":      testConfig.VCD.Catalog.NsxtBackedCatalogName,
+		"VAppTemplate": testConfig.VCD.Catalog.NsxtCatalogItem,
+		"OvaPath":      filepath.Join(outputDir, "export.ova"),
+		"OvfPath":      filepath.Join(outputDir, "export-ovf"),
+		"Tags":         "catalog",
+		"FuncName":     t.Name(),
+	}
+	testParamsNotEmpty(t, params)
+
+	configText := templateFill(testAccVcdVappTemplateExport, params)
+	debugPrintf("#[DEBUG] CONFIGURATION: %s\n", configText)
+
+	if vcdShortTest {
+		t.Skip(acceptanceTestsSkipped)
+		return
+	}
+
+	resource.Test(t, resource.TestCase{
+		ProviderFactories: testAccProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: configText,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttrSet("vcd_vapp_template_export.ova", "manifest"),
+					resource.TestCheckResourceAttr("vcd_vapp_template_export.ova", "files.0", "descriptor.ovf"),
+					resource.TestCheckResourceAttrPair("vcd_vapp_template_export.ova", "manifest", "vcd_vapp_template_export.ovf", "manifest"),
+					resource.TestCheckResourceAttrPair("vcd_vapp_template_export.ova", "size", "vcd_vapp_template_export.ovf", "size"),
+					testCheckExportedFileExists(params["OvaPath"].(string)),
+					testCheckExportedFileExists(filepath.Join(params["OvfPath"].(string), "descriptor.mf")),
+				),
+			},
+			{
+				// Removing the OVA makes the resource disappear from state, and it is exported again
+				PreConfig: func() {
+					err := os.Remove(params["OvaPath"].(string))
+					if err != nil {
+						t.Fatalf("error removing OVA: %s", err)
+					}
+				},
+				Config: configText,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					testCheckExportedFileExists(params["OvaPath"].(string)),
+				),
+			},
+		},
+	})
+	postTestChecks(t)
+}
+
+func testCheckExportedFileExists(fileName string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		if _, err := os.Stat(fileName); err != nil {
+			return fmt.Errorf("expected file %s to exist: %s", fileName, err)
+		}
+		return nil
+	}
+}
+
+const testAccVcdVappTemplateExport = `
+data "vcd_catalog" "catalog" {
+  org  = "{{.Org}}"
+  name = "{{.Catalog}}"
+}
+
+data "vcd_catalog_vapp_template" "template" {
+  org        = "{{.Org}}"
+  catalog_id = data.vcd_catalog.catalog.id
+  name       = "{{.VAppTemplate}}"
+}
+
+resource "vcd_vapp_template_export" "ova" {
+  org              = "{{.Org}}"
+  vapp_template_id = data.vcd_catalog_vapp_template.template.id
+  output_path      = "{{.OvaPath}}"
+}
+
+resource "vcd_vapp_template_export" "ovf" {
+  org              = "{{.Org}}"
+  vapp_template_id = data.vcd_catalog_vapp_template.template.id
+  output_path      = "{{.OvfPath}}"
+  format           = "ovf"
+}
+`
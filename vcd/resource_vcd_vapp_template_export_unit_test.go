@@ -0,0 +1,230 @@
+//go:build unit || ALL
+
+package vcd
+
+import (
+	"archive/tar"
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/vmware/go-vcloud-director/v3/types/v56"
+	"github.com/vmware/terraform-provider-vcd/v4/vcd/mockvcd"
+)
+
+const testOvfDescriptor = `<?xml version="1.0" encoding="UTF-8"?>
+<ovf:Envelope xmlns:ovf="http://schemas.dmtf.org/ovf/envelope/1">
+  <ovf:References>
+    <ovf:File ovf:href="disk1.vmdk" ovf:id="file1" ovf:size="%d"/>
+    <ovf:File ovf:href="disk2.vmdk" ovf:id="file2" ovf:size="%d"/>
+  </ovf:References>
+</ovf:Envelope>`
+
+// mockVappTemplateDownload serves a vApp template whose download publishes a descriptor with two disks. It returns
+// the template and a function counting the disk downloads
+func mockVappTemplateDownload(t *testing.T, server *mockvcd.Server, disks map[string]string, manifest string) (*types.VAppTemplate, func() int) {
+	t.Helper()
+	templatePath := "/api/vAppTemplate/vappTemplate-11111111-2222-3333-4444-555555555555"
+	transferPath := "/transfer/00000000-aaaa-bbbb-cccc-dddddddddddd/"
+	descriptor := fmt.Sprintf(testOvfDescriptor, len(disks["disk1.vmdk"]), len(disks["disk2.vmdk"]))
+
+	downloadEnabled := false
+	server.HandleFunc(http.MethodGet, templatePath, func(w http.ResponseWriter, r *http.Request) {
+		links := `<Link rel="enable" href="` + server.URL + templatePath + `/action/enableDownload"/>`
+		if downloadEnabled {
+			links += `<Link rel="download:default" href="` + server.URL + transferPath + `descriptor.ovf"/>` +
+				`<Link rel="disable" href="` + server.URL + templatePath + `/action/disableDownload"/>`
+		}
+		w.Header().Set("Content-Type", types.MimeVAppTemplate)
+		_, _ = fmt.Fprintf(w, `<VAppTemplate xmlns="%s" href="%s" name="photon">%s</VAppTemplate>`,
+			types.XMLNamespaceVCloud, server.URL+templatePath, links)
+	})
+	for action, enabled := range map[string]bool{"enableDownload": true, "disableDownload": false} {
+		server.HandleFunc(http.MethodPost, templatePath+"/action/"+action, func(w http.ResponseWriter, r *http.Request) {
+			downloadEnabled = enabled
+			taskHref := server.NewTask("urn:vcloud:vapptemplate:11111111-2222-3333-4444-555555555555", server.URL+templatePath)
+			w.Header().Set("Content-Type", types.MimeTask)
+			w.WriteHeader(http.StatusAccepted)
+			_, _ = fmt.Fprintf(w, `<Task xmlns="%s" href="%s" status="running"></Task>`, types.XMLNamespaceVCloud, taskHref)
+		})
+	}
+	server.HandleFunc(http.MethodGet, transferPath+"descriptor.ovf", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.WriteString(w, descriptor)
+	})
+	if manifest != "" {
+		server.HandleFunc(http.MethodGet, transferPath+"descriptor.mf", func(w http.ResponseWriter, r *http.Request) {
+			_, _ = io.WriteString(w, manifest)
+		})
+	}
+	for name, content := range disks {
+		server.HandleFunc(http.MethodGet, transferPath+name, func(w http.ResponseWriter, r *http.Request) {
+			_, _ = io.WriteString(w, content)
+		})
+	}
+
+	template := &types.VAppTemplate{
+		HREF: server.URL + templatePath,
+		Name: "photon",
+		Link: types.LinkList{{Rel: "enable", HREF: server.URL + templatePath + "/action/enableDownload"}},
+	}
+	diskDownloads := func() int {
+		count := 0
+		for _, request := range server.Requests() {
+			if strings.HasSuffix(request.Path, ".vmdk") {
+				count++
+			}
+		}
+		return count
+	}
+	return template, diskDownloads
+}
+
+// Test_vappTemplateExportOva checks that the OVA contains the descriptor first, then the manifest and the disks, and
+// that a second export is skipped when the local OVA matches
+func Test_vappTemplateExportOva(t *testing.T) {
+	server := mockvcd.NewServer()
+	defer server.Close()
+	vcdClient := newMockVcdClient(t, server)
+
+	disks := map[string]string{"disk1.vmdk": "first disk content", "disk2.vmdk": strings.Repeat("second disk ", 1000)}
+	sum := sha1.Sum([]byte(disks["disk1.vmdk"]))
+	remoteManifest := fmt.Sprintf("SHA1(disk1.vmdk)= %s\n", hex.EncodeToString(sum[:]))
+	template, diskDownloads := mockVappTemplateDownload(t, server, disks, remoteManifest)
+
+	ovaPath := filepath.Join(t.TempDir(), "exports", "photon.ova")
+	export := &vappTemplateExport{client: &vcdClient.Client, format: vappTemplateExportOva, outputPath: ovaPath}
+	err := export.run(template, false)
+	if err != nil {
+		t.Fatalf("error exporting template: %s", err)
+	}
+	if diskDownloads() != 2 {
+		t.Fatalf("expected 2 disk downloads, got %d", diskDownloads())
+	}
+
+	ova, err := os.Open(ovaPath)
+	if err != nil {
+		t.Fatalf("error opening OVA: %s", err)
+	}
+	defer func() {
+		_ = ova.Close()
+	}()
+	archive := tar.NewReader(ova)
+	var names []string
+	contents := make(map[string]string)
+	for {
+		header, err := archive.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("error reading OVA: %s", err)
+		}
+		content, _ := io.ReadAll(archive)
+		names = append(names, header.Name)
+		contents[header.Name] = string(content)
+	}
+	if strings.Join(names, ",") != "descriptor.ovf,descriptor.mf,disk1.vmdk,disk2.vmdk" {
+		t.Fatalf("unexpected OVA entries %v", names)
+	}
+	diskSum := sha256.Sum256([]byte(disks["disk2.vmdk"]))
+	if !strings.Contains(contents["descriptor.mf"], "SHA256(disk2.vmdk)= "+hex.EncodeToString(diskSum[:])) {
+		t.Fatalf("unexpected manifest %s", contents["descriptor.mf"])
+	}
+	for name, content := range disks {
+		if contents[name] != content {
+			t.Errorf("unexpected content of %s", name)
+		}
+	}
+
+	err = export.run(template, false)
+	if err != nil {
+		t.Fatalf("error exporting template again: %s", err)
+	}
+	if diskDownloads() != 2 {
+		t.Fatalf("expected the second export to be skipped, got %d disk downloads", diskDownloads())
+	}
+
+	// A changed local file makes the OVA invalid, and it is downloaded again
+	err = os.WriteFile(ovaPath, []byte("corrupted"), 0600)
+	if err != nil {
+		t.Fatalf("error corrupting OVA: %s", err)
+	}
+	if _, err = export.readLocal(); err == nil {
+		t.Fatalf("expected the corrupted OVA to be invalid")
+	}
+	err = export.run(template, false)
+	if err != nil {
+		t.Fatalf("error exporting template after corruption: %s", err)
+	}
+	if diskDownloads() != 4 {
+		t.Fatalf("expected 4 disk downloads, got %d", diskDownloads())
+	}
+}
+
+// Test_vappTemplateExportOvf checks the export to a directory and the verification of the published manifest
+func Test_vappTemplateExportOvf(t *testing.T) {
+	server := mockvcd.NewServer()
+	defer server.Close()
+	vcdClient := newMockVcdClient(t, server)
+
+	disks := map[string]string{"disk1.vmdk": "first disk content", "disk2.vmdk": "second disk content"}
+	template, _ := mockVappTemplateDownload(t, server, disks, "SHA256(disk2.vmdk)= 0123456789abcdef\n")
+
+	outputDir := filepath.Join(t.TempDir(), "photon")
+	export := &vappTemplateExport{client: &vcdClient.Client, format: vappTemplateExportOvf, outputPath: outputDir}
+	err := export.run(template, false)
+	if err == nil || !strings.Contains(err.Error(), "checksum mismatch") {
+		t.Fatalf("expected checksum mismatch, got %v", err)
+	}
+	if _, err = export.readLocal(); err == nil {
+		t.Fatalf("expected no valid package after a failed export")
+	}
+
+	server.HandleFunc(http.MethodGet, "/transfer/00000000-aaaa-bbbb-cccc-dddddddddddd/descriptor.mf", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	})
+	err = export.run(template, false)
+	if err != nil {
+		t.Fatalf("error exporting template: %s", err)
+	}
+	pkg, err := export.readLocal()
+	if err != nil {
+		t.Fatalf("error reading exported directory: %s", err)
+	}
+	if strings.Join(pkg.fileNames(), ",") != "descriptor.ovf,disk1.vmdk,disk2.vmdk" {
+		t.Fatalf("unexpected files %v", pkg.fileNames())
+	}
+	if pkg.size() != len(pkg.descriptor)+len(disks["disk1.vmdk"])+len(disks["disk2.vmdk"]) {
+		t.Fatalf("unexpected size %d", pkg.size())
+	}
+
+	// The identity link is required when the catalog preserves identity information
+	err = export.run(template, true)
+	if err == nil || !strings.Contains(err.Error(), types.RelDownloadIdentity) {
+		t.Fatalf("expected error about missing identity link, got %v", err)
+	}
+}
+
+// Test_isOvfPackageFileName checks that references outside the package directory are rejected
+func Test_isOvfPackageFileName(t *testing.T) {
+	for name, expected := range map[string]bool{
+		"disk1.vmdk":     true,
+		"../disk1.vmdk":  false,
+		"/etc/passwd":    false,
+		`dir\disk.vmdk`:  false,
+		"..":             false,
+		"":               false,
+		"nvram-file.bin": true,
+	} {
+		if got := isOvfPackageFileName(name); got != expected {
+			t.Errorf("expected %t for '%s', got %t", expected, name, got)
+		}
+	}
+}
@@ -90,8 +90,7 @@ func vmSchemaFunc(vmType typeOfVm) map[string]*schema.Schema {
 			Required:    vmType == vappVmType,
 			Optional:    vmType == standaloneVmType,
 			Computed:    vmType == standaloneVmType,
-			ForceNew:    vmType == vappVmType,
-			Description: "The vApp this VM belongs to - Required, unless it is a standalone VM",
+			Description: "The vApp this VM belongs to - Required, unless it is a standalone VM. Changing it moves the VM to the given vApp",
 		},
 		"vapp_id": {
 			Type:        schema.TypeString,
@@ -125,8 +124,7 @@ func vmSchemaFunc(vmType typeOfVm) map[string]*schema.Schema {
 		"vdc": {
 			Type:        schema.TypeString,
 			Optional:    true,
-			ForceNew:    true,
-			Description: "The name of VDC to use, optional if defined at provider level",
+			Description: "The name of VDC to use, optional if defined at provider level. Changing it moves the VM to the VDC",
 		},
 		"template_name": {
 			Type:             schema.TypeString,
@@ -563,6 +561,57 @@ func vmSchemaFunc(vmType typeOfVm) map[string]*schema.Schema {
 			},
 			},
 		},
+		"cloud_init": cloudInitSchema(),
+		"startup": {
+			Type:        schema.TypeList,
+			MaxItems:    1,
+			Optional:    true,
+			Computed:    true,
+			Description: "A block defining the start and stop behavior of the VM when its vApp is powered on or off",
+			Elem: &schema.Resource{Schema: map[string]*schema.Schema{
+				"order": {
+					Type:         schema.TypeInt,
+					Optional:     true,
+					Computed:     true,
+					Description:  "Start order of the VM in the vApp. VMs are started in ascending order and stopped in descending order",
+					ValidateFunc: validation.IntAtLeast(0),
+				},
+				"start_action": {
+					Type:         schema.TypeString,
+					Optional:     true,
+					Computed:     true,
+					Description:  "Action performed when the vApp is powered on. One of 'powerOn' or 'none'",
+					ValidateFunc: validation.StringInSlice([]string{"powerOn", "none"}, false),
+				},
+				"start_delay": {
+					Type:         schema.TypeInt,
+					Optional:     true,
+					Computed:     true,
+					Description:  "Seconds to wait after starting the VM before starting the next VM in the order",
+					ValidateFunc: validation.IntAtLeast(0),
+				},
+				"wait_for_guest": {
+					Type:        schema.TypeBool,
+					Optional:    true,
+					Computed:    true,
+					Description: "Whether to wait for the guest operating system to be ready before starting the next VM in the order",
+				},
+				"stop_action": {
+					Type:         schema.TypeString,
+					Optional:     true,
+					Computed:     true,
+					Description:  "Action performed when the vApp is powered off. One of 'powerOff' or 'guestShutdown'",
+					ValidateFunc: validation.StringInSlice([]string{"powerOff", "guestShutdown"}, false),
+				},
+				"stop_delay": {
+					Type:         schema.TypeInt,
+					Optional:     true,
+					Computed:     true,
+					Description:  "Seconds to wait after stopping the VM before stopping the next VM in the order",
+					ValidateFunc: validation.IntAtLeast(0),
+				},
+			}},
+		},
 		"expose_hardware_virtualization": {
 			Type:        schema.TypeBool,
 			Optional:    true,
@@ -747,6 +796,13 @@ func vmSchemaFunc(vmType typeOfVm) map[string]*schema.Schema {
 			Description:      "True if the update of resource should fail when virtual machine power off needed.",
 			DiffSuppressFunc: suppressFieldAfterImport("prevent_update_power_off"),
 		},
+		"ignore_additional_network_adapters": {
+			Type:     schema.TypeBool,
+			Optional: true,
+			Default:  false,
+			Description: "When true, the NICs with an index higher than the ones of the 'network' blocks are managed with " +
+				"vcd_vm_network_adapter: they are not read into 'network' and are kept when the networks are updated",
+		},
 		"sizing_policy_id": {
 			Type:        schema.TypeString,
 			Optional:    true,
@@ -791,7 +847,7 @@ func vmSchemaFunc(vmType typeOfVm) map[string]*schema.Schema {
 
 // resourceVcdVAppVmCreate is an entry function for VM within vApp creation. It locks parent vApp and cascades down the
 // other functions that need to be run
-func resourceVcdVAppVmCreate(_ context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+func resourceVcdVAppVmCreate(_ context.Context, d *schema.ResourceData, meta interface{}) (diags diag.Diagnostics) {
 	startTime := time.Now()
 
 	vappName := d.Get("vapp_name").(string)
@@ -801,10 +857,31 @@ func resourceVcdVAppVmCreate(_ context.Context, d *schema.ResourceData, meta int
 	vmName := d.Get("name").(string)
 	util.Logger.Printf("[DEBUG] [VM create] started VM creation in vApp (vApp name: %s, VM Name: %s)", vappName, vmName)
 
-	// vApp lock must be acquired for VMs that are vApp members
+	// vApp lock must be acquired for VMs that are vApp members. It is exclusive while the VM is added to the vApp (a vApp
+	// recomposition). When the rest of the setup changes only the new VM, relaxLocks turns it into a shared lock of the
+	// vApp and an exclusive lock of the VM, so that the VMs of the same vApp are set up concurrently
 	vcdClient := meta.(*VCDClient)
 	vcdClient.lockParentVapp(d)
-	defer vcdClient.unLockParentVapp(d)
+	unlockParentVapp := func() { vcdClient.unLockParentVapp(d) }
+	unlockSourceVapp := func() {}
+	defer func() {
+		unlockSourceVapp()
+		unlockParentVapp()
+	}()
+	unlockDistributed, err := vcdClient.lockDistributedVapp(d, vappName)
+	if err != nil {
+		return diag.Errorf("[VM create] %s", err)
+	}
+	defer releaseDistributedLock(unlockDistributed, &diags)
+	var relaxLocks func()
+	if !vmSetupGoesThroughVapp(d) {
+		relaxLocks = func() {
+			unlockSourceVapp()
+			unlockSourceVapp = func() {}
+			unlockParentVapp()
+			unlockParentVapp = vcdClient.lockVappVm(d, vappName, vmName)
+		}
+	}
 
 	// If VM is a copy of another VM (has 'copy_from_vm_id' specified), parent vApp lock of source
 	// VM must also be acquired because when a copy is being made - that vApp becomes busy
@@ -842,15 +919,14 @@ func resourceVcdVAppVmCreate(_ context.Context, d *schema.ResourceData, meta int
 		if parentSourceVapp.VApp.Name != vappName || parentSourceVdc.Vdc.Name != destinationVdc.Vdc.Name {
 			util.Logger.Printf("[DEBUG] [VM create] locking parent vApp for source VM  (Org Name: '%s', VDC Name: '%s', vApp name: '%s', VM Name: '%s')",
 				destinationOrg.Org.Name, parentSourceVdc.Vdc.Name, parentSourceVapp.VApp.Name, sourceVm.VM.Name)
-			unlock := vcdClient.lockVappWithName(destinationOrg.Org.Name, parentSourceVdc.Vdc.Name, parentSourceVapp.VApp.Name)
-			defer unlock()
+			unlockSourceVapp = vcdClient.lockVappWithName(destinationOrg.Org.Name, parentSourceVdc.Vdc.Name, parentSourceVapp.VApp.Name)
 		} else {
 			util.Logger.Printf("[DEBUG] [VM create] not locking parent vApp for source VM because source and destination are the same (Source vApp: '%s', Destination vApp: '%s')  (Source VDC: '%s', Destination VDC: '%s')",
 				parentSourceVapp.VApp.Name, parentSourceVdc.Vdc.Name, parentSourceVdc.Vdc.Name, destinationVdc.Vdc.Name)
 		}
 	}
 
-	diags := genericResourceVmCreate(d, meta, vappVmType)
+	diags = genericResourceVmCreate(d, meta, vappVmType, relaxLocks)
 	// We need to check if there were errors, as genericResourceVmCreate can also return a warning
 	if diags.HasError() {
 		return diags
@@ -868,8 +944,9 @@ func resourceVcdVAppVmCreate(_ context.Context, d *schema.ResourceData, meta int
 
 // genericResourceVmCreate does the following:
 // * Executes VM create functions based on the type of VM (standalone or vApp member)
+// * Calls vmAdded (when not nil) as soon as the VM exists, so that the caller can relax the locks needed to create it
 // * Runs additional customization functions which are common for all 4 types of VMs
-func genericResourceVmCreate(d *schema.ResourceData, meta interface{}, vmType typeOfVm) diag.Diagnostics {
+func genericResourceVmCreate(d *schema.ResourceData, meta interface{}, vmType typeOfVm, vmAdded func()) diag.Diagnostics {
 	diags := diag.Diagnostics{}
 	vcdClient := meta.(*VCDClient)
 
@@ -909,6 +986,9 @@ func genericResourceVmCreate(d *schema.ResourceData, meta interface{}, vmType ty
 	default:
 		return diag.Errorf("unknown VM type")
 	}
+	if vmAdded != nil {
+		vmAdded()
+	}
 
 	////////////////////////////////////////////////////////////////////////////////////////////////
 	// This part of code performs any additional operations that should be applied to all 4 VM types
@@ -998,6 +1078,18 @@ func genericResourceVmCreate(d *schema.ResourceData, meta interface{}, vmType ty
 		return diag.Errorf("error setting extra configuration: %s", err)
 	}
 
+	// Handle cloud-init data
+	// Such schema fields are processed:
+	// * cloud_init
+	org, _, err := vcdClient.GetOrgAndVdcFromResource(d)
+	if err != nil {
+		return diag.Errorf(errorRetrievingOrgAndVdc, err)
+	}
+	err = updateVmCloudInit(d, vcdClient, org, vm)
+	if err != nil {
+		return diag.Errorf("error delivering cloud-init data: %s", err)
+	}
+
 	// vm.VM structure contains ProductSection, so it needs to be refreshed after
 	// `addRemoveGuestProperties`
 	if err = vm.Refresh(); err != nil {
@@ -1075,6 +1167,20 @@ func genericResourceVmCreate(d *schema.ResourceData, meta interface{}, vmType ty
 		}
 	}
 
+	// Handle the start and stop behavior of the VM in its vApp
+	// Such schema fields are processed:
+	// * startup
+	if _, isSet := d.GetOk("startup"); isSet {
+		vapp, err := vm.GetParentVApp()
+		if err != nil {
+			return diag.Errorf("[VM create] error retrieving the vApp of VM %s: %s", vm.VM.Name, err)
+		}
+		err = updateVmStartup(d, vcdClient, vapp, vm.VM.Name)
+		if err != nil {
+			return diag.Errorf("[VM create] error setting startup options for VM %s : %s", vm.VM.Name, err)
+		}
+	}
+
 	////////////////////////////////////////////////////////////////////////////////////////////////
 	// VM power on handling is the last step, no other VM adjustment operations should be performed
 	// after this
@@ -1751,18 +1857,48 @@ func resourceVcdVAppVmUpdate(_ context.Context, d *schema.ResourceData, meta int
 	return genericResourceVcdVmUpdate(d, meta, vappVmType)
 }
 
-func genericResourceVcdVmUpdate(d *schema.ResourceData, meta interface{}, vmType typeOfVm) diag.Diagnostics {
+func genericResourceVcdVmUpdate(d *schema.ResourceData, meta interface{}, vmType typeOfVm) (diags diag.Diagnostics) {
 	log.Printf("[DEBUG] [VM update] started with lock")
 	vcdClient := meta.(*VCDClient)
 
 	// When there is more then one VM in a vApp Terraform will try to parallelise their creation.
 	// However, vApp throws errors when simultaneous requests are executed.
-	// To avoid them, below block is using mutex as a workaround,
-	// so that the one vApp VMs are created not in parallelisation.
-
-	if vmType == vappVmType {
-		vcdClient.lockParentVapp(d)
-		defer vcdClient.unLockParentVapp(d)
+	// To avoid them, below block is using mutex as a workaround, so that the changes that go through the vApp are not
+	// parallelised. The other changes affect only the VM, so VMs of the same vApp are updated concurrently, while the
+	// changes of the vApp composition (e.g. adding or removing VMs) still wait for them
+	relocated := false
+	if vmRelocationNeeded(d) {
+		source, target, err := vmRelocationLocations(d, vcdClient, vmType)
+		if err != nil {
+			return diag.Errorf("[VM update] error moving VM: %s", err)
+		}
+		if source != target {
+			// Moving the VM changes the composition of both vApps, which stay locked for the rest of the update, as it
+			// finds the VM in its new vApp
+			unlock := lockVmLocations(vcdClient.operationContext(), vcdClient.getOrgName(d), source, target)
+			defer unlock()
+			unlockDistributed, err := relocateVm(d, vcdClient, source, target)
+			if err != nil {
+				return diag.Errorf("[VM update] error moving VM: %s", err)
+			}
+			defer releaseDistributedLock(unlockDistributed, &diags)
+			relocated = true
+		}
+	}
+	if vmType == vappVmType && !relocated {
+		if vmUpdateGoesThroughVapp(d) {
+			vcdClient.lockParentVapp(d)
+			defer vcdClient.unLockParentVapp(d)
+		} else {
+			vmName, _ := d.GetChange("name")
+			unlock := vcdClient.lockVappVm(d, d.Get("vapp_name").(string), vmName.(string))
+			defer unlock()
+		}
+		unlockDistributed, err := vcdClient.lockDistributedVapp(d, d.Get("vapp_name").(string))
+		if err != nil {
+			return diag.Errorf("[VM update] %s", err)
+		}
+		defer releaseDistributedLock(unlockDistributed, &diags)
 	}
 
 	// Exit early only if "network_dhcp_wait_seconds" is changed because this field only supports
@@ -1780,36 +1916,25 @@ func genericResourceVcdVmUpdate(d *schema.ResourceData, meta interface{}, vmType
 	return resourceVcdVAppVmUpdateExecute(d, meta, "update", vmType, nil)
 }
 
+// vmSetupGoesThroughVapp returns whether the setup of a new VM, after it is added to its vApp, includes operations that
+// go through the vApp: the attachment of independent disks and the insertion of the cloud-init media
+func vmSetupGoesThroughVapp(d *schema.ResourceData) bool {
+	return d.Get("disk").(*schema.Set).Len() > 0 || len(d.Get("cloud_init").([]interface{})) > 0
+}
+
+// vmUpdateGoesThroughVapp returns whether the update of a VM includes operations that go through its vApp: changes
+// of independent disks, boot media and cloud-init media
+func vmUpdateGoesThroughVapp(d *schema.ResourceData) bool {
+	return d.HasChanges("disk", "boot_image", "boot_image_id", "cloud_init")
+}
+
 func resourceVmHotUpdate(d *schema.ResourceData, meta interface{}, vmType typeOfVm) diag.Diagnostics {
-	_, _, vdc, vapp, _, vm, err := getVmFromResource(d, meta, vmType)
+	// Memory, CPU and network changes, which can be applied without powering off the VM, are part of the single
+	// reconfiguration of resourceVcdVAppVmUpdateExecute
+	vcdClient, org, vdc, _, _, vm, err := getVmFromResource(d, meta, vmType)
 	if err != nil {
 		return diag.FromErr(err)
 	}
-	if d.Get("memory_hot_add_enabled").(bool) && d.HasChange("memory") {
-		err = vm.ChangeMemory(int64(d.Get("memory").(int)))
-		if err != nil {
-			return diag.FromErr(err)
-		}
-	}
-
-	if d.Get("cpu_hot_add_enabled").(bool) && d.HasChange("cpus") {
-		err = vm.ChangeCPU(d.Get("cpus").(int), d.Get("cpu_cores").(int))
-		if err != nil {
-			return diag.FromErr(err)
-		}
-	}
-
-	// * Primary NIC cannot be removed on a powered on VM
-	if d.HasChange("network") && !isPrimaryNicRemoved(d) {
-		networkConnectionSection, err := networksToConfig(d, vapp)
-		if err != nil {
-			return diag.Errorf("unable to setup network configuration for update: %s", err)
-		}
-		err = vm.UpdateNetworkConnectionSection(&networkConnectionSection)
-		if err != nil {
-			return diag.Errorf("unable to update network configuration: %s", err)
-		}
-	}
 
 	err = createOrUpdateMetadata(d, vm, "metadata")
 	if err != nil {
@@ -1826,6 +1951,11 @@ func resourceVmHotUpdate(d *schema.ResourceData, meta interface{}, vmType typeOf
 		return diag.Errorf("error setting extra configuration: %s", err)
 	}
 
+	err = updateVmCloudInit(d, vcdClient, org, vm)
+	if err != nil {
+		return diag.Errorf("error delivering cloud-init data: %s", err)
+	}
+
 	sizingId, newSizingId := d.GetChange("sizing_policy_id")
 	placementId, newPlacementId := d.GetChange("placement_policy_id")
 
@@ -1909,11 +2039,21 @@ func resourceVcdVAppVmUpdateExecute(d *schema.ResourceData, meta interface{}, ex
 
 	}
 
+	// All the hardware changes are collected and applied with a single reconfiguration, after powering off the VM
+	// once, if any of them requires it
+	reconfiguration := newVmReconfiguration(vm)
+
 	if d.HasChanges("memory_reservation", "memory_priority", "memory_shares", "memory_limit",
 		"cpu_reservation", "cpu_priority", "cpu_limit", "cpu_shares") {
-		err = updateAdvancedComputeSettings(d, vm)
+		reconfiguration.changeSpec("advanced_compute", func(vmSpecSection *types.VmSpecSection) {
+			setAdvancedComputeSettings(d, vmSpecSection)
+		})
+	}
+
+	if d.HasChange("startup") {
+		err = updateVmStartup(d, vcd, vapp, vm.VM.Name)
 		if err != nil {
-			return diag.Errorf("[VM update] error advanced compute settings for standalone VM %s : %s", vm.VM.Name, err)
+			return diag.Errorf("[VM update] error updating startup options for VM %s : %s", vm.VM.Name, err)
 		}
 	}
 
@@ -1930,6 +2070,20 @@ func resourceVcdVAppVmUpdateExecute(d *schema.ResourceData, meta interface{}, ex
 		if d.HasChange("network") && isPrimaryNicRemoved(d) {
 			networksNeedsColdChange = true
 		}
+
+		if d.HasChange("memory") && !memoryNeedsColdChange {
+			reconfiguration.setMemory(int64(d.Get("memory").(int)))
+		}
+		if d.HasChange("cpus") && !cpusNeedsColdChange {
+			reconfiguration.setCpu(d.Get("cpus").(int), d.Get("cpu_cores").(int))
+		}
+		if d.HasChange("network") && !networksNeedsColdChange {
+			networkConnectionSection, err := networksToConfig(d, vapp)
+			if err != nil {
+				return diag.Errorf("unable to setup network configuration for update: %s", err)
+			}
+			reconfiguration.setNetworks(networkConnectionSection, managedNicCount(d))
+		}
 	}
 	if executionType == "create" && len(d.Get("network").([]interface{})) > 0 {
 		networksNeedsColdChange = true
@@ -1973,7 +2127,7 @@ func resourceVcdVAppVmUpdateExecute(d *schema.ResourceData, meta interface{}, ex
 		if d.HasChange("disk") {
 			err = attachDetachIndependentDisks(d, *vm, vdc)
 			if err != nil {
-				errAttachedDisk := updateStateOfAttachedIndependentDisks(d, *vm)
+				errAttachedDisk := updateStateOfAttachedIndependentDisks(d, *vm, managedIndependentDisks(d))
 				if errAttachedDisk != nil {
 					dSet(d, "disk", nil)
 					return diag.Errorf("error reading attached disks : %s and internal error : %s", errAttachedDisk, err)
@@ -1990,18 +2144,12 @@ func resourceVcdVAppVmUpdateExecute(d *schema.ResourceData, meta interface{}, ex
 			}
 
 			if !isMemoryComingFromSizingPolicy {
-				err = vm.ChangeMemory(int64(memory.(int)))
-				if err != nil {
-					return diag.FromErr(err)
-				}
+				reconfiguration.setMemory(int64(memory.(int)))
 			}
 		}
 
 		if d.HasChange("cpu_cores") {
-			err = vm.ChangeCPU(d.Get("cpus").(int), d.Get("cpu_cores").(int))
-			if err != nil {
-				return diag.FromErr(err)
-			}
+			reconfiguration.setCpu(d.Get("cpus").(int), d.Get("cpu_cores").(int))
 		}
 
 		if cpusNeedsColdChange || (executionType == "create") {
@@ -2016,10 +2164,7 @@ func resourceVcdVAppVmUpdateExecute(d *schema.ResourceData, meta interface{}, ex
 			}
 
 			if !isCpuComingFromSizingPolicy {
-				err = vm.ChangeCPU(cpus.(int), cpuCores.(int))
-				if err != nil {
-					return diag.FromErr(err)
-				}
+				reconfiguration.setCpu(cpus.(int), cpuCores.(int))
 			}
 		}
 
@@ -2028,10 +2173,7 @@ func resourceVcdVAppVmUpdateExecute(d *schema.ResourceData, meta interface{}, ex
 			if err != nil {
 				return diag.Errorf("unable to setup network configuration for update: %s", err)
 			}
-			err = vm.UpdateNetworkConnectionSection(&networkConnectionSection)
-			if err != nil {
-				return diag.Errorf("unable to update network configuration: %s", err)
-			}
+			reconfiguration.setNetworks(networkConnectionSection, managedNicCount(d))
 		}
 
 		if d.HasChange("expose_hardware_virtualization") {
@@ -2049,16 +2191,14 @@ func resourceVcdVAppVmUpdateExecute(d *schema.ResourceData, meta interface{}, ex
 
 		// updating fields of VM spec section
 		if d.HasChange("hardware_version") || d.HasChange("os_type") || d.HasChange("description") || d.HasChange("firmware") {
-			vmSpecSection := vm.VM.VmSpecSection
-			description := vm.VM.Description
 			if d.HasChange("hardware_version") {
-				vmSpecSection.HardwareVersion = &types.HardwareVersion{Value: d.Get("hardware_version").(string)}
+				reconfiguration.setHardwareVersion(d.Get("hardware_version").(string))
 			}
 			if d.HasChange("os_type") {
-				vmSpecSection.OsType = d.Get("os_type").(string)
+				reconfiguration.setOsType(d.Get("os_type").(string))
 			}
 			if d.HasChange("description") {
-				description = d.Get("description").(string)
+				reconfiguration.setDescription(d.Get("description").(string))
 			}
 			if d.HasChange("firmware") {
 				firmware := d.Get("firmware").(string)
@@ -2097,14 +2237,11 @@ func resourceVcdVAppVmUpdateExecute(d *schema.ResourceData, meta interface{}, ex
 					if err != nil {
 						return diag.Errorf("error refreshing VM: %s", err)
 					}
+					// The reconfiguration must use the VM returned by the boot options update
+					reconfiguration.vm = vm
 				}
 
-				vmSpecSection.Firmware = firmware
-			}
-
-			vm, err = vm.UpdateVmSpecSection(vmSpecSection, description)
-			if err != nil {
-				return diag.Errorf("error changing VM spec section: %s", err)
+				reconfiguration.setFirmware(firmware)
 			}
 		}
 
@@ -2141,6 +2278,11 @@ func resourceVcdVAppVmUpdateExecute(d *schema.ResourceData, meta interface{}, ex
 		}
 	}
 
+	err = reconfiguration.submit(&vcd.Client)
+	if err != nil {
+		return diag.Errorf("[VM update] error reconfiguring VM %s: %s", vm.VM.Name, err)
+	}
+
 	if d.HasChange("boot_options.0") {
 		bootOptions := &types.BootOptions{}
 
@@ -2329,7 +2471,13 @@ func genericVcdVmRead(d *schema.ResourceData, meta interface{}, origin string) d
 	d.SetId(vm.VM.ID)
 	dSet(d, "vm_type", computedVmType)
 
-	networks, err := readNetworks(d, *vm, *vapp, vdc)
+	// The resource manages all the NICs, unless the following ones are managed with vcd_vm_network_adapter, in which
+	// case it manages as many NICs as its configuration. The data source reads all of them
+	managedNics := -1
+	if origin == "resource" && d.Get("ignore_additional_network_adapters").(bool) {
+		managedNics = len(d.Get("network").([]interface{}))
+	}
+	networks, err := readNetworks(d, *vm, *vapp, vdc, managedNics)
 	if err != nil {
 		return diag.Errorf("[VM read] failed reading network details: %s", err)
 	}
@@ -2405,24 +2553,39 @@ func genericVcdVmRead(d *schema.ResourceData, meta interface{}, origin string) d
 		dSet(d, "storage_profile", vm.VM.StorageProfile.Name)
 	}
 
+	err = setVmStartupData(d, vcdClient, vapp, vm.VM.Name)
+	if err != nil {
+		return diag.Errorf("[VM read] unable to set startup options: %s", err)
+	}
+
 	// update guest properties
 	guestProperties, err := vm.GetProductSectionList()
 	if err != nil {
 		return diag.Errorf("[VM read] unable to read guest properties: %s", err)
 	}
 
+	removeCloudInitOvfProperties(d, guestProperties)
 	err = setGuestProperties(d, guestProperties)
 	if err != nil {
 		return diag.Errorf("[VM read] unable to set guest properties in state: %s", err)
 	}
 
+	err = readVmCloudInit(d, vcdClient, vm)
+	if err != nil {
+		return diag.Errorf("[VM read] unable to read cloud-init data: %s", err)
+	}
+
 	err = updateStateOfInternalDisks(d, *vm)
 	if err != nil {
 		dSet(d, "internal_disk", nil)
 		return diag.Errorf("[VM read] error reading internal disks : %s", err)
 	}
 
-	err = updateStateOfAttachedIndependentDisks(d, *vm)
+	var managedDisks map[string]bool
+	if origin == "resource" {
+		managedDisks = managedIndependentDisks(d)
+	}
+	err = updateStateOfAttachedIndependentDisks(d, *vm, managedDisks)
 	if err != nil {
 		dSet(d, "disk", nil)
 		return diag.Errorf("[VM read] error reading attached disks : %s", err)
@@ -2474,13 +2637,18 @@ func genericVcdVmRead(d *schema.ResourceData, meta interface{}, origin string) d
 	return nil
 }
 
-func resourceVcdVAppVmDelete(_ context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+func resourceVcdVAppVmDelete(_ context.Context, d *schema.ResourceData, meta interface{}) (diags diag.Diagnostics) {
 	log.Printf("[DEBUG] [VM delete] started")
 
 	vcdClient := meta.(*VCDClient)
 
 	vcdClient.lockParentVapp(d)
 	defer vcdClient.unLockParentVapp(d)
+	unlockDistributed, err := vcdClient.lockDistributedVapp(d, d.Get("vapp_name").(string))
+	if err != nil {
+		return diag.Errorf("[VM delete] %s", err)
+	}
+	defer releaseDistributedLock(unlockDistributed, &diags)
 
 	_, vdc, err := vcdClient.GetOrgAndVdcFromResource(d)
 	if err != nil {
@@ -2507,13 +2675,26 @@ func resourceVcdVAppVmDelete(_ context.Context, d *schema.ResourceData, meta int
 		return diag.Errorf("[VM delete] error getting VM %s : %s", identifier, err)
 	}
 
+	// The NoCloud ISO of cloud-init is ejected by the VM removal, and then deleted from its catalog
+	cloudInit := getVmCloudInit(d.Get("cloud_init"))
+	removeCloudInitIso := func() diag.Diagnostics {
+		if cloudInit == nil || cloudInit.delivery != cloudInitDeliveryNocloudIso {
+			return nil
+		}
+		err := removeCloudInitMedia(vcdClient, nil, cloudInit.mediaId)
+		if err != nil {
+			return diag.Errorf("[VM delete] error removing cloud-init ISO: %s", err)
+		}
+		return nil
+	}
+
 	// If it is a standalone VM, we remove it in one go
 	if vapp.VApp.IsAutoNature {
 		err = vm.Delete()
 		if err != nil {
 			return diag.FromErr(err)
 		}
-		return nil
+		return removeCloudInitIso()
 	}
 	util.Logger.Printf("[VM delete] vApp before deletion %# v", pretty.Formatter(vapp.VApp))
 	util.Logger.Printf("[VM delete] VM before deletion %# v", pretty.Formatter(vm.VM))
@@ -2561,6 +2742,9 @@ func resourceVcdVAppVmDelete(_ context.Context, d *schema.ResourceData, meta int
 	if err != nil {
 		return diag.Errorf("error deleting: %s", err)
 	}
+	if diags := removeCloudInitIso(); diags != nil {
+		return diags
+	}
 	log.Printf("[DEBUG] [VM delete] finished")
 	return nil
 }
@@ -2652,7 +2836,29 @@ func resourceVcdVappVmImport(_ context.Context, d *schema.ResourceData, meta int
 	dSet(d, "prevent_update_power_off", d.Get("prevent_update_power_off"))
 	dSet(d, "power_on", d.Get("power_on"))
 	dSet(d, "consolidate_disks_on_create", d.Get("consolidate_disks_on_create"))
+	dSet(d, "ignore_additional_network_adapters", d.Get("ignore_additional_network_adapters"))
 	dSet(d, "imported", true)
+
+	// All the NICs of an imported VM are managed by the resource
+	if vapp == nil {
+		vapp, err = vm.GetParentVApp()
+		if err != nil {
+			return nil, fmt.Errorf("[VM import] error retrieving parent vApp for VM %s: %s", vm.VM.Name, err)
+		}
+	}
+	networks, err := readNetworks(d, *vm, *vapp, vdc, -1)
+	if err != nil {
+		return nil, fmt.Errorf("[VM import] failed reading network details: %s", err)
+	}
+	err = d.Set("network", networks)
+	if err != nil {
+		return nil, err
+	}
+	// The same applies to the attached independent disks
+	err = updateStateOfAttachedIndependentDisks(d, *vm, nil)
+	if err != nil {
+		return nil, fmt.Errorf("[VM import] error reading attached disks: %s", err)
+	}
 	d.SetId(vm.VM.ID)
 	return []*schema.ResourceData{d}, nil
 }
@@ -0,0 +1,191 @@
+package vcd
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/vmware/go-vcloud-director/v3/govcd"
+	"github.com/vmware/go-vcloud-director/v3/types/v56"
+)
+
+// vmReconfiguration collects the hardware changes of a VM, so that they are applied with a single reconfigure task
+// instead of a task for each of them. Each task can take several seconds, and the VM can't be changed while one is
+// running
+type vmReconfiguration struct {
+	vm *govcd.VM
+	// specChanges are applied to the spec section of the VM read when the changes are submitted, so that the settings
+	// changed meanwhile with their own tasks (e.g. boot options and hardware virtualization) are not reverted
+	specChanges              []func(vmSpecSection *types.VmSpecSection)
+	description              *string
+	networkConnectionSection *types.NetworkConnectionSection
+	// managedNics is the number of NICs managed by the resource. The following ones are kept as they are
+	managedNics int
+	// changes lists the names of the changed settings, for logging
+	changes []string
+}
+
+func newVmReconfiguration(vm *govcd.VM) *vmReconfiguration {
+	return &vmReconfiguration{vm: vm}
+}
+
+// changeSpec records a change of the VM spec section, applied when the changes are submitted
+func (r *vmReconfiguration) changeSpec(change string, apply func(vmSpecSection *types.VmSpecSection)) {
+	r.changes = append(r.changes, change)
+	r.specChanges = append(r.specChanges, apply)
+}
+
+// spec returns a copy of the current spec section of the VM with the recorded changes applied
+func (r *vmReconfiguration) spec() *types.VmSpecSection {
+	vmSpecSection := types.VmSpecSection{}
+	if r.vm.VM.VmSpecSection != nil {
+		vmSpecSection = *r.vm.VM.VmSpecSection
+	}
+	// Sending the disks would update them too, and fail when they are not changed. The media are inserted and ejected
+	// with their own tasks
+	vmSpecSection.DiskSection = nil
+	vmSpecSection.MediaSection = nil
+	if vmSpecSection.CpuResourceMhz != nil {
+		cpuResource := *vmSpecSection.CpuResourceMhz
+		vmSpecSection.CpuResourceMhz = &cpuResource
+	}
+	if vmSpecSection.MemoryResourceMb != nil {
+		memoryResource := *vmSpecSection.MemoryResourceMb
+		vmSpecSection.MemoryResourceMb = &memoryResource
+	}
+	for _, apply := range r.specChanges {
+		apply(&vmSpecSection)
+	}
+	return &vmSpecSection
+}
+
+// setMemory changes the memory size in MB
+func (r *vmReconfiguration) setMemory(memory int64) {
+	r.changeSpec("memory", func(vmSpecSection *types.VmSpecSection) {
+		if vmSpecSection.MemoryResourceMb == nil {
+			vmSpecSection.MemoryResourceMb = &types.MemoryResourceMb{}
+		}
+		vmSpecSection.MemoryResourceMb.Configured = memory
+	})
+}
+
+// setCpu changes the number of CPUs and of cores per socket, which must be set together
+func (r *vmReconfiguration) setCpu(cpus, cpuCores int) {
+	r.changeSpec("cpu", func(vmSpecSection *types.VmSpecSection) {
+		vmSpecSection.NumCpus = &cpus
+		vmSpecSection.NumCoresPerSocket = &cpuCores
+	})
+}
+
+func (r *vmReconfiguration) setHardwareVersion(hardwareVersion string) {
+	r.changeSpec("hardware_version", func(vmSpecSection *types.VmSpecSection) {
+		vmSpecSection.HardwareVersion = &types.HardwareVersion{Value: hardwareVersion}
+	})
+}
+
+func (r *vmReconfiguration) setOsType(osType string) {
+	r.changeSpec("os_type", func(vmSpecSection *types.VmSpecSection) {
+		vmSpecSection.OsType = osType
+	})
+}
+
+func (r *vmReconfiguration) setFirmware(firmware string) {
+	r.changeSpec("firmware", func(vmSpecSection *types.VmSpecSection) {
+		vmSpecSection.Firmware = firmware
+	})
+}
+
+func (r *vmReconfiguration) setDescription(description string) {
+	r.changes = append(r.changes, "description")
+	r.description = &description
+}
+
+// setNetworks replaces the first managedNics NICs of the VM. The NICs after them are managed outside the VM resource,
+// and are kept
+func (r *vmReconfiguration) setNetworks(networkConnectionSection types.NetworkConnectionSection, managedNics int) {
+	r.changes = append(r.changes, "network")
+	r.networkConnectionSection = &networkConnectionSection
+	r.managedNics = managedNics
+}
+
+// hasChanges returns true when there is anything to submit
+func (r *vmReconfiguration) hasChanges() bool {
+	return len(r.specChanges) > 0 || r.description != nil || r.networkConnectionSection != nil
+}
+
+// payload returns the body of the reconfigure request. Only the changed sections are included, as VCD does not update
+// the sections missing from the request. The VM must be current, see submit
+func (r *vmReconfiguration) payload() (*types.Vm, error) {
+	vm := &types.Vm{
+		Xmlns:       types.XMLNamespaceVCloud,
+		Ovf:         types.XMLNamespaceOVF,
+		Name:        r.vm.VM.Name,
+		Description: r.vm.VM.Description,
+		// Without the compute policy, VCD assigns the default sizing policy of the VDC to a VM that is not compliant
+		// with its current policy
+		ComputePolicy: r.vm.VM.ComputePolicy,
+	}
+	if r.description != nil {
+		vm.Description = *r.description
+	}
+	if len(r.specChanges) > 0 {
+		vmSpecSection := r.spec()
+		setVmSpecSectionDefaults(vmSpecSection)
+		vmSpecSection.Modified = addrOf(true)
+		vm.VmSpecSection = vmSpecSection
+	}
+	if r.networkConnectionSection != nil {
+		// The current section keeps the fields which are not managed by the resource
+		networkConnectionSection, err := r.vm.GetNetworkConnectionSection()
+		if err != nil {
+			return nil, fmt.Errorf("cannot read network section for update: %s", err)
+		}
+		err = keepExternalNics(r.networkConnectionSection, networkConnectionSection, r.managedNics)
+		if err != nil {
+			return nil, err
+		}
+		networkConnectionSection.PrimaryNetworkConnectionIndex = r.networkConnectionSection.PrimaryNetworkConnectionIndex
+		networkConnectionSection.NetworkConnection = r.networkConnectionSection.NetworkConnection
+		networkConnectionSection.Xmlns = ""
+		networkConnectionSection.Ovf = ""
+		vm.NetworkConnectionSection = networkConnectionSection
+	}
+	return vm, nil
+}
+
+// submit applies all the collected changes to the current VM with a single reconfigure task and refreshes the VM. It
+// does nothing when there are no changes
+func (r *vmReconfiguration) submit(client *govcd.Client) error {
+	if !r.hasChanges() {
+		return nil
+	}
+	// The VM can have been changed with other tasks since the changes were collected
+	err := r.vm.Refresh()
+	if err != nil {
+		return fmt.Errorf("error refreshing VM %s before reconfiguring it: %s", r.vm.VM.Name, err)
+	}
+	payload, err := r.payload()
+	if err != nil {
+		return err
+	}
+
+	if payload.VmSpecSection != nil && payload.VmSpecSection.Firmware != "" && client.APIVCDMaxVersionIs("<37.1") {
+		return fmt.Errorf("VM Firmware can only be set on VCD 10.4.1+ (API 37.1+)")
+	}
+
+	log.Printf("[DEBUG] Reconfiguring VM %s with changes: %s", r.vm.VM.Name, strings.Join(r.changes, ", "))
+	// Since 37.1 there is a Firmware field in VmSpecSection
+	task, err := client.ExecuteTaskRequestWithApiVersion(r.vm.VM.HREF+"/action/reconfigureVm", http.MethodPost,
+		types.MimeVM, "error reconfiguring VM: %s", payload, client.GetSpecificApiVersionOnCondition(">=37.1", "37.1"))
+	if err != nil {
+		return err
+	}
+	err = task.WaitTaskCompletion()
+	if err != nil {
+		return fmt.Errorf("error waiting for reconfiguration of VM %s (%s): %s", r.vm.VM.Name, strings.Join(r.changes, ", "), err)
+	}
+
+	*r = vmReconfiguration{vm: r.vm}
+	return r.vm.Refresh()
+}
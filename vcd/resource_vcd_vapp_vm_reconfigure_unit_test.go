@@ -0,0 +1,107 @@
+//go:build unit || ALL
+
+package vcd
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/vmware/go-vcloud-director/v3/govcd"
+	"github.com/vmware/go-vcloud-director/v3/types/v56"
+	"github.com/vmware/terraform-provider-vcd/v4/vcd/mockvcd"
+)
+
+// Test_vmReconfiguration checks that memory, CPU, description and network changes are sent with a single reconfigure
+// request, applied to the current VM, and that the disks are left out of it
+func Test_vmReconfiguration(t *testing.T) {
+	server := mockvcd.NewServer()
+	defer server.Close()
+	vcdClient := newMockVcdClient(t, server)
+
+	vmPath := "/api/vApp/vm-11111111-2222-3333-4444-555555555555"
+	// The VM in VCD was changed by other tasks (the firmware by the boot options) after the changes were collected,
+	// and has no memory settings
+	vmXmlFormat := `<Vm xmlns="%s" xmlns:ovf="%s" href="%s" name="web"><Description>%s</Description>` +
+		`<VmSpecSection><NumCpus>1</NumCpus><CpuResourceMhz><Configured>1000</Configured></CpuResourceMhz>` +
+		`<Firmware>efi</Firmware><DiskSection><DiskSettings><DiskId>2000</DiskId>` +
+		`</DiskSettings></DiskSection></VmSpecSection></Vm>`
+	vmXml := fmt.Sprintf(vmXmlFormat, types.XMLNamespaceVCloud, types.XMLNamespaceOVF, server.URL+vmPath, "old")
+	server.HandleFunc(http.MethodGet, vmPath, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", types.MimeVM)
+		_, _ = io.WriteString(w, vmXml)
+	})
+	server.HandleFunc(http.MethodGet, vmPath+"/networkConnectionSection/", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", types.MimeNetworkConnectionSection)
+		_, _ = fmt.Fprintf(w, `<NetworkConnectionSection xmlns="%s" xmlns:ovf="%s" href="%s"><ovf:Info>Network</ovf:Info>`+
+			`<PrimaryNetworkConnectionIndex>0</PrimaryNetworkConnectionIndex></NetworkConnectionSection>`,
+			types.XMLNamespaceVCloud, types.XMLNamespaceOVF, server.URL+vmPath+"/networkConnectionSection/")
+	})
+	var bodies []string
+	server.HandleFunc(http.MethodPost, vmPath+"/action/reconfigureVm", func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		bodies = append(bodies, string(body))
+		vmXml = fmt.Sprintf(vmXmlFormat, types.XMLNamespaceVCloud, types.XMLNamespaceOVF, server.URL+vmPath, "new")
+		taskHref := server.NewTask("urn:vcloud:vm:11111111-2222-3333-4444-555555555555", server.URL+vmPath)
+		w.Header().Set("Content-Type", types.MimeTask)
+		w.WriteHeader(http.StatusAccepted)
+		_, _ = fmt.Fprintf(w, `<Task xmlns="%s" href="%s" status="running"></Task>`, types.XMLNamespaceVCloud, taskHref)
+	})
+
+	vm := govcd.NewVM(&vcdClient.Client)
+	vm.VM = &types.Vm{
+		HREF:        server.URL + vmPath,
+		Name:        "web",
+		Description: "old",
+		VmSpecSection: &types.VmSpecSection{
+			NumCpus:          addrOf(1),
+			Firmware:         "bios",
+			MemoryResourceMb: &types.MemoryResourceMb{Configured: 512},
+			CpuResourceMhz:   &types.CpuResourceMhz{Configured: 1000},
+			DiskSection:      &types.DiskSection{DiskSettings: []*types.DiskSettings{{DiskId: "2000"}}},
+			MediaSection:     &types.MediaSection{MediaSettings: []*types.MediaSettings{{DeviceId: "3000"}}},
+		},
+	}
+
+	reconfiguration := newVmReconfiguration(vm)
+	err := reconfiguration.submit(&vcdClient.Client)
+	if err != nil || len(bodies) != 0 {
+		t.Fatalf("expected no request without changes, got %d requests and error %v", len(bodies), err)
+	}
+
+	reconfiguration.setMemory(1024)
+	reconfiguration.setCpu(4, 2)
+	reconfiguration.setDescription("new")
+	reconfiguration.setNetworks(types.NetworkConnectionSection{NetworkConnection: []*types.NetworkConnection{
+		{Network: "net1", NetworkConnectionIndex: 0, IPAddressAllocationMode: types.IPAllocationModePool, MACAddress: "00:50:56:01:02:03"},
+	}}, 1)
+	err = reconfiguration.submit(&vcdClient.Client)
+	if err != nil {
+		t.Fatalf("error reconfiguring VM: %s", err)
+	}
+	if len(bodies) != 1 {
+		t.Fatalf("expected a single reconfigure request, got %d", len(bodies))
+	}
+	for _, expected := range []string{`<Description>new</Description>`, `<NumCpus>4</NumCpus>`,
+		`<NumCoresPerSocket>2</NumCoresPerSocket>`, `<Configured>1024</Configured>`, `<Firmware>efi</Firmware>`,
+		`<NetworkConnectionSection`,
+		`<MACAddress>00:50:56:01:02:03</MACAddress>`} {
+		if !strings.Contains(bodies[0], expected) {
+			t.Errorf("expected '%s' in the reconfigure payload, got: %s", expected, bodies[0])
+		}
+	}
+	if strings.Contains(bodies[0], "DiskSection") || strings.Contains(bodies[0], "MediaSection") {
+		t.Errorf("expected no disks and media in the reconfigure payload, got: %s", bodies[0])
+	}
+	if vm.VM.Description != "new" {
+		t.Errorf("expected the VM to be refreshed after the reconfiguration")
+	}
+
+	// Submitted changes are not sent again
+	err = reconfiguration.submit(&vcdClient.Client)
+	if err != nil || len(bodies) != 1 {
+		t.Fatalf("expected no further request, got %d requests and error %v", len(bodies), err)
+	}
+}
@@ -0,0 +1,273 @@
+package vcd
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/vmware/go-vcloud-director/v3/govcd"
+	"github.com/vmware/go-vcloud-director/v3/types/v56"
+)
+
+// vmLocation identifies the vApp containing a VM
+type vmLocation struct {
+	vdcName  string
+	vappName string
+}
+
+// vmRelocationNeeded returns true when the VM must be moved to another vApp or VDC
+func vmRelocationNeeded(d *schema.ResourceData) bool {
+	return d.Id() != "" && d.HasChanges("vapp_name", "vdc")
+}
+
+// vmRelocationLocations returns the vApp recorded in state and the vApp of the configuration of a VM, after checking
+// that VCD can move the VM between them. Standalone VMs can be moved into an existing vApp, but VCD has no operation to
+// turn a VM of a vApp into a standalone VM: resourceVcdStandaloneVmCustomizeDiff replaces it instead
+func vmRelocationLocations(d *schema.ResourceData, vcdClient *VCDClient, vmType typeOfVm) (vmLocation, vmLocation, error) {
+	oldVdc, newVdc := d.GetChange("vdc")
+	oldVapp, newVapp := d.GetChange("vapp_name")
+	source := vmLocation{vdcName: vdcNameOrDefault(vcdClient, oldVdc.(string)), vappName: oldVapp.(string)}
+	target := vmLocation{vdcName: vdcNameOrDefault(vcdClient, newVdc.(string)), vappName: newVapp.(string)}
+	if vmType == standaloneVmType && !d.HasChange("vapp_name") {
+		// A standalone VM in its own vApp can only be moved together with it, which VCD does not support
+		return source, target, fmt.Errorf("moving standalone VM '%s' to VDC '%s' requires setting 'vapp_name' to an existing vApp of that VDC",
+			d.Get("name"), target.vdcName)
+	}
+	if target.vappName == "" {
+		return source, target, fmt.Errorf("VCD can't move VM '%s' out of vApp '%s' to make it a standalone VM. Move it to another vApp instead",
+			d.Get("name"), source.vappName)
+	}
+	return source, target, nil
+}
+
+// relocateVm moves a VM, keeping its disks and MAC addresses, from the source to the target vApp, which can be in
+// another VDC. The caller holds the locks of both vApps (see lockVmLocations), which must cover the rest of the update
+// too, as it finds the VM through the new vApp. relocateVm acquires the distributed locks of both vApps, and returns
+// the function that releases them, to be called when the update is over
+func relocateVm(d *schema.ResourceData, vcdClient *VCDClient, source, target vmLocation) (release func() error, err error) {
+	var releases []func() error
+	release = func() error {
+		var releaseErr error
+		for i := len(releases) - 1; i >= 0; i-- {
+			if err := releases[i](); err != nil && releaseErr == nil {
+				releaseErr = err
+			}
+		}
+		return releaseErr
+	}
+	defer func() {
+		if err != nil {
+			_ = release()
+			release = nil
+		}
+	}()
+
+	orgName := vcdClient.getOrgName(d)
+	_, sourceVdc, err := vcdClient.GetOrgAndVdc(orgName, source.vdcName)
+	if err != nil {
+		return nil, fmt.Errorf(errorRetrievingOrgAndVdc, err)
+	}
+	_, targetVdc, err := vcdClient.GetOrgAndVdc(orgName, target.vdcName)
+	if err != nil {
+		return nil, fmt.Errorf(errorRetrievingOrgAndVdc, err)
+	}
+	sourceVapp, err := sourceVdc.GetVAppByName(source.vappName, false)
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving vApp '%s' of the VM: %s", source.vappName, err)
+	}
+	targetVapp, err := targetVdc.GetVAppByName(target.vappName, false)
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving destination vApp '%s': %s", target.vappName, err)
+	}
+	vm, err := sourceVapp.GetVMById(d.Id(), false)
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving VM %s in vApp '%s': %s", d.Id(), source.vappName, err)
+	}
+
+	for _, vapp := range []*govcd.VApp{sourceVapp, targetVapp} {
+		unlockDistributed, err := vcdClient.lockDistributed(vapp.VApp.ID)
+		if err != nil {
+			return nil, err
+		}
+		releases = append(releases, unlockDistributed)
+	}
+
+	status, err := vm.GetStatus()
+	if err != nil {
+		return nil, fmt.Errorf("error getting status of VM '%s': %s", vm.VM.Name, err)
+	}
+	if status != "POWERED_OFF" {
+		if d.Get("prevent_update_power_off").(bool) {
+			return nil, fmt.Errorf("update stopped: VM needs to power off to move to vApp '%s', but `prevent_update_power_off` is `true`",
+				target.vappName)
+		}
+		log.Printf("[DEBUG] Un-deploying VM %s to move it to vApp %s. Previous state %s", vm.VM.Name, target.vappName, status)
+		task, err := vm.Undeploy()
+		if err != nil {
+			return nil, fmt.Errorf("error triggering undeploy for VM %s: %s", vm.VM.Name, err)
+		}
+		err = task.WaitTaskCompletion()
+		if err != nil {
+			return nil, fmt.Errorf("error waiting for undeploy task for VM %s: %s", vm.VM.Name, err)
+		}
+	}
+
+	params, err := vmRelocationParams(d, vm, targetVapp, targetVdc, source.vdcName != target.vdcName)
+	if err != nil {
+		return nil, err
+	}
+	log.Printf("[TRACE] Moving VM %s from vApp %s (VDC %s) to vApp %s (VDC %s)", vm.VM.Name,
+		source.vappName, source.vdcName, target.vappName, target.vdcName)
+	movedVm, err := targetVapp.AddRawVM(params)
+	if err != nil {
+		hint := ""
+		if source.vdcName != target.vdcName {
+			hint = ". Moving VMs across VDCs requires VDCs backed by the same vCenter"
+		}
+		return nil, fmt.Errorf("error moving VM '%s' to vApp '%s': %s%s", vm.VM.Name, target.vappName, err, hint)
+	}
+	if movedVm.VM.ID != d.Id() {
+		// The resource can't follow the VM to its new ID, as the ID of a resource can't change in an update
+		return nil, fmt.Errorf("VM '%s' was moved to vApp '%s', but VCD gave it the new ID %s instead of %s. Remove it from "+
+			"the state and import it again", movedVm.VM.Name, target.vappName, movedVm.VM.ID, d.Id())
+	}
+
+	err = removeEmptyStandaloneVapp(sourceVapp)
+	if err != nil {
+		return nil, err
+	}
+
+	if status == "POWERED_ON" && d.Get("power_on").(bool) {
+		task, err := movedVm.PowerOn()
+		if err != nil {
+			return nil, fmt.Errorf("error powering on VM '%s' after moving it: %s", movedVm.VM.Name, err)
+		}
+		err = task.WaitTaskCompletion()
+		if err != nil {
+			return nil, fmt.Errorf("error powering on VM '%s' after moving it: %s", movedVm.VM.Name, err)
+		}
+	}
+	return release, nil
+}
+
+// vmRelocationParams returns the recomposition of the destination vApp that moves the VM into it. The network
+// configuration follows the resource, so that the NICs keep their MAC addresses and connect to the networks of the
+// destination vApp. When moving across VDCs, the storage profile is taken from the destination VDC
+func vmRelocationParams(d *schema.ResourceData, vm *govcd.VM, targetVapp *govcd.VApp, targetVdc *govcd.Vdc, crossVdc bool) (*types.ReComposeVAppParams, error) {
+	networkConnectionSection, err := networksToConfig(d, targetVapp)
+	if err != nil {
+		return nil, fmt.Errorf("unable to setup network configuration for the move: %s", err)
+	}
+	err = keepExternalNics(&networkConnectionSection, vm.VM.NetworkConnectionSection, managedNicCount(d))
+	if err != nil {
+		return nil, err
+	}
+
+	sourcedItem := &types.SourcedCompositionItemParam{
+		SourceDelete: true,
+		Source: &types.Reference{
+			HREF: vm.VM.HREF,
+			Name: vm.VM.Name,
+		},
+		InstantiationParams: &types.InstantiationParams{
+			NetworkConnectionSection: &networkConnectionSection,
+		},
+	}
+	storageProfileName := d.Get("storage_profile").(string)
+	if !crossVdc && vm.VM.StorageProfile != nil {
+		storageProfileName = vm.VM.StorageProfile.Name
+	}
+	if storageProfileName != "" {
+		storageProfile, err := targetVdc.FindStorageProfileReference(storageProfileName)
+		if err != nil {
+			return nil, fmt.Errorf("error retrieving storage profile '%s' in the destination VDC: %s", storageProfileName, err)
+		}
+		sourcedItem.StorageProfile = &storageProfile
+	}
+
+	return &types.ReComposeVAppParams{
+		Ovf:              types.XMLNamespaceOVF,
+		Xsi:              types.XMLNamespaceXSI,
+		Xmlns:            types.XMLNamespaceVCloud,
+		Deploy:           false,
+		PowerOn:          false,
+		AllEULAsAccepted: true,
+		SourcedItem:      sourcedItem,
+	}, nil
+}
+
+// removeEmptyStandaloneVapp deletes the hidden vApp of a standalone VM moved to a regular vApp, if VCD did not remove
+// it already
+func removeEmptyStandaloneVapp(vapp *govcd.VApp) error {
+	if !vapp.VApp.IsAutoNature {
+		return nil
+	}
+	err := vapp.Refresh()
+	if govcd.ContainsNotFound(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("error refreshing vApp '%s' after moving its VM: %s", vapp.VApp.Name, err)
+	}
+	if vapp.VApp.Children != nil && len(vapp.VApp.Children.VM) > 0 {
+		return nil
+	}
+	log.Printf("[TRACE] Removing empty standalone vApp %s", vapp.VApp.Name)
+	task, err := vapp.Delete()
+	if err != nil {
+		return fmt.Errorf("error removing empty vApp '%s': %s", vapp.VApp.Name, err)
+	}
+	return task.WaitTaskCompletion()
+}
+
+// resourceVcdStandaloneVmCustomizeDiff replaces a standalone VM that was moved into a vApp when 'vapp_name' is removed
+// from its configuration, as VCD can't move a VM out of a vApp to make it standalone again. Without it, the removal
+// would go unnoticed, as 'vapp_name' is computed for standalone VMs
+func resourceVcdStandaloneVmCustomizeDiff(_ context.Context, d *schema.ResourceDiff, _ interface{}) error {
+	if d.Id() == "" || d.Get("vm_type").(string) != string(vappVmType) {
+		return nil
+	}
+	rawConfig := d.GetRawConfig()
+	if rawConfig.IsNull() || !rawConfig.IsKnown() || !rawConfig.GetAttr("vapp_name").IsNull() {
+		return nil
+	}
+	err := d.SetNewComputed("vapp_name")
+	if err != nil {
+		return err
+	}
+	return d.ForceNew("vapp_name")
+}
+
+// lockVmLocations locks the source and destination vApps of a VM move. Both composition changes, so that the vApps are
+// locked exclusively, in a fixed order to avoid deadlocks with concurrent moves. It returns the function that releases
+// the locks. The context traces the waits for the locks
+func lockVmLocations(ctx context.Context, orgName string, locations ...vmLocation) func() {
+	keys := make([]string, 0, len(locations))
+	for _, location := range locations {
+		keys = append(keys, vappLockKey(orgName, location.vdcName, location.vappName))
+	}
+	sort.Strings(keys)
+	var unlocks []func()
+	for i, key := range keys {
+		if i > 0 && key == keys[i-1] {
+			continue
+		}
+		vcdMutexKV.kvLockContext(ctx, key)
+		unlocks = append(unlocks, func() { vcdMutexKV.kvUnlock(key) })
+	}
+	return func() {
+		for i := len(unlocks) - 1; i >= 0; i-- {
+			unlocks[i]()
+		}
+	}
+}
+
+// vdcNameOrDefault returns the given VDC name, or the one of the provider when it is empty
+func vdcNameOrDefault(vcdClient *VCDClient, vdcName string) string {
+	if vdcName == "" {
+		return vcdClient.Vdc
+	}
+	return vdcName
+}
@@ -0,0 +1,128 @@
+//go:build vapp || vm || ALL || functional
+
+package vcd
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+)
+
+// TestAccVcdVAppVmRelocate moves a VM from one vApp to another, and checks that the same VM, with the same MAC
+// address, is found in the destination vApp
+func TestAccVcdVAppVmRelocate(t *testing.T) {
+	preTestChecks(t)
+
+	var params = StringMap{
+		"Org":      testConfig.VCD.Org,
+		"Vdc":      testConfig.VCD.Vdc,
+		"VappName": t.Name(),
+		"VmVapp":   "source",
+		"Tags":     "vapp vm",
+		"FuncName": t.Name(),
+	}
+	testParamsNotEmpty(t, params)
+
+	configText := templateFill(testAccVcdVAppVmRelocate, params)
+	debugPrintf("#[DEBUG] CONFIGURATION: %s\n", configText)
+
+	params["FuncName"] = t.Name() + "-update"
+	params["VmVapp"] = "target"
+	configTextUpdate := templateFill(testAccVcdVAppVmRelocate, params)
+	debugPrintf("#[DEBUG] CONFIGURATION: %s\n", configTextUpdate)
+
+	if vcdShortTest {
+		t.Skip(acceptanceTestsSkipped)
+		return
+	}
+
+	var vmId, macAddress string
+	resource.Test(t, resource.TestCase{
+		ProviderFactories: testAccProviders,
+		CheckDestroy:      testAccCheckVcdVAppDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: configText,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttrPair("vcd_vapp_vm.vm", "vapp_name", "vcd_vapp.source", "name"),
+					testAccStoreVmAttributes("vcd_vapp_vm.vm", &vmId, &macAddress),
+				),
+			},
+			{
+				Config: configTextUpdate,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttrPair("vcd_vapp_vm.vm", "vapp_name", "vcd_vapp.target", "name"),
+					resource.TestCheckResourceAttrPtr("vcd_vapp_vm.vm", "id", &vmId),
+					resource.TestCheckResourceAttrPtr("vcd_vapp_vm.vm", "network.0.mac", &macAddress),
+					resource.TestCheckResourceAttrPtr("data.vcd_vapp_vm.vm", "id", &vmId),
+				),
+			},
+			{
+				ResourceName:            "vcd_vapp_vm.vm",
+				ImportState:             true,
+				ImportStateVerify:       true,
+				ImportStateIdFunc:       importStateIdVappObject(params["VappName"].(string)+"-target", "vm", testConfig.VCD.Vdc),
+				ImportStateVerifyIgnore: []string{"power_on", "computer_name", "prevent_update_power_off", "consolidate_disks_on_create", "imported"},
+			},
+		},
+	})
+	postTestChecks(t)
+}
+
+// testAccStoreVmAttributes saves the ID and the MAC address of the first NIC of a VM, to compare them in later steps
+func testAccStoreVmAttributes(resourceName string, id, macAddress *string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		rs, ok := s.RootModule().Resources[resourceName]
+		if !ok {
+			return fmt.Errorf("resource %s not found", resourceName)
+		}
+		*id = rs.Primary.ID
+		*macAddress = rs.Primary.Attributes["network.0.mac"]
+		if *id == "" || *macAddress == "" {
+			return fmt.Errorf("resource %s has no ID or MAC address", resourceName)
+		}
+		return nil
+	}
+}
+
+const testAccVcdVAppVmRelocate = `
+resource "vcd_vapp" "source" {
+  org  = "{{.Org}}"
+  vdc  = "{{.Vdc}}"
+  name = "{{.VappName}}-source"
+}
+
+resource "vcd_vapp" "target" {
+  org  = "{{.Org}}"
+  vdc  = "{{.Vdc}}"
+  name = "{{.VappName}}-target"
+}
+
+resource "vcd_vapp_vm" "vm" {
+  org              = "{{.Org}}"
+  vdc              = "{{.Vdc}}"
+  vapp_name        = vcd_vapp.{{.VmVapp}}.name
+  name             = "vm"
+  computer_name    = "vm"
+  memory           = 512
+  cpus             = 1
+  cpu_cores        = 1
+  os_type          = "sles10_64Guest"
+  hardware_version = "vmx-11"
+  power_on         = false
+
+  network {
+    type               = "none"
+    ip_allocation_mode = "NONE"
+  }
+}
+
+data "vcd_vapp_vm" "vm" {
+  org       = "{{.Org}}"
+  vdc       = "{{.Vdc}}"
+  vapp_name = vcd_vapp_vm.vm.vapp_name
+  name      = vcd_vapp_vm.vm.name
+}
+`
@@ -0,0 +1,167 @@
+//go:build unit || ALL
+
+package vcd
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/hashicorp/go-cty/cty"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+	"github.com/vmware/go-vcloud-director/v3/govcd"
+	"github.com/vmware/go-vcloud-director/v3/types/v56"
+)
+
+// Test_vmRelocationParams checks that the recomposition deletes the VM from its source and keeps its MAC addresses
+func Test_vmRelocationParams(t *testing.T) {
+	d := schema.TestResourceDataRaw(t, vmSchemaFunc(vappVmType), map[string]interface{}{
+		"name":      "web",
+		"vapp_name": "target",
+		"network": []interface{}{
+			map[string]interface{}{
+				"type":               "none",
+				"ip_allocation_mode": types.IPAllocationModeNone,
+				"mac":                "00:50:56:01:02:03",
+				"connected":          true,
+				"is_primary":         true,
+			},
+		},
+	})
+	vm := &govcd.VM{VM: &types.Vm{HREF: "https://vcd.example.com/api/vApp/vm-1", Name: "web"}}
+	targetVapp := &govcd.VApp{VApp: &types.VApp{Name: "target"}}
+
+	params, err := vmRelocationParams(d, vm, targetVapp, nil, false)
+	if err != nil {
+		t.Fatalf("error building relocation parameters: %s", err)
+	}
+	item := params.SourcedItem
+	if !item.SourceDelete || item.Source.HREF != vm.VM.HREF {
+		t.Fatalf("expected the VM to be moved from its source, got %+v", item)
+	}
+	if item.StorageProfile != nil {
+		t.Fatalf("expected no storage profile without one set, got %+v", item.StorageProfile)
+	}
+	connections := item.InstantiationParams.NetworkConnectionSection.NetworkConnection
+	if len(connections) != 1 || connections[0].MACAddress != "00:50:56:01:02:03" {
+		t.Fatalf("expected the MAC address to be kept, got %+v", connections)
+	}
+	if connections[0].Network != types.NoneNetwork {
+		t.Fatalf("expected a disconnected NIC, got network '%s'", connections[0].Network)
+	}
+}
+
+// Test_vmRelocationParamsExternalNics checks that the NICs after the 'network' blocks are kept only when
+// 'ignore_additional_network_adapters' is set
+func Test_vmRelocationParamsExternalNics(t *testing.T) {
+	vm := &govcd.VM{VM: &types.Vm{
+		HREF: "https://vcd.example.com/api/vApp/vm-1",
+		Name: "web",
+		NetworkConnectionSection: &types.NetworkConnectionSection{
+			NetworkConnection: []*types.NetworkConnection{
+				{Network: types.NoneNetwork, NetworkConnectionIndex: 0, MACAddress: "00:50:56:01:02:03"},
+				{Network: types.NoneNetwork, NetworkConnectionIndex: 1, MACAddress: "00:50:56:01:02:04"},
+			},
+		},
+	}}
+	targetVapp := &govcd.VApp{VApp: &types.VApp{Name: "target"}}
+
+	for _, ignoreAdditional := range []bool{false, true} {
+		// The NICs managed by the resource are the ones in state
+		d := resourceVcdVAppVm().Data(&terraform.InstanceState{
+			ID: "urn:vcloud:vm:11111111-2222-3333-4444-555555555555",
+			Attributes: map[string]string{
+				"name":                               "web",
+				"vapp_name":                          "target",
+				"ignore_additional_network_adapters": fmt.Sprintf("%t", ignoreAdditional),
+				"network.#":                          "1",
+				"network.0.type":                     "none",
+				"network.0.ip_allocation_mode":       types.IPAllocationModeNone,
+				"network.0.mac":                      "00:50:56:01:02:03",
+			},
+		})
+		params, err := vmRelocationParams(d, vm, targetVapp, nil, false)
+		if err != nil {
+			t.Fatalf("error building relocation parameters: %s", err)
+		}
+		expectedNics := 1
+		if ignoreAdditional {
+			expectedNics = 2
+		}
+		connections := params.SourcedItem.InstantiationParams.NetworkConnectionSection.NetworkConnection
+		if len(connections) != expectedNics {
+			t.Fatalf("expected %d NICs with ignore_additional_network_adapters=%t, got %+v", expectedNics, ignoreAdditional, connections)
+		}
+	}
+}
+
+// Test_lockVmLocations checks that a move within the same vApp locks it once and that the locks are released
+func Test_lockVmLocations(t *testing.T) {
+	source := vmLocation{vdcName: "vdc1", vappName: "web"}
+	target := vmLocation{vdcName: "vdc2", vappName: "app"}
+
+	unlock := lockVmLocations(context.Background(), "org", source, source)
+	unlock()
+
+	unlock = lockVmLocations(context.Background(), "org", target, source)
+	unlock()
+	// Locking in the opposite order after the release must not block
+	unlock = lockVmLocations(context.Background(), "org", source, target)
+	unlock()
+}
+
+// Test_resourceVcdStandaloneVmCustomizeDiff checks that removing 'vapp_name' replaces a standalone VM that was moved
+// into a vApp, and nothing else
+func Test_resourceVcdStandaloneVmCustomizeDiff(t *testing.T) {
+	tests := []struct {
+		name        string
+		vmType      typeOfVm
+		vappName    string
+		wantReplace bool
+	}{
+		{name: "RemovedFromVappVm", vmType: vappVmType, wantReplace: true},
+		{name: "KeptInVapp", vmType: vappVmType, vappName: "web"},
+		{name: "Standalone", vmType: standaloneVmType},
+	}
+	res := resourceVcdStandaloneVm()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := map[string]interface{}{"name": "vm1"}
+			if tt.vappName != "" {
+				config["vapp_name"] = tt.vappName
+			}
+			state := &terraform.InstanceState{
+				ID: "urn:vcloud:vm:11111111-2222-3333-4444-555555555555",
+				Attributes: map[string]string{
+					"id":        "urn:vcloud:vm:11111111-2222-3333-4444-555555555555",
+					"name":      "vm1",
+					"vapp_name": "web",
+					"vm_type":   string(tt.vmType),
+					// Default of a ForceNew field
+					"consolidate_disks_on_create": "false",
+				},
+				RawConfig: vmRawConfig(res, config),
+			}
+			diff, err := res.Diff(context.Background(), state, terraform.NewResourceConfigRaw(config), nil)
+			if err != nil {
+				t.Fatalf("error computing the diff: %s", err)
+			}
+			if replace := diff != nil && diff.RequiresNew(); replace != tt.wantReplace {
+				t.Errorf("expected replacement: %t, got: %t", tt.wantReplace, replace)
+			}
+		})
+	}
+}
+
+// vmRawConfig returns the raw configuration of a VM resource with the given string attributes, and all the others null
+func vmRawConfig(res *schema.Resource, config map[string]interface{}) cty.Value {
+	values := make(map[string]cty.Value)
+	for name, attributeType := range res.CoreConfigSchema().ImpliedType().AttributeTypes() {
+		values[name] = cty.NullVal(attributeType)
+		if value, ok := config[name]; ok {
+			values[name] = cty.StringVal(value.(string))
+		}
+	}
+	return cty.ObjectVal(values)
+}
@@ -0,0 +1,132 @@
+//go:build vapp || vm || ALL || functional
+
+package vcd
+
+import (
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+)
+
+// TestAccVcdVAppVmStartup sets the startup order and actions of two VMs of the same vApp, swaps them on update and
+// powers the vApp on and off following that order
+func TestAccVcdVAppVmStartup(t *testing.T) {
+	preTestChecks(t)
+
+	var params = StringMap{
+		"Org":         testConfig.VCD.Org,
+		"Vdc":         testConfig.VCD.Vdc,
+		"VappName":    t.Name(),
+		"DbOrder":     "1",
+		"WebOrder":    "2",
+		"StopAction":  "powerOff",
+		"VappPowerOn": "false",
+		"Tags":        "vapp vm",
+		"FuncName":    t.Name(),
+	}
+	testParamsNotEmpty(t, params)
+
+	configText := templateFill(testAccVcdVAppVmStartup, params)
+	debugPrintf("#[DEBUG] CONFIGURATION: %s\n", configText)
+
+	params["FuncName"] = t.Name() + "-update"
+	params["DbOrder"] = "2"
+	params["WebOrder"] = "1"
+	params["VappPowerOn"] = "true"
+	configTextUpdate := templateFill(testAccVcdVAppVmStartup, params)
+	debugPrintf("#[DEBUG] CONFIGURATION: %s\n", configTextUpdate)
+
+	if vcdShortTest {
+		t.Skip(acceptanceTestsSkipped)
+		return
+	}
+
+	resource.Test(t, resource.TestCase{
+		ProviderFactories: testAccProviders,
+		CheckDestroy:      testAccCheckVcdVAppDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: configText,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("vcd_vapp_vm.db", "startup.0.order", "1"),
+					resource.TestCheckResourceAttr("vcd_vapp_vm.db", "startup.0.start_delay", "10"),
+					resource.TestCheckResourceAttr("vcd_vapp_vm.db", "startup.0.stop_action", "powerOff"),
+					resource.TestCheckResourceAttr("vcd_vapp_vm.web", "startup.0.order", "2"),
+					resource.TestCheckResourceAttr("vcd_vapp_vm.web", "startup.0.start_action", "powerOn"),
+					resource.TestCheckResourceAttr("data.vcd_vapp_vm.web", "startup.0.order", "2"),
+				),
+			},
+			{
+				Config: configTextUpdate,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("vcd_vapp_vm.db", "startup.0.order", "2"),
+					resource.TestCheckResourceAttr("vcd_vapp_vm.web", "startup.0.order", "1"),
+					resource.TestCheckResourceAttr("vcd_vapp.vapp", "status_text", "POWERED_ON"),
+				),
+			},
+			{
+				ResourceName:            "vcd_vapp_vm.db",
+				ImportState:             true,
+				ImportStateVerify:       true,
+				ImportStateIdFunc:       importStateIdVappObject(params["VappName"].(string), "db", testConfig.VCD.Vdc),
+				ImportStateVerifyIgnore: []string{"power_on", "computer_name", "prevent_update_power_off", "consolidate_disks_on_create", "imported"},
+			},
+		},
+	})
+	postTestChecks(t)
+}
+
+const testAccVcdVAppVmStartup = `
+resource "vcd_vapp" "vapp" {
+  org      = "{{.Org}}"
+  vdc      = "{{.Vdc}}"
+  name     = "{{.VappName}}"
+  power_on = {{.VappPowerOn}}
+}
+
+resource "vcd_vapp_vm" "db" {
+  org              = "{{.Org}}"
+  vdc              = "{{.Vdc}}"
+  vapp_name        = vcd_vapp.vapp.name
+  name             = "db"
+  computer_name    = "db"
+  memory           = 512
+  cpus             = 1
+  cpu_cores        = 1
+  os_type          = "sles10_64Guest"
+  hardware_version = "vmx-11"
+  power_on         = false
+
+  startup {
+    order       = {{.DbOrder}}
+    start_delay = 10
+    stop_action = "{{.StopAction}}"
+  }
+}
+
+resource "vcd_vapp_vm" "web" {
+  org              = "{{.Org}}"
+  vdc              = "{{.Vdc}}"
+  vapp_name        = vcd_vapp.vapp.name
+  name             = "web"
+  computer_name    = "web"
+  memory           = 512
+  cpus             = 1
+  cpu_cores        = 1
+  os_type          = "sles10_64Guest"
+  hardware_version = "vmx-11"
+  power_on         = false
+
+  startup {
+    order        = {{.WebOrder}}
+    start_action = "powerOn"
+  }
+}
+
+data "vcd_vapp_vm" "web" {
+  org       = "{{.Org}}"
+  vdc       = "{{.Vdc}}"
+  vapp_name = vcd_vapp.vapp.name
+  name      = vcd_vapp_vm.web.name
+}
+`
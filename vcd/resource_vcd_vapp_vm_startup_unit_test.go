@@ -0,0 +1,90 @@
+//go:build unit || ALL
+
+package vcd
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/vmware/go-vcloud-director/v3/govcd"
+	"github.com/vmware/go-vcloud-director/v3/types/v56"
+	"github.com/vmware/terraform-provider-vcd/v4/vcd/mockvcd"
+)
+
+// Test_vmStartup checks that the startup block of a VM changes only its own item of the vApp startup section, and
+// that it is read back from the section
+func Test_vmStartup(t *testing.T) {
+	server := mockvcd.NewServer()
+	defer server.Close()
+	vcdClient := newMockVcdClient(t, server)
+
+	vappPath := "/api/vApp/vapp-11111111-2222-3333-4444-555555555555"
+	startupSection := `<ovf:StartupSection xmlns:ovf="` + types.XMLNamespaceOVF + `" xmlns:vcloud="` + types.XMLNamespaceVCloud + `">
+  <ovf:Info>VApp startup section</ovf:Info>
+  <ovf:Item ovf:id="db" ovf:order="0" ovf:startAction="powerOn" ovf:startDelay="0" ovf:stopAction="powerOff" ovf:stopDelay="0"/>
+  <ovf:Item ovf:id="web" ovf:order="0" ovf:startAction="powerOn" ovf:startDelay="0" ovf:stopAction="powerOff" ovf:stopDelay="0"/>
+</ovf:StartupSection>`
+	server.HandleFunc(http.MethodGet, vappPath+"/startupSection/", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/vnd.vmware.vcloud.startupSection+xml")
+		_, _ = io.WriteString(w, startupSection)
+	})
+	server.HandleFunc(http.MethodPut, vappPath+"/startupSection/", func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		startupSection = string(body)
+		taskHref := server.NewTask("urn:vcloud:vapp:11111111-2222-3333-4444-555555555555", server.URL+vappPath)
+		w.Header().Set("Content-Type", types.MimeTask)
+		w.WriteHeader(http.StatusAccepted)
+		_, _ = fmt.Fprintf(w, `<Task xmlns="%s" href="%s" status="running"></Task>`, types.XMLNamespaceVCloud, taskHref)
+	})
+
+	vapp := &govcd.VApp{VApp: &types.VApp{Name: "app", HREF: server.URL + vappPath}}
+	d := schema.TestResourceDataRaw(t, vmSchemaFunc(vappVmType), map[string]interface{}{
+		"vapp_name": "app",
+		"name":      "web",
+		"startup": []interface{}{map[string]interface{}{
+			"order":          2,
+			"start_action":   "powerOn",
+			"start_delay":    30,
+			"wait_for_guest": true,
+			"stop_action":    "guestShutdown",
+			"stop_delay":     10,
+		}},
+	})
+	err := updateVmStartup(d, vcdClient, vapp, "web")
+	if err != nil {
+		t.Fatalf("error updating startup section: %s", err)
+	}
+
+	section, err := getVappStartupSection(&vcdClient.Client, vapp.VApp.HREF)
+	if err != nil {
+		t.Fatalf("error reading startup section: %s", err)
+	}
+	if len(section.Item) != 2 {
+		t.Fatalf("expected 2 items in the startup section, got %d:\n%s", len(section.Item), startupSection)
+	}
+	expected := map[string]vappStartupItem{
+		"db":  {ID: "db", Order: 0, StartAction: "powerOn", StopAction: "powerOff"},
+		"web": {ID: "web", Order: 2, StartAction: "powerOn", StartDelay: 30, WaitingForGuest: true, StopAction: "guestShutdown", StopDelay: 10},
+	}
+	for _, item := range section.Item {
+		if *item != expected[item.ID] {
+			t.Errorf("expected item %+v, got %+v", expected[item.ID], *item)
+		}
+	}
+	if !strings.Contains(startupSection, `<ovf:Item ovf:id="web" ovf:order="2"`) {
+		t.Errorf("expected 'ovf' prefixes in the update payload, got:\n%s", startupSection)
+	}
+
+	readData := schema.TestResourceDataRaw(t, vmSchemaFunc(vappVmType), map[string]interface{}{"vapp_name": "app", "name": "db"})
+	err = setVmStartupData(readData, vcdClient, vapp, "db")
+	if err != nil {
+		t.Fatalf("error reading startup block: %s", err)
+	}
+	if readData.Get("startup.0.stop_action").(string) != "powerOff" || readData.Get("startup.0.order").(int) != 0 {
+		t.Errorf("unexpected startup block for 'db': %v", readData.Get("startup"))
+	}
+}
@@ -9,9 +9,11 @@ package vcd
 // More information in https://github.com/hashicorp/terraform-plugin-sdk/issues/817
 import (
 	"bytes"
+	"encoding/xml"
 	"fmt"
 	"log"
 	"net"
+	"net/http"
 	"sort"
 	"strconv"
 	"strings"
@@ -229,6 +231,25 @@ func updateAdvancedComputeSettings(d *schema.ResourceData, vm *govcd.VM) error {
 	// update and fail
 	vmSpecSection.DiskSection = nil
 
+	if setAdvancedComputeSettings(d, vmSpecSection) {
+		err := updateVmSpecSection(vmSpecSection, vm, description)
+		if err != nil {
+			return fmt.Errorf("error updating advanced compute settings: %s", err)
+		}
+	}
+
+	// Refresh VM to ensure that latest VM structure is used in other function calls
+	err := vm.Refresh()
+	if err != nil {
+		return fmt.Errorf("error refreshing VM after updating advanced compute settings: %s", err)
+	}
+
+	return nil
+}
+
+// setAdvancedComputeSettings copies the CPU and memory shares, limits and reservations of the resource into the given
+// VM spec section. It returns true if any of them is set
+func setAdvancedComputeSettings(d *schema.ResourceData, vmSpecSection *types.VmSpecSection) bool {
 	updateNeeded := false
 
 	if memorySharesLevel, ok := d.GetOk("memory_priority"); ok {
@@ -271,20 +292,7 @@ func updateAdvancedComputeSettings(d *schema.ResourceData, vm *govcd.VM) error {
 		updateNeeded = true
 	}
 
-	if updateNeeded {
-		err := updateVmSpecSection(vmSpecSection, vm, description)
-		if err != nil {
-			return fmt.Errorf("error updating advanced compute settings: %s", err)
-		}
-	}
-
-	// Refresh VM to ensure that latest VM structure is used in other function calls
-	err := vm.Refresh()
-	if err != nil {
-		return fmt.Errorf("error refreshing VM after updating advanced compute settings: %s", err)
-	}
-
-	return nil
+	return updateNeeded
 }
 
 // isItVappNetwork checks if it is a vApp network (not vApp Org Network)
@@ -391,11 +399,17 @@ func addRemoveExtraConfiguration(d *schema.ResourceData, vm *govcd.VM) error {
 }
 
 func addRemoveGuestProperties(d *schema.ResourceData, vm *govcd.VM) error {
-	if d.HasChange("guest_properties") {
+	// The 'cloud_init' block with 'ovf_properties' delivery sets guest properties too, so that both fields are applied
+	// together
+	if d.HasChange("guest_properties") || cloudInitOvfPropertiesChanged(d) {
 		vmProperties, err := getGuestProperties(d)
 		if err != nil {
 			return fmt.Errorf("unable to convert guest properties to data structure")
 		}
+		err = addCloudInitOvfProperties(d, vmProperties)
+		if err != nil {
+			return err
+		}
 
 		log.Printf("[TRACE] Updating VM guest properties")
 		_, err = vm.SetProductSectionList(vmProperties)
@@ -425,7 +439,17 @@ func isPrimaryNicRemoved(d *schema.ResourceData) bool {
 }
 
 func updateVmSpecSection(vmSpecSection *types.VmSpecSection, vm *govcd.VM, description string) error {
-	// add missing values if not inherited from template, otherwise API throws error if some value is nil
+	setVmSpecSectionDefaults(vmSpecSection)
+	_, err := vm.UpdateVmSpecSection(vmSpecSection, description)
+	if err != nil {
+		return fmt.Errorf("error updating Vm Spec Section: %s", err)
+	}
+	return nil
+}
+
+// setVmSpecSectionDefaults adds the compute resource values that are missing when not inherited from the template, as
+// the API throws an error if any of them is nil
+func setVmSpecSectionDefaults(vmSpecSection *types.VmSpecSection) {
 	if vmSpecSection.MemoryResourceMb.Reservation == nil {
 		vmSpecSection.MemoryResourceMb.Reservation = addrOf(int64(0))
 	}
@@ -444,11 +468,6 @@ func updateVmSpecSection(vmSpecSection *types.VmSpecSection, vm *govcd.VM, descr
 	if vmSpecSection.CpuResourceMhz.SharesLevel == "" {
 		vmSpecSection.CpuResourceMhz.SharesLevel = "NORMAL"
 	}
-	_, err := vm.UpdateVmSpecSection(vmSpecSection, description)
-	if err != nil {
-		return fmt.Errorf("error updating Vm Spec Section: %s", err)
-	}
-	return nil
 }
 
 // getVmFromResource retrieves a VM by using HCL schema configuration
@@ -472,8 +491,7 @@ func getVmFromResource(d *schema.ResourceData, meta interface{}, vmType typeOfVm
 	if err != nil {
 		additionalMessage := ""
 		if vmType == standaloneVmType {
-			additionalMessage = fmt.Sprintf("\nAdding a vApp name to a standalone VM is not allowed." +
-				"Please use 'vcd_vapp_vm' resource to specify vApp")
+			additionalMessage = "\nA standalone VM can only be moved to an existing vApp"
 			dSet(d, "vapp_name", "")
 		}
 
@@ -573,7 +591,9 @@ func attachDetachIndependentDisks(d *schema.ResourceData, vm govcd.VM, vdc *govc
 	return nil
 }
 
-func updateStateOfAttachedIndependentDisks(d *schema.ResourceData, vm govcd.VM) error {
+// updateStateOfAttachedIndependentDisks sets in `disk` the independent disks attached to the VM. When managedDisks is
+// not nil, only the disks with a name in it are set, as the other ones are attached outside the VM resource
+func updateStateOfAttachedIndependentDisks(d *schema.ResourceData, vm govcd.VM, managedDisks map[string]bool) error {
 
 	existingDisks := getVmIndependentDisks(vm)
 	transformed := schema.NewSet(resourceVcdVmIndependentDiskHash, []interface{}{})
@@ -583,6 +603,9 @@ func updateStateOfAttachedIndependentDisks(d *schema.ResourceData, vm govcd.VM)
 		if err != nil {
 			return fmt.Errorf("did not find disk `%s`: %s", existingDiskHref, err)
 		}
+		if managedDisks != nil && !managedDisks[diskSettings.Disk.Name] {
+			continue
+		}
 		newValues := map[string]interface{}{
 			"name":        diskSettings.Disk.Name,
 			"bus_number":  strconv.Itoa(diskSettings.BusNumber),
@@ -913,6 +936,53 @@ func networksToConfig(d *schema.ResourceData, vapp *govcd.VApp) (types.NetworkCo
 	return networkConnectionSection, nil
 }
 
+// managedNicCount returns the number of NICs managed by the VM resource before the current change. When
+// 'ignore_additional_network_adapters' is set, the NICs with a higher index are managed outside the resource, with
+// vcd_vm_network_adapter. Otherwise, the resource manages all the NICs
+func managedNicCount(d *schema.ResourceData) int {
+	if !d.Get("ignore_additional_network_adapters").(bool) {
+		return vmNicMaxIndex + 1
+	}
+	oldNetworks, _ := d.GetChange("network")
+	return len(oldNetworks.([]interface{}))
+}
+
+// managedIndependentDisks returns the names of the independent disks declared in `disk`, before and after the current
+// change. The other disks attached to the VM are managed outside the resource, with vcd_vm_independent_disk_attachment
+func managedIndependentDisks(d *schema.ResourceData) map[string]bool {
+	oldDisks, newDisks := d.GetChange("disk")
+	managedDisks := make(map[string]bool)
+	for _, disks := range []interface{}{oldDisks, newDisks} {
+		for _, disk := range disks.(*schema.Set).List() {
+			managedDisks[disk.(map[string]interface{})["name"].(string)] = true
+		}
+	}
+	return managedDisks
+}
+
+// keepExternalNics adds to the network configuration of a VM the current NICs with an index from managedNics on, as
+// they are not managed by the VM resource. It fails when one of them uses an index needed by the configuration
+func keepExternalNics(networkConnectionSection, current *types.NetworkConnectionSection, managedNics int) error {
+	if current == nil {
+		return nil
+	}
+	usedIndexes := make(map[int]bool)
+	for _, nic := range networkConnectionSection.NetworkConnection {
+		usedIndexes[nic.NetworkConnectionIndex] = true
+	}
+	for _, nic := range current.NetworkConnection {
+		if nic.NetworkConnectionIndex < managedNics {
+			continue
+		}
+		if usedIndexes[nic.NetworkConnectionIndex] {
+			return fmt.Errorf("NIC index %d is used by the NIC with MAC address %s, which is not managed by this resource",
+				nic.NetworkConnectionIndex, nic.MACAddress)
+		}
+		networkConnectionSection.NetworkConnection = append(networkConnectionSection.NetworkConnection, nic)
+	}
+	return nil
+}
+
 // isItVappOrgNetwork checks if it is a vApp Org network (not vApp Network)
 func isItVappOrgNetwork(vAppNetworkName string, vapp govcd.VApp) (bool, error) {
 	vAppNetworkConfig, err := vapp.GetNetworkConfig()
@@ -1183,8 +1253,9 @@ func handleExposeHardwareVirtualization(d *schema.ResourceData, newVm *govcd.VM)
 	return nil
 }
 
-// readNetworks returns network configuration for saving into statefile
-func readNetworks(d *schema.ResourceData, vm govcd.VM, vapp govcd.VApp, vdc *govcd.Vdc) ([]map[string]interface{}, error) {
+// readNetworks returns the NICs of the VM, sorted by index. When managedNics is not negative, only the NICs with a
+// lower index are returned, as the ones after them are managed outside the VM resource (e.g. by vcd_vm_network_adapter)
+func readNetworks(d *schema.ResourceData, vm govcd.VM, vapp govcd.VApp, vdc *govcd.Vdc, managedNics int) ([]map[string]interface{}, error) {
 	// Determine type for all networks in vApp
 	vAppNetworkConfig, err := vapp.GetNetworkConfig()
 	if err != nil {
@@ -1212,6 +1283,9 @@ func readNetworks(d *schema.ResourceData, vm govcd.VM, vapp govcd.VApp, vdc *gov
 	})
 
 	for _, vmNet := range vm.VM.NetworkConnectionSection.NetworkConnection {
+		if managedNics >= 0 && vmNet.NetworkConnectionIndex >= managedNics {
+			continue
+		}
 		singleNIC := make(map[string]interface{})
 		singleNIC["ip_allocation_mode"] = vmNet.IPAddressAllocationMode
 		singleNIC["secondary_ip_allocation_mode"] = vmNet.SecondaryIpAddressAllocationMode
@@ -1250,7 +1324,12 @@ func readNetworks(d *schema.ResourceData, vm govcd.VM, vapp govcd.VApp, vdc *gov
 		maxDhcpWaitSecondsInt := maxDhcpWaitSeconds.(int)
 
 		// look up NIC indexes which have DHCP enabled
-		dhcpNicIndexes := getVmNicIndexesWithDhcpEnabled(vm.VM.NetworkConnectionSection)
+		var dhcpNicIndexes []int
+		for _, nicIndex := range getVmNicIndexesWithDhcpEnabled(vm.VM.NetworkConnectionSection) {
+			if nicIndex < len(nets) {
+				dhcpNicIndexes = append(dhcpNicIndexes, nicIndex)
+			}
+		}
 		log.Printf("[DEBUG] [VM read] [DHCP IP Lookup] '%s' DHCP is used on NICs %v with wait time '%d seconds'",
 			vm.VM.Name, dhcpNicIndexes, maxDhcpWaitSecondsInt)
 		if len(dhcpNicIndexes) == 0 {
@@ -1345,3 +1424,158 @@ func createOrUpdateVmSecurityTags(d *schema.ResourceData, vm *govcd.VM) error {
 
 	return nil
 }
+
+// vappStartupSection is the startup and shutdown configuration of the VMs of a vApp (OVF StartupSection), as
+// returned by VCD
+type vappStartupSection struct {
+	XMLName xml.Name           `xml:"http://schemas.dmtf.org/ovf/envelope/1 StartupSection"`
+	Info    string             `xml:"http://schemas.dmtf.org/ovf/envelope/1 Info"`
+	Item    []*vappStartupItem `xml:"http://schemas.dmtf.org/ovf/envelope/1 Item,omitempty"`
+}
+
+// vappStartupItem is the startup and shutdown configuration of a VM. The ID is the name of the VM
+type vappStartupItem struct {
+	ID              string `xml:"http://schemas.dmtf.org/ovf/envelope/1 id,attr"`
+	Order           int    `xml:"http://schemas.dmtf.org/ovf/envelope/1 order,attr"`
+	StartAction     string `xml:"http://schemas.dmtf.org/ovf/envelope/1 startAction,attr,omitempty"`
+	StartDelay      int    `xml:"http://schemas.dmtf.org/ovf/envelope/1 startDelay,attr"`
+	WaitingForGuest bool   `xml:"http://schemas.dmtf.org/ovf/envelope/1 waitingForGuest,attr"`
+	StopAction      string `xml:"http://schemas.dmtf.org/ovf/envelope/1 stopAction,attr,omitempty"`
+	StopDelay       int    `xml:"http://schemas.dmtf.org/ovf/envelope/1 stopDelay,attr"`
+}
+
+// vappStartupSectionUpdate is the payload that updates a vApp startup section. It mirrors vappStartupSection with
+// explicit 'ovf' prefixes, as encoding/xml would otherwise generate its own prefix for the namespaced attributes
+type vappStartupSectionUpdate struct {
+	XMLName xml.Name                `xml:"ovf:StartupSection"`
+	Ovf     string                  `xml:"xmlns:ovf,attr"`
+	Info    string                  `xml:"ovf:Info"`
+	Item    []vappStartupItemUpdate `xml:"ovf:Item,omitempty"`
+}
+
+type vappStartupItemUpdate struct {
+	ID              string `xml:"ovf:id,attr"`
+	Order           int    `xml:"ovf:order,attr"`
+	StartAction     string `xml:"ovf:startAction,attr,omitempty"`
+	StartDelay      int    `xml:"ovf:startDelay,attr"`
+	WaitingForGuest bool   `xml:"ovf:waitingForGuest,attr"`
+	StopAction      string `xml:"ovf:stopAction,attr,omitempty"`
+	StopDelay       int    `xml:"ovf:stopDelay,attr"`
+}
+
+// getVappStartupSection retrieves the startup section of a vApp
+func getVappStartupSection(client *govcd.Client, vappHref string) (*vappStartupSection, error) {
+	startupSection := &vappStartupSection{}
+	_, err := client.ExecuteRequest(vappHref+"/startupSection/", http.MethodGet, "",
+		"error retrieving vApp startup section: %s", nil, startupSection)
+	if err != nil {
+		return nil, err
+	}
+	return startupSection, nil
+}
+
+// updateVmStartup sets the startup and shutdown configuration of the VM from the 'startup' block. As the startup
+// section is shared by all the VMs of the vApp, it is changed under a dedicated lock, so that VMs of the same vApp
+// that are updated concurrently don't overwrite each other's configuration
+func updateVmStartup(d *schema.ResourceData, vcdClient *VCDClient, vapp *govcd.VApp, vmName string) error {
+	startupBlock := d.Get("startup").([]interface{})
+	if len(startupBlock) == 0 || startupBlock[0] == nil {
+		return nil
+	}
+	startup := startupBlock[0].(map[string]interface{})
+
+	unlock := vcdClient.lockVappStartupSection(vcdClient.getOrgName(d), vcdClient.getVdcName(d), vapp.VApp.Name)
+	defer unlock()
+
+	startupSection, err := getVappStartupSection(&vcdClient.Client, vapp.VApp.HREF)
+	if err != nil {
+		return err
+	}
+	var item *vappStartupItem
+	for _, existingItem := range startupSection.Item {
+		if existingItem.ID == vmName {
+			item = existingItem
+			break
+		}
+	}
+	if item == nil {
+		item = &vappStartupItem{ID: vmName}
+		startupSection.Item = append(startupSection.Item, item)
+	}
+	item.Order = startup["order"].(int)
+	item.StartAction = startup["start_action"].(string)
+	item.StartDelay = startup["start_delay"].(int)
+	item.WaitingForGuest = startup["wait_for_guest"].(bool)
+	item.StopAction = startup["stop_action"].(string)
+	item.StopDelay = startup["stop_delay"].(int)
+
+	update := &vappStartupSectionUpdate{Ovf: types.XMLNamespaceOVF, Info: startupSection.Info}
+	for _, existingItem := range startupSection.Item {
+		update.Item = append(update.Item, vappStartupItemUpdate(*existingItem))
+	}
+	task, err := vcdClient.Client.ExecuteTaskRequest(vapp.VApp.HREF+"/startupSection/", http.MethodPut,
+		"application/vnd.vmware.vcloud.startupSection+xml", "error updating vApp startup section: %s", update)
+	if err != nil {
+		return err
+	}
+	return task.WaitTaskCompletion()
+}
+
+// setVmStartupData sets the 'startup' block with the configuration of the VM in the startup section of its vApp
+func setVmStartupData(d *schema.ResourceData, vcdClient *VCDClient, vapp *govcd.VApp, vmName string) error {
+	startupSection, err := getVappStartupSection(&vcdClient.Client, vapp.VApp.HREF)
+	if err != nil {
+		return err
+	}
+	var startupBlock []interface{}
+	for _, item := range startupSection.Item {
+		if item.ID != vmName {
+			continue
+		}
+		startupBlock = append(startupBlock, map[string]interface{}{
+			"order":          item.Order,
+			"start_action":   item.StartAction,
+			"start_delay":    item.StartDelay,
+			"wait_for_guest": item.WaitingForGuest,
+			"stop_action":    item.StopAction,
+			"stop_delay":     item.StopDelay,
+		})
+		break
+	}
+	return d.Set("startup", startupBlock)
+}
+
+// lockVmById retrieves the VM set in `vm_id` and locks its vApp. It returns the function that releases the locks, which
+// fails if the distributed lock was lost meanwhile
+func lockVmById(d *schema.ResourceData, vcdClient *VCDClient) (*govcd.VM, func() error, error) {
+	_, vdc, err := vcdClient.GetOrgAndVdcFromResource(d)
+	if err != nil {
+		return nil, nil, fmt.Errorf(errorRetrievingOrgAndVdc, err)
+	}
+	vm, err := vdc.QueryVmById(d.Get("vm_id").(string))
+	if err != nil {
+		return nil, nil, err
+	}
+	vapp, err := vm.GetParentVApp()
+	if err != nil {
+		return nil, nil, fmt.Errorf("error retrieving the vApp of VM '%s': %s", vm.VM.Name, err)
+	}
+
+	unlock := vcdClient.lockVappWithName(vcdClient.getOrgName(d), vcdClient.getVdcName(d), vapp.VApp.Name)
+	unlockDistributed, err := vcdClient.lockDistributed(vapp.VApp.ID)
+	if err != nil {
+		unlock()
+		return nil, nil, err
+	}
+	// The VM is retrieved again, as it may have changed while waiting for the locks
+	err = vm.Refresh()
+	if err != nil {
+		_ = unlockDistributed()
+		unlock()
+		return nil, nil, fmt.Errorf("error refreshing VM '%s': %s", vm.VM.Name, err)
+	}
+	return vm, func() error {
+		defer unlock()
+		return unlockDistributed()
+	}, nil
+}
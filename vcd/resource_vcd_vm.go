@@ -19,8 +19,9 @@ func resourceVcdStandaloneVm() *schema.Resource {
 		Importer: &schema.ResourceImporter{
 			StateContext: resourceVcdVappVmImport,
 		},
-		Schema:      vmSchemaFunc(standaloneVmType),
-		Description: "Standalone VM",
+		Schema:        vmSchemaFunc(standaloneVmType),
+		CustomizeDiff: resourceVcdStandaloneVmCustomizeDiff,
+		Description:   "Standalone VM",
 	}
 }
 
@@ -31,7 +32,7 @@ func resourceVcdStandaloneVmCreate(_ context.Context, d *schema.ResourceData, me
 		return diag.Errorf("vApp name must not be set for a standalone VM (resource `vcd_vm`)")
 	}
 
-	diags := genericResourceVmCreate(d, meta, standaloneVmType)
+	diags := genericResourceVmCreate(d, meta, standaloneVmType, nil)
 	// We need to check if there were errors, as genericResourceVmCreate can also return a warning
 	if diags.HasError() {
 		return diags
@@ -0,0 +1,243 @@
+package vcd
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+	"github.com/vmware/go-vcloud-director/v3/govcd"
+	"github.com/vmware/go-vcloud-director/v3/types/v56"
+)
+
+func resourceVcdVmIndependentDiskAttachment() *schema.Resource {
+	return &schema.Resource{
+		CreateContext: resourceVcdVmIndependentDiskAttachmentCreate,
+		ReadContext:   resourceVcdVmIndependentDiskAttachmentRead,
+		DeleteContext: resourceVcdVmIndependentDiskAttachmentDelete,
+		Importer: &schema.ResourceImporter{
+			StateContext: resourceVcdVmIndependentDiskAttachmentImport,
+		},
+		Schema: map[string]*schema.Schema{
+			"org": {
+				Type:     schema.TypeString,
+				Optional: true,
+				ForceNew: true,
+				Description: "The name of organization to use, optional if defined at provider " +
+					"level. Useful when connected as sysadmin working across different organizations",
+			},
+			"vdc": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				ForceNew:    true,
+				Description: "The name of VDC to use, optional if defined at provider level",
+			},
+			"vm_id": {
+				Type:        schema.TypeString,
+				Required:    true,
+				ForceNew:    true,
+				Description: "The ID of the VM to which the disk is attached",
+			},
+			"disk_id": {
+				Type:        schema.TypeString,
+				Required:    true,
+				ForceNew:    true,
+				Description: "The ID of the independent disk to attach",
+			},
+			"bus_number": {
+				Type:         schema.TypeInt,
+				Optional:     true,
+				Computed:     true,
+				ForceNew:     true,
+				ValidateFunc: validation.IntAtLeast(0),
+				Description:  "Bus number on which to place the disk controller. VCD chooses it when not set",
+			},
+			"unit_number": {
+				Type:         schema.TypeInt,
+				Optional:     true,
+				Computed:     true,
+				ForceNew:     true,
+				ValidateFunc: validation.IntAtLeast(0),
+				Description:  "Unit number (slot) on the bus specified by bus_number. VCD chooses it when not set",
+			},
+			"bus_type": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "The bus type of the disk controller, defined when creating the independent disk",
+			},
+			"size_in_mb": {
+				Type:        schema.TypeInt,
+				Computed:    true,
+				Description: "The size of the disk in MB",
+			},
+		},
+	}
+}
+
+func resourceVcdVmIndependentDiskAttachmentCreate(ctx context.Context, d *schema.ResourceData, meta interface{}) (diags diag.Diagnostics) {
+	vcdClient := meta.(*VCDClient)
+	vm, unlock, err := lockVmById(d, vcdClient)
+	if err != nil {
+		return diag.Errorf("[disk attachment create] error retrieving VM %s: %s", d.Get("vm_id"), err)
+	}
+	defer releaseDistributedLock(unlock, &diags)
+	// The update of an independent disk locks the VMs it is attached to, as it detaches it and attaches it again when it
+	// is an IDE disk or moves to another storage profile. Attaching a disk meanwhile would be lost
+	vmHrefs := []string{vm.VM.HREF}
+	lockVmsForIndependentDisks(vmHrefs)
+	defer unlockVmsForIndependentDisks(vmHrefs)
+
+	_, vdc, err := vcdClient.GetOrgAndVdcFromResource(d)
+	if err != nil {
+		return diag.Errorf(errorRetrievingOrgAndVdc, err)
+	}
+	disk, err := vdc.GetDiskById(d.Get("disk_id").(string), true)
+	if err != nil {
+		return diag.Errorf("[disk attachment create] error retrieving independent disk %s: %s", d.Get("disk_id"), err)
+	}
+
+	attachParams := &types.DiskAttachOrDetachParams{Disk: &types.Reference{HREF: disk.Disk.HREF}}
+	// 0 is a valid bus and unit number, so the configuration tells whether they were set
+	if !d.GetRawConfig().GetAttr("bus_number").IsNull() {
+		attachParams.BusNumber = addrOf(d.Get("bus_number").(int))
+	}
+	if !d.GetRawConfig().GetAttr("unit_number").IsNull() {
+		attachParams.UnitNumber = addrOf(d.Get("unit_number").(int))
+	}
+	task, err := vm.AttachDisk(attachParams)
+	if err != nil {
+		return diag.Errorf("[disk attachment create] error attaching disk %s to VM %s: %s", disk.Disk.Name, vm.VM.Name, err)
+	}
+	err = task.WaitTaskCompletion()
+	if err != nil {
+		return diag.Errorf("[disk attachment create] error waiting for disk %s to be attached to VM %s: %s", disk.Disk.Name, vm.VM.Name, err)
+	}
+
+	d.SetId(vm.VM.ID + "_" + disk.Disk.Id)
+	return resourceVcdVmIndependentDiskAttachmentRead(ctx, d, meta)
+}
+
+func resourceVcdVmIndependentDiskAttachmentRead(_ context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	vcdClient := meta.(*VCDClient)
+	_, vdc, err := vcdClient.GetOrgAndVdcFromResource(d)
+	if err != nil {
+		return diag.Errorf(errorRetrievingOrgAndVdc, err)
+	}
+	vm, err := vdc.QueryVmById(d.Get("vm_id").(string))
+	if govcd.ContainsNotFound(err) {
+		log.Printf("[DEBUG] unable to find VM %s of disk attachment %s. Removing it from state", d.Get("vm_id"), d.Id())
+		d.SetId("")
+		return nil
+	}
+	if err != nil {
+		return diag.Errorf("[disk attachment read] error retrieving VM %s: %s", d.Get("vm_id"), err)
+	}
+	disk, err := vdc.GetDiskById(d.Get("disk_id").(string), true)
+	if govcd.ContainsNotFound(err) {
+		log.Printf("[DEBUG] unable to find independent disk %s of disk attachment %s. Removing it from state", d.Get("disk_id"), d.Id())
+		d.SetId("")
+		return nil
+	}
+	if err != nil {
+		return diag.Errorf("[disk attachment read] error retrieving independent disk %s: %s", d.Get("disk_id"), err)
+	}
+
+	diskSettings, err := getIndependentDiskFromVmDisks(*vm, disk.Disk.HREF)
+	if govcd.ContainsNotFound(err) {
+		log.Printf("[DEBUG] independent disk %s is not attached to VM %s. Removing it from state", disk.Disk.Name, vm.VM.Name)
+		d.SetId("")
+		return nil
+	}
+	if err != nil {
+		return diag.Errorf("[disk attachment read] error reading disks of VM %s: %s", vm.VM.Name, err)
+	}
+
+	dSet(d, "bus_number", diskSettings.BusNumber)
+	dSet(d, "unit_number", diskSettings.UnitNumber)
+	dSet(d, "size_in_mb", diskSettings.SizeMb)
+	dSet(d, "bus_type", busTypesFromValues[disk.Disk.BusType])
+	if disk.Disk.BusSubType == "vmware.nvme.controller" {
+		dSet(d, "bus_type", busTypesFromValues["20nvme"])
+	}
+	return nil
+}
+
+func resourceVcdVmIndependentDiskAttachmentDelete(_ context.Context, d *schema.ResourceData, meta interface{}) (diags diag.Diagnostics) {
+	vcdClient := meta.(*VCDClient)
+	vm, unlock, err := lockVmById(d, vcdClient)
+	if govcd.ContainsNotFound(err) {
+		return nil
+	}
+	if err != nil {
+		return diag.Errorf("[disk attachment delete] error retrieving VM %s: %s", d.Get("vm_id"), err)
+	}
+	defer releaseDistributedLock(unlock, &diags)
+	vmHrefs := []string{vm.VM.HREF}
+	lockVmsForIndependentDisks(vmHrefs)
+	defer unlockVmsForIndependentDisks(vmHrefs)
+
+	_, vdc, err := vcdClient.GetOrgAndVdcFromResource(d)
+	if err != nil {
+		return diag.Errorf(errorRetrievingOrgAndVdc, err)
+	}
+	disk, err := vdc.GetDiskById(d.Get("disk_id").(string), true)
+	if govcd.ContainsNotFound(err) {
+		return nil
+	}
+	if err != nil {
+		return diag.Errorf("[disk attachment delete] error retrieving independent disk %s: %s", d.Get("disk_id"), err)
+	}
+	_, err = getIndependentDiskFromVmDisks(*vm, disk.Disk.HREF)
+	if govcd.ContainsNotFound(err) {
+		log.Printf("[DEBUG] independent disk %s already detached from VM %s", disk.Disk.Name, vm.VM.Name)
+		return nil
+	}
+
+	task, err := vm.DetachDisk(&types.DiskAttachOrDetachParams{Disk: &types.Reference{HREF: disk.Disk.HREF}})
+	if err != nil {
+		return diag.Errorf("[disk attachment delete] error detaching disk %s from VM %s: %s", disk.Disk.Name, vm.VM.Name, err)
+	}
+	err = task.WaitTaskCompletion()
+	if err != nil {
+		return diag.Errorf("[disk attachment delete] error waiting for disk %s to be detached from VM %s: %s", disk.Disk.Name, vm.VM.Name, err)
+	}
+	return nil
+}
+
+// resourceVcdVmIndependentDiskAttachmentImport imports a disk attachment with an ID in the format
+// org-name.vdc-name.vm-id.disk-id, e.g. 'my-org.my-vdc.urn:vcloud:vm:xxx.urn:vcloud:disk:yyy'
+func resourceVcdVmIndependentDiskAttachmentImport(_ context.Context, d *schema.ResourceData, meta interface{}) ([]*schema.ResourceData, error) {
+	resourceURI := strings.Split(d.Id(), ImportSeparator)
+	if len(resourceURI) != 4 {
+		return nil, fmt.Errorf("resource id must be specified as org-name.vdc-name.vm-id.disk-id")
+	}
+	orgName, vdcName, vmId, diskId := resourceURI[0], resourceURI[1], resourceURI[2], resourceURI[3]
+
+	vcdClient := meta.(*VCDClient)
+	_, vdc, err := vcdClient.GetOrgAndVdc(orgName, vdcName)
+	if err != nil {
+		return nil, fmt.Errorf(errorRetrievingOrgAndVdc, err)
+	}
+	vm, err := vdc.QueryVmById(vmId)
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving VM %s: %s", vmId, err)
+	}
+	disk, err := vdc.GetDiskById(diskId, true)
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving independent disk %s: %s", diskId, err)
+	}
+	_, err = getIndependentDiskFromVmDisks(*vm, disk.Disk.HREF)
+	if err != nil {
+		return nil, fmt.Errorf("independent disk %s is not attached to VM %s", disk.Disk.Name, vm.VM.Name)
+	}
+
+	dSet(d, "org", orgName)
+	dSet(d, "vdc", vdcName)
+	dSet(d, "vm_id", vm.VM.ID)
+	dSet(d, "disk_id", disk.Disk.Id)
+	d.SetId(vm.VM.ID + "_" + disk.Disk.Id)
+	return []*schema.ResourceData{d}, nil
+}
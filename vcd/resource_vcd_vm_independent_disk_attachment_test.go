@@ -0,0 +1,129 @@
+//go:build vapp || vm || ALL || functional
+
+package vcd
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+)
+
+// TestAccVcdVmIndependentDiskAttachment attaches an independent disk to a VM which declares no disks, and checks that
+// updating the VM keeps the disk attached
+func TestAccVcdVmIndependentDiskAttachment(t *testing.T) {
+	preTestChecks(t)
+
+	var params = StringMap{
+		"Org":      testConfig.VCD.Org,
+		"Vdc":      testConfig.VCD.Vdc,
+		"VappName": t.Name(),
+		"DiskName": t.Name(),
+		"Memory":   "512",
+		"Tags":     "vapp vm",
+		"FuncName": t.Name(),
+	}
+	testParamsNotEmpty(t, params)
+
+	configText := templateFill(testAccVcdVmIndependentDiskAttachment, params)
+	debugPrintf("#[DEBUG] CONFIGURATION: %s\n", configText)
+
+	params["FuncName"] = t.Name() + "-update"
+	params["Memory"] = "1024"
+	configTextUpdate := templateFill(testAccVcdVmIndependentDiskAttachment, params)
+	debugPrintf("#[DEBUG] CONFIGURATION: %s\n", configTextUpdate)
+
+	if vcdShortTest {
+		t.Skip(acceptanceTestsSkipped)
+		return
+	}
+
+	resource.Test(t, resource.TestCase{
+		ProviderFactories: testAccProviders,
+		CheckDestroy:      testAccCheckVcdVAppDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: configText,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("vcd_vapp_vm.vm", "disk.#", "0"),
+					resource.TestCheckResourceAttr("vcd_vm_independent_disk_attachment.attachment", "bus_number", "1"),
+					resource.TestCheckResourceAttr("vcd_vm_independent_disk_attachment.attachment", "unit_number", "0"),
+					resource.TestCheckResourceAttr("vcd_vm_independent_disk_attachment.attachment", "bus_type", "SCSI"),
+					resource.TestCheckResourceAttr("vcd_vm_independent_disk_attachment.attachment", "size_in_mb", "100"),
+				),
+			},
+			{
+				Config: configTextUpdate,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("vcd_vapp_vm.vm", "memory", "1024"),
+					resource.TestCheckResourceAttr("vcd_vapp_vm.vm", "disk.#", "0"),
+					resource.TestCheckResourceAttr("vcd_vm_independent_disk_attachment.attachment", "bus_number", "1"),
+				),
+			},
+			{
+				ResourceName:      "vcd_vm_independent_disk_attachment.attachment",
+				ImportState:       true,
+				ImportStateVerify: true,
+				ImportStateIdFunc: importStateIdVmIndependentDiskAttachment("vcd_vapp_vm.vm", "vcd_independent_disk.disk"),
+			},
+		},
+	})
+	postTestChecks(t)
+}
+
+// importStateIdVmIndependentDiskAttachment builds the import ID of the attachment of a disk to a VM
+func importStateIdVmIndependentDiskAttachment(vmResourceName, diskResourceName string) resource.ImportStateIdFunc {
+	return func(s *terraform.State) (string, error) {
+		vm, ok := s.RootModule().Resources[vmResourceName]
+		if !ok {
+			return "", fmt.Errorf("resource %s not found", vmResourceName)
+		}
+		disk, ok := s.RootModule().Resources[diskResourceName]
+		if !ok {
+			return "", fmt.Errorf("resource %s not found", diskResourceName)
+		}
+		return testConfig.VCD.Org + ImportSeparator + testConfig.VCD.Vdc + ImportSeparator + vm.Primary.ID +
+			ImportSeparator + disk.Primary.ID, nil
+	}
+}
+
+const testAccVcdVmIndependentDiskAttachment = `
+resource "vcd_independent_disk" "disk" {
+  org          = "{{.Org}}"
+  vdc          = "{{.Vdc}}"
+  name         = "{{.DiskName}}"
+  size_in_mb   = 100
+  bus_type     = "SCSI"
+  bus_sub_type = "VirtualSCSI"
+}
+
+resource "vcd_vapp" "vapp" {
+  org  = "{{.Org}}"
+  vdc  = "{{.Vdc}}"
+  name = "{{.VappName}}"
+}
+
+resource "vcd_vapp_vm" "vm" {
+  org              = "{{.Org}}"
+  vdc              = "{{.Vdc}}"
+  vapp_name        = vcd_vapp.vapp.name
+  name             = "vm"
+  computer_name    = "vm"
+  memory           = {{.Memory}}
+  cpus             = 1
+  cpu_cores        = 1
+  os_type          = "sles10_64Guest"
+  hardware_version = "vmx-11"
+  power_on         = false
+}
+
+resource "vcd_vm_independent_disk_attachment" "attachment" {
+  org         = "{{.Org}}"
+  vdc         = "{{.Vdc}}"
+  vm_id       = vcd_vapp_vm.vm.id
+  disk_id     = vcd_independent_disk.disk.id
+  bus_number  = 1
+  unit_number = 0
+}
+`
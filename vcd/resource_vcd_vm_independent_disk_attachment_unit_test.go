@@ -0,0 +1,62 @@
+//go:build unit || ALL
+
+package vcd
+
+import (
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/vmware/go-vcloud-director/v3/govcd"
+	"github.com/vmware/go-vcloud-director/v3/types/v56"
+)
+
+// Test_updateStateOfAttachedIndependentDisks checks that the VM resource reads only the independent disks declared in
+// `disk`, so that the ones attached with vcd_vm_independent_disk_attachment are not detached at the next update
+func Test_updateStateOfAttachedIndependentDisks(t *testing.T) {
+	vm := govcd.VM{VM: &types.Vm{
+		VirtualHardwareSection: &types.VirtualHardwareSection{},
+		VmSpecSection:          &types.VmSpecSection{DiskSection: &types.DiskSection{}},
+	}}
+	for i, name := range []string{"declared", "attached"} {
+		href := "https://vcd.example.com/api/disk/" + name
+		vm.VM.VirtualHardwareSection.Item = append(vm.VM.VirtualHardwareSection.Item, &types.VirtualHardwareItem{
+			ResourceType: 17,
+			HostResource: []*types.VirtualHardwareHostResource{{Disk: href}},
+		})
+		vm.VM.VmSpecSection.DiskSection.DiskSettings = append(vm.VM.VmSpecSection.DiskSection.DiskSettings, &types.DiskSettings{
+			Disk:       &types.Reference{HREF: href, Name: name},
+			BusNumber:  1,
+			UnitNumber: i,
+			SizeMb:     1024,
+		})
+	}
+
+	d := schema.TestResourceDataRaw(t, vmSchemaFunc(vappVmType), map[string]interface{}{
+		"name": "web",
+		"disk": []interface{}{
+			map[string]interface{}{"name": "declared", "bus_number": "1", "unit_number": "0"},
+		},
+	})
+	managedDisks := managedIndependentDisks(d)
+	if len(managedDisks) != 1 || !managedDisks["declared"] {
+		t.Fatalf("expected only the declared disk to be managed, got %v", managedDisks)
+	}
+
+	err := updateStateOfAttachedIndependentDisks(d, vm, managedDisks)
+	if err != nil {
+		t.Fatalf("error reading attached disks: %s", err)
+	}
+	disks := d.Get("disk").(*schema.Set).List()
+	if len(disks) != 1 || disks[0].(map[string]interface{})["name"] != "declared" {
+		t.Fatalf("expected only the declared disk in state, got %v", disks)
+	}
+
+	// Without a filter, as when importing, all the attached disks are read
+	err = updateStateOfAttachedIndependentDisks(d, vm, nil)
+	if err != nil {
+		t.Fatalf("error reading attached disks: %s", err)
+	}
+	if count := d.Get("disk").(*schema.Set).Len(); count != 2 {
+		t.Fatalf("expected all the attached disks in state, got %d", count)
+	}
+}
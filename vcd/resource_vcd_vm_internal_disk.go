@@ -120,11 +120,16 @@ var internalDiskBusTypesFromValues = map[string]string{
 }
 
 // resourceVmInternalDiskCreate creates an internal disk for VM
-func resourceVmInternalDiskCreate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+func resourceVmInternalDiskCreate(ctx context.Context, d *schema.ResourceData, meta interface{}) (diags diag.Diagnostics) {
 	vcdClient := meta.(*VCDClient)
 
 	vcdClient.lockParentVapp(d)
 	defer vcdClient.unLockParentVapp(d)
+	unlockDistributed, err := vcdClient.lockDistributedVapp(d, d.Get("vapp_name").(string))
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	defer releaseDistributedLock(unlockDistributed, &diags)
 
 	vm, vdc, err := getVm(vcdClient, d)
 	if err != nil {
@@ -249,11 +254,16 @@ func powerOffIfNeeded(d *schema.ResourceData, vm *govcd.VM) (string, error) {
 }
 
 // resourceVmInternalDiskDelete deletes disk from VM
-func resourceVmInternalDiskDelete(_ context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+func resourceVmInternalDiskDelete(_ context.Context, d *schema.ResourceData, m interface{}) (diags diag.Diagnostics) {
 	vcdClient := m.(*VCDClient)
 
 	vcdClient.lockParentVapp(d)
 	defer vcdClient.unLockParentVapp(d)
+	unlockDistributed, err := vcdClient.lockDistributedVapp(d, d.Get("vapp_name").(string))
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	defer releaseDistributedLock(unlockDistributed, &diags)
 
 	vm, _, err := getVm(vcdClient, d)
 	if err != nil {
@@ -297,12 +307,17 @@ func getVm(vcdClient *VCDClient, d *schema.ResourceData) (*govcd.VM, *govcd.Vdc,
 }
 
 // Update the resource
-func resourceVmInternalDiskUpdate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+func resourceVmInternalDiskUpdate(ctx context.Context, d *schema.ResourceData, meta interface{}) (diags diag.Diagnostics) {
 	log.Printf("[TRACE] Update Internal Disk with ID: %s started.", d.Id())
 	vcdClient := meta.(*VCDClient)
 
 	vcdClient.lockParentVapp(d)
 	defer vcdClient.unLockParentVapp(d)
+	unlockDistributed, err := vcdClient.lockDistributedVapp(d, d.Get("vapp_name").(string))
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	defer releaseDistributedLock(unlockDistributed, &diags)
 
 	// ignore only allow_vm_reboot change, allows to avoid empty update
 	if d.HasChange("allow_vm_reboot") && !d.HasChange("iops") && !d.HasChange("size_in_mb") && !d.HasChange("storage_profile") {
@@ -0,0 +1,451 @@
+package vcd
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net"
+	"strconv"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+	"github.com/vmware/go-vcloud-director/v3/govcd"
+	"github.com/vmware/go-vcloud-director/v3/types/v56"
+)
+
+// vmNicMaxIndex is the highest NIC index supported by VCD
+const vmNicMaxIndex = 9
+
+func resourceVcdVmNetworkAdapter() *schema.Resource {
+	return &schema.Resource{
+		CreateContext: resourceVcdVmNetworkAdapterCreate,
+		ReadContext:   resourceVcdVmNetworkAdapterRead,
+		UpdateContext: resourceVcdVmNetworkAdapterUpdate,
+		DeleteContext: resourceVcdVmNetworkAdapterDelete,
+		Importer: &schema.ResourceImporter{
+			StateContext: resourceVcdVmNetworkAdapterImport,
+		},
+		Schema: map[string]*schema.Schema{
+			"org": {
+				Type:     schema.TypeString,
+				Optional: true,
+				ForceNew: true,
+				Description: "The name of organization to use, optional if defined at provider " +
+					"level. Useful when connected as sysadmin working across different organizations",
+			},
+			"vdc": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				ForceNew:    true,
+				Description: "The name of VDC to use, optional if defined at provider level",
+			},
+			"vm_id": {
+				Type:        schema.TypeString,
+				Required:    true,
+				ForceNew:    true,
+				Description: "The ID of the VM which owns the network adapter",
+			},
+			"nic_index": {
+				Type:         schema.TypeInt,
+				Optional:     true,
+				Computed:     true,
+				ForceNew:     true,
+				ValidateFunc: validation.IntBetween(0, vmNicMaxIndex),
+				Description:  "The index of the network adapter in the VM. When not set, the first index after the existing adapters is used",
+			},
+			"type": {
+				Type:         schema.TypeString,
+				Required:     true,
+				ValidateFunc: validation.StringInSlice([]string{"vapp", "org", "none"}, false),
+				Description:  "Network type to use: 'vapp', 'org' or 'none'. Use 'vapp' for vApp network, 'org' to attach Org VDC network. 'none' for empty NIC.",
+			},
+			"name": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "Name of the network this network adapter should connect to. Always required except for `type` `none`",
+			},
+			"ip_allocation_mode": {
+				Type:         schema.TypeString,
+				Required:     true,
+				ValidateFunc: validation.StringInSlice([]string{"POOL", "DHCP", "MANUAL", "NONE"}, false),
+				Description:  "IP address allocation mode. One of POOL, DHCP, MANUAL, NONE",
+			},
+			"ip": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				Computed:     true,
+				ValidateFunc: checkEmptyOrSingleIP(),
+				Description:  "IP of the network adapter. Settings depend on `ip_allocation_mode`. Omitted or empty for DHCP, POOL, NONE. Required for MANUAL",
+			},
+			"mac": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Computed:    true,
+				ForceNew:    true,
+				Description: "MAC address of the network adapter. VCD generates it when not set",
+			},
+			"adapter_type": {
+				Type:             schema.TypeString,
+				Optional:         true,
+				Computed:         true,
+				ForceNew:         true,
+				DiffSuppressFunc: suppressCase,
+				Description:      "Network card adapter type. (e.g. 'E1000', 'E1000E', 'SRIOVETHERNETCARD', 'VMXNET3', 'PCNet32')",
+			},
+			"connected": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     true,
+				Description: "It defines if the network adapter is connected or not.",
+			},
+			"is_primary": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+				Description: "Set to true if the network adapter should be the primary one of the VM",
+			},
+		},
+	}
+}
+
+// vmNetworkAdapterTarget is the VM of a network adapter, with its vApp and the function releasing their locks
+type vmNetworkAdapterTarget struct {
+	vm     *govcd.VM
+	vapp   *govcd.VApp
+	unlock func() error
+}
+
+// lockVmOfNetworkAdapter retrieves the VM of the network adapter and locks it, as its network configuration is changed
+// as a whole. The returned unlock function must always be called
+func lockVmOfNetworkAdapter(d *schema.ResourceData, vcdClient *VCDClient) (*vmNetworkAdapterTarget, error) {
+	_, vdc, err := vcdClient.GetOrgAndVdcFromResource(d)
+	if err != nil {
+		return nil, fmt.Errorf(errorRetrievingOrgAndVdc, err)
+	}
+	vm, err := vdc.QueryVmById(d.Get("vm_id").(string))
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving VM %s: %s", d.Get("vm_id"), err)
+	}
+	vapp, err := vm.GetParentVApp()
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving the vApp of VM %s: %s", vm.VM.Name, err)
+	}
+
+	unlock := vcdClient.lockVappWithName(vcdClient.getOrgName(d), vcdClient.getVdcName(d), vapp.VApp.Name)
+	unlockDistributed, err := vcdClient.lockDistributed(vapp.VApp.ID)
+	if err != nil {
+		unlock()
+		return nil, err
+	}
+	return &vmNetworkAdapterTarget{vm: vm, vapp: vapp, unlock: func() error {
+		defer unlock()
+		return unlockDistributed()
+	}}, nil
+}
+
+func resourceVcdVmNetworkAdapterCreate(ctx context.Context, d *schema.ResourceData, meta interface{}) (diags diag.Diagnostics) {
+	vcdClient := meta.(*VCDClient)
+	target, err := lockVmOfNetworkAdapter(d, vcdClient)
+	if err != nil {
+		return diag.Errorf("[network adapter create] %s", err)
+	}
+	defer releaseDistributedLock(target.unlock, &diags)
+
+	networkConnectionSection, err := target.vm.GetNetworkConnectionSection()
+	if err != nil {
+		return diag.Errorf("[network adapter create] error retrieving network configuration of VM %s: %s", target.vm.VM.Name, err)
+	}
+
+	// 0 is a valid index, so the configuration tells whether it was set
+	nicIndex := d.Get("nic_index").(int)
+	if !d.GetRawConfig().GetAttr("nic_index").IsNull() {
+		if findVmNic(networkConnectionSection, func(nic *types.NetworkConnection) bool {
+			return nic.NetworkConnectionIndex == nicIndex
+		}) != nil {
+			return diag.Errorf("[network adapter create] VM %s already has a network adapter with index %d", target.vm.VM.Name, nicIndex)
+		}
+	} else {
+		nicIndex = nextVmNicIndex(networkConnectionSection)
+		if nicIndex > vmNicMaxIndex {
+			return diag.Errorf("[network adapter create] VM %s has no free network adapter index", target.vm.VM.Name)
+		}
+	}
+
+	nic, err := vmNetworkAdapterConfig(d, target.vapp)
+	if err != nil {
+		return diag.Errorf("[network adapter create] %s", err)
+	}
+	nic.NetworkConnectionIndex = nicIndex
+	nic.MACAddress = d.Get("mac").(string)
+	networkConnectionSection.NetworkConnection = append(networkConnectionSection.NetworkConnection, nic)
+	if d.Get("is_primary").(bool) {
+		networkConnectionSection.PrimaryNetworkConnectionIndex = nic.NetworkConnectionIndex
+	}
+
+	err = target.vm.UpdateNetworkConnectionSection(networkConnectionSection)
+	if err != nil {
+		return diag.Errorf("[network adapter create] error adding network adapter to VM %s: %s", target.vm.VM.Name, err)
+	}
+
+	// The MAC address generated by VCD identifies the adapter
+	networkConnectionSection, err = target.vm.GetNetworkConnectionSection()
+	if err != nil {
+		return diag.Errorf("[network adapter create] error retrieving network configuration of VM %s: %s", target.vm.VM.Name, err)
+	}
+	created := findVmNic(networkConnectionSection, func(nic *types.NetworkConnection) bool {
+		return nic.NetworkConnectionIndex == nicIndex
+	})
+	if created == nil || created.MACAddress == "" {
+		return diag.Errorf("[network adapter create] network adapter %d not found in VM %s after creation", nicIndex, target.vm.VM.Name)
+	}
+	d.SetId(created.MACAddress)
+
+	return resourceVcdVmNetworkAdapterRead(ctx, d, meta)
+}
+
+func resourceVcdVmNetworkAdapterRead(_ context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	vcdClient := meta.(*VCDClient)
+	_, vdc, err := vcdClient.GetOrgAndVdcFromResource(d)
+	if err != nil {
+		return diag.Errorf(errorRetrievingOrgAndVdc, err)
+	}
+	vm, err := vdc.QueryVmById(d.Get("vm_id").(string))
+	if govcd.ContainsNotFound(err) {
+		log.Printf("[DEBUG] unable to find VM %s of network adapter %s. Removing it from state", d.Get("vm_id"), d.Id())
+		d.SetId("")
+		return nil
+	}
+	if err != nil {
+		return diag.Errorf("[network adapter read] error retrieving VM %s: %s", d.Get("vm_id"), err)
+	}
+
+	networkConnectionSection, err := vm.GetNetworkConnectionSection()
+	if err != nil {
+		return diag.Errorf("[network adapter read] error retrieving network configuration of VM %s: %s", vm.VM.Name, err)
+	}
+	nic := findVmNic(networkConnectionSection, func(nic *types.NetworkConnection) bool {
+		return strings.EqualFold(nic.MACAddress, d.Id())
+	})
+	if nic == nil {
+		log.Printf("[DEBUG] unable to find network adapter %s in VM %s. Removing it from state", d.Id(), vm.VM.Name)
+		d.SetId("")
+		return nil
+	}
+
+	vapp, err := vm.GetParentVApp()
+	if err != nil {
+		return diag.Errorf("[network adapter read] error retrieving the vApp of VM %s: %s", vm.VM.Name, err)
+	}
+	networkType, err := vmNicNetworkType(vapp, nic.Network)
+	if err != nil {
+		return diag.Errorf("[network adapter read] %s", err)
+	}
+
+	dSet(d, "nic_index", nic.NetworkConnectionIndex)
+	dSet(d, "type", networkType)
+	if nic.Network != types.NoneNetwork {
+		dSet(d, "name", nic.Network)
+	} else {
+		dSet(d, "name", "")
+	}
+	dSet(d, "ip_allocation_mode", nic.IPAddressAllocationMode)
+	dSet(d, "ip", nic.IPAddress)
+	dSet(d, "mac", nic.MACAddress)
+	dSet(d, "adapter_type", nic.NetworkAdapterType)
+	dSet(d, "connected", nic.IsConnected)
+	dSet(d, "is_primary", nic.NetworkConnectionIndex == networkConnectionSection.PrimaryNetworkConnectionIndex)
+	return nil
+}
+
+func resourceVcdVmNetworkAdapterUpdate(ctx context.Context, d *schema.ResourceData, meta interface{}) (diags diag.Diagnostics) {
+	vcdClient := meta.(*VCDClient)
+	target, err := lockVmOfNetworkAdapter(d, vcdClient)
+	if err != nil {
+		return diag.Errorf("[network adapter update] %s", err)
+	}
+	defer releaseDistributedLock(target.unlock, &diags)
+
+	networkConnectionSection, err := target.vm.GetNetworkConnectionSection()
+	if err != nil {
+		return diag.Errorf("[network adapter update] error retrieving network configuration of VM %s: %s", target.vm.VM.Name, err)
+	}
+	nic := findVmNic(networkConnectionSection, func(nic *types.NetworkConnection) bool {
+		return strings.EqualFold(nic.MACAddress, d.Id())
+	})
+	if nic == nil {
+		return diag.Errorf("[network adapter update] network adapter %s not found in VM %s", d.Id(), target.vm.VM.Name)
+	}
+
+	config, err := vmNetworkAdapterConfig(d, target.vapp)
+	if err != nil {
+		return diag.Errorf("[network adapter update] %s", err)
+	}
+	nic.Network = config.Network
+	nic.IPAddressAllocationMode = config.IPAddressAllocationMode
+	nic.IPAddress = config.IPAddress
+	nic.IsConnected = config.IsConnected
+	if d.Get("is_primary").(bool) {
+		networkConnectionSection.PrimaryNetworkConnectionIndex = nic.NetworkConnectionIndex
+	}
+
+	err = target.vm.UpdateNetworkConnectionSection(networkConnectionSection)
+	if err != nil {
+		return diag.Errorf("[network adapter update] error updating network adapter %s of VM %s: %s", d.Id(), target.vm.VM.Name, err)
+	}
+	return resourceVcdVmNetworkAdapterRead(ctx, d, meta)
+}
+
+func resourceVcdVmNetworkAdapterDelete(_ context.Context, d *schema.ResourceData, meta interface{}) (diags diag.Diagnostics) {
+	vcdClient := meta.(*VCDClient)
+	target, err := lockVmOfNetworkAdapter(d, vcdClient)
+	if govcd.ContainsNotFound(err) {
+		return nil
+	}
+	if err != nil {
+		return diag.Errorf("[network adapter delete] %s", err)
+	}
+	defer releaseDistributedLock(target.unlock, &diags)
+
+	networkConnectionSection, err := target.vm.GetNetworkConnectionSection()
+	if err != nil {
+		return diag.Errorf("[network adapter delete] error retrieving network configuration of VM %s: %s", target.vm.VM.Name, err)
+	}
+	var remaining []*types.NetworkConnection
+	for _, nic := range networkConnectionSection.NetworkConnection {
+		if !strings.EqualFold(nic.MACAddress, d.Id()) {
+			remaining = append(remaining, nic)
+		}
+	}
+	if len(remaining) == len(networkConnectionSection.NetworkConnection) {
+		log.Printf("[DEBUG] network adapter %s already removed from VM %s", d.Id(), target.vm.VM.Name)
+		return nil
+	}
+	networkConnectionSection.NetworkConnection = remaining
+
+	err = target.vm.UpdateNetworkConnectionSection(networkConnectionSection)
+	if err != nil {
+		return diag.Errorf("[network adapter delete] error removing network adapter %s from VM %s: %s", d.Id(), target.vm.VM.Name, err)
+	}
+	return nil
+}
+
+// resourceVcdVmNetworkAdapterImport imports a network adapter with an ID in the format
+// org-name.vdc-name.vm-id.nic-index-or-mac, e.g. 'my-org.my-vdc.urn:vcloud:vm:xxx.1'
+func resourceVcdVmNetworkAdapterImport(_ context.Context, d *schema.ResourceData, meta interface{}) ([]*schema.ResourceData, error) {
+	resourceURI := strings.Split(d.Id(), ImportSeparator)
+	if len(resourceURI) != 4 {
+		return nil, fmt.Errorf("resource id must be specified as org-name.vdc-name.vm-id.nic-index-or-mac")
+	}
+	orgName, vdcName, vmId, nicKey := resourceURI[0], resourceURI[1], resourceURI[2], resourceURI[3]
+
+	vcdClient := meta.(*VCDClient)
+	_, vdc, err := vcdClient.GetOrgAndVdc(orgName, vdcName)
+	if err != nil {
+		return nil, fmt.Errorf(errorRetrievingOrgAndVdc, err)
+	}
+	vm, err := vdc.QueryVmById(vmId)
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving VM %s: %s", vmId, err)
+	}
+	networkConnectionSection, err := vm.GetNetworkConnectionSection()
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving network configuration of VM %s: %s", vm.VM.Name, err)
+	}
+	nic := findVmNic(networkConnectionSection, func(nic *types.NetworkConnection) bool {
+		return strings.EqualFold(nic.MACAddress, nicKey) || strconv.Itoa(nic.NetworkConnectionIndex) == nicKey
+	})
+	if nic == nil {
+		return nil, fmt.Errorf("no network adapter with index or MAC address '%s' found in VM %s", nicKey, vm.VM.Name)
+	}
+
+	dSet(d, "org", orgName)
+	dSet(d, "vdc", vdcName)
+	dSet(d, "vm_id", vm.VM.ID)
+	d.SetId(nic.MACAddress)
+	return []*schema.ResourceData{d}, nil
+}
+
+// vmNetworkAdapterConfig returns the network connection described by the resource. The index and MAC address are not
+// set, as they can't change
+func vmNetworkAdapterConfig(d *schema.ResourceData, vapp *govcd.VApp) (*types.NetworkConnection, error) {
+	networkType := d.Get("type").(string)
+	networkName := d.Get("name").(string)
+	switch {
+	case networkType == "none" || d.Get("ip_allocation_mode").(string) == types.IPAllocationModeNone:
+		networkName = types.NoneNetwork
+	case networkName == "":
+		return nil, fmt.Errorf("'name' is required for network type '%s'", networkType)
+	case networkType == "org" && !vapp.VApp.IsAutoNature:
+		isVappOrgNetwork, err := isItVappOrgNetwork(networkName, *vapp)
+		if err != nil {
+			return nil, err
+		}
+		if !isVappOrgNetwork {
+			return nil, fmt.Errorf("vApp Org network %s is not found in vApp %s", networkName, vapp.VApp.Name)
+		}
+	case networkType == "vapp":
+		isVappNetwork, err := isItVappNetwork(networkName, *vapp)
+		if err != nil {
+			return nil, fmt.Errorf("unable to find vApp network %s: %s", networkName, err)
+		}
+		if !isVappNetwork {
+			return nil, fmt.Errorf("vApp network %s is not found in vApp %s", networkName, vapp.VApp.Name)
+		}
+	}
+
+	nic := &types.NetworkConnection{
+		Network:                 networkName,
+		IsConnected:             d.Get("connected").(bool),
+		IPAddressAllocationMode: d.Get("ip_allocation_mode").(string),
+		IpType:                  "IPV4",
+		NetworkAdapterType:      d.Get("adapter_type").(string),
+	}
+	if ip := d.Get("ip").(string); net.ParseIP(ip) != nil {
+		nic.IPAddress = ip
+	}
+	return nic, nil
+}
+
+// vmNicNetworkType returns the type of the network of a NIC in the vApp, as used by the `type` field
+func vmNicNetworkType(vapp *govcd.VApp, networkName string) (string, error) {
+	if networkName == "" || networkName == types.NoneNetwork {
+		return "none", nil
+	}
+	vAppNetworkConfig, err := vapp.GetNetworkConfig()
+	if err != nil {
+		return "", fmt.Errorf("error getting vApp networks: %s", err)
+	}
+	for _, netConfig := range vAppNetworkConfig.NetworkConfig {
+		if netConfig.NetworkName != networkName {
+			continue
+		}
+		if govcd.IsVappNetwork(netConfig.Configuration) {
+			return "vapp", nil
+		}
+		return "org", nil
+	}
+	return "none", nil
+}
+
+// findVmNic returns the first NIC of the network configuration for which found returns true
+func findVmNic(networkConnectionSection *types.NetworkConnectionSection, found func(*types.NetworkConnection) bool) *types.NetworkConnection {
+	for _, nic := range networkConnectionSection.NetworkConnection {
+		if found(nic) {
+			return nic
+		}
+	}
+	return nil
+}
+
+// nextVmNicIndex returns the index following the highest one used by the NICs of the network configuration
+func nextVmNicIndex(networkConnectionSection *types.NetworkConnectionSection) int {
+	next := 0
+	for _, nic := range networkConnectionSection.NetworkConnection {
+		if nic.NetworkConnectionIndex >= next {
+			next = nic.NetworkConnectionIndex + 1
+		}
+	}
+	return next
+}
@@ -0,0 +1,124 @@
+//go:build vapp || vm || ALL || functional
+
+package vcd
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+)
+
+// TestAccVcdVmNetworkAdapter adds a network adapter to a VM with a NIC of its own, and checks that updating the VM
+// keeps the adapter without showing it in the VM plan
+func TestAccVcdVmNetworkAdapter(t *testing.T) {
+	preTestChecks(t)
+
+	var params = StringMap{
+		"Org":      testConfig.VCD.Org,
+		"Vdc":      testConfig.VCD.Vdc,
+		"VappName": t.Name(),
+		"Memory":   "512",
+		"Tags":     "vapp vm",
+		"FuncName": t.Name(),
+	}
+	testParamsNotEmpty(t, params)
+
+	configText := templateFill(testAccVcdVmNetworkAdapter, params)
+	debugPrintf("#[DEBUG] CONFIGURATION: %s\n", configText)
+
+	params["FuncName"] = t.Name() + "-update"
+	params["Memory"] = "1024"
+	configTextUpdate := templateFill(testAccVcdVmNetworkAdapter, params)
+	debugPrintf("#[DEBUG] CONFIGURATION: %s\n", configTextUpdate)
+
+	if vcdShortTest {
+		t.Skip(acceptanceTestsSkipped)
+		return
+	}
+
+	resource.Test(t, resource.TestCase{
+		ProviderFactories: testAccProviders,
+		CheckDestroy:      testAccCheckVcdVAppDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: configText,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("vcd_vapp_vm.vm", "network.#", "1"),
+					resource.TestCheckResourceAttr("vcd_vm_network_adapter.nic", "nic_index", "1"),
+					resource.TestCheckResourceAttr("vcd_vm_network_adapter.nic", "connected", "false"),
+					resource.TestCheckResourceAttrSet("vcd_vm_network_adapter.nic", "mac"),
+					resource.TestCheckResourceAttrPair("vcd_vm_network_adapter.nic", "id", "vcd_vm_network_adapter.nic", "mac"),
+				),
+			},
+			{
+				Config: configTextUpdate,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("vcd_vapp_vm.vm", "memory", "1024"),
+					resource.TestCheckResourceAttr("vcd_vapp_vm.vm", "network.#", "1"),
+					resource.TestCheckResourceAttr("vcd_vm_network_adapter.nic", "nic_index", "1"),
+					resource.TestCheckResourceAttr("vcd_vm_network_adapter.nic", "connected", "false"),
+				),
+			},
+			{
+				ResourceName:      "vcd_vm_network_adapter.nic",
+				ImportState:       true,
+				ImportStateVerify: true,
+				ImportStateIdFunc: importStateIdVmNetworkAdapter("vcd_vapp_vm.vm", 1),
+			},
+		},
+	})
+	postTestChecks(t)
+}
+
+// importStateIdVmNetworkAdapter builds the import ID of the network adapter with the given index in a VM
+func importStateIdVmNetworkAdapter(vmResourceName string, nicIndex int) resource.ImportStateIdFunc {
+	return func(s *terraform.State) (string, error) {
+		rs, ok := s.RootModule().Resources[vmResourceName]
+		if !ok {
+			return "", fmt.Errorf("resource %s not found", vmResourceName)
+		}
+		return fmt.Sprintf("%s%s%s%s%s%s%d", testConfig.VCD.Org, ImportSeparator, testConfig.VCD.Vdc, ImportSeparator,
+			rs.Primary.ID, ImportSeparator, nicIndex), nil
+	}
+}
+
+const testAccVcdVmNetworkAdapter = `
+resource "vcd_vapp" "vapp" {
+  org  = "{{.Org}}"
+  vdc  = "{{.Vdc}}"
+  name = "{{.VappName}}"
+}
+
+resource "vcd_vapp_vm" "vm" {
+  org              = "{{.Org}}"
+  vdc              = "{{.Vdc}}"
+  vapp_name        = vcd_vapp.vapp.name
+  name             = "vm"
+  computer_name    = "vm"
+  memory           = {{.Memory}}
+  cpus             = 1
+  cpu_cores        = 1
+  os_type          = "sles10_64Guest"
+  hardware_version = "vmx-11"
+  power_on         = false
+
+  ignore_additional_network_adapters = true
+
+  network {
+    type               = "none"
+    ip_allocation_mode = "NONE"
+    connected          = false
+  }
+}
+
+resource "vcd_vm_network_adapter" "nic" {
+  org                = "{{.Org}}"
+  vdc                = "{{.Vdc}}"
+  vm_id              = vcd_vapp_vm.vm.id
+  type               = "none"
+  ip_allocation_mode = "NONE"
+  connected          = false
+}
+`
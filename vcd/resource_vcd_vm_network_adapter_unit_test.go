@@ -0,0 +1,78 @@
+//go:build unit || ALL
+
+package vcd
+
+import (
+	"testing"
+
+	"github.com/vmware/go-vcloud-director/v3/types/v56"
+)
+
+// Test_keepExternalNics checks that the NICs after the ones managed by the VM resource are kept, and that an index
+// collision between the two is reported
+func Test_keepExternalNics(t *testing.T) {
+	current := &types.NetworkConnectionSection{NetworkConnection: []*types.NetworkConnection{
+		{NetworkConnectionIndex: 0, MACAddress: "00:50:56:00:00:00"},
+		{NetworkConnectionIndex: 1, MACAddress: "00:50:56:00:00:01"},
+		{NetworkConnectionIndex: 3, MACAddress: "00:50:56:00:00:03"},
+	}}
+
+	section := &types.NetworkConnectionSection{NetworkConnection: []*types.NetworkConnection{
+		{NetworkConnectionIndex: 0, MACAddress: "00:50:56:00:00:10"},
+	}}
+	err := keepExternalNics(section, current, 1)
+	if err != nil {
+		t.Fatalf("error keeping external NICs: %s", err)
+	}
+	var macs []string
+	for _, nic := range section.NetworkConnection {
+		macs = append(macs, nic.MACAddress)
+	}
+	expected := []string{"00:50:56:00:00:10", "00:50:56:00:00:01", "00:50:56:00:00:03"}
+	if len(macs) != len(expected) {
+		t.Fatalf("expected NICs %v, got %v", expected, macs)
+	}
+	for i := range expected {
+		if macs[i] != expected[i] {
+			t.Fatalf("expected NICs %v, got %v", expected, macs)
+		}
+	}
+
+	// A second NIC in the VM resource would take the index of an external NIC
+	section = &types.NetworkConnectionSection{NetworkConnection: []*types.NetworkConnection{
+		{NetworkConnectionIndex: 0},
+		{NetworkConnectionIndex: 1},
+	}}
+	err = keepExternalNics(section, current, 1)
+	if err == nil {
+		t.Fatalf("expected an error for a NIC index used outside the VM resource")
+	}
+
+	err = keepExternalNics(section, nil, 1)
+	if err != nil || len(section.NetworkConnection) != 2 {
+		t.Fatalf("expected no change without current NICs, got %d NICs and error %v", len(section.NetworkConnection), err)
+	}
+}
+
+func Test_nextVmNicIndex(t *testing.T) {
+	tests := []struct {
+		name    string
+		indexes []int
+		want    int
+	}{
+		{name: "NoNics", want: 0},
+		{name: "Contiguous", indexes: []int{0, 1}, want: 2},
+		{name: "Gap", indexes: []int{3, 0}, want: 4},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			section := &types.NetworkConnectionSection{}
+			for _, index := range tt.indexes {
+				section.NetworkConnection = append(section.NetworkConnection, &types.NetworkConnection{NetworkConnectionIndex: index})
+			}
+			if got := nextVmNicIndex(section); got != tt.want {
+				t.Errorf("nextVmNicIndex() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
@@ -195,6 +195,23 @@ func genericVcdVmSizingPolicyRead(ctx context.Context, d *schema.ResourceData, m
 		}
 	}
 
+	// The data source can be retrieved with a 'filter' block instead of name
+	if d.Id() == "" && policyName == "" {
+		method = "filter"
+		policy, err = getOpenApiEntityByFilter(&vcdClient.Client, d.Get("filter"), "VM sizing policy", openApiFilterConfig[*govcd.VdcComputePolicy]{
+			getAllEntitiesFunc: func(queryParameters url.Values) ([]*govcd.VdcComputePolicy, error) {
+				return vcdClient.Client.GetAllVdcComputePolicies(fiqlFilterAnd(queryParameters, "isSizingOnly==true"))
+			},
+			getIdAndNameFunc: func(e *govcd.VdcComputePolicy) (string, string) {
+				return e.VdcComputePolicy.ID, e.VdcComputePolicy.Name
+			},
+		})
+		if err != nil {
+			return diag.Errorf("unable to find VM sizing policy by filter: %s", err)
+		}
+		d.SetId(policy.VdcComputePolicy.ID)
+	}
+
 	// The secondary method of retrieval is from name
 	if d.Id() == "" {
 		if policyName == "" {
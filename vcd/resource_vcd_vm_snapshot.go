@@ -0,0 +1,329 @@
+package vcd
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/vmware/go-vcloud-director/v3/govcd"
+	"github.com/vmware/go-vcloud-director/v3/types/v56"
+)
+
+const labelVmSnapshot = "VM snapshot"
+
+// createSnapshotParams is the payload of the createSnapshot action of VMs and vApps
+type createSnapshotParams struct {
+	XMLName     xml.Name `xml:"CreateSnapshotParams"`
+	Xmlns       string   `xml:"xmlns,attr"`
+	Memory      bool     `xml:"memory,attr"`
+	Quiesce     bool     `xml:"quiesce,attr"`
+	Name        string   `xml:"name,attr,omitempty"`
+	Description string   `xml:"Description,omitempty"`
+}
+
+// snapshotTarget is a VM or vApp that supports the snapshot actions. VCD keeps a single snapshot for each VM, so
+// the actions always refer to the current snapshot
+type snapshotTarget struct {
+	client *govcd.Client
+	href   string
+	label  string
+}
+
+// create takes a new snapshot, replacing the current one if it exists
+func (target snapshotTarget) create(params *createSnapshotParams) error {
+	params.Xmlns = types.XMLNamespaceVCloud
+	return target.action("createSnapshot", "application/vnd.vmware.vcloud.createSnapshotParams+xml", params)
+}
+
+// revert reverts the target to its current snapshot
+func (target snapshotTarget) revert() error {
+	return target.action("revertToCurrentSnapshot", "", nil)
+}
+
+// remove removes all the snapshots of the target
+func (target snapshotTarget) remove() error {
+	return target.action("removeAllSnapshots", "", nil)
+}
+
+func (target snapshotTarget) action(action, contentType string, payload interface{}) error {
+	actionUrl, err := url.ParseRequestURI(target.href + "/action/" + action)
+	if err != nil {
+		return fmt.Errorf("error building %s URL for %s: %s", action, target.label, err)
+	}
+	task, err := target.client.ExecuteTaskRequest(actionUrl.String(), http.MethodPost, contentType,
+		fmt.Sprintf("error running %s on %s: %%s", action, target.label), payload)
+	if err != nil {
+		return err
+	}
+	return task.WaitTaskCompletion()
+}
+
+func resourceVcdVmSnapshot() *schema.Resource {
+	return &schema.Resource{
+		CreateContext: resourceVcdVmSnapshotCreate,
+		ReadContext:   resourceVcdVmSnapshotRead,
+		UpdateContext: resourceVcdVmSnapshotUpdate,
+		DeleteContext: resourceVcdVmSnapshotDelete,
+		Importer: &schema.ResourceImporter{
+			StateContext: resourceVcdVmSnapshotImport,
+		},
+
+		Schema: map[string]*schema.Schema{
+			"org": {
+				Type:     schema.TypeString,
+				Optional: true,
+				ForceNew: true,
+				Description: "The name of organization to use, optional if defined at provider " +
+					"level. Useful when connected as sysadmin working across different organizations",
+			},
+			"vdc": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				ForceNew:    true,
+				Description: "The name of VDC to use, optional if defined at provider level",
+			},
+			"vm_id": {
+				Type:        schema.TypeString,
+				Required:    true,
+				ForceNew:    true,
+				Description: "ID of the VM to snapshot",
+			},
+			"name": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				ForceNew:    true,
+				Description: "Name of the snapshot",
+			},
+			"description": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				ForceNew:    true,
+				Description: "Description of the snapshot",
+			},
+			"memory": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				ForceNew:    true,
+				Default:     false,
+				Description: "Whether to include the memory of the VM in the snapshot",
+			},
+			"quiesce": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				ForceNew:    true,
+				Default:     false,
+				Description: "Whether to quiesce the file system of the VM before taking the snapshot. Requires VMware Tools",
+			},
+			"revert_on": {
+				Type:     schema.TypeString,
+				Optional: true,
+				Description: "Any value. When it changes, the VM is reverted to this snapshot. " +
+					"Setting it on creation does not revert the VM",
+			},
+			"size": {
+				Type:        schema.TypeInt,
+				Computed:    true,
+				Description: "Size of the snapshot in bytes",
+			},
+			"created_at": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "Creation date of the snapshot",
+			},
+			"powered_on": {
+				Type:        schema.TypeBool,
+				Computed:    true,
+				Description: "Whether the VM was powered on when the snapshot was taken",
+			},
+		},
+	}
+}
+
+func resourceVcdVmSnapshotCreate(ctx context.Context, d *schema.ResourceData, meta interface{}) (diags diag.Diagnostics) {
+	vcdClient := meta.(*VCDClient)
+	vmId := d.Get("vm_id").(string)
+
+	vm, unlock, err := lockVmById(d, vcdClient)
+	if err != nil {
+		return diag.Errorf("error retrieving VM %s for its snapshot: %s", vmId, err)
+	}
+	defer releaseDistributedLock(unlock, &diags)
+
+	// A new snapshot would silently replace the existing one, which may belong to another resource
+	if created := vmSnapshotCreated(vm.VM); created != "" {
+		return diag.Errorf("error creating %s: VM '%s' already has a snapshot, created at %s. "+
+			"Remove it or import it into this resource", labelVmSnapshot, vm.VM.Name, created)
+	}
+	target := snapshotTarget{client: &vcdClient.Client, href: vm.VM.HREF, label: fmt.Sprintf("VM '%s'", vm.VM.Name)}
+	err = target.create(&createSnapshotParams{
+		Memory:      d.Get("memory").(bool),
+		Quiesce:     d.Get("quiesce").(bool),
+		Name:        d.Get("name").(string),
+		Description: d.Get("description").(string),
+	})
+	if err != nil {
+		return diag.Errorf("error creating %s: %s", labelVmSnapshot, err)
+	}
+
+	d.SetId(vm.VM.ID)
+	// The creation date identifies the snapshot taken here. It is stored before the read, so that the read does not
+	// mistake it for a snapshot taken out of band
+	err = setVmSnapshotData(d, vcdClient, true)
+	if err != nil {
+		return diag.Errorf("error reading %s after creation: %s", labelVmSnapshot, err)
+	}
+	return resourceVcdVmSnapshotRead(ctx, d, meta)
+}
+
+func resourceVcdVmSnapshotRead(_ context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	err := setVmSnapshotData(d, meta.(*VCDClient), false)
+	if err != nil {
+		return diag.Errorf("error reading %s: %s", labelVmSnapshot, err)
+	}
+	return nil
+}
+
+func resourceVcdVmSnapshotUpdate(ctx context.Context, d *schema.ResourceData, meta interface{}) (diags diag.Diagnostics) {
+	vcdClient := meta.(*VCDClient)
+
+	if d.HasChange("revert_on") {
+		vm, unlock, err := lockVmById(d, vcdClient)
+		if err != nil {
+			return diag.Errorf("error retrieving VM %s for its snapshot: %s", d.Id(), err)
+		}
+		defer releaseDistributedLock(unlock, &diags)
+
+		if vmSnapshotCreated(vm.VM) == "" {
+			return diag.Errorf("error reverting VM '%s': the snapshot does not exist anymore", vm.VM.Name)
+		}
+		target := snapshotTarget{client: &vcdClient.Client, href: vm.VM.HREF, label: fmt.Sprintf("VM '%s'", vm.VM.Name)}
+		err = target.revert()
+		if err != nil {
+			return diag.Errorf("error reverting VM '%s' to its snapshot: %s", vm.VM.Name, err)
+		}
+	}
+	return resourceVcdVmSnapshotRead(ctx, d, meta)
+}
+
+func resourceVcdVmSnapshotDelete(_ context.Context, d *schema.ResourceData, meta interface{}) (diags diag.Diagnostics) {
+	vcdClient := meta.(*VCDClient)
+
+	vm, unlock, err := lockVmById(d, vcdClient)
+	if govcd.ContainsNotFound(err) {
+		return nil
+	}
+	if err != nil {
+		return diag.Errorf("error retrieving VM %s for its snapshot: %s", d.Id(), err)
+	}
+	defer releaseDistributedLock(unlock, &diags)
+
+	created := vmSnapshotCreated(vm.VM)
+	if created == "" {
+		log.Printf("[DEBUG] %s of VM '%s' already removed", labelVmSnapshot, vm.VM.Name)
+		return nil
+	}
+	// The snapshot of this resource was replaced by another one, which is not removed
+	if created != d.Get("created_at").(string) {
+		log.Printf("[DEBUG] %s of VM '%s' was replaced by a snapshot created at %s, which is kept",
+			labelVmSnapshot, vm.VM.Name, created)
+		return nil
+	}
+	target := snapshotTarget{client: &vcdClient.Client, href: vm.VM.HREF, label: fmt.Sprintf("VM '%s'", vm.VM.Name)}
+	err = target.remove()
+	if err != nil {
+		return diag.Errorf("error removing %s: %s", labelVmSnapshot, err)
+	}
+	return nil
+}
+
+// resourceVcdVmSnapshotImport imports the current snapshot of a VM
+// Example import path (id): org-name.vdc-name.vm-id
+func resourceVcdVmSnapshotImport(_ context.Context, d *schema.ResourceData, meta interface{}) ([]*schema.ResourceData, error) {
+	resourceURI := strings.Split(d.Id(), ImportSeparator)
+	if len(resourceURI) != 3 {
+		return nil, fmt.Errorf("resource name must be specified as org-name.vdc-name.vm-id")
+	}
+	orgName, vdcName, vmId := resourceURI[0], resourceURI[1], resourceURI[2]
+
+	vcdClient := meta.(*VCDClient)
+	_, vdc, err := vcdClient.GetOrgAndVdc(orgName, vdcName)
+	if err != nil {
+		return nil, fmt.Errorf(errorRetrievingOrgAndVdc, err)
+	}
+	vm, err := vdc.QueryVmById(vmId)
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving VM %s: %s", vmId, err)
+	}
+	if vmSnapshotCreated(vm.VM) == "" {
+		return nil, fmt.Errorf("VM '%s' has no snapshot", vm.VM.Name)
+	}
+
+	dSet(d, "org", orgName)
+	dSet(d, "vdc", vdcName)
+	dSet(d, "vm_id", vm.VM.ID)
+	dSet(d, "memory", false)
+	dSet(d, "quiesce", false)
+	d.SetId(vm.VM.ID)
+
+	err = setVmSnapshotData(d, vcdClient, true)
+	if err != nil {
+		return nil, err
+	}
+	return []*schema.ResourceData{d}, nil
+}
+
+// vmSnapshotCreated returns the creation date of the current snapshot of a VM. It is empty when the VM has no snapshot
+func vmSnapshotCreated(vm *types.Vm) string {
+	if vm.Snapshots == nil || len(vm.Snapshots.Snapshot) == 0 {
+		return ""
+	}
+	return vm.Snapshots.Snapshot[0].Created
+}
+
+// setVmSnapshotData sets the computed properties of the VM snapshot. Unless 'adopt' is set, the resource is removed
+// from state when the snapshot was removed or replaced by another one out of band
+func setVmSnapshotData(d *schema.ResourceData, vcdClient *VCDClient, adopt bool) error {
+	_, vdc, err := vcdClient.GetOrgAndVdcFromResource(d)
+	if err != nil {
+		return fmt.Errorf(errorRetrievingOrgAndVdc, err)
+	}
+	vm, err := vdc.QueryVmById(d.Get("vm_id").(string))
+	if govcd.ContainsNotFound(err) && !adopt {
+		log.Printf("[DEBUG] VM %s not found. Removing %s from state", d.Get("vm_id"), labelVmSnapshot)
+		d.SetId("")
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	var snapshot *types.SnapshotItem
+	if vm.VM.Snapshots != nil && len(vm.VM.Snapshots.Snapshot) > 0 {
+		snapshot = vm.VM.Snapshots.Snapshot[0]
+	}
+	if snapshot == nil {
+		if adopt {
+			return fmt.Errorf("VM '%s' has no snapshot", vm.VM.Name)
+		}
+		log.Printf("[DEBUG] %s of VM '%s' was removed. Removing it from state", labelVmSnapshot, vm.VM.Name)
+		d.SetId("")
+		return nil
+	}
+	if !adopt && snapshot.Created != d.Get("created_at").(string) {
+		log.Printf("[DEBUG] %s of VM '%s' was replaced by a snapshot created at %s. Removing it from state",
+			labelVmSnapshot, vm.VM.Name, snapshot.Created)
+		d.SetId("")
+		return nil
+	}
+
+	dSet(d, "size", snapshot.Size)
+	dSet(d, "created_at", snapshot.Created)
+	dSet(d, "powered_on", snapshot.PoweredOn)
+	return nil
+}
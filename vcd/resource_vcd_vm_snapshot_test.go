@@ -0,0 +1,148 @@
+//go:build vapp || vm || ALL || functional
+
+package vcd
+
+import (
+	"fmt"
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+)
+
+// TestAccVcdVmSnapshot takes snapshots of a vApp VM and of its vApp, reverts them and checks the computed properties
+func TestAccVcdVmSnapshot(t *testing.T) {
+	preTestChecks(t)
+
+	var params = StringMap{
+		"Org":      testConfig.VCD.Org,
+		"Vdc":      testConfig.VCD.Vdc,
+		"VappName": t.Name(),
+		"VmName":   t.Name() + "-vm",
+		"RevertOn": "1",
+		"Tags":     "vapp vm",
+		"FuncName": t.Name(),
+	}
+	testParamsNotEmpty(t, params)
+
+	configText := templateFill(testAccVcdVmSnapshot, params)
+	debugPrintf("#[DEBUG] CONFIGURATION: %s\n", configText)
+
+	params["FuncName"] = t.Name() + "-revert"
+	params["RevertOn"] = "2"
+	configTextRevert := templateFill(testAccVcdVmSnapshot, params)
+	debugPrintf("#[DEBUG] CONFIGURATION: %s\n", configTextRevert)
+
+	if vcdShortTest {
+		t.Skip(acceptanceTestsSkipped)
+		return
+	}
+
+	vmSnapshot := "vcd_vm_snapshot.snapshot"
+	vappSnapshot := "vcd_vapp_snapshot.snapshot"
+	resource.Test(t, resource.TestCase{
+		ProviderFactories: testAccProviders,
+		CheckDestroy:      testAccCheckVcdVAppDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: configText,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttrPair(vmSnapshot, "id", "vcd_vapp_vm.vm", "id"),
+					resource.TestMatchResourceAttr(vmSnapshot, "size", regexp.MustCompile(`^\d+$`)),
+					resource.TestCheckResourceAttrSet(vmSnapshot, "created_at"),
+					resource.TestCheckResourceAttr(vmSnapshot, "powered_on", "false"),
+					resource.TestCheckResourceAttrPair(vappSnapshot, "id", "vcd_vapp.vapp2", "id"),
+					resource.TestMatchResourceAttr(vappSnapshot, "size", regexp.MustCompile(`^\d+$`)),
+					resource.TestCheckResourceAttrSet(vappSnapshot, "created_at"),
+				),
+			},
+			{
+				Config: configTextRevert,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr(vmSnapshot, "revert_on", "2"),
+					resource.TestCheckResourceAttr(vappSnapshot, "revert_on", "2"),
+				),
+			},
+			{
+				ResourceName:            vmSnapshot,
+				ImportState:             true,
+				ImportStateVerify:       true,
+				ImportStateIdFunc:       importStateIdVmSnapshot(vmSnapshot),
+				ImportStateVerifyIgnore: []string{"org", "vdc", "name", "description", "revert_on"},
+			},
+		},
+	})
+	postTestChecks(t)
+}
+
+// importStateIdVmSnapshot builds the import ID of a snapshot from the VM ID found in the state
+func importStateIdVmSnapshot(resourceName string) resource.ImportStateIdFunc {
+	return func(state *terraform.State) (string, error) {
+		rs, ok := state.RootModule().Resources[resourceName]
+		if !ok {
+			return "", fmt.Errorf("resource %s not found", resourceName)
+		}
+		return testConfig.VCD.Org + ImportSeparator + testConfig.VCD.Vdc + ImportSeparator + rs.Primary.ID, nil
+	}
+}
+
+// The VM and vApp snapshots use different vApps, as a snapshot of a vApp replaces the ones of its VMs
+const testAccVcdVmSnapshot = `
+resource "vcd_vapp" "vapp" {
+  org  = "{{.Org}}"
+  vdc  = "{{.Vdc}}"
+  name = "{{.VappName}}"
+}
+
+resource "vcd_vapp_vm" "vm" {
+  org              = "{{.Org}}"
+  vdc              = "{{.Vdc}}"
+  vapp_name        = vcd_vapp.vapp.name
+  name             = "{{.VmName}}"
+  computer_name    = "snapshot-vm"
+  memory           = 512
+  cpus             = 1
+  cpu_cores        = 1
+  os_type          = "sles10_64Guest"
+  hardware_version = "vmx-11"
+  power_on         = false
+}
+
+resource "vcd_vapp" "vapp2" {
+  org  = "{{.Org}}"
+  vdc  = "{{.Vdc}}"
+  name = "{{.VappName}}-2"
+}
+
+resource "vcd_vapp_vm" "vm2" {
+  org              = "{{.Org}}"
+  vdc              = "{{.Vdc}}"
+  vapp_name        = vcd_vapp.vapp2.name
+  name             = "{{.VmName}}-2"
+  computer_name    = "snapshot-vm2"
+  memory           = 512
+  cpus             = 1
+  cpu_cores        = 1
+  os_type          = "sles10_64Guest"
+  hardware_version = "vmx-11"
+  power_on         = false
+}
+
+resource "vcd_vapp_snapshot" "snapshot" {
+  org       = "{{.Org}}"
+  vdc       = "{{.Vdc}}"
+  vapp_id   = vcd_vapp_vm.vm2.vapp_id
+  name      = "{{.VappName}}-snapshot"
+  revert_on = "{{.RevertOn}}"
+}
+
+resource "vcd_vm_snapshot" "snapshot" {
+  org         = "{{.Org}}"
+  vdc         = "{{.Vdc}}"
+  vm_id       = vcd_vapp_vm.vm.id
+  name        = "{{.VmName}}-snapshot"
+  description = "Snapshot taken by {{.FuncName}}"
+  revert_on   = "{{.RevertOn}}"
+}
+`
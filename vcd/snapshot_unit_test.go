@@ -0,0 +1,93 @@
+//go:build unit || ALL
+
+package vcd
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/vmware/go-vcloud-director/v3/govcd"
+	"github.com/vmware/go-vcloud-director/v3/types/v56"
+	"github.com/vmware/terraform-provider-vcd/v4/vcd/mockvcd"
+)
+
+// Test_snapshotTarget checks the requests sent by the snapshot actions and that their tasks are awaited
+func Test_snapshotTarget(t *testing.T) {
+	server := mockvcd.NewServer()
+	defer server.Close()
+	vcdClient := newMockVcdClient(t, server)
+
+	vmPath := "/api/vApp/vm-11111111-2222-3333-4444-555555555555"
+	bodies := make(map[string]string)
+	for _, action := range []string{"createSnapshot", "revertToCurrentSnapshot", "removeAllSnapshots"} {
+		server.HandleFunc(http.MethodPost, vmPath+"/action/"+action, func(w http.ResponseWriter, r *http.Request) {
+			body, _ := io.ReadAll(r.Body)
+			bodies[action] = string(body)
+			taskHref := server.NewTask("urn:vcloud:vm:11111111-2222-3333-4444-555555555555", server.URL+vmPath)
+			w.Header().Set("Content-Type", types.MimeTask)
+			w.WriteHeader(http.StatusAccepted)
+			_, _ = fmt.Fprintf(w, `<Task xmlns="%s" href="%s" status="running"></Task>`, types.XMLNamespaceVCloud, taskHref)
+		})
+	}
+
+	target := snapshotTarget{client: &vcdClient.Client, href: server.URL + vmPath, label: "VM 'test'"}
+	err := target.create(&createSnapshotParams{Memory: true, Name: "before-upgrade", Description: "first"})
+	if err != nil {
+		t.Fatalf("error creating snapshot: %s", err)
+	}
+	for _, expected := range []string{`<CreateSnapshotParams xmlns="` + types.XMLNamespaceVCloud + `"`,
+		`memory="true"`, `quiesce="false"`, `name="before-upgrade"`, `<Description>first</Description>`} {
+		if !strings.Contains(bodies["createSnapshot"], expected) {
+			t.Errorf("expected '%s' in the createSnapshot payload, got: %s", expected, bodies["createSnapshot"])
+		}
+	}
+
+	if err := target.revert(); err != nil {
+		t.Fatalf("error reverting snapshot: %s", err)
+	}
+	if err := target.remove(); err != nil {
+		t.Fatalf("error removing snapshot: %s", err)
+	}
+	if bodies["revertToCurrentSnapshot"] != "" || bodies["removeAllSnapshots"] != "" {
+		t.Errorf("expected no payload for revert and remove, got '%s' and '%s'",
+			bodies["revertToCurrentSnapshot"], bodies["removeAllSnapshots"])
+	}
+
+	missing := snapshotTarget{client: &vcdClient.Client, href: server.URL + "/api/vApp/vm-missing", label: "VM 'missing'"}
+	err = missing.revert()
+	if err == nil || !strings.Contains(err.Error(), "revertToCurrentSnapshot on VM 'missing'") {
+		t.Errorf("expected error naming the action and the VM, got: %v", err)
+	}
+}
+
+func Test_vappSnapshotSummary(t *testing.T) {
+	vapp := &govcd.VApp{VApp: &types.VApp{Children: &types.VAppChildren{VM: []*types.Vm{
+		{Snapshots: &types.SnapshotSection{Snapshot: []*types.SnapshotItem{{Created: "2024-05-02T10:00:00.000Z", Size: 100}}}},
+		{Snapshots: &types.SnapshotSection{Snapshot: []*types.SnapshotItem{{Created: "2024-05-02T10:00:05.000Z", Size: 50}}}},
+		{},
+	}}}}
+	size, created := vappSnapshotSummary(vapp)
+	if size != 150 || created != "2024-05-02T10:00:05.000Z" {
+		t.Errorf("expected size 150 and the most recent date, got %d and '%s'", size, created)
+	}
+
+	size, created = vappSnapshotSummary(&govcd.VApp{VApp: &types.VApp{}})
+	if size != 0 || created != "" {
+		t.Errorf("expected no snapshot for a vApp without VMs, got %d and '%s'", size, created)
+	}
+}
+
+func Test_vmSnapshotCreated(t *testing.T) {
+	vm := &types.Vm{Snapshots: &types.SnapshotSection{Snapshot: []*types.SnapshotItem{{Created: "2024-05-02T10:00:00.000Z"}}}}
+	if created := vmSnapshotCreated(vm); created != "2024-05-02T10:00:00.000Z" {
+		t.Errorf("expected the date of the snapshot, got '%s'", created)
+	}
+	for _, vm := range []*types.Vm{{}, {Snapshots: &types.SnapshotSection{}}} {
+		if created := vmSnapshotCreated(vm); created != "" {
+			t.Errorf("expected no snapshot, got '%s'", created)
+		}
+	}
+}
@@ -0,0 +1,454 @@
+package vcd
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// OpenTelemetry tracing of provider operations.
+//
+// Tracing is enabled when OTEL_EXPORTER_OTLP_ENDPOINT or OTEL_EXPORTER_OTLP_TRACES_ENDPOINT is set, and the spans are
+// exported with OTLP over HTTP. The exporter honors the other standard OTEL_EXPORTER_OTLP_* variables (headers,
+// timeout, etc.). The following spans are produced:
+//   - one span for each CRUD or import operation of a resource or data source, with resource type, operation, Org and
+//     VDC, and the total time spent waiting on locks and tasks
+//   - one span for each HTTP request to VCD, with method, URL, status code and task ID
+//   - one span for each VCD task, from its creation (or first poll) to its completion
+//   - one span for each wait on a vcdMutexKV lock
+//
+// The go-vcloud-director SDK does not propagate a context.Context, so every traced operation receives its own copy of
+// the provider client, whose requests carry the context of the operation. The HTTP and task spans find their parent
+// operation in that context, and so do the lock waits of the VCDClient lock helpers, through operationContext.
+// Requests made with the client shared by all operations (e.g. during the provider configuration) produce root spans,
+// and lock waits requested without the context of an operation are not traced.
+
+const (
+	tracerName         = "github.com/vmware/terraform-provider-vcd"
+	tracingServiceName = "terraform-provider-vcd"
+)
+
+var (
+	// tracer creates the spans of the provider. It is nil when tracing is disabled
+	tracer         trace.Tracer
+	tracerProvider *sdktrace.TracerProvider
+	tracingOnce    sync.Once
+)
+
+// tracedOperationKey is the context key of the *tracedOperation of a context
+type tracedOperationKey struct{}
+
+// tracedOperation is a CRUD operation in progress, which accumulates the time spent waiting on locks and tasks
+type tracedOperation struct {
+	lockWait atomic.Int64
+	taskWait atomic.Int64
+
+	// tasks are the tasks started by the operation whose completion has not been observed yet
+	mutex sync.Mutex
+	tasks map[string]*tracedTaskWait
+}
+
+// initTracing enables tracing when an OTLP endpoint is configured in the environment. It is safe to call it more
+// than once
+func initTracing() {
+	tracingOnce.Do(func() {
+		if os.Getenv("OTEL_EXPORTER_OTLP_ENDPOINT") == "" && os.Getenv("OTEL_EXPORTER_OTLP_TRACES_ENDPOINT") == "" {
+			return
+		}
+		exporter, err := otlptracehttp.New(context.Background())
+		if err != nil {
+			log.Printf("[WARN] tracing disabled: error creating the OTLP exporter: %s", err)
+			return
+		}
+		enableTracing(sdktrace.WithBatcher(exporter))
+	})
+}
+
+// enableTracing sets up the tracer provider with the given options (which must include an exporter)
+func enableTracing(options ...sdktrace.TracerProviderOption) {
+	options = append(options, sdktrace.WithResource(resource.NewWithAttributes(semconv.SchemaURL,
+		semconv.ServiceName(tracingServiceName),
+		semconv.ServiceVersion(BuildVersion),
+	)))
+	tracerProvider = sdktrace.NewTracerProvider(options...)
+	otel.SetTracerProvider(tracerProvider)
+	tracer = tracerProvider.Tracer(tracerName, trace.WithInstrumentationVersion(BuildVersion))
+}
+
+// ShutdownTracing sends the pending spans to the OTLP endpoint. It must be called before the provider exits
+func ShutdownTracing() {
+	if tracerProvider == nil {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := tracerProvider.Shutdown(ctx); err != nil {
+		log.Printf("[WARN] error sending the pending spans: %s", err)
+	}
+}
+
+// tracedOperationFromContext returns the operation of the given context, or nil
+func tracedOperationFromContext(ctx context.Context) *tracedOperation {
+	operation, _ := ctx.Value(tracedOperationKey{}).(*tracedOperation)
+	return operation
+}
+
+// operationContext returns the context of the traced operation that uses this copy of the client, or
+// context.Background() for the client shared by all operations
+func (cli *VCDClient) operationContext() context.Context {
+	if cli.operationCtx == nil {
+		return context.Background()
+	}
+	return cli.operationCtx
+}
+
+// withTracedOperation returns a copy of the provider client for the operation running with the given context, whose
+// requests carry that context
+func withTracedOperation(ctx context.Context, meta interface{}) interface{} {
+	vcdClient, ok := meta.(*VCDClient)
+	if !ok || vcdClient == nil || vcdClient.VCDClient == nil {
+		return meta
+	}
+	govcdClient := *vcdClient.VCDClient
+	govcdClient.Client.Http.Transport = &tracingContextTransport{next: govcdClient.Client.Http.Transport, ctx: ctx}
+	operationClient := *vcdClient
+	operationClient.VCDClient = &govcdClient
+	operationClient.operationCtx = ctx
+	return &operationClient
+}
+
+// tracingContextTransport sends the requests with the context of a traced operation. The cancellation of the context
+// is not propagated, as the requests of the SDK were never interrupted by it
+type tracingContextTransport struct {
+	next http.RoundTripper
+	ctx  context.Context
+}
+
+func (t *tracingContextTransport) RoundTrip(request *http.Request) (*http.Response, error) {
+	next := t.next
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	return next.RoundTrip(request.WithContext(context.WithoutCancel(t.ctx)))
+}
+
+// startTracedOperation starts the span of a CRUD operation. It returns the context of the operation, and the function
+// that ends the span with the result of the operation
+func startTracedOperation(ctx context.Context, kind, resourceType, operation string, d *schema.ResourceData, meta interface{}, schemaMap map[string]*schema.Schema) (context.Context, func(error)) {
+	attributes := []attribute.KeyValue{
+		attribute.String("vcd.resource.kind", kind),
+		attribute.String("vcd.resource.type", resourceType),
+		attribute.String("vcd.operation", operation),
+	}
+	var defaultOrg, defaultVdc string
+	if vcdClient, ok := meta.(*VCDClient); ok {
+		defaultOrg, defaultVdc = vcdClient.Org, vcdClient.Vdc
+	}
+	for _, field := range []struct{ name, defaultValue string }{{"org", defaultOrg}, {"vdc", defaultVdc}} {
+		value := field.defaultValue
+		if _, ok := schemaMap[field.name]; ok && d != nil {
+			if fieldValue, ok := d.Get(field.name).(string); ok && fieldValue != "" {
+				value = fieldValue
+			}
+		}
+		if value != "" {
+			attributes = append(attributes, attribute.String("vcd."+field.name, value))
+		}
+	}
+
+	ctx, span := tracer.Start(ctx, resourceType+" "+operation, trace.WithAttributes(attributes...))
+	traced := &tracedOperation{tasks: make(map[string]*tracedTaskWait)}
+	ctx = context.WithValue(ctx, tracedOperationKey{}, traced)
+
+	return ctx, func(err error) {
+		traced.endTaskWaits()
+		if d != nil && d.Id() != "" {
+			span.SetAttributes(attribute.String("vcd.resource.id", d.Id()))
+		}
+		span.SetAttributes(
+			attribute.Float64("vcd.lock.wait_seconds", time.Duration(traced.lockWait.Load()).Seconds()),
+			attribute.Float64("vcd.task.wait_seconds", time.Duration(traced.taskWait.Load()).Seconds()),
+		)
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+		span.End()
+	}
+}
+
+// diagnosticsError returns the errors in the diagnostics as a single error, or nil
+func diagnosticsError(diags diag.Diagnostics) error {
+	if !diags.HasError() {
+		return nil
+	}
+	var messages []string
+	for _, diagnostic := range diags {
+		if diagnostic.Severity == diag.Error {
+			messages = append(messages, diagnostic.Summary)
+		}
+	}
+	return fmt.Errorf("%s", strings.Join(messages, "; "))
+}
+
+// tracedResourceMap returns a copy of the given resources or data sources ("resource" or "data_source" kind), where
+// the CRUD and import functions create tracing spans
+func tracedResourceMap(kind string, resources map[string]*schema.Resource) map[string]*schema.Resource {
+	traced := make(map[string]*schema.Resource, len(resources))
+	for resourceType, original := range resources {
+		r := *original
+		schemaMap := original.SchemaMap()
+		r.CreateContext = traceContextFunc(kind, resourceType, "create", schemaMap, r.CreateContext)
+		r.ReadContext = traceContextFunc(kind, resourceType, "read", schemaMap, r.ReadContext)
+		r.UpdateContext = traceContextFunc(kind, resourceType, "update", schemaMap, r.UpdateContext)
+		r.DeleteContext = traceContextFunc(kind, resourceType, "delete", schemaMap, r.DeleteContext)
+		r.Create = traceFunc(kind, resourceType, "create", schemaMap, r.Create)
+		r.Read = traceFunc(kind, resourceType, "read", schemaMap, r.Read)
+		r.Update = traceFunc(kind, resourceType, "update", schemaMap, r.Update)
+		r.Delete = traceFunc(kind, resourceType, "delete", schemaMap, r.Delete)
+		if original.Importer != nil {
+			importer := *original.Importer
+			importer.StateContext = traceImportFunc(kind, resourceType, schemaMap, importer.StateContext)
+			importer.State = traceLegacyImportFunc(kind, resourceType, schemaMap, importer.State)
+			r.Importer = &importer
+		}
+		traced[resourceType] = &r
+	}
+	return traced
+}
+
+func traceContextFunc(kind, resourceType, operation string, schemaMap map[string]*schema.Schema,
+	f func(context.Context, *schema.ResourceData, interface{}) diag.Diagnostics) func(context.Context, *schema.ResourceData, interface{}) diag.Diagnostics {
+	if f == nil {
+		return nil
+	}
+	return func(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+		ctx, end := startTracedOperation(ctx, kind, resourceType, operation, d, meta, schemaMap)
+		diags := f(ctx, d, withTracedOperation(ctx, meta))
+		end(diagnosticsError(diags))
+		return diags
+	}
+}
+
+func traceFunc(kind, resourceType, operation string, schemaMap map[string]*schema.Schema,
+	f func(*schema.ResourceData, interface{}) error) func(*schema.ResourceData, interface{}) error {
+	if f == nil {
+		return nil
+	}
+	return func(d *schema.ResourceData, meta interface{}) error {
+		ctx, end := startTracedOperation(context.Background(), kind, resourceType, operation, d, meta, schemaMap)
+		err := f(d, withTracedOperation(ctx, meta))
+		end(err)
+		return err
+	}
+}
+
+func traceImportFunc(kind, resourceType string, schemaMap map[string]*schema.Schema,
+	f func(context.Context, *schema.ResourceData, interface{}) ([]*schema.ResourceData, error)) func(context.Context, *schema.ResourceData, interface{}) ([]*schema.ResourceData, error) {
+	if f == nil {
+		return nil
+	}
+	return func(ctx context.Context, d *schema.ResourceData, meta interface{}) ([]*schema.ResourceData, error) {
+		ctx, end := startTracedOperation(ctx, kind, resourceType, "import", d, meta, schemaMap)
+		result, err := f(ctx, d, withTracedOperation(ctx, meta))
+		end(err)
+		return result, err
+	}
+}
+
+// traceLegacyImportFunc traces the importers that only define the State function
+func traceLegacyImportFunc(kind, resourceType string, schemaMap map[string]*schema.Schema,
+	f schema.StateFunc) schema.StateFunc {
+	if f == nil {
+		return nil
+	}
+	return func(d *schema.ResourceData, meta interface{}) ([]*schema.ResourceData, error) {
+		ctx, end := startTracedOperation(context.Background(), kind, resourceType, "import", d, meta, schemaMap)
+		result, err := f(d, withTracedOperation(ctx, meta))
+		end(err)
+		return result, err
+	}
+}
+
+// traceLockWait starts a span for the wait on a vcdMutexKV lock in the given mode ("exclusive" or "shared"), when
+// the context belongs to a traced operation. The returned function must be called once the lock is acquired
+func traceLockWait(ctx context.Context, key, mode string) func() {
+	operation := tracedOperationFromContext(ctx)
+	if tracer == nil || operation == nil {
+		return func() {}
+	}
+	start := time.Now()
+	_, span := tracer.Start(ctx, "lock wait", trace.WithAttributes(
+		attribute.String("vcd.lock.key", key),
+		attribute.String("vcd.lock.mode", mode),
+	))
+	return func() {
+		waited := time.Since(start)
+		operation.lockWait.Add(int64(waited))
+		span.SetAttributes(attribute.Float64("vcd.lock.wait_seconds", waited.Seconds()))
+		span.End()
+	}
+}
+
+// tracedTaskWait is a task whose completion has not been observed yet
+type tracedTaskWait struct {
+	span  trace.Span
+	start time.Time
+}
+
+// tracingTransport is an http.RoundTripper that creates a span for each request to VCD, and follows the tasks
+// returned by VCD until they complete. The parent spans are taken from the context of the requests
+type tracingTransport struct {
+	next http.RoundTripper
+}
+
+func newTracingTransport(next http.RoundTripper) *tracingTransport {
+	return &tracingTransport{next: next}
+}
+
+func (t *tracingTransport) RoundTrip(request *http.Request) (*http.Response, error) {
+	operation := tracedOperationFromContext(request.Context())
+	requestUrl := *request.URL
+	requestUrl.User = nil
+	_, span := tracer.Start(request.Context(), "HTTP "+request.Method,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			semconv.HTTPRequestMethodKey.String(request.Method),
+			semconv.URLFull(requestUrl.String()),
+		))
+	defer span.End()
+
+	polledTaskId := taskIdFromHref(request.URL.Path)
+	if polledTaskId != "" {
+		span.SetAttributes(attribute.String("vcd.task.id", polledTaskId))
+	}
+
+	response, err := t.next.RoundTrip(request)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+	span.SetAttributes(semconv.HTTPResponseStatusCode(response.StatusCode))
+	if response.StatusCode >= http.StatusBadRequest {
+		span.SetStatus(codes.Error, response.Status)
+	}
+
+	// Asynchronous operations return the task in the Location header
+	if createdTaskId := taskIdFromHref(response.Header.Get("Location")); createdTaskId != "" {
+		span.SetAttributes(attribute.String("vcd.task.id", createdTaskId))
+		operation.startTaskWait(request.Context(), createdTaskId)
+	}
+	// Tasks are only followed within an operation, which ends the spans of the tasks it did not see completing
+	if operation != nil && polledTaskId != "" && request.Method == http.MethodGet && response.StatusCode == http.StatusOK {
+		operation.startTaskWait(request.Context(), polledTaskId)
+		status, body, err := readTaskStatus(response.Body)
+		response.Body = body
+		if err == nil {
+			operation.observeTaskStatus(polledTaskId, status)
+		}
+	}
+	return response, nil
+}
+
+// startTaskWait starts the span of a task, unless it was started already. It does nothing without an operation
+func (operation *tracedOperation) startTaskWait(ctx context.Context, taskId string) {
+	if operation == nil {
+		return
+	}
+	operation.mutex.Lock()
+	defer operation.mutex.Unlock()
+	if _, ok := operation.tasks[taskId]; ok {
+		return
+	}
+	_, span := tracer.Start(ctx, "task wait", trace.WithAttributes(attribute.String("vcd.task.id", taskId)))
+	operation.tasks[taskId] = &tracedTaskWait{span: span, start: time.Now()}
+}
+
+// observeTaskStatus ends the span of a task when its status is final
+func (operation *tracedOperation) observeTaskStatus(taskId, status string) {
+	if status != "success" && status != "error" && status != "aborted" {
+		return
+	}
+	operation.mutex.Lock()
+	wait, ok := operation.tasks[taskId]
+	delete(operation.tasks, taskId)
+	operation.mutex.Unlock()
+	if !ok {
+		return
+	}
+	waited := time.Since(wait.start)
+	operation.taskWait.Add(int64(waited))
+	wait.span.SetAttributes(
+		attribute.String("vcd.task.status", status),
+		attribute.Float64("vcd.task.wait_seconds", waited.Seconds()),
+	)
+	if status != "success" {
+		wait.span.SetStatus(codes.Error, "task "+status)
+	}
+	wait.span.End()
+}
+
+// endTaskWaits ends the spans of the tasks whose completion was not observed, when the operation ends
+func (operation *tracedOperation) endTaskWaits() {
+	operation.mutex.Lock()
+	defer operation.mutex.Unlock()
+	for taskId, wait := range operation.tasks {
+		wait.span.SetAttributes(attribute.String("vcd.task.status", "unobserved"))
+		wait.span.End()
+		delete(operation.tasks, taskId)
+	}
+}
+
+// taskIdFromHref returns the ID of a task from its HREF (e.g. https://vcd.example.com/api/task/<ID>) or an empty
+// string when the HREF does not belong to a task
+func taskIdFromHref(href string) string {
+	const taskPath = "/api/task/"
+	index := strings.Index(href, taskPath)
+	if index < 0 {
+		return ""
+	}
+	taskId := href[index+len(taskPath):]
+	if end := strings.IndexAny(taskId, "/?"); end >= 0 {
+		taskId = taskId[:end]
+	}
+	return taskId
+}
+
+// readTaskStatus reads the status of a task from a response body. It returns a replacement for the body, which
+// can be read again by the caller
+func readTaskStatus(body io.ReadCloser) (string, io.ReadCloser, error) {
+	content, err := io.ReadAll(body)
+	_ = body.Close()
+	replacement := io.NopCloser(bytes.NewReader(content))
+	if err != nil {
+		return "", replacement, err
+	}
+	var task struct {
+		Status string `xml:"status,attr"`
+	}
+	if err := xml.Unmarshal(content, &task); err != nil {
+		return "", replacement, err
+	}
+	return task.Status, replacement, nil
+}
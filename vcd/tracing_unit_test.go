@@ -0,0 +1,196 @@
+//go:build unit || ALL
+
+package vcd
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/vmware/go-vcloud-director/v3/govcd"
+	"github.com/vmware/go-vcloud-director/v3/types/v56"
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+
+	"github.com/vmware/terraform-provider-vcd/v4/vcd/mockvcd"
+)
+
+func Test_taskIdFromHref(t *testing.T) {
+	tests := map[string]string{
+		"https://vcd.example.com/api/task/1b5ac8a6-3f9b-4f6c-9c3e-6a2b8e2d1f00":        "1b5ac8a6-3f9b-4f6c-9c3e-6a2b8e2d1f00",
+		"https://vcd.example.com/api/task/1b5ac8a6-3f9b-4f6c-9c3e-6a2b8e2d1f00/action": "1b5ac8a6-3f9b-4f6c-9c3e-6a2b8e2d1f00",
+		"/api/task/abc?links=true":                   "abc",
+		"https://vcd.example.com/api/vApp/vapp-1234": "",
+		"": "",
+	}
+	for href, expected := range tests {
+		if got := taskIdFromHref(href); got != expected {
+			t.Errorf("taskIdFromHref(%q): expected '%s', got '%s'", href, expected, got)
+		}
+	}
+}
+
+// Test_tracing checks the spans of a traced resource operation that takes a lock and runs an asynchronous task
+// in the mock VCD. The task runs in another goroutine, as the parent spans are found through the context of the
+// operation. The import uses a legacy State function
+func Test_tracing(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+	previousTracer, previousTracerProvider := tracer, tracerProvider
+	enableTracing(sdktrace.WithSyncer(exporter))
+	defer func() {
+		tracer, tracerProvider = previousTracer, previousTracerProvider
+	}()
+
+	server := mockvcd.NewServer(mockvcd.WithAsyncCollections("1.0.0/globalRoles"))
+	defer server.Close()
+	vcdClient := newMockVcdClient(t, server)
+
+	resources := tracedResourceMap("resource", map[string]*schema.Resource{
+		"vcd_test_role": {
+			Schema: map[string]*schema.Schema{
+				"org":  {Type: schema.TypeString, Optional: true},
+				"name": {Type: schema.TypeString, Required: true},
+			},
+			CreateContext: func(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+				key := "test-tracing-lock"
+				vcdMutexKV.kvLockContext(ctx, key)
+				defer vcdMutexKV.kvUnlock(key)
+				var role *govcd.GlobalRole
+				var err error
+				done := make(chan struct{})
+				go func() {
+					defer close(done)
+					role, err = meta.(*VCDClient).Client.CreateGlobalRole(&types.GlobalRole{Name: d.Get("name").(string)})
+				}()
+				<-done
+				if err != nil {
+					return diag.FromErr(err)
+				}
+				d.SetId(role.GlobalRole.Id)
+				return nil
+			},
+			Importer: &schema.ResourceImporter{
+				State: func(d *schema.ResourceData, meta interface{}) ([]*schema.ResourceData, error) {
+					_, err := meta.(*VCDClient).Client.GetGlobalRoleById(d.Id())
+					return []*schema.ResourceData{d}, err
+				},
+			},
+		},
+	})
+	res := resources["vcd_test_role"]
+	d := schema.TestResourceDataRaw(t, res.Schema, map[string]interface{}{"org": "org1", "name": "role1"})
+	if diags := res.CreateContext(context.Background(), d, vcdClient); diags.HasError() {
+		t.Fatalf("error creating role: %v", diags)
+	}
+
+	spans := exporter.GetSpans()
+	var operation *tracetest.SpanStub
+	for i := range spans {
+		if spans[i].Name == "vcd_test_role create" {
+			operation = &spans[i]
+		}
+	}
+	if operation == nil {
+		t.Fatalf("operation span not found in %d spans", len(spans))
+	}
+	checkSpanAttributes(t, *operation, map[attribute.Key]string{
+		"vcd.resource.kind": "resource",
+		"vcd.resource.type": "vcd_test_role",
+		"vcd.operation":     "create",
+		"vcd.org":           "org1",
+		"vcd.resource.id":   d.Id(),
+	})
+
+	children := map[string]int{}
+	for _, span := range spans {
+		if span.Parent.SpanID() != operation.SpanContext.SpanID() {
+			continue
+		}
+		children[span.Name]++
+		switch span.Name {
+		case "lock wait":
+			checkSpanAttributes(t, span, map[attribute.Key]string{"vcd.lock.key": "test-tracing-lock"})
+		case "task wait":
+			checkSpanAttributes(t, span, map[attribute.Key]string{"vcd.task.status": "success"})
+		}
+	}
+	for _, name := range []string{"lock wait", "task wait", "HTTP POST", "HTTP GET"} {
+		if children[name] == 0 {
+			t.Errorf("expected span '%s' in the operation, got %v", name, children)
+		}
+	}
+
+	exporter.Reset()
+	if _, err := res.Importer.State(d, vcdClient); err != nil {
+		t.Fatalf("error importing role: %s", err)
+	}
+	spans = exporter.GetSpans()
+	var importSpan *tracetest.SpanStub
+	for i := range spans {
+		if spans[i].Name == "vcd_test_role import" {
+			importSpan = &spans[i]
+		}
+	}
+	if importSpan == nil {
+		t.Fatalf("import span not found in %d spans", len(spans))
+	}
+	requests := 0
+	for _, span := range spans {
+		if span.Name == "HTTP GET" && span.Parent.SpanID() == importSpan.SpanContext.SpanID() {
+			requests++
+		}
+	}
+	if requests == 0 {
+		t.Errorf("expected the requests of the import in its span")
+	}
+}
+
+// Test_tracedOperationEndTaskWaits checks that the spans of the tasks that were not seen completing are ended with
+// their operation
+func Test_tracedOperationEndTaskWaits(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+	previousTracer, previousTracerProvider := tracer, tracerProvider
+	enableTracing(sdktrace.WithSyncer(exporter))
+	defer func() {
+		tracer, tracerProvider = previousTracer, previousTracerProvider
+	}()
+
+	ctx, end := startTracedOperation(context.Background(), "resource", "vcd_test", "create", nil, nil, nil)
+	operation := tracedOperationFromContext(ctx)
+	operation.startTaskWait(ctx, "task1")
+	operation.startTaskWait(ctx, "task2")
+	operation.observeTaskStatus("task2", "success")
+	end(nil)
+
+	if len(operation.tasks) != 0 {
+		t.Errorf("expected no pending tasks, got %d", len(operation.tasks))
+	}
+	statuses := map[string]string{}
+	for _, span := range exporter.GetSpans() {
+		if span.Name != "task wait" {
+			continue
+		}
+		attributes := map[attribute.Key]string{}
+		for _, attr := range span.Attributes {
+			attributes[attr.Key] = attr.Value.Emit()
+		}
+		statuses[attributes["vcd.task.id"]] = attributes["vcd.task.status"]
+	}
+	if statuses["task1"] != "unobserved" || statuses["task2"] != "success" {
+		t.Errorf("expected an unobserved and a successful task, got %v", statuses)
+	}
+}
+
+func checkSpanAttributes(t *testing.T, span tracetest.SpanStub, expected map[attribute.Key]string) {
+	found := map[attribute.Key]string{}
+	for _, attr := range span.Attributes {
+		found[attr.Key] = attr.Value.Emit()
+	}
+	for key, value := range expected {
+		if found[key] != value {
+			t.Errorf("span '%s': expected attribute %s='%s', got '%s'", span.Name, key, value, found[key])
+		}
+	}
+}
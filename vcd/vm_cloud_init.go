@@ -0,0 +1,541 @@
+package vcd
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+	"github.com/vmware/go-vcloud-director/v3/govcd"
+	"github.com/vmware/go-vcloud-director/v3/types/v56"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	// cloudInitDeliveryGuestinfo delivers the cloud-init data with VM extra configuration (cloud-init VMware datasource)
+	cloudInitDeliveryGuestinfo = "guestinfo"
+	// cloudInitDeliveryOvfProperties delivers the cloud-init data with guest properties (cloud-init OVF datasource)
+	cloudInitDeliveryOvfProperties = "ovf_properties"
+	// cloudInitDeliveryNocloudIso delivers the cloud-init data with an ISO inserted in the VM (cloud-init NoCloud
+	// datasource)
+	cloudInitDeliveryNocloudIso = "nocloud_iso"
+)
+
+// cloudInitGuestinfoKeys are the extra configuration keys set by the guestinfo delivery
+var cloudInitGuestinfoKeys = []string{"guestinfo.userdata", "guestinfo.userdata.encoding",
+	"guestinfo.metadata", "guestinfo.metadata.encoding"}
+
+// cloudInitOvfMetaDataKeys are the meta_data keys supported by the ovf_properties delivery
+var cloudInitOvfMetaDataKeys = []string{"instance-id", "local-hostname", "hostname", "public-keys", "seedfrom"}
+
+// cloudInitOvfKeys are the guest properties set by the ovf_properties delivery
+var cloudInitOvfKeys = append([]string{"user-data", "network-config"}, cloudInitOvfMetaDataKeys...)
+
+// vmCloudInit holds the content of the 'cloud_init' block of a VM
+type vmCloudInit struct {
+	userData      string
+	metaData      string
+	networkConfig string
+	delivery      string
+	catalogId     string
+	mediaId       string
+	contentHash   string
+}
+
+// cloudInitSchema returns the schema of the 'cloud_init' block of VMs
+func cloudInitSchema() *schema.Schema {
+	return &schema.Schema{
+		Type:        schema.TypeList,
+		MaxItems:    1,
+		Optional:    true,
+		Description: "A block to deliver cloud-init configuration to the VM",
+		Elem: &schema.Resource{Schema: map[string]*schema.Schema{
+			"user_data": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "cloud-init user data (e.g. a '#cloud-config' document)",
+			},
+			"meta_data": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "cloud-init meta data, as a YAML or JSON document",
+			},
+			"network_config": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "cloud-init network configuration, as a YAML or JSON document",
+			},
+			"delivery": {
+				Type:     schema.TypeString,
+				Optional: true,
+				Default:  cloudInitDeliveryGuestinfo,
+				Description: "How the data is delivered to the VM. One of 'guestinfo' (extra configuration), " +
+					"'ovf_properties' (guest properties) or 'nocloud_iso' (NoCloud ISO uploaded to a catalog and inserted in the VM)",
+				ValidateFunc: validation.StringInSlice([]string{cloudInitDeliveryGuestinfo,
+					cloudInitDeliveryOvfProperties, cloudInitDeliveryNocloudIso}, false),
+			},
+			"catalog_id": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "ID of the catalog where the NoCloud ISO is uploaded. Required when 'delivery' is 'nocloud_iso'",
+			},
+			"media_id": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "ID of the NoCloud ISO media, when 'delivery' is 'nocloud_iso'",
+			},
+			"content_hash": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "SHA-256 hash of the delivered content",
+			},
+		}},
+	}
+}
+
+// getVmCloudInit returns the content of a 'cloud_init' block, or nil when the block is not set
+func getVmCloudInit(rawBlock interface{}) *vmCloudInit {
+	block, ok := rawBlock.([]interface{})
+	if !ok || len(block) == 0 || block[0] == nil {
+		return nil
+	}
+	values := block[0].(map[string]interface{})
+	return &vmCloudInit{
+		userData:      values["user_data"].(string),
+		metaData:      values["meta_data"].(string),
+		networkConfig: values["network_config"].(string),
+		delivery:      values["delivery"].(string),
+		catalogId:     values["catalog_id"].(string),
+		mediaId:       values["media_id"].(string),
+		contentHash:   values["content_hash"].(string),
+	}
+}
+
+// block returns the 'cloud_init' block with the content of the receiver
+func (c *vmCloudInit) block() []interface{} {
+	return []interface{}{map[string]interface{}{
+		"user_data":      c.userData,
+		"meta_data":      c.metaData,
+		"network_config": c.networkConfig,
+		"delivery":       c.delivery,
+		"catalog_id":     c.catalogId,
+		"media_id":       c.mediaId,
+		"content_hash":   c.contentHash,
+	}}
+}
+
+// hash returns the hash of the delivered content. A change of the hash means that the content must be delivered again
+func (c *vmCloudInit) hash() string {
+	sum := sha256.Sum256([]byte(strings.Join([]string{c.delivery, c.userData, c.metaData, c.networkConfig}, "\x00")))
+	return hex.EncodeToString(sum[:])
+}
+
+// guestinfoExtraConfig returns the extra configuration items of the VMware datasource. The network configuration is
+// added to the meta data, as expected by the datasource
+func (c *vmCloudInit) guestinfoExtraConfig() ([]*types.ExtraConfigMarshal, error) {
+	var extraConfig []*types.ExtraConfigMarshal
+	if c.userData != "" {
+		extraConfig = append(extraConfig,
+			&types.ExtraConfigMarshal{Key: "guestinfo.userdata", Value: base64.StdEncoding.EncodeToString([]byte(c.userData))},
+			&types.ExtraConfigMarshal{Key: "guestinfo.userdata.encoding", Value: "base64"})
+	}
+	metaData := c.metaData
+	if c.networkConfig != "" {
+		metaDataMap, err := parseCloudInitDocument("meta_data", c.metaData)
+		if err != nil {
+			return nil, err
+		}
+		metaDataMap["network"] = base64.StdEncoding.EncodeToString([]byte(c.networkConfig))
+		metaDataMap["network.encoding"] = "base64"
+		// JSON is valid YAML, and encoding/json sorts the keys, so that the result is stable
+		encoded, err := json.Marshal(metaDataMap)
+		if err != nil {
+			return nil, fmt.Errorf("error encoding cloud-init meta data: %s", err)
+		}
+		metaData = string(encoded)
+	}
+	if metaData != "" {
+		extraConfig = append(extraConfig,
+			&types.ExtraConfigMarshal{Key: "guestinfo.metadata", Value: base64.StdEncoding.EncodeToString([]byte(metaData))},
+			&types.ExtraConfigMarshal{Key: "guestinfo.metadata.encoding", Value: "base64"})
+	}
+	return extraConfig, nil
+}
+
+// ovfProperties returns the guest properties of the OVF datasource. Only the meta data keys known by the datasource
+// can be delivered in this way
+func (c *vmCloudInit) ovfProperties() (map[string]string, error) {
+	properties := make(map[string]string)
+	if c.userData != "" {
+		properties["user-data"] = base64.StdEncoding.EncodeToString([]byte(c.userData))
+	}
+	if c.networkConfig != "" {
+		properties["network-config"] = base64.StdEncoding.EncodeToString([]byte(c.networkConfig))
+	}
+	metaData, err := parseCloudInitDocument("meta_data", c.metaData)
+	if err != nil {
+		return nil, err
+	}
+	for key, value := range metaData {
+		if !contains(cloudInitOvfMetaDataKeys, key) {
+			return nil, fmt.Errorf("meta_data key '%s' can't be delivered with '%s'. Supported keys: %s",
+				key, cloudInitDeliveryOvfProperties, strings.Join(cloudInitOvfMetaDataKeys, ", "))
+		}
+		switch typedValue := value.(type) {
+		case []interface{}:
+			// e.g. a list of public keys
+			var lines []string
+			for _, item := range typedValue {
+				lines = append(lines, fmt.Sprintf("%v", item))
+			}
+			properties[key] = strings.Join(lines, "\n")
+		default:
+			properties[key] = fmt.Sprintf("%v", typedValue)
+		}
+	}
+	return properties, nil
+}
+
+// nocloudImage returns the NoCloud seed ISO image. The 'meta-data' file is always present, as the datasource requires
+// it
+func (c *vmCloudInit) nocloudImage() ([]byte, error) {
+	files := []isoFile{
+		{name: "meta-data", content: []byte(c.metaData)},
+		{name: "user-data", content: []byte(c.userData)},
+	}
+	if c.networkConfig != "" {
+		files = append(files, isoFile{name: "network-config", content: []byte(c.networkConfig)})
+	}
+	return buildIsoImage("cidata", files, time.Now())
+}
+
+// parseCloudInitDocument parses a YAML or JSON document with a top level map. An empty document returns an empty map
+func parseCloudInitDocument(fieldName, document string) (map[string]interface{}, error) {
+	result := make(map[string]interface{})
+	if strings.TrimSpace(document) == "" {
+		return result, nil
+	}
+	err := yaml.Unmarshal([]byte(document), &result)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing cloud-init %s: %s", fieldName, err)
+	}
+	return result, nil
+}
+
+// cloudInitOvfPropertiesChanged returns true when the guest properties set by the 'cloud_init' block must be updated
+func cloudInitOvfPropertiesChanged(d *schema.ResourceData) bool {
+	if !d.HasChange("cloud_init") {
+		return false
+	}
+	oldRaw, newRaw := d.GetChange("cloud_init")
+	oldCloudInit, newCloudInit := getVmCloudInit(oldRaw), getVmCloudInit(newRaw)
+	return (oldCloudInit != nil && oldCloudInit.delivery == cloudInitDeliveryOvfProperties) ||
+		(newCloudInit != nil && newCloudInit.delivery == cloudInitDeliveryOvfProperties)
+}
+
+// addCloudInitOvfProperties adds the guest properties of the 'cloud_init' block, when it uses the ovf_properties
+// delivery, to the ones of 'guest_properties'
+func addCloudInitOvfProperties(d *schema.ResourceData, properties *types.ProductSectionList) error {
+	cloudInit := getVmCloudInit(d.Get("cloud_init"))
+	if cloudInit == nil || cloudInit.delivery != cloudInitDeliveryOvfProperties {
+		return nil
+	}
+	cloudInitProperties, err := cloudInit.ovfProperties()
+	if err != nil {
+		return err
+	}
+	keys := make([]string, 0, len(cloudInitProperties))
+	for key := range cloudInitProperties {
+		if _, isSet := d.Get("guest_properties").(map[string]interface{})[key]; isSet {
+			return fmt.Errorf("guest property '%s' is also set by the cloud_init block", key)
+		}
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		properties.ProductSection.Property = append(properties.ProductSection.Property, &types.Property{
+			UserConfigurable: true,
+			Type:             "string",
+			Key:              key,
+			Label:            key,
+			Value:            &types.Value{Value: cloudInitProperties[key]},
+		})
+	}
+	return nil
+}
+
+// removeCloudInitOvfProperties removes the guest properties set by the 'cloud_init' block from the given ones, so that
+// they are not reported in 'guest_properties'
+func removeCloudInitOvfProperties(d *schema.ResourceData, properties *types.ProductSectionList) {
+	cloudInit := getVmCloudInit(d.Get("cloud_init"))
+	if cloudInit == nil || cloudInit.delivery != cloudInitDeliveryOvfProperties ||
+		properties == nil || properties.ProductSection == nil {
+		return
+	}
+	var remaining []*types.Property
+	for _, property := range properties.ProductSection.Property {
+		if !contains(cloudInitOvfKeys, property.Key) {
+			remaining = append(remaining, property)
+		}
+	}
+	properties.ProductSection.Property = remaining
+}
+
+// updateVmCloudInit delivers the content of the 'cloud_init' block to the VM, removing what was delivered for the
+// previous content. Guest properties are handled in addRemoveGuestProperties, together with 'guest_properties'
+func updateVmCloudInit(d *schema.ResourceData, vcdClient *VCDClient, org *govcd.Org, vm *govcd.VM) error {
+	if !d.HasChange("cloud_init") {
+		return nil
+	}
+	oldRaw, newRaw := d.GetChange("cloud_init")
+	oldCloudInit, newCloudInit := getVmCloudInit(oldRaw), getVmCloudInit(newRaw)
+	if newCloudInit != nil {
+		newCloudInit.contentHash = newCloudInit.hash()
+		if oldCloudInit != nil && oldCloudInit.hash() == newCloudInit.contentHash && oldCloudInit.catalogId == newCloudInit.catalogId {
+			// Nothing to deliver again (e.g. only the media ID was computed)
+			newCloudInit.mediaId = oldCloudInit.mediaId
+			return d.Set("cloud_init", newCloudInit.block())
+		}
+	}
+
+	if oldCloudInit != nil {
+		switch oldCloudInit.delivery {
+		case cloudInitDeliveryGuestinfo:
+			if newCloudInit == nil || newCloudInit.delivery != cloudInitDeliveryGuestinfo {
+				var removals []*types.ExtraConfigMarshal
+				for _, key := range cloudInitGuestinfoKeys {
+					// An empty value removes the item
+					removals = append(removals, &types.ExtraConfigMarshal{Key: key})
+				}
+				log.Printf("[TRACE] Removing cloud-init extra configuration from VM %s", vm.VM.Name)
+				if _, err := vm.UpdateExtraConfig(removals); err != nil {
+					return fmt.Errorf("error removing cloud-init extra configuration: %s", err)
+				}
+			}
+		case cloudInitDeliveryNocloudIso:
+			if err := removeCloudInitMedia(vcdClient, vm, oldCloudInit.mediaId); err != nil {
+				return err
+			}
+		}
+	}
+
+	if newCloudInit == nil {
+		return nil
+	}
+	switch newCloudInit.delivery {
+	case cloudInitDeliveryGuestinfo:
+		extraConfig, err := newCloudInit.guestinfoExtraConfig()
+		if err != nil {
+			return err
+		}
+		// Items that are not in the new content are removed
+		for _, key := range cloudInitGuestinfoKeys {
+			found := false
+			for _, item := range extraConfig {
+				found = found || item.Key == key
+			}
+			if !found {
+				extraConfig = append(extraConfig, &types.ExtraConfigMarshal{Key: key})
+			}
+		}
+		log.Printf("[TRACE] Setting cloud-init extra configuration of VM %s", vm.VM.Name)
+		if _, err = vm.UpdateExtraConfig(extraConfig); err != nil {
+			return fmt.Errorf("error setting cloud-init extra configuration: %s", err)
+		}
+	case cloudInitDeliveryNocloudIso:
+		mediaId, err := insertCloudInitMedia(vcdClient, org, vm, newCloudInit)
+		if err != nil {
+			return err
+		}
+		newCloudInit.mediaId = mediaId
+	}
+	if err := vm.Refresh(); err != nil {
+		return fmt.Errorf("error refreshing VM after delivering cloud-init data: %s", err)
+	}
+	return d.Set("cloud_init", newCloudInit.block())
+}
+
+// cloudInitMediaName returns the name of the NoCloud ISO of a VM. It contains the VM ID, as VM names are not unique
+// across vApps, and the content hash, so that each VM and each content get their own media
+func cloudInitMediaName(vmId, contentHash string) string {
+	return fmt.Sprintf("cloud-init-%s-%s.iso", extractUuid(vmId), contentHash[:12])
+}
+
+// cloudInitMediaDescription returns the description of the NoCloud ISO of a VM, which tells the media uploaded for
+// the VM from any other media with the same name
+func cloudInitMediaDescription(vm *govcd.VM) string {
+	return fmt.Sprintf("cloud-init NoCloud data of VM %s (%s)", vm.VM.Name, vm.VM.ID)
+}
+
+// insertCloudInitMedia uploads the NoCloud ISO to the catalog of the 'cloud_init' block and inserts it in the VM. A
+// media with the same name is only reused when it was uploaded for this VM. It returns the media ID
+func insertCloudInitMedia(vcdClient *VCDClient, org *govcd.Org, vm *govcd.VM, cloudInit *vmCloudInit) (string, error) {
+	if cloudInit.catalogId == "" {
+		return "", fmt.Errorf("'catalog_id' is required when the cloud-init delivery is '%s'", cloudInitDeliveryNocloudIso)
+	}
+	catalog, err := org.GetCatalogById(cloudInit.catalogId, false)
+	if err != nil {
+		return "", fmt.Errorf("error retrieving catalog %s for the cloud-init ISO: %s", cloudInit.catalogId, err)
+	}
+
+	image, err := cloudInit.nocloudImage()
+	if err != nil {
+		return "", fmt.Errorf("error building the cloud-init ISO: %s", err)
+	}
+	imageFile, err := os.CreateTemp("", "cloud-init-*.iso")
+	if err != nil {
+		return "", fmt.Errorf("error creating the cloud-init ISO file: %s", err)
+	}
+	defer func() {
+		_ = os.Remove(imageFile.Name())
+	}()
+	_, err = imageFile.Write(image)
+	if closeErr := imageFile.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return "", fmt.Errorf("error writing the cloud-init ISO file: %s", err)
+	}
+
+	mediaName := cloudInitMediaName(vm.VM.ID, cloudInit.contentHash)
+	mediaDescription := cloudInitMediaDescription(vm)
+	media, err := catalog.GetMediaByName(mediaName, true)
+	if govcd.ContainsNotFound(err) {
+		log.Printf("[TRACE] Uploading cloud-init ISO %s for VM %s", mediaName, vm.VM.Name)
+		task, err := catalog.UploadMediaImage(mediaName, mediaDescription, imageFile.Name(), int64(len(image)))
+		if err != nil {
+			return "", fmt.Errorf("error uploading the cloud-init ISO: %s", err)
+		}
+		err = task.WaitTaskCompletion()
+		if err != nil {
+			return "", fmt.Errorf("error uploading the cloud-init ISO: %s", err)
+		}
+		media, err = catalog.GetMediaByName(mediaName, true)
+		if err != nil {
+			return "", fmt.Errorf("error retrieving the uploaded cloud-init ISO: %s", err)
+		}
+	} else if err != nil {
+		return "", fmt.Errorf("error retrieving cloud-init ISO %s: %s", mediaName, err)
+	} else if media.Media.Description != mediaDescription {
+		return "", fmt.Errorf("media %s in catalog %s was not uploaded for the cloud-init data of VM %s. Remove or rename it",
+			mediaName, catalog.Catalog.Name, vm.VM.Name)
+	}
+
+	task, err := vm.InsertMedia(&types.MediaInsertOrEjectParams{
+		Media: &types.Reference{HREF: media.Media.HREF, Name: media.Media.Name, ID: media.Media.ID, Type: media.Media.Type},
+	})
+	if err != nil {
+		return "", fmt.Errorf("error inserting the cloud-init ISO in VM %s: %s", vm.VM.Name, err)
+	}
+	err = task.WaitTaskCompletion()
+	if err != nil {
+		return "", fmt.Errorf("error inserting the cloud-init ISO in VM %s: %s", vm.VM.Name, err)
+	}
+	return media.Media.ID, nil
+}
+
+// removeCloudInitMedia ejects the NoCloud ISO from the VM, when a VM is given, and deletes it from its catalog
+func removeCloudInitMedia(vcdClient *VCDClient, vm *govcd.VM, mediaId string) error {
+	if mediaId == "" {
+		return nil
+	}
+	mediaRecord, err := vcdClient.QueryMediaById(mediaId)
+	if govcd.ContainsNotFound(err) {
+		log.Printf("[DEBUG] cloud-init ISO %s already removed", mediaId)
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("error retrieving cloud-init ISO %s: %s", mediaId, err)
+	}
+
+	if vm != nil && mediaRecord.MediaRecord.IsBusy {
+		task, err := vm.EjectMedia(&types.MediaInsertOrEjectParams{Media: &types.Reference{HREF: mediaRecord.MediaRecord.HREF}})
+		if err != nil {
+			return fmt.Errorf("error ejecting cloud-init ISO from VM %s: %s", vm.VM.Name, err)
+		}
+		err = task.WaitTaskCompletion(true)
+		if err != nil {
+			return fmt.Errorf("error ejecting cloud-init ISO from VM %s: %s", vm.VM.Name, err)
+		}
+	}
+
+	task, err := mediaRecord.Delete()
+	if err != nil {
+		return fmt.Errorf("error deleting cloud-init ISO %s: %s", mediaRecord.MediaRecord.Name, err)
+	}
+	return task.WaitTaskCompletion()
+}
+
+// readVmCloudInit checks that the content of the 'cloud_init' block is still delivered to the VM. When it was changed
+// or removed out of band, the content is removed from state, so that the next apply delivers it again. The rest of the
+// block is kept, so that the update removes the ISO of the previous delivery
+func readVmCloudInit(d *schema.ResourceData, vcdClient *VCDClient, vm *govcd.VM) error {
+	cloudInit := getVmCloudInit(d.Get("cloud_init"))
+	if cloudInit == nil {
+		return nil
+	}
+
+	delivered := true
+	switch cloudInit.delivery {
+	case cloudInitDeliveryGuestinfo:
+		expected, err := cloudInit.guestinfoExtraConfig()
+		if err != nil {
+			return err
+		}
+		current, err := vm.GetExtraConfig()
+		if err != nil {
+			return fmt.Errorf("error retrieving VM extra configuration: %s", err)
+		}
+		values := make(map[string]string)
+		for _, item := range current {
+			values[item.Key] = item.Value
+		}
+		for _, item := range expected {
+			delivered = delivered && values[item.Key] == item.Value
+		}
+	case cloudInitDeliveryOvfProperties:
+		expected, err := cloudInit.ovfProperties()
+		if err != nil {
+			return err
+		}
+		current, err := vm.GetProductSectionList()
+		if err != nil {
+			return fmt.Errorf("error retrieving VM guest properties: %s", err)
+		}
+		values := make(map[string]string)
+		if current != nil && current.ProductSection != nil {
+			for _, property := range current.ProductSection.Property {
+				if property.Value != nil {
+					values[property.Key] = property.Value.Value
+				}
+			}
+		}
+		for key, value := range expected {
+			delivered = delivered && values[key] == value
+		}
+	case cloudInitDeliveryNocloudIso:
+		mediaRecord, err := vcdClient.QueryMediaById(cloudInit.mediaId)
+		if err != nil && !govcd.ContainsNotFound(err) && cloudInit.mediaId != "" {
+			return fmt.Errorf("error retrieving cloud-init ISO %s: %s", cloudInit.mediaId, err)
+		}
+		delivered = err == nil && mediaRecord.MediaRecord.IsBusy
+	}
+
+	if !delivered {
+		log.Printf("[DEBUG] cloud-init data of VM %s was changed out of band. Removing its content from state", vm.VM.Name)
+		cloudInit.userData = ""
+		cloudInit.metaData = ""
+		cloudInit.networkConfig = ""
+		cloudInit.contentHash = ""
+		return d.Set("cloud_init", cloudInit.block())
+	}
+	return nil
+}
@@ -0,0 +1,143 @@
+//go:build vapp || vm || ALL || functional
+
+package vcd
+
+import (
+	"fmt"
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+)
+
+// TestAccVcdVAppVmCloudInit delivers cloud-init data to a VM with extra configuration, changes the content and then
+// switches to a NoCloud ISO uploaded to a catalog
+func TestAccVcdVAppVmCloudInit(t *testing.T) {
+	preTestChecks(t)
+
+	var params = StringMap{
+		"Org":          testConfig.VCD.Org,
+		"Vdc":          testConfig.Nsxt.Vdc,
+		"Catalog":      testConfig.VCD.Catalog.NsxtBackedCatalogName,
+		"VAppTemplate": testConfig.VCD.Catalog.NsxtCatalogItem,
+		"VappName":     t.Name(),
+		"Hostname":     "web",
+		"Delivery":     cloudInitDeliveryGuestinfo,
+		"Tags":         "vapp vm",
+		"FuncName":     t.Name(),
+	}
+	testParamsNotEmpty(t, params)
+
+	configText := templateFill(testAccVcdVAppVmCloudInit, params)
+	debugPrintf("#[DEBUG] CONFIGURATION: %s\n", configText)
+
+	params["FuncName"] = t.Name() + "-content"
+	params["Hostname"] = "web-changed"
+	configTextContent := templateFill(testAccVcdVAppVmCloudInit, params)
+	debugPrintf("#[DEBUG] CONFIGURATION: %s\n", configTextContent)
+
+	params["FuncName"] = t.Name() + "-nocloud"
+	params["Delivery"] = cloudInitDeliveryNocloudIso
+	configTextNocloud := templateFill(testAccVcdVAppVmCloudInit, params)
+	debugPrintf("#[DEBUG] CONFIGURATION: %s\n", configTextNocloud)
+
+	if vcdShortTest {
+		t.Skip(acceptanceTestsSkipped)
+		return
+	}
+
+	hash := regexp.MustCompile(`^[0-9a-f]{64}$`)
+	var firstHash string
+	resource.Test(t, resource.TestCase{
+		ProviderFactories: testAccProviders,
+		CheckDestroy:      testAccCheckVcdVAppDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: configText,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("vcd_vapp_vm.web", "cloud_init.0.delivery", cloudInitDeliveryGuestinfo),
+					resource.TestMatchResourceAttr("vcd_vapp_vm.web", "cloud_init.0.content_hash", hash),
+					resource.TestCheckResourceAttr("vcd_vapp_vm.web", "cloud_init.0.media_id", ""),
+					resource.TestCheckTypeSetElemNestedAttrs("vcd_vapp_vm.web", "extra_config.*", map[string]string{
+						"key":   "guestinfo.userdata.encoding",
+						"value": "base64",
+					}),
+					resource.TestCheckResourceAttrWith("vcd_vapp_vm.web", "cloud_init.0.content_hash", func(value string) error {
+						firstHash = value
+						return nil
+					}),
+				),
+			},
+			{
+				Config: configTextContent,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttrWith("vcd_vapp_vm.web", "cloud_init.0.content_hash", func(value string) error {
+						if value == firstHash {
+							return fmt.Errorf("expected content hash to change after the content change")
+						}
+						return nil
+					}),
+				),
+			},
+			{
+				Config: configTextNocloud,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("vcd_vapp_vm.web", "cloud_init.0.delivery", cloudInitDeliveryNocloudIso),
+					resource.TestMatchResourceAttr("vcd_vapp_vm.web", "cloud_init.0.media_id", getUuidRegex("urn:vcloud:media:", "$")),
+				),
+			},
+		},
+	})
+	postTestChecks(t)
+}
+
+const testAccVcdVAppVmCloudInit = `
+data "vcd_catalog" "catalog" {
+  org  = "{{.Org}}"
+  name = "{{.Catalog}}"
+}
+
+data "vcd_catalog_vapp_template" "template" {
+  org        = "{{.Org}}"
+  catalog_id = data.vcd_catalog.catalog.id
+  name       = "{{.VAppTemplate}}"
+}
+
+resource "vcd_vapp" "vapp" {
+  org  = "{{.Org}}"
+  vdc  = "{{.Vdc}}"
+  name = "{{.VappName}}"
+}
+
+resource "vcd_vapp_vm" "web" {
+  org              = "{{.Org}}"
+  vdc              = "{{.Vdc}}"
+  vapp_name        = vcd_vapp.vapp.name
+  name             = "web"
+  computer_name    = "web"
+  vapp_template_id = data.vcd_catalog_vapp_template.template.id
+  memory           = 512
+  cpus             = 1
+  cpu_cores        = 1
+  power_on         = false
+
+  cloud_init {
+    delivery   = "{{.Delivery}}"
+    catalog_id = data.vcd_catalog.catalog.id
+    meta_data = yamlencode({
+      "instance-id"    = "{{.Hostname}}"
+      "local-hostname" = "{{.Hostname}}"
+    })
+    user_data = <<-EOT
+      #cloud-config
+      hostname: {{.Hostname}}
+    EOT
+    network_config = <<-EOT
+      version: 2
+      ethernets:
+        eth0:
+          dhcp4: true
+    EOT
+  }
+}
+`
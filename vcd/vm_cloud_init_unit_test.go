@@ -0,0 +1,220 @@
+//go:build unit || ALL
+
+package vcd
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf16"
+
+	"github.com/vmware/go-vcloud-director/v3/govcd"
+	"github.com/vmware/go-vcloud-director/v3/types/v56"
+)
+
+// Test_buildIsoImage checks that the files of the NoCloud ISO can be found through the Joliet volume descriptor, with
+// their original names, and that the volume label is the one expected by cloud-init
+func Test_buildIsoImage(t *testing.T) {
+	cloudInit := vmCloudInit{
+		userData:      "#cloud-config\npackages:\n  - nginx\n",
+		metaData:      "instance-id: web-1\nlocal-hostname: web\n",
+		networkConfig: strings.Repeat("# a long network configuration\n", 100),
+	}
+	image, err := cloudInit.nocloudImage()
+	if err != nil {
+		t.Fatalf("error building image: %s", err)
+	}
+	if len(image)%isoSectorSize != 0 {
+		t.Fatalf("image size %d is not a multiple of the sector size", len(image))
+	}
+
+	sector := func(number uint32) []byte {
+		return image[int(number)*isoSectorSize : int(number+1)*isoSectorSize]
+	}
+	primary := sector(16)
+	if primary[0] != 1 || string(primary[1:6]) != "CD001" {
+		t.Fatalf("unexpected primary volume descriptor header %v", primary[:6])
+	}
+	if label := strings.TrimSpace(string(primary[40:72])); label != "CIDATA" {
+		t.Fatalf("expected primary volume label CIDATA, got '%s'", label)
+	}
+	if size := binary.LittleEndian.Uint32(primary[80:]); int(size)*isoSectorSize != len(image) {
+		t.Fatalf("volume space size %d doesn't match the image size %d", size, len(image))
+	}
+
+	joliet := sector(17)
+	if joliet[0] != 2 || string(joliet[88:91]) != "%/E" {
+		t.Fatalf("unexpected Joliet volume descriptor header %v", joliet[:6])
+	}
+	if label := strings.TrimSpace(decodeUcs2(joliet[40:72])); label != "cidata" {
+		t.Fatalf("expected Joliet volume label cidata, got '%s'", label)
+	}
+	if terminator := sector(18); terminator[0] != 255 {
+		t.Fatalf("expected volume descriptor set terminator, got type %d", terminator[0])
+	}
+
+	// The root directory record of the Joliet descriptor leads to the files
+	rootRecord := joliet[156:190]
+	rootDirectory := sector(binary.LittleEndian.Uint32(rootRecord[2:]))
+	files := make(map[string][]byte)
+	for offset := 0; offset < len(rootDirectory) && rootDirectory[offset] != 0; offset += int(rootDirectory[offset]) {
+		record := rootDirectory[offset:]
+		identifierLength := int(record[32])
+		identifier := record[33 : 33+identifierLength]
+		if identifierLength == 1 && identifier[0] <= 1 {
+			// '.' and '..'
+			continue
+		}
+		start := int(binary.LittleEndian.Uint32(record[2:])) * isoSectorSize
+		size := int(binary.BigEndian.Uint32(record[14:]))
+		files[decodeUcs2(identifier)] = image[start : start+size]
+	}
+
+	expected := map[string]string{
+		"user-data":      cloudInit.userData,
+		"meta-data":      cloudInit.metaData,
+		"network-config": cloudInit.networkConfig,
+	}
+	if len(files) != len(expected) {
+		t.Fatalf("expected %d files, got %d", len(expected), len(files))
+	}
+	for name, content := range expected {
+		if !bytes.Equal(files[name], []byte(content)) {
+			t.Errorf("unexpected content of file %s: '%s'", name, files[name])
+		}
+	}
+}
+
+func decodeUcs2(data []byte) string {
+	units := make([]uint16, len(data)/2)
+	for i := range units {
+		units[i] = binary.BigEndian.Uint16(data[2*i:])
+	}
+	return string(utf16.Decode(units))
+}
+
+// Test_isoPrimaryName checks the conversion of file names to ISO 9660 identifiers
+func Test_isoPrimaryName(t *testing.T) {
+	tests := map[string]string{
+		"user-data":    "USER_DATA.;1",
+		"meta-data":    "META_DATA.;1",
+		"vendor.data1": "VENDOR.DATA1;1",
+	}
+	for name, expected := range tests {
+		if got := string(isoPrimaryName(name)); got != expected {
+			t.Errorf("expected '%s' for '%s', got '%s'", expected, name, got)
+		}
+	}
+	if date := isoVolumeDate(time.Date(2024, 5, 6, 7, 8, 9, 0, time.UTC)); string(date[:16]) != "2024050607080900" {
+		t.Errorf("unexpected volume date '%s'", date)
+	}
+}
+
+// Test_cloudInitMediaName checks that VMs with the same name and the same content get different NoCloud ISOs
+func Test_cloudInitMediaName(t *testing.T) {
+	contentHash := (&vmCloudInit{delivery: cloudInitDeliveryNocloudIso, userData: "#cloud-config"}).hash()
+	vm1 := &govcd.VM{VM: &types.Vm{Name: "web", ID: "urn:vcloud:vm:11111111-2222-3333-4444-555555555555"}}
+	vm2 := &govcd.VM{VM: &types.Vm{Name: "web", ID: "urn:vcloud:vm:66666666-7777-8888-9999-000000000000"}}
+
+	name1 := cloudInitMediaName(vm1.VM.ID, contentHash)
+	if expected := "cloud-init-11111111-2222-3333-4444-555555555555-" + contentHash[:12] + ".iso"; name1 != expected {
+		t.Errorf("expected media name '%s', got '%s'", expected, name1)
+	}
+	if name2 := cloudInitMediaName(vm2.VM.ID, contentHash); name1 == name2 {
+		t.Errorf("expected different media names for different VMs, got '%s'", name1)
+	}
+	if cloudInitMediaDescription(vm1) == cloudInitMediaDescription(vm2) {
+		t.Errorf("expected different media descriptions for different VMs, got '%s'", cloudInitMediaDescription(vm1))
+	}
+}
+
+// Test_vmCloudInitGuestinfo checks that the network configuration is merged into the meta data of the guestinfo
+// delivery
+func Test_vmCloudInitGuestinfo(t *testing.T) {
+	cloudInit := vmCloudInit{
+		delivery:      cloudInitDeliveryGuestinfo,
+		userData:      "#cloud-config\n",
+		metaData:      "instance-id: web-1\n",
+		networkConfig: "version: 2\n",
+	}
+	extraConfig, err := cloudInit.guestinfoExtraConfig()
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	values := make(map[string]string)
+	for _, item := range extraConfig {
+		values[item.Key] = item.Value
+	}
+	if len(values) != len(cloudInitGuestinfoKeys) {
+		t.Fatalf("expected %d items, got %v", len(cloudInitGuestinfoKeys), values)
+	}
+	if values["guestinfo.userdata"] != base64.StdEncoding.EncodeToString([]byte(cloudInit.userData)) ||
+		values["guestinfo.userdata.encoding"] != "base64" || values["guestinfo.metadata.encoding"] != "base64" {
+		t.Fatalf("unexpected user data items %v", values)
+	}
+
+	rawMetaData, err := base64.StdEncoding.DecodeString(values["guestinfo.metadata"])
+	if err != nil {
+		t.Fatalf("meta data is not base64 encoded: %s", err)
+	}
+	var metaData map[string]string
+	err = json.Unmarshal(rawMetaData, &metaData)
+	if err != nil {
+		t.Fatalf("meta data is not a JSON document: %s", err)
+	}
+	if metaData["instance-id"] != "web-1" || metaData["network.encoding"] != "base64" ||
+		metaData["network"] != base64.StdEncoding.EncodeToString([]byte(cloudInit.networkConfig)) {
+		t.Fatalf("unexpected meta data %v", metaData)
+	}
+
+	// Without network configuration, the meta data is delivered as is
+	cloudInit.networkConfig = ""
+	extraConfig, err = cloudInit.guestinfoExtraConfig()
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if extraConfig[2].Value != base64.StdEncoding.EncodeToString([]byte(cloudInit.metaData)) {
+		t.Fatalf("unexpected meta data item %v", extraConfig[2])
+	}
+
+	if cloudInit.hash() == (&vmCloudInit{delivery: cloudInitDeliveryOvfProperties, userData: cloudInit.userData,
+		metaData: cloudInit.metaData}).hash() {
+		t.Fatalf("expected the delivery to change the content hash")
+	}
+}
+
+// Test_vmCloudInitOvfProperties checks the guest properties of the ovf_properties delivery
+func Test_vmCloudInitOvfProperties(t *testing.T) {
+	cloudInit := vmCloudInit{
+		delivery: cloudInitDeliveryOvfProperties,
+		userData: "#cloud-config\n",
+		metaData: "instance-id: web-1\npublic-keys:\n  - ssh-ed25519 AAAA first\n  - ssh-ed25519 BBBB second\n",
+	}
+	properties, err := cloudInit.ovfProperties()
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	expected := map[string]string{
+		"user-data":   base64.StdEncoding.EncodeToString([]byte(cloudInit.userData)),
+		"instance-id": "web-1",
+		"public-keys": "ssh-ed25519 AAAA first\nssh-ed25519 BBBB second",
+	}
+	if len(properties) != len(expected) {
+		t.Fatalf("expected %v, got %v", expected, properties)
+	}
+	for key, value := range expected {
+		if properties[key] != value {
+			t.Errorf("expected '%s' for property %s, got '%s'", value, key, properties[key])
+		}
+	}
+
+	cloudInit.metaData = "instance-id: web-1\nunknown-key: value\n"
+	_, err = cloudInit.ovfProperties()
+	if err == nil || !strings.Contains(err.Error(), "unknown-key") {
+		t.Fatalf("expected error about unsupported meta data key, got %v", err)
+	}
+}